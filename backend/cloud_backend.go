@@ -55,6 +55,7 @@ var backendNames = map[string]newBackend{
 	"s3":      newS3Backend,
 	"azurerm": newAzureRMBackend,
 	"gcs":     newGCSBackend,
+	"swift":   newSwiftBackend,
 	"local":   newLocalBackend,
 }
 
@@ -23,6 +23,11 @@ type s3Backend struct {
 type s3BackendConfig struct {
 	Bucket *string `json:"bucket,omitempty" form_field:"bucket"`
 	Key    *string `json:"key,omitempty" form_field:"key"`
+
+	Endpoint                  *string `json:"endpoint,omitempty" form_field:"endpoint"`
+	ForcePathStyle            *string `json:"force_path_style,omitempty" form_field:"force_path_style"`
+	SkipCredentialsValidation *string `json:"skip_credentials_validation,omitempty" form_field:"skip_credentials_validation"`
+	SkipRegionValidation      *string `json:"skip_region_validation,omitempty" form_field:"skip_region_validation"`
 }
 
 func newS3Backend() (CloudBackend, error) {
@@ -81,6 +86,57 @@ func (b *s3Backend) createS3InputForm() error {
 	}); err != nil {
 		return err
 	}
+	// the following fields are required when the
+	// state is stored in an s3 compatible store
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "endpoint",
+		DisplayName:  "Endpoint",
+		Description:  "The URL of an S3 compatible API endpoint. The AWS S3 endpoint of the region is used if empty.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr(""),
+		Tags:         []string{"backend", "target-undeployed"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "force_path_style",
+		DisplayName:  "Force Path Style",
+		Description:  "Address the bucket as a path of the endpoint URL.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr("false"),
+		Tags:         []string{"backend", "target-undeployed"},
+
+		AcceptedValues:             []string{"true", "false"},
+		AcceptedValuesErrorMessage: "Must be one of \"true\" or \"false\".",
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "skip_credentials_validation",
+		DisplayName:  "Skip Credentials Validation",
+		Description:  "Skip validating the credentials using the AWS STS API.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr("false"),
+		Tags:         []string{"backend", "target-undeployed"},
+
+		AcceptedValues:             []string{"true", "false"},
+		AcceptedValuesErrorMessage: "Must be one of \"true\" or \"false\".",
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "skip_region_validation",
+		DisplayName:  "Skip Region Validation",
+		Description:  "Skip validating the region against the AWS regions.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr("false"),
+		Tags:         []string{"backend", "target-undeployed"},
+
+		AcceptedValues:             []string{"true", "false"},
+		AcceptedValuesErrorMessage: "Must be one of \"true\" or \"false\".",
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -105,6 +161,10 @@ func (b *s3Backend) Copy() (config.Configurable, error) {
 
 	configCopy.Bucket = utils.CopyStrPtr(config.Bucket)
 	configCopy.Key = utils.CopyStrPtr(config.Key)
+	configCopy.Endpoint = utils.CopyStrPtr(config.Endpoint)
+	configCopy.ForcePathStyle = utils.CopyStrPtr(config.ForcePathStyle)
+	configCopy.SkipCredentialsValidation = utils.CopyStrPtr(config.SkipCredentialsValidation)
+	configCopy.SkipRegionValidation = utils.CopyStrPtr(config.SkipRegionValidation)
 
 	return copy, nil
 }
@@ -120,6 +180,10 @@ func (b *s3Backend) IsValid() bool {
 
 // interface: backend/CloudBackend functions
 
+// The backend can be configured using an aws provider or
+// an s3compatible provider. With an s3compatible provider
+// the backend is configured to use the provider's endpoint
+// and not to validate the credentials and region with AWS.
 func (b *s3Backend) Configure(
 	cloudProvider provider.CloudProvider,
 	storagePrefix, stateKey string,
@@ -130,10 +194,14 @@ func (b *s3Backend) Configure(
 
 		inputForm forms.InputForm
 		region    *string
+
+		endpoint,
+		forcePathStyle *string
 	)
 
-	if cloudProvider.Name() != b.providerType {
-		return fmt.Errorf("the s3 backend can only be used with an aws cloud provider")
+	isS3Compatible := cloudProvider.Name() == "s3compatible"
+	if cloudProvider.Name() != b.providerType && !isS3Compatible {
+		return fmt.Errorf("the s3 backend can only be used with an aws or s3compatible cloud provider")
 	}
 	if inputForm, err = cloudProvider.InputForm(); err != nil {
 		return err
@@ -142,7 +210,7 @@ func (b *s3Backend) Configure(
 		return err
 	}
 	if region == nil {
-		return fmt.Errorf("aws provider's region cannot be empty")
+		return fmt.Errorf("%s provider's region cannot be empty", cloudProvider.Name())
 	}
 
 	uid := strings.ReplaceAll(uuid.New().String(), "-", "")
@@ -155,6 +223,19 @@ func (b *s3Backend) Configure(
 	config.Bucket = &bucketName
 	config.Key = &stateKey
 
+	if isS3Compatible {
+		if endpoint, err = inputForm.GetFieldValue("endpoint"); err != nil {
+			return err
+		}
+		if forcePathStyle, err = inputForm.GetFieldValue("force_path_style"); err != nil {
+			return err
+		}
+		config.Endpoint = utils.CopyStrPtr(endpoint)
+		config.ForcePathStyle = utils.CopyStrPtr(forcePathStyle)
+		config.SkipCredentialsValidation = utils.PtrToStr("true")
+		config.SkipRegionValidation = utils.PtrToStr("true")
+	}
+
 	// rebind fields
 	if _, err = b.InputForm(); err != nil {
 		return err
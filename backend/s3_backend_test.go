@@ -86,6 +86,48 @@ var _ = Describe("S3 Backend Tests", func() {
 			value, err = inputForm.GetFieldValue("key")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(*value).To(Equal("mystatekey"))
+
+			value, err = inputForm.GetFieldValue("skip_credentials_validation")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*value).To(Equal("false"))
+		})
+
+		It("can be inititialized using an s3 compatible cloud provider", func() {
+
+			var (
+				inputForm forms.InputForm
+				value     *string
+			)
+			s3CompatibleProvider, err := provider.NewCloudProvider("s3compatible")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s3CompatibleProvider).ToNot(BeNil())
+			test_data.ParseConfigDocument(s3CompatibleProvider, s3CompatibleConfigDocument, "s3CompatibleProvider")
+
+			err = s3Backend.Configure(s3CompatibleProvider, "mybackend", "mystatekey")
+			Expect(err).NotTo(HaveOccurred())
+
+			inputForm, err = s3Backend.InputForm()
+			Expect(err).NotTo(HaveOccurred())
+
+			value, err = inputForm.GetFieldValue("bucket")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*value).To(MatchRegexp(`^mybackend-us-east-1-[0-9a-f]{32}$`))
+
+			value, err = inputForm.GetFieldValue("endpoint")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*value).To(Equal("http://localhost:9000"))
+
+			value, err = inputForm.GetFieldValue("force_path_style")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*value).To(Equal("true"))
+
+			value, err = inputForm.GetFieldValue("skip_credentials_validation")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*value).To(Equal("true"))
+
+			value, err = inputForm.GetFieldValue("skip_region_validation")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*value).To(Equal("true"))
 		})
 	})
 
@@ -105,8 +147,17 @@ Amazon Web Services S3 Storage Backend
 
 ` + term.ITALIC + `CONFIGURATION DATA INPUT REFERENCE` + term.NC + `
 
-* Bucket - The S3 bucket to store state in.
-* Key    - The key with which to identify the state object in the bucket.`
+* Bucket                      - The S3 bucket to store state in.
+* Key                         - The key with which to identify the state object
+                                in the bucket.
+* Endpoint                    - The URL of an S3 compatible API endpoint. The
+                                AWS S3 endpoint of the region is used if empty.
+* Force Path Style            - Address the bucket as a path of the endpoint
+                                URL.
+* Skip Credentials Validation - Skip validating the credentials using the AWS
+                                STS API.
+* Skip Region Validation      - Skip validating the region against the AWS
+                                regions.`
 
 const s3ConfigDocument = `
 {
@@ -123,3 +174,11 @@ const awsConfigDocument = `
 	}
 }
 `
+
+const s3CompatibleConfigDocument = `
+{
+	"cloud": {
+		"s3CompatibleProvider": ` + test_data.S3CompatibleProviderConfig + `
+	}
+}
+`
@@ -0,0 +1,167 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mevansam/gocloud/provider"
+	"github.com/mevansam/goforms/config"
+	"github.com/mevansam/goforms/forms"
+	"github.com/mevansam/goutils/logger"
+	"github.com/mevansam/goutils/utils"
+
+	forms_config "github.com/mevansam/gocloud/forms"
+)
+
+type swiftBackend struct {
+	cloudBackend
+
+	isInitialized bool
+}
+
+type swiftBackendConfig struct {
+	Container *string `json:"container,omitempty" form_field:"container"`
+	StateName *string `json:"state_name,omitempty" form_field:"state_name"`
+}
+
+func newSwiftBackend() (CloudBackend, error) {
+
+	var (
+		err           error
+		beckendConfig swiftBackendConfig
+	)
+
+	backend := &swiftBackend{
+		cloudBackend: cloudBackend{
+			name:         "swift",
+			providerType: "openstack",
+
+			config: &beckendConfig,
+		},
+		isInitialized: false,
+	}
+	err = backend.createSwiftInputForm()
+	return backend, err
+}
+
+func (p *swiftBackend) createSwiftInputForm() error {
+
+	// Do not recreate form template if it exists
+	clougConfig := forms_config.CloudConfigForms
+	if clougConfig.HasGroup(p.name) {
+		return nil
+	}
+
+	var (
+		err  error
+		form *forms.InputGroup
+	)
+
+	form = forms_config.CloudConfigForms.NewGroup(p.name, "OpenStack Swift Storage Backend")
+
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "container",
+		DisplayName: "Container",
+		Description: "The Swift container to store state in.",
+		InputType:   forms.String,
+		Tags:        []string{"backend", "target-undeployed"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "state_name",
+		DisplayName: "State Name",
+		Description: "The name of the state object in the container.",
+		InputType:   forms.String,
+		Tags:        []string{"backend", "target-undeployed"},
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// interface: config/Configurable functions of base cloud backend
+
+func (b *swiftBackend) Copy() (config.Configurable, error) {
+
+	var (
+		err error
+
+		copy CloudBackend
+	)
+
+	if copy, err = newSwiftBackend(); err != nil {
+		return nil, err
+	}
+
+	config := b.cloudBackend.
+		config.(*swiftBackendConfig)
+	configCopy := copy.(*swiftBackend).cloudBackend.
+		config.(*swiftBackendConfig)
+
+	configCopy.Container = utils.CopyStrPtr(config.Container)
+	configCopy.StateName = utils.CopyStrPtr(config.StateName)
+
+	return copy, nil
+}
+
+func (b *swiftBackend) IsValid() bool {
+
+	config := b.cloudBackend.
+		config.(*swiftBackendConfig)
+
+	return config.Container != nil && len(*config.Container) > 0 &&
+		config.StateName != nil && len(*config.StateName) > 0
+}
+
+// interface: backend/CloudBackend functions
+
+func (b *swiftBackend) Configure(
+	cloudProvider provider.CloudProvider,
+	storagePrefix, stateKey string,
+) error {
+
+	var (
+		err error
+
+		inputForm forms.InputForm
+		region    *string
+	)
+
+	if cloudProvider.Name() != b.providerType {
+		return fmt.Errorf("the swift backend can only be used with an openstack cloud provider")
+	}
+	if inputForm, err = cloudProvider.InputForm(); err != nil {
+		return err
+	}
+	if region, err = inputForm.GetFieldValue("region"); err != nil {
+		return err
+	}
+	if region == nil {
+		return fmt.Errorf("openstack provider's region cannot be empty")
+	}
+
+	containerName := strings.ToLower(
+		fmt.Sprintf("%s-%s", storagePrefix, *region),
+	)
+
+	config := b.cloudBackend.
+		config.(*swiftBackendConfig)
+	config.Container = &containerName
+	config.StateName = &stateKey
+
+	// rebind fields
+	if _, err = b.InputForm(); err != nil {
+		return err
+	}
+	logger.TraceMessage(
+		"Swift backend configured using provider attributes: %# v",
+		config)
+
+	return nil
+}
+
+func (b *swiftBackend) GetStorageInstanceName() string {
+	return *b.cloudBackend.config.(*swiftBackendConfig).Container
+}
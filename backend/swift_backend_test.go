@@ -0,0 +1,130 @@
+package backend_test
+
+import (
+	"strings"
+
+	"github.com/mevansam/gocloud/backend"
+	"github.com/mevansam/gocloud/provider"
+	"github.com/mevansam/goforms/forms"
+	"github.com/mevansam/goutils/term"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_data "github.com/mevansam/gocloud/test/data"
+)
+
+var _ = Describe("OpenStack Swift Storage Backend Tests", func() {
+
+	var (
+		err error
+
+		outputBuffer strings.Builder
+		swiftBackend backend.CloudBackend
+	)
+
+	BeforeEach(func() {
+		outputBuffer.Reset()
+
+		swiftBackend, err = backend.NewCloudBackend("swift")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(swiftBackend).ToNot(BeNil())
+	})
+
+	Context("swift backend config inputs", func() {
+
+		It("outputs a detailed input data form reference for swift backend config inputs", func() {
+			testConfigReferenceOutput(swiftBackend, swiftInputDataReferenceOutput)
+		})
+
+		It("loads configuration values", func() {
+
+			test_data.ParseConfigDocument(swiftBackend, swiftConfigDocument, "swiftBackend")
+			test_data.ValidateSwiftConfigDocument(swiftBackend)
+
+			// Run some negative tests
+			_, err = swiftBackend.GetValue("non_existent_key")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("saves configuration values", func() {
+			test_data.ParseConfigDocument(swiftBackend, swiftConfigDocument, "swiftBackend")
+			test_data.MarshalConfigDocumentAndValidate(swiftBackend, "swiftBackend", swiftConfigDocument)
+		})
+	})
+
+	Context("initialization", func() {
+
+		It("the cloud provider must match the backend cloud requirement", func() {
+
+			googleProvider, err := provider.NewCloudProvider("google")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(googleProvider).ToNot(BeNil())
+
+			err = swiftBackend.Configure(googleProvider, "mybackend", "mystatekey")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("can be inititialized using the correct cloud provider", func() {
+
+			var (
+				inputForm forms.InputForm
+				value     *string
+			)
+			openStackProvider, err := provider.NewCloudProvider("openstack")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(openStackProvider).ToNot(BeNil())
+			test_data.ParseConfigDocument(openStackProvider, openStackConfigDocument, "openStackProvider")
+
+			err = swiftBackend.Configure(openStackProvider, "mybackend", "mystatekey")
+			Expect(err).NotTo(HaveOccurred())
+
+			inputForm, err = swiftBackend.InputForm()
+			Expect(err).NotTo(HaveOccurred())
+
+			value, err = inputForm.GetFieldValue("container")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*value).To(Equal("mybackend-regiontwo"))
+
+			value, err = inputForm.GetFieldValue("state_name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*value).To(Equal("mystatekey"))
+
+			Expect(swiftBackend.GetStorageInstanceName()).To(Equal("mybackend-regiontwo"))
+		})
+	})
+
+	Context("copying", func() {
+
+		It("can creates a copy of itself", func() {
+			test_data.ParseConfigDocument(swiftBackend, swiftConfigDocument, "swiftBackend")
+			test_data.CopyConfigAndValidate(swiftBackend, "container", "mystatecontainer", "newcontainer")
+		})
+	})
+})
+
+const swiftInputDataReferenceOutput = term.BOLD + `Cloud Backend Configuration
+===========================` + term.NC + `
+
+OpenStack Swift Storage Backend
+
+` + term.ITALIC + `CONFIGURATION DATA INPUT REFERENCE` + term.NC + `
+
+* Container  - The Swift container to store state in.
+* State Name - The name of the state object in the container.`
+
+const swiftConfigDocument = `
+{
+	"cloud": {
+		"swiftBackend": ` + test_data.SwiftBackendConfig + `
+	}
+}
+`
+
+const openStackConfigDocument = `
+{
+	"cloud": {
+		"openStackProvider": ` + test_data.OpenStackProviderConfig + `
+	}
+}
+`
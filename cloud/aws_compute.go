@@ -1,7 +1,11 @@
 package cloud
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
@@ -11,7 +15,18 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 )
 
+// state reason code of instances
+// that have been hibernated
+const awsHibernatedStateReason = "Client.UserInitiatedHibernate"
+
 type AWSComputeProperties struct {
+	// timeout for start/stop operations
+	OpTimeout time.Duration
+
+	// maximum number of instance state waits
+	// to run concurrently for batch operations
+	MaxConcurrency int
+
 	FilterTags map[string]string
 }
 
@@ -25,6 +40,12 @@ type awsComputeInstance struct {
 	session  *session.Session
 	instance *ec2.Instance
 	name     string
+
+	// guards the instance detail which is refreshed
+	// by operations running asynchronously
+	mx sync.RWMutex
+
+	props *AWSComputeProperties
 }
 
 func NewAWSCompute(
@@ -35,6 +56,9 @@ func NewAWSCompute(
 		session: session,
 
 		props: AWSComputeProperties{
+			OpTimeout:      defaultOpTimeout,
+			MaxConcurrency: defaultMaxConcurrency,
+
 			FilterTags: make(map[string]string),
 		},
 	}, nil
@@ -51,6 +75,8 @@ func (c *awsCompute) newAWSComputeInstance(
 				session:  session,
 				instance: instance,
 				name:     *v.Value,
+
+				props: &c.props,
 			}, nil
 		}
 	}
@@ -65,6 +91,12 @@ func (c *awsCompute) newAWSComputeInstance(
 func (c *awsCompute) SetProperties(props interface{}) {
 
 	p := props.(AWSComputeProperties)
+	if p.OpTimeout != 0 {
+		c.props.OpTimeout = p.OpTimeout
+	}
+	if p.MaxConcurrency != 0 {
+		c.props.MaxConcurrency = p.MaxConcurrency
+	}
 	if p.FilterTags != nil {
 		c.props.FilterTags = p.FilterTags
 	}
@@ -217,10 +249,127 @@ func (c *awsCompute) listInstances(filters []*ec2.Filter) ([]ComputeInstance, er
 	return computeInstances, nil
 }
 
+func (c *awsCompute) ListSizes() ([]InstanceSize, error) {
+
+	var (
+		err error
+	)
+	svc := ec2.New(c.session)
+
+	sizes := []InstanceSize{}
+	if err = svc.DescribeInstanceTypesPages(&ec2.DescribeInstanceTypesInput{},
+		func(page *ec2.DescribeInstanceTypesOutput, lastPage bool) bool {
+			for _, t := range page.InstanceTypes {
+				size := InstanceSize{
+					Name: aws.StringValue(t.InstanceType),
+				}
+				if t.VCpuInfo != nil {
+					size.VCPUs = int(aws.Int64Value(t.VCpuInfo.DefaultVCpus))
+				}
+				if t.MemoryInfo != nil {
+					size.MemoryMB = int(aws.Int64Value(t.MemoryInfo.SizeInMiB))
+				}
+				sizes = append(sizes, size)
+			}
+			return true
+		},
+	); err != nil {
+		return nil, err
+	}
+	sortInstanceSizes(sizes)
+	return sizes, nil
+}
+
+func (c *awsCompute) StartInstances(ids []string) ([]InstanceResult, error) {
+
+	var (
+		err error
+	)
+	svc := ec2.New(c.session)
+
+	if _, err = svc.StartInstances(&ec2.StartInstancesInput{
+		InstanceIds: aws.StringSlice(ids),
+	}); err != nil {
+		// a single invalid instance fails the whole
+		// request so fallback to starting each
+		// instance individually
+		logger.DebugMessage(
+			"Starting instances %v in a single request failed. Starting each instance individually: %s",
+			ids, err.Error(),
+		)
+		return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+			return instance.Start()
+		})
+	}
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.WaitForState(context.Background(), StateRunning, 0)
+	})
+}
+
+func (c *awsCompute) StopInstances(ids []string) ([]InstanceResult, error) {
+
+	var (
+		err error
+	)
+	svc := ec2.New(c.session)
+
+	if _, err = svc.StopInstances(&ec2.StopInstancesInput{
+		InstanceIds: aws.StringSlice(ids),
+	}); err != nil {
+		logger.DebugMessage(
+			"Stopping instances %v in a single request failed. Stopping each instance individually: %s",
+			ids, err.Error(),
+		)
+		return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+			return instance.Stop()
+		})
+	}
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.WaitForState(context.Background(), StateStopped, 0)
+	})
+}
+
+func (c *awsCompute) RestartInstances(ids []string) ([]InstanceResult, error) {
+
+	var (
+		err error
+	)
+	svc := ec2.New(c.session)
+
+	if _, err = svc.RebootInstances(&ec2.RebootInstancesInput{
+		InstanceIds: aws.StringSlice(ids),
+	}); err != nil {
+		logger.DebugMessage(
+			"Restarting instances %v in a single request failed. Restarting each instance individually: %s",
+			ids, err.Error(),
+		)
+		return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+			return instance.Restart()
+		})
+	}
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.WaitForState(context.Background(), StateRunning, 0)
+	})
+}
+
+// returns the last retrieved instance detail
+func (c *awsComputeInstance) detail() *ec2.Instance {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+	return c.instance
+}
+
+// replaces the instance detail with a refreshed copy
+func (c *awsComputeInstance) setDetail(instance *ec2.Instance) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.instance = instance
+}
+
 // interface: cloud/ComputeInstance implementation
 
 func (c *awsComputeInstance) ID() string {
-	return *c.instance.InstanceId
+	return *c.detail().InstanceId
 }
 
 func (c *awsComputeInstance) Name() string {
@@ -228,16 +377,24 @@ func (c *awsComputeInstance) Name() string {
 }
 
 func (c *awsComputeInstance) PublicIP() string {
-	if c.instance.PublicIpAddress != nil {
-		return *c.instance.PublicIpAddress
+	if instance := c.detail(); instance.PublicIpAddress != nil {
+		return *instance.PublicIpAddress
 	} else {
 		return ""
 	}
 }
 
 func (c *awsComputeInstance) PublicDNS() string {
-	if c.instance.PublicIpAddress != nil {
-		return *c.instance.PublicDnsName
+	if instance := c.detail(); instance.PublicIpAddress != nil {
+		return *instance.PublicDnsName
+	} else {
+		return ""
+	}
+}
+
+func (c *awsComputeInstance) PrivateIP() string {
+	if instance := c.detail(); instance.PrivateIpAddress != nil {
+		return *instance.PrivateIpAddress
 	} else {
 		return ""
 	}
@@ -253,7 +410,7 @@ func (c *awsComputeInstance) State() (InstanceState, error) {
 	svc := ec2.New(c.session)
 
 	if describeResult, err = svc.DescribeInstances(&ec2.DescribeInstancesInput{
-		InstanceIds: []*string{c.instance.InstanceId},
+		InstanceIds: []*string{c.detail().InstanceId},
 	}); err != nil {
 		return StateUnknown, err
 	}
@@ -263,15 +420,22 @@ func (c *awsComputeInstance) State() (InstanceState, error) {
 		return StateUnknown, fmt.Errorf(
 			fmt.Sprintf(
 				"unable to retrieve state for instance with id '%s', as it was not found",
-				*c.instance.InstanceId),
+				*c.detail().InstanceId),
 		)
 	}
-	c.instance = (*describeResult.Reservations[0]).Instances[0]
+	instance := (*describeResult.Reservations[0]).Instances[0]
+	c.setDetail(instance)
 
-	switch *c.instance.State.Name {
+	switch *instance.State.Name {
 	case "running":
 		return StateRunning, nil
 	case "stopped":
+		// a hibernated instance is stopped with
+		// the reason that it was hibernated
+		if instance.StateReason != nil &&
+			aws.StringValue(instance.StateReason.Code) == awsHibernatedStateReason {
+			return StateSuspended, nil
+		}
 		return StateStopped, nil
 	case "pending", "shutting-down", "stopping":
 		return StatePending, nil
@@ -284,60 +448,222 @@ func (c *awsComputeInstance) Start() error {
 
 	var (
 		err error
+
+		op Operation
 	)
-	svc := ec2.New(c.session)
 
-	if _, err = svc.StartInstances(&ec2.StartInstancesInput{
-		InstanceIds: []*string{c.instance.InstanceId},
-	}); err != nil {
+	if op, err = c.StartAsync(); err != nil {
 		return err
 	}
-	if err = svc.WaitUntilInstanceRunning(&ec2.DescribeInstancesInput{
-		InstanceIds: []*string{c.instance.InstanceId},
-	}); err != nil {
+	return op.Wait(context.Background())
+}
+
+func (c *awsComputeInstance) Restart() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.RestartAsync(); err != nil {
 		return err
 	}
-	return nil
+	return op.Wait(context.Background())
 }
 
-func (c *awsComputeInstance) Restart() error {
+func (c *awsComputeInstance) Stop() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.StopAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *awsComputeInstance) StartAsync() (Operation, error) {
 
 	var (
 		err error
 	)
 	svc := ec2.New(c.session)
 
-	if _, err = svc.RebootInstances(&ec2.RebootInstancesInput{
-		InstanceIds: []*string{c.instance.InstanceId},
+	logger.TraceMessage(
+		"Starting instance '%s'.", *c.detail().InstanceId)
+
+	if _, err = svc.StartInstances(&ec2.StartInstancesInput{
+		InstanceIds: []*string{c.detail().InstanceId},
 	}); err != nil {
-		return err
+		return nil, err
 	}
-	if err = svc.WaitUntilInstanceRunning(&ec2.DescribeInstancesInput{
-		InstanceIds: []*string{c.instance.InstanceId},
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return svc.WaitUntilInstanceRunningWithContext(ctx, &ec2.DescribeInstancesInput{
+			InstanceIds: []*string{c.detail().InstanceId},
+		})
+	}), nil
+}
+
+func (c *awsComputeInstance) RestartAsync() (Operation, error) {
+
+	var (
+		err error
+	)
+	svc := ec2.New(c.session)
+
+	logger.TraceMessage(
+		"Restarting instance '%s'.", *c.detail().InstanceId)
+
+	if _, err = svc.RebootInstances(&ec2.RebootInstancesInput{
+		InstanceIds: []*string{c.detail().InstanceId},
 	}); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return svc.WaitUntilInstanceRunningWithContext(ctx, &ec2.DescribeInstancesInput{
+			InstanceIds: []*string{c.detail().InstanceId},
+		})
+	}), nil
 }
 
-func (c *awsComputeInstance) Stop() error {
+func (c *awsComputeInstance) StopAsync() (Operation, error) {
 
 	var (
 		err error
 	)
 	svc := ec2.New(c.session)
 
+	logger.TraceMessage(
+		"Stopping instance '%s'.", *c.detail().InstanceId)
+
 	if _, err = svc.StopInstances(&ec2.StopInstancesInput{
-		InstanceIds: []*string{c.instance.InstanceId},
+		InstanceIds: []*string{c.detail().InstanceId},
 	}); err != nil {
+		return nil, err
+	}
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return svc.WaitUntilInstanceStoppedWithContext(ctx, &ec2.DescribeInstancesInput{
+			InstanceIds: []*string{c.detail().InstanceId},
+		})
+	}), nil
+}
+
+func (c *awsComputeInstance) Capabilities() (InstanceCapabilities, error) {
+
+	var (
+		err error
+	)
+
+	// refresh instance detail
+	if _, err = c.State(); err != nil {
+		return InstanceCapabilities{}, err
+	}
+	return InstanceCapabilities{
+		SuspendResume: c.detail().HibernationOptions != nil &&
+			aws.BoolValue(c.detail().HibernationOptions.Configured),
+	}, nil
+}
+
+func (c *awsComputeInstance) Suspend() error {
+
+	var (
+		err error
+
+		capabilities InstanceCapabilities
+	)
+	svc := ec2.New(c.session)
+
+	if capabilities, err = c.Capabilities(); err != nil {
 		return err
 	}
-	if err = svc.WaitUntilInstanceStopped(&ec2.DescribeInstancesInput{
-		InstanceIds: []*string{c.instance.InstanceId},
+	if !capabilities.SuspendResume {
+		return fmt.Errorf(
+			"instance '%s' has not been configured for hibernation",
+			*c.detail().InstanceId,
+		)
+	}
+
+	logger.TraceMessage(
+		"Hibernating instance '%s'.", *c.detail().InstanceId)
+
+	if _, err = svc.StopInstances(&ec2.StopInstancesInput{
+		InstanceIds: []*string{c.detail().InstanceId},
+		Hibernate:   aws.Bool(true),
 	}); err != nil {
 		return err
 	}
-	return nil
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return svc.WaitUntilInstanceStoppedWithContext(ctx, &ec2.DescribeInstancesInput{
+			InstanceIds: []*string{c.detail().InstanceId},
+		})
+	}).Wait(context.Background())
+}
+
+func (c *awsComputeInstance) Resume() error {
+	// a hibernated instance is
+	// resumed by starting it
+	return c.Start()
+}
+
+func (c *awsComputeInstance) Resize(size string, autoRestart bool) error {
+
+	svc := ec2.New(c.session)
+
+	return resizeInstance(c, autoRestart, true, func() error {
+
+		logger.TraceMessage(
+			"Changing type of instance '%s' to '%s'.",
+			*c.detail().InstanceId, size,
+		)
+
+		_, err := svc.ModifyInstanceAttribute(&ec2.ModifyInstanceAttributeInput{
+			InstanceId: c.detail().InstanceId,
+			InstanceType: &ec2.AttributeValue{
+				Value: aws.String(size),
+			},
+		})
+		return err
+	})
+}
+
+func (c *awsComputeInstance) ConsoleOutput() (string, error) {
+
+	var (
+		err error
+
+		consoleResult *ec2.GetConsoleOutputOutput
+		output        []byte
+	)
+	svc := ec2.New(c.session)
+
+	if consoleResult, err = svc.GetConsoleOutput(&ec2.GetConsoleOutputInput{
+		InstanceId: c.detail().InstanceId,
+	}); err != nil {
+		return "", err
+	}
+	if consoleResult.Output == nil {
+		return "", nil
+	}
+	if output, err = base64.StdEncoding.DecodeString(*consoleResult.Output); err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+func (c *awsComputeInstance) WaitForState(
+	ctx context.Context,
+	state InstanceState,
+	timeout time.Duration,
+) error {
+
+	if timeout == 0 {
+		timeout = c.props.OpTimeout
+	}
+	return waitForState(ctx, c, state, timeout)
 }
 
 func (c *awsComputeInstance) CanConnect(port int) bool {
@@ -349,3 +675,11 @@ func (c *awsComputeInstance) CanConnect(port int) bool {
 		return false
 	}
 }
+
+func (c *awsComputeInstance) HealthCheck(ctx context.Context, check HealthCheck) HealthCheckResult {
+	return CheckHealth(ctx, c, check)
+}
+
+func (c *awsComputeInstance) WaitUntilHealthy(ctx context.Context, checks ...HealthCheck) error {
+	return WaitUntilHealthy(ctx, c, checks...)
+}
@@ -88,6 +88,15 @@ var _ = Describe("AWS Compute Tests", func() {
 			}
 		})
 
+		It("stops and starts a batch of compute instances", func() {
+
+			instanceIds := []string{}
+			for _, ec2Instance := range testInstances {
+				instanceIds = append(instanceIds, *ec2Instance.InstanceId)
+			}
+			testBatchStopAndStart(awsCompute, instanceIds, "i-00000000000000000")
+		})
+
 		It("retrieves a compute instance", func() {
 
 			_, err := awsCompute.GetInstance("test-X")
@@ -156,5 +165,68 @@ var _ = Describe("AWS Compute Tests", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(state).To(Equal(cloud.StateRunning))
 		})
+
+		It("stops and starts compute instances asynchronously", func() {
+			testAsyncStopAndStart(instance0, instance1)
+		})
+
+		It("suspends and resumes a compute instance if supported", func() {
+			testSuspendAndResume(instance0)
+		})
+
+		It("retrieves the console output of a compute instance", func() {
+
+			output, err := instance0.ConsoleOutput()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(output)).To(BeNumerically(">", 0))
+		})
+
+		It("resizes a compute instance", func() {
+			testResize(awsCompute, instance1, "t3.nano", "t3.micro")
+		})
+
+		It("retrieves the scheduling of a compute instance", func() {
+			testOnDemandScheduling(instance0)
+
+			Expect(cloud.AWSInstanceMarketOptions(cloud.InstanceScheduling{})).To(BeNil())
+
+			options := cloud.AWSInstanceMarketOptions(cloud.InstanceScheduling{
+				PurchaseModel:      cloud.PurchaseSpot,
+				MaxPrice:           0.05,
+				InterruptionAction: cloud.InterruptStop,
+			})
+			Expect(*options.MarketType).To(Equal(ec2.MarketTypeSpot))
+			Expect(*options.SpotOptions.MaxPrice).To(Equal("0.05"))
+			Expect(*options.SpotOptions.SpotInstanceType).To(Equal(ec2.SpotInstanceTypePersistent))
+			Expect(*options.SpotOptions.InstanceInterruptionBehavior).To(Equal(ec2.InstanceInterruptionBehaviorStop))
+		})
+
+		It("finds public images", func() {
+			testFindImages(awsCompute)
+		})
+
+		It("creates and deletes an image of a compute instance", func() {
+			testCreateAndDeleteImage(awsCompute, instance1)
+		})
+
+		It("adds and removes firewall rules of a compute instance", func() {
+			testFirewallRules(instance0)
+		})
+
+		It("sets and retrieves the user data of a compute instance", func() {
+			testUserData(instance1)
+		})
+
+		It("allocates, associates and releases a static ip", func() {
+			testStaticIPs(awsCompute, instance1, map[string]string{
+				"Role": "Cloudbuilder-Test",
+			}, false)
+		})
+
+		It("creates, attaches, snapshots and detaches a volume", func() {
+			testVolumes(awsCompute, instance0, map[string]string{
+				"Role": "Cloudbuilder-Test",
+			})
+		})
 	})
 })
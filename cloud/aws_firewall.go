@@ -0,0 +1,201 @@
+package cloud
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mevansam/goutils/logger"
+)
+
+func newAWSFirewallRule(rule *ec2.SecurityGroupRule) (FirewallRule, bool) {
+
+	r := FirewallRule{
+		ID:          *rule.SecurityGroupRuleId,
+		Description: aws.StringValue(rule.Description),
+		Protocol:    aws.StringValue(rule.IpProtocol),
+		FromPort:    int(aws.Int64Value(rule.FromPort)),
+		ToPort:      int(aws.Int64Value(rule.ToPort)),
+	}
+	switch {
+	case rule.CidrIpv4 != nil:
+		r.CIDR = *rule.CidrIpv4
+	case rule.CidrIpv6 != nil:
+		r.CIDR = *rule.CidrIpv6
+	default:
+		// rules with a security group or prefix
+		// list as their source are not returned
+		return r, false
+	}
+
+	// a protocol of -1 is all protocols and
+	// port -1 is all ports or icmp types
+	if r.Protocol == "-1" {
+		r.Protocol = ProtocolAll
+	}
+	if r.FromPort == -1 {
+		r.FromPort = 0
+	}
+	if r.ToPort == -1 {
+		r.ToPort = 65535
+	}
+	r.Expires = parseFirewallRuleExpiry(r.Description)
+	return r, true
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *awsComputeInstance) FirewallRules() ([]FirewallRule, error) {
+
+	var (
+		err error
+	)
+	svc := ec2.New(c.session)
+
+	groupIDs := []*string{}
+	for _, group := range c.detail().SecurityGroups {
+		groupIDs = append(groupIDs, group.GroupId)
+	}
+	rules := []FirewallRule{}
+	if len(groupIDs) == 0 {
+		return rules, nil
+	}
+
+	if err = svc.DescribeSecurityGroupRulesPages(
+		&ec2.DescribeSecurityGroupRulesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("group-id"),
+					Values: groupIDs,
+				},
+			},
+		},
+		func(page *ec2.DescribeSecurityGroupRulesOutput, lastPage bool) bool {
+			for _, rule := range page.SecurityGroupRules {
+				if !aws.BoolValue(rule.IsEgress) {
+					if r, ok := newAWSFirewallRule(rule); ok {
+						rules = append(rules, r)
+					}
+				}
+			}
+			return true
+		},
+	); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Ingress rules are added to the first security group
+// of the instance so they will also apply to any other
+// instances that share that group.
+func (c *awsComputeInstance) AddIngressRule(spec IngressRuleSpec) (FirewallRule, error) {
+
+	var (
+		err error
+
+		authorizeResult *ec2.AuthorizeSecurityGroupIngressOutput
+	)
+	svc := ec2.New(c.session)
+
+	if spec, err = spec.normalize(); err != nil {
+		return FirewallRule{}, err
+	}
+	securityGroups := c.detail().SecurityGroups
+	if len(securityGroups) == 0 {
+		return FirewallRule{}, fmt.Errorf("instance '%s' has no security groups", c.name)
+	}
+	groupID := securityGroups[0].GroupId
+
+	permission := &ec2.IpPermission{
+		IpProtocol: aws.String(spec.Protocol),
+	}
+	switch spec.Protocol {
+	case ProtocolAll:
+		permission.IpProtocol = aws.String("-1")
+	case ProtocolICMP:
+		permission.FromPort = aws.Int64(-1)
+		permission.ToPort = aws.Int64(-1)
+	default:
+		permission.FromPort = aws.Int64(int64(spec.Port))
+		permission.ToPort = aws.Int64(int64(spec.Port))
+	}
+	if ip, _, _ := net.ParseCIDR(spec.CIDR); ip.To4() != nil {
+		permission.IpRanges = []*ec2.IpRange{
+			{
+				CidrIp:      aws.String(spec.CIDR),
+				Description: aws.String(spec.Description),
+			},
+		}
+	} else {
+		permission.Ipv6Ranges = []*ec2.Ipv6Range{
+			{
+				CidrIpv6:    aws.String(spec.CIDR),
+				Description: aws.String(spec.Description),
+			},
+		}
+	}
+
+	logger.TraceMessage(
+		"Adding ingress rule for %s port %d from '%s' to security group '%s' of instance '%s'.",
+		spec.Protocol, spec.Port, spec.CIDR, *groupID, c.name,
+	)
+
+	if authorizeResult, err = svc.AuthorizeSecurityGroupIngress(&ec2.AuthorizeSecurityGroupIngressInput{
+		GroupId:       groupID,
+		IpPermissions: []*ec2.IpPermission{permission},
+	}); err != nil {
+		return FirewallRule{}, err
+	}
+	if len(authorizeResult.SecurityGroupRules) == 0 {
+		return FirewallRule{}, fmt.Errorf("no rule was added to security group '%s'", *groupID)
+	}
+	rule, _ := newAWSFirewallRule(authorizeResult.SecurityGroupRules[0])
+	return rule, nil
+}
+
+func (c *awsComputeInstance) RemoveFirewallRule(rule FirewallRule) error {
+
+	var (
+		err error
+
+		describeResult *ec2.DescribeSecurityGroupRulesOutput
+	)
+	svc := ec2.New(c.session)
+
+	if describeResult, err = svc.DescribeSecurityGroupRules(&ec2.DescribeSecurityGroupRulesInput{
+		SecurityGroupRuleIds: []*string{aws.String(rule.ID)},
+	}); err != nil {
+		return err
+	}
+	if len(describeResult.SecurityGroupRules) == 0 {
+		return fmt.Errorf("security group rule '%s' not found", rule.ID)
+	}
+	groupID := describeResult.SecurityGroupRules[0].GroupId
+
+	// only rules of the instance's own security
+	// groups may be removed via the instance
+	attached := false
+	for _, securityGroup := range c.detail().SecurityGroups {
+		if aws.StringValue(securityGroup.GroupId) == aws.StringValue(groupID) {
+			attached = true
+			break
+		}
+	}
+	if !attached {
+		return fmt.Errorf(
+			"security group rule '%s' does not belong to a security group of instance '%s'",
+			rule.ID, c.name,
+		)
+	}
+
+	logger.TraceMessage(
+		"Removing ingress rule '%s' from security group '%s'.", rule.ID, *groupID)
+
+	_, err = svc.RevokeSecurityGroupIngress(&ec2.RevokeSecurityGroupIngressInput{
+		GroupId:              groupID,
+		SecurityGroupRuleIds: []*string{aws.String(rule.ID)},
+	})
+	return err
+}
@@ -0,0 +1,198 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mevansam/goutils/logger"
+)
+
+func newAWSImage(image *ec2.Image, source *imageSource) Image {
+
+	i := Image{
+		ID:   *image.ImageId,
+		Name: aws.StringValue(image.Name),
+		Arch: normalizeArch(aws.StringValue(image.Architecture)),
+	}
+	if source != nil {
+		i.OS = source.os
+		i.Version = source.version
+	}
+	if image.CreationDate != nil {
+		i.Created, _ = time.Parse(time.RFC3339, *image.CreationDate)
+	}
+	return i
+}
+
+// interface: cloud/Compute implementation
+
+func (c *awsCompute) FindImages(filter ImageFilter) ([]Image, error) {
+
+	var (
+		err error
+
+		source       *imageSource
+		imagesResult *ec2.DescribeImagesOutput
+	)
+	svc := ec2.New(c.session)
+
+	input := &ec2.DescribeImagesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("state"),
+				Values: []*string{aws.String("available")},
+			},
+		},
+	}
+	if filter.Owned {
+		input.Owners = []*string{aws.String("self")}
+		if len(filter.Name) > 0 {
+			input.Filters = append(input.Filters, &ec2.Filter{
+				Name:   aws.String("name"),
+				Values: []*string{aws.String(filter.Name + "*")},
+			})
+		}
+		if len(filter.Arch) > 0 {
+			input.Filters = append(input.Filters, &ec2.Filter{
+				Name:   aws.String("architecture"),
+				Values: []*string{aws.String(filter.Arch)},
+			})
+		}
+	} else {
+		if source, err = lookupImageSource(filter); err != nil {
+			return nil, err
+		}
+		input.Owners = []*string{aws.String(source.awsOwner)}
+		input.Filters = append(input.Filters, &ec2.Filter{
+			Name:   aws.String("name"),
+			Values: []*string{aws.String(source.awsName)},
+		})
+	}
+
+	if imagesResult, err = svc.DescribeImages(input); err != nil {
+		return nil, err
+	}
+	images := make([]Image, 0, len(imagesResult.Images))
+	for _, image := range imagesResult.Images {
+		images = append(images, newAWSImage(image, source))
+	}
+	sortImages(images)
+	return images, nil
+}
+
+func (c *awsCompute) CreateImage(instance ComputeInstance, name string) (Image, error) {
+
+	var (
+		err error
+		ok  bool
+
+		awsInstance  *awsComputeInstance
+		createResult *ec2.CreateImageOutput
+		imagesResult *ec2.DescribeImagesOutput
+	)
+	svc := ec2.New(c.session)
+
+	if awsInstance, ok = instance.(*awsComputeInstance); !ok {
+		return Image{}, fmt.Errorf("instance '%s' is not an AWS EC2 instance", instance.Name())
+	}
+
+	detail := awsInstance.detail()
+
+	tags := []*ec2.Tag{
+		{
+			Key:   aws.String("Name"),
+			Value: aws.String(name),
+		},
+	}
+	for _, t := range detail.Tags {
+		if *t.Key != "Name" {
+			tags = append(tags, t)
+		}
+	}
+
+	logger.TraceMessage(
+		"Creating image '%s' from instance '%s'.", name, instance.Name())
+
+	// the instance is rebooted to ensure
+	// the file system is consistent
+	if createResult, err = svc.CreateImage(&ec2.CreateImageInput{
+		InstanceId:  detail.InstanceId,
+		Name:        aws.String(name),
+		Description: aws.String(fmt.Sprintf("image of instance '%s'", instance.Name())),
+		TagSpecifications: []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeImage),
+				Tags:         tags,
+			},
+			{
+				ResourceType: aws.String(ec2.ResourceTypeSnapshot),
+				Tags:         tags,
+			},
+		},
+	}); err != nil {
+		return Image{}, err
+	}
+
+	input := &ec2.DescribeImagesInput{
+		ImageIds: []*string{createResult.ImageId},
+	}
+	if err = newOperation(defaultImageOpTimeout, func(ctx context.Context) error {
+		return svc.WaitUntilImageAvailableWithContext(ctx, input)
+	}).Wait(context.Background()); err != nil {
+		return Image{}, err
+	}
+	if imagesResult, err = svc.DescribeImages(input); err != nil {
+		return Image{}, err
+	}
+	if len(imagesResult.Images) == 0 {
+		return Image{}, fmt.Errorf("image '%s' not found", *createResult.ImageId)
+	}
+	return newAWSImage(imagesResult.Images[0], nil), nil
+}
+
+func (c *awsCompute) DeleteImage(id string) error {
+
+	var (
+		err error
+
+		imagesResult *ec2.DescribeImagesOutput
+	)
+	svc := ec2.New(c.session)
+
+	if imagesResult, err = svc.DescribeImages(&ec2.DescribeImagesInput{
+		ImageIds: []*string{aws.String(id)},
+		Owners:   []*string{aws.String("self")},
+	}); err != nil {
+		return err
+	}
+	if len(imagesResult.Images) == 0 {
+		return fmt.Errorf("image '%s' not found", id)
+	}
+
+	logger.TraceMessage("Deregistering image '%s'.", id)
+
+	if _, err = svc.DeregisterImage(&ec2.DeregisterImageInput{
+		ImageId: aws.String(id),
+	}); err != nil {
+		return err
+	}
+
+	// the ebs snapshots backing the image
+	// are not deleted with the image
+	for _, mapping := range imagesResult.Images[0].BlockDeviceMappings {
+		if mapping.Ebs != nil && mapping.Ebs.SnapshotId != nil {
+			logger.TraceMessage(
+				"Deleting snapshot '%s' of image '%s'.", *mapping.Ebs.SnapshotId, id)
+
+			if _, err = svc.DeleteSnapshot(&ec2.DeleteSnapshotInput{
+				SnapshotId: mapping.Ebs.SnapshotId,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package cloud
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// time between an ec2 spot interruption
+// notice and the interruption
+const awsSpotInterruptionWarning = time.Minute * 2
+
+// Returns the market options to request spot capacity
+// when launching an EC2 instance with the given
+// scheduling. Returns nil for on-demand capacity.
+func AWSInstanceMarketOptions(scheduling InstanceScheduling) *ec2.InstanceMarketOptionsRequest {
+
+	if scheduling.PurchaseModel == PurchaseOnDemand {
+		return nil
+	}
+
+	spotOptions := &ec2.SpotMarketOptions{
+		SpotInstanceType:             aws.String(ec2.SpotInstanceTypeOneTime),
+		InstanceInterruptionBehavior: aws.String(ec2.InstanceInterruptionBehaviorTerminate),
+	}
+	if scheduling.MaxPrice > 0 {
+		spotOptions.MaxPrice = aws.String(strconv.FormatFloat(scheduling.MaxPrice, 'f', -1, 64))
+	}
+	// spot instances that are stopped or hibernated
+	// on interruption require a persistent request
+	switch scheduling.InterruptionAction {
+	case InterruptStop:
+		spotOptions.SpotInstanceType = aws.String(ec2.SpotInstanceTypePersistent)
+		spotOptions.InstanceInterruptionBehavior = aws.String(ec2.InstanceInterruptionBehaviorStop)
+	case InterruptHibernate:
+		spotOptions.SpotInstanceType = aws.String(ec2.SpotInstanceTypePersistent)
+		spotOptions.InstanceInterruptionBehavior = aws.String(ec2.InstanceInterruptionBehaviorHibernate)
+	}
+
+	return &ec2.InstanceMarketOptionsRequest{
+		MarketType:  aws.String(ec2.MarketTypeSpot),
+		SpotOptions: spotOptions,
+	}
+}
+
+// returns the spot request of the instance
+// or nil if it is not a spot instance
+func (c *awsComputeInstance) spotRequest() (*ec2.SpotInstanceRequest, error) {
+
+	var (
+		err error
+
+		describeResult *ec2.DescribeSpotInstanceRequestsOutput
+	)
+	svc := ec2.New(c.session)
+
+	if c.detail().SpotInstanceRequestId == nil {
+		return nil, nil
+	}
+	if describeResult, err = svc.DescribeSpotInstanceRequests(&ec2.DescribeSpotInstanceRequestsInput{
+		SpotInstanceRequestIds: []*string{c.detail().SpotInstanceRequestId},
+	}); err != nil {
+		return nil, err
+	}
+	if len(describeResult.SpotInstanceRequests) == 0 {
+		return nil, nil
+	}
+	return describeResult.SpotInstanceRequests[0], nil
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *awsComputeInstance) Scheduling() (InstanceScheduling, error) {
+
+	var (
+		err error
+
+		request *ec2.SpotInstanceRequest
+	)
+
+	scheduling := InstanceScheduling{
+		PurchaseModel: PurchaseOnDemand,
+	}
+	if aws.StringValue(c.detail().InstanceLifecycle) != ec2.InstanceLifecycleTypeSpot {
+		return scheduling, nil
+	}
+	scheduling.PurchaseModel = PurchaseSpot
+	scheduling.InterruptionAction = InterruptTerminate
+
+	if request, err = c.spotRequest(); err != nil || request == nil {
+		return scheduling, err
+	}
+	if request.SpotPrice != nil {
+		if scheduling.MaxPrice, err = strconv.ParseFloat(*request.SpotPrice, 64); err != nil {
+			return scheduling, err
+		}
+	}
+	if request.InstanceInterruptionBehavior != nil {
+		scheduling.InterruptionAction = *request.InstanceInterruptionBehavior
+	}
+	return scheduling, nil
+}
+
+func (c *awsComputeInstance) InterruptionNotice() (InterruptionNotice, error) {
+
+	var (
+		err error
+
+		request *ec2.SpotInstanceRequest
+	)
+
+	notice := InterruptionNotice{}
+	if request, err = c.spotRequest(); err != nil || request == nil || request.Status == nil {
+		return notice, err
+	}
+
+	// the spot request status code reflects the
+	// instance-action returned by the instance
+	// metadata service from within the instance
+	//
+	// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/spot-request-status.html
+	code := aws.StringValue(request.Status.Code)
+	switch {
+	case strings.HasPrefix(code, "marked-for-"):
+		notice.Interrupted = true
+		if request.Status.UpdateTime != nil {
+			notice.Time = request.Status.UpdateTime.Add(awsSpotInterruptionWarning)
+		}
+	case strings.HasPrefix(code, "instance-terminated-"),
+		strings.HasPrefix(code, "instance-stopped-"),
+		strings.HasPrefix(code, "instance-hibernated-"):
+		notice.Interrupted = !strings.HasSuffix(code, "-by-user") &&
+			!strings.HasSuffix(code, "-by-experiment")
+		if request.Status.UpdateTime != nil {
+			notice.Time = *request.Status.UpdateTime
+		}
+	default:
+		return notice, nil
+	}
+	if !notice.Interrupted {
+		return InterruptionNotice{}, nil
+	}
+
+	switch {
+	case strings.Contains(code, "stop"):
+		notice.Action = InterruptStop
+	case strings.Contains(code, "hibernat"):
+		notice.Action = InterruptHibernate
+	default:
+		notice.Action = InterruptTerminate
+	}
+	notice.Message = aws.StringValue(request.Status.Message)
+	return notice, nil
+}
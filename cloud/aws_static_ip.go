@@ -0,0 +1,184 @@
+package cloud
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mevansam/goutils/logger"
+)
+
+func newAWSStaticIP(address *ec2.Address) StaticIP {
+
+	ip := StaticIP{
+		ID:         aws.StringValue(address.AllocationId),
+		Address:    aws.StringValue(address.PublicIp),
+		InstanceID: aws.StringValue(address.InstanceId),
+	}
+	for _, t := range address.Tags {
+		if *t.Key == "Name" {
+			ip.Name = aws.StringValue(t.Value)
+		}
+	}
+	return ip
+}
+
+// returns the elastic ip with the given allocation id
+func (c *awsComputeInstance) elasticIP(allocationID string) (*ec2.Address, error) {
+
+	var (
+		err error
+
+		describeResult *ec2.DescribeAddressesOutput
+	)
+	svc := ec2.New(c.session)
+
+	if describeResult, err = svc.DescribeAddresses(&ec2.DescribeAddressesInput{
+		AllocationIds: []*string{aws.String(allocationID)},
+	}); err != nil {
+		return nil, err
+	}
+	if len(describeResult.Addresses) == 0 {
+		return nil, fmt.Errorf("elastic ip '%s' not found", allocationID)
+	}
+	return describeResult.Addresses[0], nil
+}
+
+// interface: cloud/Compute implementation
+
+func (c *awsCompute) AllocateStaticIP(name string, tags map[string]string) (StaticIP, error) {
+
+	var (
+		err error
+
+		allocateResult *ec2.AllocateAddressOutput
+	)
+	svc := ec2.New(c.session)
+
+	ec2Tags := []*ec2.Tag{
+		{
+			Key:   aws.String("Name"),
+			Value: aws.String(name),
+		},
+	}
+	for k, v := range tags {
+		ec2Tags = append(ec2Tags, &ec2.Tag{
+			Key:   aws.String(k),
+			Value: aws.String(v),
+		})
+	}
+
+	logger.TraceMessage("Allocating elastic ip '%s'.", name)
+
+	if allocateResult, err = svc.AllocateAddress(&ec2.AllocateAddressInput{
+		Domain: aws.String(ec2.DomainTypeVpc),
+		TagSpecifications: []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeElasticIp),
+				Tags:         ec2Tags,
+			},
+		},
+	}); err != nil {
+		return StaticIP{}, err
+	}
+	return StaticIP{
+		ID:      *allocateResult.AllocationId,
+		Name:    name,
+		Address: aws.StringValue(allocateResult.PublicIp),
+	}, nil
+}
+
+func (c *awsCompute) ListStaticIPs() ([]StaticIP, error) {
+
+	var (
+		err error
+
+		describeResult *ec2.DescribeAddressesOutput
+	)
+	svc := ec2.New(c.session)
+
+	if describeResult, err = svc.DescribeAddresses(&ec2.DescribeAddressesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("domain"),
+				Values: []*string{aws.String(ec2.DomainTypeVpc)},
+			},
+		},
+	}); err != nil {
+		return nil, err
+	}
+	ips := make([]StaticIP, 0, len(describeResult.Addresses))
+	for _, address := range describeResult.Addresses {
+		ips = append(ips, newAWSStaticIP(address))
+	}
+	return ips, nil
+}
+
+func (c *awsCompute) ReleaseStaticIP(ip StaticIP) error {
+
+	svc := ec2.New(c.session)
+
+	logger.TraceMessage("Releasing elastic ip '%s' (%s).", ip.ID, ip.Address)
+
+	_, err := svc.ReleaseAddress(&ec2.ReleaseAddressInput{
+		AllocationId: aws.String(ip.ID),
+	})
+	return err
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *awsComputeInstance) AssociateStaticIP(ip StaticIP) error {
+
+	var (
+		err error
+	)
+	svc := ec2.New(c.session)
+
+	logger.TraceMessage(
+		"Associating elastic ip '%s' (%s) with instance '%s'.", ip.ID, ip.Address, c.name)
+
+	if _, err = svc.AssociateAddress(&ec2.AssociateAddressInput{
+		AllocationId:       aws.String(ip.ID),
+		InstanceId:         c.detail().InstanceId,
+		AllowReassociation: aws.Bool(false),
+	}); err != nil {
+		return err
+	}
+	// refresh the instance's public ip and dns
+	_, err = c.State()
+	return err
+}
+
+// On disassociation the instance is assigned a new
+// ephemeral public IP if it is in a subnet that
+// assigns public IPs to instances.
+func (c *awsComputeInstance) DisassociateStaticIP(ip StaticIP) error {
+
+	var (
+		err error
+
+		address *ec2.Address
+	)
+	svc := ec2.New(c.session)
+
+	if address, err = c.elasticIP(ip.ID); err != nil {
+		return err
+	}
+	if address.AssociationId == nil ||
+		aws.StringValue(address.InstanceId) != *c.detail().InstanceId {
+		return fmt.Errorf("elastic ip '%s' is not associated with instance '%s'", ip.ID, c.name)
+	}
+
+	logger.TraceMessage(
+		"Disassociating elastic ip '%s' (%s) from instance '%s'.", ip.ID, ip.Address, c.name)
+
+	if _, err = svc.DisassociateAddress(&ec2.DisassociateAddressInput{
+		AssociationId: address.AssociationId,
+	}); err != nil {
+		return err
+	}
+	// refresh the instance's public ip and dns
+	_, err = c.State()
+	return err
+}
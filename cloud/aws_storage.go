@@ -27,6 +27,11 @@ type AWSStorageProperties struct {
 	// Concurrency
 	UploadConcurrency   int
 	DownloadConcurrency int
+
+	// buckets are created without object locking
+	// and a public access block which are not
+	// supported by all s3 compatible stores
+	S3Compatible bool
 }
 
 type awsStorage struct {
@@ -76,6 +81,9 @@ func (s *awsStorage) SetProperties(props interface{}) {
 	if p.DownloadConcurrency > 0 {
 		s.props.DownloadConcurrency = p.DownloadConcurrency
 	}
+	if p.S3Compatible {
+		s.props.S3Compatible = true
+	}
 }
 
 func (s *awsStorage) NewInstance(name string) (StorageInstance, error) {
@@ -112,7 +120,7 @@ func (s *awsStorage) NewInstance(name string) (StorageInstance, error) {
 
 					ACL:                        aws.String("private"),
 					CreateBucketConfiguration:  bucketConfiguration,
-					ObjectLockEnabledForBucket: aws.Bool(true),
+					ObjectLockEnabledForBucket: aws.Bool(!s.props.S3Compatible),
 				}); err != nil {
 					return nil, err
 				}
@@ -121,17 +129,19 @@ func (s *awsStorage) NewInstance(name string) (StorageInstance, error) {
 				}); err != nil {
 					return nil, err
 				}
-				if _, err = svc.PutPublicAccessBlock(&s3.PutPublicAccessBlockInput{
-					Bucket: aws.String(name),
-
-					PublicAccessBlockConfiguration: &s3.PublicAccessBlockConfiguration{
-						BlockPublicAcls:       aws.Bool(true),
-						BlockPublicPolicy:     aws.Bool(true),
-						IgnorePublicAcls:      aws.Bool(true),
-						RestrictPublicBuckets: aws.Bool(true),
-					},
-				}); err != nil {
-					return nil, err
+				if !s.props.S3Compatible {
+					if _, err = svc.PutPublicAccessBlock(&s3.PutPublicAccessBlockInput{
+						Bucket: aws.String(name),
+
+						PublicAccessBlockConfiguration: &s3.PublicAccessBlockConfiguration{
+							BlockPublicAcls:       aws.Bool(true),
+							BlockPublicPolicy:     aws.Bool(true),
+							IgnorePublicAcls:      aws.Bool(true),
+							RestrictPublicBuckets: aws.Bool(true),
+						},
+					}); err != nil {
+						return nil, err
+					}
 				}
 
 			} else {
@@ -0,0 +1,59 @@
+package cloud
+
+import (
+	"encoding/base64"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mevansam/goutils/logger"
+)
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *awsComputeInstance) UserData() (string, error) {
+
+	var (
+		err error
+
+		attributeResult *ec2.DescribeInstanceAttributeOutput
+		data            []byte
+	)
+	svc := ec2.New(c.session)
+
+	if attributeResult, err = svc.DescribeInstanceAttribute(&ec2.DescribeInstanceAttributeInput{
+		InstanceId: c.detail().InstanceId,
+		Attribute:  aws.String(ec2.InstanceAttributeNameUserData),
+	}); err != nil {
+		return "", err
+	}
+	if attributeResult.UserData == nil || attributeResult.UserData.Value == nil {
+		return "", nil
+	}
+	if data, err = base64.StdEncoding.DecodeString(*attributeResult.UserData.Value); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (c *awsComputeInstance) SetUserData(data string) error {
+
+	var (
+		err error
+	)
+	svc := ec2.New(c.session)
+
+	if err = requireStopped(c); err != nil {
+		return err
+	}
+
+	logger.TraceMessage("Setting user data of instance '%s'.", c.name)
+
+	// the sdk base64 encodes the value
+	_, err = svc.ModifyInstanceAttribute(&ec2.ModifyInstanceAttributeInput{
+		InstanceId: c.detail().InstanceId,
+		UserData: &ec2.BlobAttributeValue{
+			Value: []byte(data),
+		},
+	})
+	return err
+}
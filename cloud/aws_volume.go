@@ -0,0 +1,384 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mevansam/goutils/logger"
+)
+
+// default type of EBS volumes
+const awsDefaultVolumeType = "gp3"
+
+type awsVolume struct {
+	session *session.Session
+	volume  *ec2.Volume
+	name    string
+	boot    bool
+
+	props *AWSComputeProperties
+}
+
+func newAWSVolume(
+	session *session.Session,
+	volume *ec2.Volume,
+	props *AWSComputeProperties,
+) *awsVolume {
+
+	name := *volume.VolumeId
+	for _, v := range volume.Tags {
+		if *v.Key == "Name" {
+			name = *v.Value
+			break
+		}
+	}
+	return &awsVolume{
+		session: session,
+		volume:  volume,
+		name:    name,
+
+		props: props,
+	}
+}
+
+// reloads the volume's state
+func (v *awsVolume) refresh() error {
+
+	var (
+		err error
+
+		describeResult *ec2.DescribeVolumesOutput
+	)
+	svc := ec2.New(v.session)
+
+	if describeResult, err = svc.DescribeVolumes(&ec2.DescribeVolumesInput{
+		VolumeIds: []*string{v.volume.VolumeId},
+	}); err != nil {
+		return err
+	}
+	if len(describeResult.Volumes) == 0 {
+		return fmt.Errorf("volume '%s' not found", *v.volume.VolumeId)
+	}
+	v.volume = describeResult.Volumes[0]
+	return nil
+}
+
+// interface: cloud/Compute implementation
+
+func (c *awsCompute) CreateVolume(spec VolumeSpec) (Volume, error) {
+
+	var (
+		err error
+
+		zonesResult *ec2.DescribeAvailabilityZonesOutput
+		volume      *ec2.Volume
+	)
+	svc := ec2.New(c.session)
+
+	zone := spec.Zone
+	if len(zone) == 0 {
+		if zonesResult, err = svc.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("state"),
+					Values: []*string{aws.String("available")},
+				},
+			},
+		}); err != nil {
+			return nil, err
+		}
+		if len(zonesResult.AvailabilityZones) == 0 {
+			return nil, fmt.Errorf("no available zones found in region '%s'", *c.session.Config.Region)
+		}
+		zone = *zonesResult.AvailabilityZones[0].ZoneName
+	}
+	volumeType := spec.Type
+	if len(volumeType) == 0 {
+		volumeType = awsDefaultVolumeType
+	}
+
+	tags := []*ec2.Tag{
+		{
+			Key:   aws.String("Name"),
+			Value: aws.String(spec.Name),
+		},
+	}
+	for k, v := range spec.Tags {
+		tags = append(tags, &ec2.Tag{
+			Key:   aws.String(k),
+			Value: aws.String(v),
+		})
+	}
+
+	logger.TraceMessage(
+		"Creating %dGB volume '%s' of type '%s' in zone '%s'.",
+		spec.SizeGB, spec.Name, volumeType, zone,
+	)
+
+	if volume, err = svc.CreateVolume(&ec2.CreateVolumeInput{
+		AvailabilityZone: aws.String(zone),
+		Size:             aws.Int64(int64(spec.SizeGB)),
+		VolumeType:       aws.String(volumeType),
+		TagSpecifications: []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeVolume),
+				Tags:         tags,
+			},
+		},
+	}); err != nil {
+		return nil, err
+	}
+	if err = newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return svc.WaitUntilVolumeAvailableWithContext(ctx, &ec2.DescribeVolumesInput{
+			VolumeIds: []*string{volume.VolumeId},
+		})
+	}).Wait(context.Background()); err != nil {
+		return nil, err
+	}
+
+	v := newAWSVolume(c.session, volume, &c.props)
+	if err = v.refresh(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *awsComputeInstance) Volumes() ([]Volume, error) {
+
+	var (
+		err error
+	)
+	svc := ec2.New(c.session)
+
+	volumes := []Volume{}
+	if err = svc.DescribeVolumesPages(
+		&ec2.DescribeVolumesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("attachment.instance-id"),
+					Values: []*string{c.detail().InstanceId},
+				},
+			},
+		},
+		func(output *ec2.DescribeVolumesOutput, lastPage bool) bool {
+			for _, volume := range output.Volumes {
+				v := newAWSVolume(c.session, volume, c.props)
+				for _, attachment := range volume.Attachments {
+					if c.detail().RootDeviceName != nil && attachment.Device != nil &&
+						*attachment.Device == *c.detail().RootDeviceName {
+						v.boot = true
+					}
+				}
+				volumes = append(volumes, v)
+			}
+			return true
+		},
+	); err != nil {
+		return nil, err
+	}
+	return volumes, nil
+}
+
+func (c *awsComputeInstance) AttachVolume(volume Volume) error {
+
+	var (
+		err error
+		ok  bool
+
+		v       *awsVolume
+		volumes []Volume
+		device  string
+	)
+	svc := ec2.New(c.session)
+
+	if v, ok = volume.(*awsVolume); !ok {
+		return fmt.Errorf("volume '%s' is not an AWS EBS volume", volume.Name())
+	}
+
+	// find the next device name not in use. data volumes
+	// are attached as /dev/sdf through /dev/sdp as
+	// recommended for EBS backed linux instances.
+	if volumes, err = c.Volumes(); err != nil {
+		return err
+	}
+	inUse := make(map[string]bool)
+	for _, attached := range volumes {
+		for _, attachment := range attached.(*awsVolume).volume.Attachments {
+			inUse[*attachment.Device] = true
+		}
+	}
+	for l := 'f'; l <= 'p'; l++ {
+		if d := fmt.Sprintf("/dev/sd%c", l); !inUse[d] {
+			device = d
+			break
+		}
+	}
+	if len(device) == 0 {
+		return fmt.Errorf(
+			"no device names available to attach volume '%s' to instance '%s'",
+			v.name, c.name,
+		)
+	}
+
+	logger.TraceMessage(
+		"Attaching volume '%s' to instance '%s' as '%s'.",
+		v.name, c.name, device,
+	)
+
+	if _, err = svc.AttachVolume(&ec2.AttachVolumeInput{
+		Device:     aws.String(device),
+		InstanceId: c.detail().InstanceId,
+		VolumeId:   v.volume.VolumeId,
+	}); err != nil {
+		return err
+	}
+	if err = newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return svc.WaitUntilVolumeInUseWithContext(ctx, &ec2.DescribeVolumesInput{
+			VolumeIds: []*string{v.volume.VolumeId},
+		})
+	}).Wait(context.Background()); err != nil {
+		return err
+	}
+	return v.refresh()
+}
+
+func (c *awsComputeInstance) DetachVolume(volume Volume) error {
+
+	var (
+		err error
+		ok  bool
+
+		v *awsVolume
+	)
+	svc := ec2.New(c.session)
+
+	if v, ok = volume.(*awsVolume); !ok {
+		return fmt.Errorf("volume '%s' is not an AWS EBS volume", volume.Name())
+	}
+	if v.boot {
+		return fmt.Errorf("the boot volume of instance '%s' cannot be detached", c.name)
+	}
+
+	logger.TraceMessage(
+		"Detaching volume '%s' from instance '%s'.",
+		v.name, c.name,
+	)
+
+	if _, err = svc.DetachVolume(&ec2.DetachVolumeInput{
+		InstanceId: c.detail().InstanceId,
+		VolumeId:   v.volume.VolumeId,
+	}); err != nil {
+		return err
+	}
+	if err = newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return svc.WaitUntilVolumeAvailableWithContext(ctx, &ec2.DescribeVolumesInput{
+			VolumeIds: []*string{v.volume.VolumeId},
+		})
+	}).Wait(context.Background()); err != nil {
+		return err
+	}
+	return v.refresh()
+}
+
+// interface: cloud/Volume implementation
+
+func (v *awsVolume) ID() string {
+	return *v.volume.VolumeId
+}
+
+func (v *awsVolume) Name() string {
+	return v.name
+}
+
+func (v *awsVolume) SizeGB() int {
+	return int(*v.volume.Size)
+}
+
+func (v *awsVolume) Type() string {
+	return *v.volume.VolumeType
+}
+
+func (v *awsVolume) Zone() string {
+	return *v.volume.AvailabilityZone
+}
+
+func (v *awsVolume) Attached() bool {
+	return len(v.volume.Attachments) > 0
+}
+
+func (v *awsVolume) Boot() bool {
+	return v.boot
+}
+
+func (v *awsVolume) Snapshot() (VolumeSnapshot, error) {
+
+	var (
+		err error
+
+		snapshot *ec2.Snapshot
+	)
+	svc := ec2.New(v.session)
+
+	name := snapshotName(v.name)
+	tags := []*ec2.Tag{
+		{
+			Key:   aws.String("Name"),
+			Value: aws.String(name),
+		},
+	}
+	for _, t := range v.volume.Tags {
+		if *t.Key != "Name" {
+			tags = append(tags, t)
+		}
+	}
+
+	logger.TraceMessage(
+		"Creating snapshot '%s' of volume '%s'.", name, v.name)
+
+	// the snapshot captures the volume's data at the point
+	// it is created but completes in the background
+	if snapshot, err = svc.CreateSnapshot(&ec2.CreateSnapshotInput{
+		VolumeId:    v.volume.VolumeId,
+		Description: aws.String(fmt.Sprintf("snapshot of volume '%s'", v.name)),
+		TagSpecifications: []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeSnapshot),
+				Tags:         tags,
+			},
+		},
+	}); err != nil {
+		return VolumeSnapshot{}, err
+	}
+	return VolumeSnapshot{
+		ID:       *snapshot.SnapshotId,
+		Name:     name,
+		VolumeID: *v.volume.VolumeId,
+		SizeGB:   int(*snapshot.VolumeSize),
+	}, nil
+}
+
+func (v *awsVolume) Delete() error {
+
+	var (
+		err error
+	)
+	svc := ec2.New(v.session)
+
+	logger.TraceMessage("Deleting volume '%s'.", v.name)
+
+	if _, err = svc.DeleteVolume(&ec2.DeleteVolumeInput{
+		VolumeId: v.volume.VolumeId,
+	}); err != nil {
+		return err
+	}
+	return newOperation(v.props.OpTimeout, func(ctx context.Context) error {
+		return svc.WaitUntilVolumeDeletedWithContext(ctx, &ec2.DescribeVolumesInput{
+			VolumeIds: []*string{v.volume.VolumeId},
+		})
+	}).Wait(context.Background())
+}
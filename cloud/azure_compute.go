@@ -2,14 +2,18 @@ package cloud
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"path"
 	"strings"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
 
@@ -17,14 +21,41 @@ import (
 	"github.com/mevansam/goutils/network"
 )
 
+// determines how an azure VM is stopped
+type AzureStopMode int
+
+const (
+	// power off and deallocate the VM so that
+	// compute resources are no longer billed
+	AzureStopDeallocate = AzureStopMode(1)
+	// power off the VM but keep its compute
+	// resources allocated for a faster start
+	AzureStopPowerOff = AzureStopMode(2)
+)
+
+type AzureComputeProperties struct {
+	// timeout for start/stop operations
+	OpTimeout time.Duration
+
+	// how VMs are stopped. the
+	// default is to deallocate.
+	StopMode AzureStopMode
+
+	// maximum number of instance operations
+	// to run concurrently for batch operations
+	MaxConcurrency int
+}
+
 type azureCompute struct {
 	resourceGroupName,
 	locationName,
 	subscriptionID string
 
 	ctx         context.Context	
-	clientCreds *azidentity.ClientSecretCredential
+	clientCreds azcore.TokenCredential
 	clientOpts  *arm.ClientOptions
+
+	props AzureComputeProperties
 }
 
 type azureComputeInstance struct {
@@ -33,18 +64,24 @@ type azureComputeInstance struct {
 
 	publicIP,
 	publicDNS,
+	privateIP,
+
+	// name of the primary network interface
+	nicName,
 
 	resourceGroupName,
 	subscriptionID string
 
 	ctx         context.Context	
-	clientCreds *azidentity.ClientSecretCredential
+	clientCreds azcore.TokenCredential
 	clientOpts  *arm.ClientOptions
+
+	props *AzureComputeProperties
 }
 
 func NewAzureCompute(
 	ctx context.Context,
-	clientCreds *azidentity.ClientSecretCredential,
+	clientCreds azcore.TokenCredential,
 	clientOpts *arm.ClientOptions,
 	resourceGroupName,
 	locationName,
@@ -60,6 +97,13 @@ func NewAzureCompute(
 		ctx:         ctx,
 		clientCreds: clientCreds,
 		clientOpts:  clientOpts,
+
+		props: AzureComputeProperties{
+			OpTimeout:      defaultOpTimeout,
+			MaxConcurrency: defaultMaxConcurrency,
+
+			StopMode: AzureStopDeallocate,
+		},
 	}, nil
 }
 
@@ -81,7 +125,8 @@ func (c *azureCompute) newAzureComputeInstance(
 
 		ipName,
 		publicIP,
-		publicFQDN string
+		publicFQDN,
+		privateIP string
 	)
 
 	logger.TraceMessage(
@@ -126,6 +171,10 @@ func (c *azureCompute) newAzureComputeInstance(
 				if ipConfig.Properties.PublicIPAddress != nil {
 					ipName = path.Base(*ipConfig.Properties.PublicIPAddress.ID)
 				}
+				if ipConfig.Properties.PrivateIPAddress != nil &&
+					(len(privateIP) == 0 || (ipConfig.Properties.Primary != nil && *ipConfig.Properties.Primary)) {
+					privateIP = *ipConfig.Properties.PrivateIPAddress
+				}
 			}
 		}
 
@@ -150,6 +199,9 @@ func (c *azureCompute) newAzureComputeInstance(
 
 		publicIP: publicIP,
 		publicDNS: publicFQDN,
+		privateIP: privateIP,
+
+		nicName: nicName,
 
 		resourceGroupName: resourceGroupName,
 		subscriptionID:    c.subscriptionID,
@@ -157,12 +209,25 @@ func (c *azureCompute) newAzureComputeInstance(
 		ctx:         c.ctx,
 		clientCreds: c.clientCreds,
 		clientOpts:  c.clientOpts,
+
+		props: &c.props,
 	}, nil
 }
 
 // interface: cloud/Compute implementation
 
 func (c *azureCompute) SetProperties(props interface{}) {
+
+	p := props.(AzureComputeProperties)
+	if p.OpTimeout != 0 {
+		c.props.OpTimeout = p.OpTimeout
+	}
+	if p.MaxConcurrency != 0 {
+		c.props.MaxConcurrency = p.MaxConcurrency
+	}
+	if p.StopMode != 0 {
+		c.props.StopMode = p.StopMode
+	}
 }
 
 func (c *azureCompute) GetInstance(name string) (ComputeInstance, error) {
@@ -297,6 +362,60 @@ func (c *azureCompute) listInstances(
 	return instances, nil
 }
 
+func (c *azureCompute) ListSizes() ([]InstanceSize, error) {
+
+	var (
+		err error
+
+		client *armcompute.VirtualMachineSizesClient
+		resp   armcompute.VirtualMachineSizesClientListResponse
+	)
+
+	if client, err = armcompute.NewVirtualMachineSizesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return nil, err
+	}
+
+	listSizes := client.NewListPager(c.locationName, nil)
+	sizes := []InstanceSize{}
+	for listSizes.More() {
+		if resp, err = listSizes.NextPage(c.ctx); err != nil {
+			return nil, err
+		}
+		for _, s := range resp.Value {
+			size := InstanceSize{
+				Name: *s.Name,
+			}
+			if s.NumberOfCores != nil {
+				size.VCPUs = int(*s.NumberOfCores)
+			}
+			if s.MemoryInMB != nil {
+				size.MemoryMB = int(*s.MemoryInMB)
+			}
+			sizes = append(sizes, size)
+		}
+	}
+	sortInstanceSizes(sizes)
+	return sizes, nil
+}
+
+func (c *azureCompute) StartInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Start()
+	})
+}
+
+func (c *azureCompute) StopInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Stop()
+	})
+}
+
+func (c *azureCompute) RestartInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Restart()
+	})
+}
+
 // interface: cloud/ComputeInstance implementation
 
 func (c *azureComputeInstance) ID() string {
@@ -315,6 +434,10 @@ func (c *azureComputeInstance) PublicDNS() string {
 	return c.publicDNS
 }
 
+func (c *azureComputeInstance) PrivateIP() string {
+	return c.privateIP
+}
+
 func (c *azureComputeInstance) State() (InstanceState, error) {
 
 	var (
@@ -336,6 +459,13 @@ func (c *azureComputeInstance) State() (InstanceState, error) {
 
 	if resp.Statuses != nil {
 		statuses := resp.Statuses
+		// a hibernated VM is deallocated and
+		// has an additional hibernation status
+		for _, s := range statuses {
+			if s.Code != nil && strings.EqualFold(*s.Code, "HibernationState/Hibernated") {
+				return StateSuspended, nil
+			}
+		}
 		if len(statuses) > 1 {
 			status := statuses[len(statuses)-1].DisplayStatus
 			if status != nil {
@@ -355,6 +485,48 @@ func (c *azureComputeInstance) State() (InstanceState, error) {
 
 func (c *azureComputeInstance) Start() error {
 
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.StartAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *azureComputeInstance) Restart() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.RestartAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *azureComputeInstance) Stop() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.StopAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *azureComputeInstance) StartAsync() (Operation, error) {
+
 	var (
 		err error
 
@@ -363,23 +535,22 @@ func (c *azureComputeInstance) Start() error {
 	)
 
 	if client, err = armcompute.NewVirtualMachinesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
-		return err
+		return nil, err
 	}
 
 	logger.TraceMessage("Starting azure VM '%s' in resource group '%s'.",
 		c.name, c.resourceGroupName)
 
 	if presp, err = client.BeginStart(c.ctx, c.resourceGroupName, c.name, nil); err != nil {
-		return err
+		return nil, err
 	}
-	if _, err = presp.PollUntilDone(c.ctx, nil); err != nil {
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		_, err := presp.PollUntilDone(ctx, nil)
 		return err
-	}
-
-	return err
+	}), nil
 }
 
-func (c *azureComputeInstance) Restart() error {
+func (c *azureComputeInstance) RestartAsync() (Operation, error) {
 
 	var (
 		err error
@@ -389,59 +560,275 @@ func (c *azureComputeInstance) Restart() error {
 	)
 
 	if client, err = armcompute.NewVirtualMachinesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
-		return err
+		return nil, err
 	}
 
 	logger.TraceMessage("Restarting azure VM '%s' in resource group '%s'.",
 		c.name, c.resourceGroupName)
 
 	if presp, err = client.BeginRestart(c.ctx, c.resourceGroupName, c.name, nil); err != nil {
-		return err
+		return nil, err
 	}
-	if _, err = presp.PollUntilDone(c.ctx, nil); err != nil {
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		_, err := presp.PollUntilDone(ctx, nil)
 		return err
-	}
-
-	return err
+	}), nil
 }
 
-func (c *azureComputeInstance) Stop() error {
+func (c *azureComputeInstance) StopAsync() (Operation, error) {
 
 	var (
 		err error
 
 		client *armcompute.VirtualMachinesClient
 
-		pOffResp     *runtime.Poller[armcompute.VirtualMachinesClientPowerOffResponse]
-		pDeallocResp *runtime.Poller[armcompute.VirtualMachinesClientDeallocateResponse]
+		pOffResp *runtime.Poller[armcompute.VirtualMachinesClientPowerOffResponse]
 	)
 
 	if client, err = armcompute.NewVirtualMachinesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
-		return err
+		return nil, err
 	}
 
 	logger.TraceMessage("Powering off azure VM '%s' in resource group '%s'.",
 		c.name, c.resourceGroupName)
 
 	if pOffResp, err = client.BeginPowerOff(c.ctx, c.resourceGroupName, c.name, nil); err != nil {
+		return nil, err
+	}
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+
+		var (
+			err error
+
+			pDeallocResp *runtime.Poller[armcompute.VirtualMachinesClientDeallocateResponse]
+		)
+
+		if _, err = pOffResp.PollUntilDone(ctx, nil); err != nil {
+			return err
+		}
+		if c.props.StopMode == AzureStopPowerOff {
+			return nil
+		}
+
+		logger.TraceMessage("Deallocating azure VM '%s' in resource group '%s'.",
+			c.name, c.resourceGroupName)
+
+		if pDeallocResp, err = client.BeginDeallocate(ctx, c.resourceGroupName, c.name, nil); err != nil {
+			return err
+		}
+		_, err = pDeallocResp.PollUntilDone(ctx, nil)
+		return err
+	}), nil
+}
+
+func (c *azureComputeInstance) Capabilities() (InstanceCapabilities, error) {
+
+	var (
+		err error
+
+		client *armcompute.VirtualMachinesClient
+		resp   armcompute.VirtualMachinesClientGetResponse
+	)
+
+	if client, err = armcompute.NewVirtualMachinesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return InstanceCapabilities{}, err
+	}
+	if resp, err = client.Get(c.ctx, c.resourceGroupName, c.name, nil); err != nil {
+		return InstanceCapabilities{}, err
+	}
+
+	capabilities := InstanceCapabilities{}
+	if resp.Properties != nil && resp.Properties.AdditionalCapabilities != nil {
+		capabilities.SuspendResume = resp.Properties.AdditionalCapabilities.HibernationEnabled != nil &&
+			*resp.Properties.AdditionalCapabilities.HibernationEnabled
+	}
+	return capabilities, nil
+}
+
+func (c *azureComputeInstance) Suspend() error {
+
+	var (
+		err error
+
+		capabilities InstanceCapabilities
+
+		client       *armcompute.VirtualMachinesClient
+		pDeallocResp *runtime.Poller[armcompute.VirtualMachinesClientDeallocateResponse]
+	)
+
+	if capabilities, err = c.Capabilities(); err != nil {
 		return err
 	}
-	if _, err = pOffResp.PollUntilDone(c.ctx, nil); err != nil {
+	if !capabilities.SuspendResume {
+		return fmt.Errorf(
+			"azure VM '%s' in resource group '%s' does not have hibernation enabled",
+			c.name, c.resourceGroupName,
+		)
+	}
+	if client, err = armcompute.NewVirtualMachinesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
 		return err
 	}
 
-	logger.TraceMessage("Deallocating azure VM '%s' in resource group '%s'.",
+	logger.TraceMessage("Hibernating azure VM '%s' in resource group '%s'.",
 		c.name, c.resourceGroupName)
 
-	if pDeallocResp, err = client.BeginDeallocate(c.ctx, c.resourceGroupName, c.name, nil); err != nil {
+	if pDeallocResp, err = client.BeginDeallocate(c.ctx, c.resourceGroupName, c.name,
+		&armcompute.VirtualMachinesClientBeginDeallocateOptions{
+			Hibernate: to.Ptr(true),
+		},
+	); err != nil {
 		return err
 	}
-	if _, err = pDeallocResp.PollUntilDone(c.ctx, nil); err != nil {
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		_, err := pDeallocResp.PollUntilDone(ctx, nil)
+		return err
+	}).Wait(context.Background())
+}
+
+func (c *azureComputeInstance) Resume() error {
+	// a hibernated VM is
+	// resumed by starting it
+	return c.Start()
+}
+
+func (c *azureComputeInstance) Resize(size string, autoRestart bool) error {
+
+	var (
+		err error
+
+		client *armcompute.VirtualMachinesClient
+	)
+
+	if client, err = armcompute.NewVirtualMachinesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
 		return err
 	}
+
+	resize := func() error {
+
+		var (
+			err error
+
+			presp *runtime.Poller[armcompute.VirtualMachinesClientUpdateResponse]
+		)
+
+		logger.TraceMessage("Resizing azure VM '%s' in resource group '%s' to '%s'.",
+			c.name, c.resourceGroupName, size)
+
+		if presp, err = client.BeginUpdate(c.ctx, c.resourceGroupName, c.name,
+			armcompute.VirtualMachineUpdate{
+				Properties: &armcompute.VirtualMachineProperties{
+					HardwareProfile: &armcompute.HardwareProfile{
+						VMSize: to.Ptr(armcompute.VirtualMachineSizeTypes(size)),
+					},
+				},
+			},
+			nil,
+		); err != nil {
+			return err
+		}
+		return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+			_, err := presp.PollUntilDone(ctx, nil)
+			return err
+		}).Wait(context.Background())
+	}
+
+	// a running azure VM is resized in place if the new size
+	// is available on its current hardware cluster. it is
+	// only stopped, which may release its dynamic public ip,
+	// if the size is not available on that cluster.
+	if err = resizeInstance(c, autoRestart, false, resize); err != nil &&
+		autoRestart && isAzureSizeNotAvailable(err) {
+
+		logger.DebugMessage(
+			"Size '%s' is not available on the hardware cluster of azure VM '%s'. Stopping it to resize it: %s",
+			size, c.name, err.Error(),
+		)
+		return resizeInstance(c, autoRestart, true, resize)
+	}
 	return err
 }
 
+// returns whether the given error is the error returned
+// when a VM is resized to a size that is not available
+// on the hardware cluster the VM is running on
+func isAzureSizeNotAvailable(err error) bool {
+
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+	return respErr.ErrorCode == "AllocationFailed" ||
+		(respErr.ErrorCode == "OperationNotAllowed" &&
+			strings.Contains(err.Error(), "hardware cluster"))
+}
+
+func (c *azureComputeInstance) ConsoleOutput() (string, error) {
+
+	var (
+		err error
+
+		client *armcompute.VirtualMachinesClient
+		resp   armcompute.VirtualMachinesClientRetrieveBootDiagnosticsDataResponse
+
+		logReq  *http.Request
+		logResp *http.Response
+		output  []byte
+	)
+
+	if client, err = armcompute.NewVirtualMachinesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return "", err
+	}
+	if resp, err = client.RetrieveBootDiagnosticsData(c.ctx, c.resourceGroupName, c.name, nil); err != nil {
+		return "", err
+	}
+	if resp.SerialConsoleLogBlobURI == nil {
+		return "", fmt.Errorf(
+			"boot diagnostics is not enabled for azure VM '%s' in resource group '%s'",
+			c.name, c.resourceGroupName,
+		)
+	}
+
+	// the blob uri includes a SAS token so it can be retrieved
+	// directly. the download is bounded by the operation
+	// timeout so a stalled blob does not block the caller.
+	ctx, cancel := context.WithTimeout(c.ctx, c.props.OpTimeout)
+	defer cancel()
+
+	if logReq, err = http.NewRequestWithContext(ctx, http.MethodGet, *resp.SerialConsoleLogBlobURI, nil); err != nil {
+		return "", err
+	}
+	httpClient := &http.Client{
+		Timeout: c.props.OpTimeout,
+	}
+	if logResp, err = httpClient.Do(logReq); err != nil {
+		return "", err
+	}
+	defer logResp.Body.Close()
+
+	if logResp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf(
+			"unable to retrieve serial console log for azure VM '%s': %s",
+			c.name, logResp.Status,
+		)
+	}
+	if output, err = io.ReadAll(logResp.Body); err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+func (c *azureComputeInstance) WaitForState(
+	ctx context.Context,
+	state InstanceState,
+	timeout time.Duration,
+) error {
+
+	if timeout == 0 {
+		timeout = c.props.OpTimeout
+	}
+	return waitForState(ctx, c, state, timeout)
+}
+
 func (c *azureComputeInstance) CanConnect(port int) bool {
 
 	if c.publicIP != "" {
@@ -450,3 +837,11 @@ func (c *azureComputeInstance) CanConnect(port int) bool {
 		return false
 	}
 }
+
+func (c *azureComputeInstance) HealthCheck(ctx context.Context, check HealthCheck) HealthCheckResult {
+	return CheckHealth(ctx, c, check)
+}
+
+func (c *azureComputeInstance) WaitUntilHealthy(ctx context.Context, checks ...HealthCheck) error {
+	return WaitUntilHealthy(ctx, c, checks...)
+}
@@ -1,6 +1,9 @@
 package cloud_test
 
 import (
+	"path"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
 	"github.com/mevansam/goutils/logger"
 
 	"github.com/mevansam/gocloud/cloud"
@@ -79,6 +82,15 @@ var _ = Describe("Azure Compute Tests", func() {
 			}
 		})
 
+		It("stops and starts a batch of compute instances", func() {
+
+			instanceIds := []string{}
+			for _, azureInstance := range testInstances {
+				instanceIds = append(instanceIds, azureInstance["id"])
+			}
+			testBatchStopAndStart(azureCompute, instanceIds, path.Dir(instanceIds[0])+"/unknown")
+		})
+
 		It("retrieves a compute instance", func() {
 
 			_, err := azureCompute.GetInstance("test-X")
@@ -147,5 +159,93 @@ var _ = Describe("Azure Compute Tests", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(state).To(Equal(cloud.StateRunning))
 		})
+
+		It("stops and starts compute instances asynchronously", func() {
+			testAsyncStopAndStart(instance0, instance1)
+		})
+
+		It("suspends and resumes a compute instance if supported", func() {
+			testSuspendAndResume(instance0)
+		})
+
+		It("retrieves the console output of a compute instance", func() {
+
+			output, err := instance0.ConsoleOutput()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(output)).To(BeNumerically(">", 0))
+		})
+
+		It("resizes a compute instance", func() {
+			testResize(azureCompute, instance1, "Standard_B1s", "Standard_B1ms")
+		})
+
+		It("retrieves the scheduling of a compute instance", func() {
+			testOnDemandScheduling(instance0)
+
+			props := armcompute.VirtualMachineProperties{}
+			cloud.AzureVMScheduling(cloud.InstanceScheduling{
+				PurchaseModel:      cloud.PurchaseSpot,
+				InterruptionAction: cloud.InterruptTerminate,
+			}, &props)
+			Expect(*props.Priority).To(Equal(armcompute.VirtualMachinePriorityTypesSpot))
+			Expect(*props.EvictionPolicy).To(Equal(armcompute.VirtualMachineEvictionPolicyTypesDelete))
+			Expect(*props.BillingProfile.MaxPrice).To(Equal(float64(-1)))
+		})
+
+		It("finds public images", func() {
+			testFindImages(azureCompute)
+		})
+
+		It("creates and deletes an image of a compute instance", func() {
+
+			// an azure managed image can only be captured
+			// from a generalized VM which cannot be restarted
+			// afterwards so a VM is deployed only to be captured
+			imageInstances := test_helpers.AzureDeployTestInstances("image", 1)
+			Expect(len(imageInstances)).To(Equal(1))
+
+			instance, err := azureCompute.GetInstance("image-0")
+			Expect(err).NotTo(HaveOccurred())
+
+			// a VM that has not been generalized is not
+			// generalized implicitly to capture an image
+			state, err := instance.State()
+			Expect(err).NotTo(HaveOccurred())
+			if state == cloud.StateRunning {
+				_, err = azureCompute.CreateImage(instance, "test-image")
+				Expect(err).To(HaveOccurred())
+
+				state, err = instance.State()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state).To(Equal(cloud.StateRunning))
+			}
+
+			test_helpers.AzureGeneralizeTestInstance("image-0")
+			testCreateAndDeleteImage(azureCompute, instance)
+
+			state, err = instance.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateStopped))
+		})
+
+		It("adds and removes firewall rules of a compute instance", func() {
+			testFirewallRules(instance0)
+		})
+
+		It("sets and retrieves the user data of a compute instance", func() {
+			testUserData(instance1)
+		})
+
+		It("allocates, associates and releases a static ip", func() {
+			testStaticIPs(azureCompute, instance1, map[string]string{
+				"Role": "Cloudbuilder-Test",
+			}, false)
+		})
+
+		It("creates, attaches, snapshots and detaches a volume", func() {
+			testVolumes(azureCompute, instance0, map[string]string{
+				"Role": "Cloudbuilder-Test",
+			})
+		})
 	})
 })
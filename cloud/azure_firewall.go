@@ -0,0 +1,338 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// range of priorities of user
+// defined network security rules
+const (
+	azureMinRulePriority = 100
+	azureMaxRulePriority = 4096
+)
+
+// returns the firewall rules of an nsg rule which will
+// be more than one if the rule has multiple port
+// ranges or source address prefixes
+func newAzureFirewallRules(rule *armnetwork.SecurityRule) []FirewallRule {
+
+	props := rule.Properties
+
+	portRanges := props.DestinationPortRanges
+	if props.DestinationPortRange != nil {
+		portRanges = append(portRanges, props.DestinationPortRange)
+	}
+	sources := props.SourceAddressPrefixes
+	if props.SourceAddressPrefix != nil {
+		sources = append(sources, props.SourceAddressPrefix)
+	}
+
+	protocol := ProtocolAll
+	if props.Protocol != nil && *props.Protocol != armnetwork.SecurityRuleProtocolAsterisk {
+		protocol = strings.ToLower(string(*props.Protocol))
+	}
+	description := ""
+	if props.Description != nil {
+		description = *props.Description
+	}
+
+	rules := []FirewallRule{}
+	for _, source := range sources {
+		// service tags such as "Internet"
+		// are not address ranges
+		cidr := "0.0.0.0/0"
+		if *source != "*" {
+			var err error
+			if cidr, err = normalizeCIDR(*source); err != nil {
+				continue
+			}
+		}
+
+		for _, portRange := range portRanges {
+			fromPort, toPort, err := parsePortRange(*portRange)
+			if err != nil {
+				logger.DebugMessage(
+					"Ignoring port range '%s' of security rule '%s': %s",
+					*portRange, *rule.ID, err.Error(),
+				)
+				continue
+			}
+			rules = append(rules, FirewallRule{
+				ID:          *rule.ID,
+				Description: description,
+				Protocol:    protocol,
+				FromPort:    fromPort,
+				ToPort:      toPort,
+				CIDR:        cidr,
+				Expires:     parseFirewallRuleExpiry(description),
+			})
+		}
+	}
+	return rules
+}
+
+// returns the ids of the network security groups of the
+// instance's primary network interface and its subnet
+// with the interface's security group first
+func (c *azureComputeInstance) networkSecurityGroups() ([]string, error) {
+
+	var (
+		err error
+
+		itfClient *armnetwork.InterfacesClient
+		itf       armnetwork.InterfacesClientGetResponse
+
+		subnetClient *armnetwork.SubnetsClient
+		subnet       armnetwork.SubnetsClientGetResponse
+	)
+
+	nsgIDs := []string{}
+	if len(c.nicName) == 0 {
+		return nsgIDs, nil
+	}
+
+	if itfClient, err = armnetwork.NewInterfacesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return nil, err
+	}
+	if itf, err = itfClient.Get(c.ctx, c.resourceGroupName, c.nicName, nil); err != nil {
+		return nil, err
+	}
+	if itf.Properties.NetworkSecurityGroup != nil {
+		nsgIDs = append(nsgIDs, *itf.Properties.NetworkSecurityGroup.ID)
+	}
+
+	for _, ipConfig := range itf.Properties.IPConfigurations {
+		if ipConfig.Properties.Subnet == nil ||
+			(len(itf.Properties.IPConfigurations) > 1 &&
+				(ipConfig.Properties.Primary == nil || !*ipConfig.Properties.Primary)) {
+			continue
+		}
+
+		// * /subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}
+		//     /providers/Microsoft.Network/virtualNetworks/{vnetName}/subnets/{subnetName}
+		elems := strings.Split(*ipConfig.Properties.Subnet.ID, "/")
+		if len(elems) < 11 {
+			return nil, fmt.Errorf("invalid subnet id '%s'", *ipConfig.Properties.Subnet.ID)
+		}
+		if subnetClient, err = armnetwork.NewSubnetsClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+			return nil, err
+		}
+		if subnet, err = subnetClient.Get(c.ctx, elems[4], elems[8], elems[10], nil); err != nil {
+			return nil, err
+		}
+		if subnet.Properties.NetworkSecurityGroup != nil {
+			nsgIDs = append(nsgIDs, *subnet.Properties.NetworkSecurityGroup.ID)
+		}
+		break
+	}
+	return nsgIDs, nil
+}
+
+// returns the inbound rules of the given network security group
+func (c *azureComputeInstance) inboundSecurityRules(nsgID string) ([]*armnetwork.SecurityRule, error) {
+
+	var (
+		err error
+
+		client *armnetwork.SecurityRulesClient
+	)
+
+	if client, err = armnetwork.NewSecurityRulesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return nil, err
+	}
+
+	rules := []*armnetwork.SecurityRule{}
+	pager := client.NewListPager(azureResourceGroupName(nsgID), path.Base(nsgID), nil)
+	for pager.More() {
+		nextResult, err := pager.NextPage(c.ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range nextResult.Value {
+			if rule.Properties != nil && rule.Properties.Direction != nil &&
+				*rule.Properties.Direction == armnetwork.SecurityRuleDirectionInbound {
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *azureComputeInstance) FirewallRules() ([]FirewallRule, error) {
+
+	var (
+		err error
+
+		nsgIDs        []string
+		securityRules []*armnetwork.SecurityRule
+	)
+
+	if nsgIDs, err = c.networkSecurityGroups(); err != nil {
+		return nil, err
+	}
+	rules := []FirewallRule{}
+	for _, nsgID := range nsgIDs {
+		if securityRules, err = c.inboundSecurityRules(nsgID); err != nil {
+			return nil, err
+		}
+		for _, rule := range securityRules {
+			if rule.Properties.Access != nil &&
+				*rule.Properties.Access == armnetwork.SecurityRuleAccessAllow {
+				rules = append(rules, newAzureFirewallRules(rule)...)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// Ingress rules are added with the lowest available priority
+// to the network security group of the instance's network
+// interface or its subnet if the interface does not have
+// one. If both have a security group then traffic also
+// needs to be allowed by the subnet's group.
+func (c *azureComputeInstance) AddIngressRule(spec IngressRuleSpec) (FirewallRule, error) {
+
+	var (
+		err error
+
+		nsgIDs        []string
+		securityRules []*armnetwork.SecurityRule
+
+		client *armnetwork.SecurityRulesClient
+		presp  *runtime.Poller[armnetwork.SecurityRulesClientCreateOrUpdateResponse]
+		resp   armnetwork.SecurityRulesClientCreateOrUpdateResponse
+	)
+
+	if spec, err = spec.normalize(); err != nil {
+		return FirewallRule{}, err
+	}
+	if nsgIDs, err = c.networkSecurityGroups(); err != nil {
+		return FirewallRule{}, err
+	}
+	if len(nsgIDs) == 0 {
+		return FirewallRule{}, fmt.Errorf("VM '%s' does not have a network security group", c.name)
+	}
+	nsgID := nsgIDs[0]
+
+	if securityRules, err = c.inboundSecurityRules(nsgID); err != nil {
+		return FirewallRule{}, err
+	}
+	usedPriorities := make(map[int32]bool)
+	for _, rule := range securityRules {
+		if rule.Properties.Priority != nil {
+			usedPriorities[*rule.Properties.Priority] = true
+		}
+	}
+	priority := int32(azureMinRulePriority)
+	for usedPriorities[priority] {
+		priority++
+	}
+	if priority > azureMaxRulePriority {
+		return FirewallRule{}, fmt.Errorf("no rule priorities available in network security group '%s'", nsgID)
+	}
+
+	protocol := armnetwork.SecurityRuleProtocolAsterisk
+	portRange := "*"
+	switch spec.Protocol {
+	case ProtocolTCP:
+		protocol = armnetwork.SecurityRuleProtocolTCP
+		portRange = strconv.Itoa(spec.Port)
+	case ProtocolUDP:
+		protocol = armnetwork.SecurityRuleProtocolUDP
+		portRange = strconv.Itoa(spec.Port)
+	case ProtocolICMP:
+		protocol = armnetwork.SecurityRuleProtocolIcmp
+	}
+	// restrict the rule to the instance as
+	// the nsg may be shared by its subnet
+	destination := "*"
+	if len(c.privateIP) > 0 {
+		destination = c.privateIP
+	}
+	ruleName := fmt.Sprintf("allow-%s-%d-%d", spec.Protocol, spec.Port, priority)
+
+	if client, err = armnetwork.NewSecurityRulesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return FirewallRule{}, err
+	}
+
+	logger.TraceMessage(
+		"Adding ingress rule '%s' for %s port %d from '%s' to network security group '%s' of VM '%s'.",
+		ruleName, spec.Protocol, spec.Port, spec.CIDR, path.Base(nsgID), c.name,
+	)
+
+	if presp, err = client.BeginCreateOrUpdate(c.ctx,
+		azureResourceGroupName(nsgID), path.Base(nsgID), ruleName,
+		armnetwork.SecurityRule{
+			Properties: &armnetwork.SecurityRulePropertiesFormat{
+				Access:                   to.Ptr(armnetwork.SecurityRuleAccessAllow),
+				Direction:                to.Ptr(armnetwork.SecurityRuleDirectionInbound),
+				Protocol:                 to.Ptr(protocol),
+				Priority:                 to.Ptr(priority),
+				Description:              to.Ptr(spec.Description),
+				SourceAddressPrefix:      to.Ptr(spec.CIDR),
+				SourcePortRange:          to.Ptr("*"),
+				DestinationAddressPrefix: to.Ptr(destination),
+				DestinationPortRange:     to.Ptr(portRange),
+			},
+		},
+		nil,
+	); err != nil {
+		return FirewallRule{}, err
+	}
+	if err = newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		var err error
+		resp, err = presp.PollUntilDone(ctx, nil)
+		return err
+	}).Wait(context.Background()); err != nil {
+		return FirewallRule{}, err
+	}
+
+	rules := newAzureFirewallRules(&resp.SecurityRule)
+	if len(rules) == 0 {
+		return FirewallRule{}, fmt.Errorf("security rule '%s' was not created as requested", ruleName)
+	}
+	return rules[0], nil
+}
+
+func (c *azureComputeInstance) RemoveFirewallRule(rule FirewallRule) error {
+
+	var (
+		err error
+
+		client *armnetwork.SecurityRulesClient
+		presp  *runtime.Poller[armnetwork.SecurityRulesClientDeleteResponse]
+	)
+
+	// * /subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}
+	//     /providers/Microsoft.Network/networkSecurityGroups/{nsgName}/securityRules/{ruleName}
+	elems := strings.Split(rule.ID, "/")
+	if len(elems) < 11 || !strings.EqualFold(elems[9], "securityRules") {
+		return fmt.Errorf("'%s' is not the id of a network security rule", rule.ID)
+	}
+	if client, err = armnetwork.NewSecurityRulesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return err
+	}
+
+	logger.TraceMessage(
+		"Removing ingress rule '%s' from network security group '%s'.", elems[10], elems[8])
+
+	if presp, err = client.BeginDelete(c.ctx, elems[4], elems[8], elems[10], nil); err != nil {
+		return err
+	}
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		_, err := presp.PollUntilDone(ctx, nil)
+		return err
+	}).Wait(context.Background())
+}
@@ -0,0 +1,224 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// number of marketplace image
+// versions returned by FindImages
+const azureMaxImageVersions = 10
+
+func newAzureImage(image *armcompute.Image) Image {
+	return Image{
+		ID:   *image.ID,
+		Name: *image.Name,
+	}
+}
+
+// interface: cloud/Compute implementation
+
+func (c *azureCompute) FindImages(filter ImageFilter) ([]Image, error) {
+
+	var (
+		err error
+
+		source *imageSource
+
+		imagesClient *armcompute.ImagesClient
+		vmClient     *armcompute.VirtualMachineImagesClient
+		resp         armcompute.VirtualMachineImagesClientListResponse
+	)
+
+	images := []Image{}
+	if filter.Owned {
+		if imagesClient, err = armcompute.NewImagesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+			return nil, err
+		}
+		pager := imagesClient.NewListByResourceGroupPager(c.resourceGroupName, nil)
+		for pager.More() {
+			nextResult, err := pager.NextPage(c.ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, image := range nextResult.Value {
+				if strings.HasPrefix(*image.Name, filter.Name) {
+					images = append(images, newAzureImage(image))
+				}
+			}
+		}
+		return images, nil
+	}
+
+	if source, err = lookupImageSource(filter); err != nil {
+		return nil, err
+	}
+	if vmClient, err = armcompute.NewVirtualMachineImagesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return nil, err
+	}
+	if resp, err = vmClient.List(c.ctx, c.locationName,
+		source.azurePublisher, source.azureOffer, source.azureSKU,
+		&armcompute.VirtualMachineImagesClientListOptions{
+			Orderby: to.Ptr("name desc"),
+			Top:     to.Ptr(int32(azureMaxImageVersions)),
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	// marketplace images are identified by their urn
+	// and the version names are ordered newest first
+	for _, version := range resp.VirtualMachineImageResourceArray {
+		urn := fmt.Sprintf("%s:%s:%s:%s",
+			source.azurePublisher, source.azureOffer, source.azureSKU, *version.Name)
+
+		images = append(images, Image{
+			ID:      urn,
+			Name:    urn,
+			OS:      source.os,
+			Version: source.version,
+			Arch:    source.arch,
+		})
+	}
+	return images, nil
+}
+
+// An azure managed image can only be captured from a VM
+// that has been deprovisioned, deallocated and marked as
+// generalized. As a generalized VM cannot be started again
+// an image is only captured from a VM that has already been
+// deallocated and generalized.
+func (c *azureCompute) CreateImage(instance ComputeInstance, name string) (Image, error) {
+
+	var (
+		err error
+		ok  bool
+
+		azureInstance *azureComputeInstance
+
+		vmClient *armcompute.VirtualMachinesClient
+		vm       armcompute.VirtualMachinesClientGetResponse
+
+		imagesClient *armcompute.ImagesClient
+		presp        *runtime.Poller[armcompute.ImagesClientCreateOrUpdateResponse]
+		resp         armcompute.ImagesClientCreateOrUpdateResponse
+	)
+
+	if azureInstance, ok = instance.(*azureComputeInstance); !ok {
+		return Image{}, fmt.Errorf("instance '%s' is not an azure VM", instance.Name())
+	}
+	if vmClient, err = armcompute.NewVirtualMachinesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return Image{}, err
+	}
+	if vm, err = vmClient.Get(c.ctx, azureInstance.resourceGroupName, azureInstance.name, nil); err != nil {
+		return Image{}, err
+	}
+	if err = c.checkGeneralizedVM(vmClient, azureInstance); err != nil {
+		return Image{}, err
+	}
+	if imagesClient, err = armcompute.NewImagesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return Image{}, err
+	}
+
+	logger.TraceMessage(
+		"Creating image '%s' from azure VM '%s' in resource group '%s'.",
+		name, azureInstance.name, azureInstance.resourceGroupName,
+	)
+
+	if presp, err = imagesClient.BeginCreateOrUpdate(c.ctx, c.resourceGroupName, name,
+		armcompute.Image{
+			Location: vm.Location,
+			Tags:     vm.Tags,
+			Properties: &armcompute.ImageProperties{
+				SourceVirtualMachine: &armcompute.SubResource{
+					ID: vm.ID,
+				},
+			},
+		},
+		nil,
+	); err != nil {
+		return Image{}, err
+	}
+	if err = newOperation(defaultImageOpTimeout, func(ctx context.Context) error {
+		var err error
+		resp, err = presp.PollUntilDone(ctx, nil)
+		return err
+	}).Wait(context.Background()); err != nil {
+		return Image{}, err
+	}
+	return newAzureImage(&resp.Image), nil
+}
+
+// returns an error if the given VM has not been
+// deallocated and generalized as azure rejects
+// capturing an image of such a VM
+func (c *azureCompute) checkGeneralizedVM(client *armcompute.VirtualMachinesClient, instance *azureComputeInstance) error {
+
+	var (
+		err error
+
+		view armcompute.VirtualMachinesClientInstanceViewResponse
+	)
+
+	if view, err = client.InstanceView(c.ctx, instance.resourceGroupName, instance.name, nil); err != nil {
+		return err
+	}
+	deallocated, generalized := false, false
+	for _, status := range view.Statuses {
+		if status.Code == nil {
+			continue
+		}
+		switch strings.ToLower(*status.Code) {
+		case "powerstate/deallocated":
+			deallocated = true
+		case "osstate/generalized":
+			generalized = true
+		}
+	}
+	if !deallocated || !generalized {
+		return fmt.Errorf(
+			"azure VM '%s' in resource group '%s' must be deprovisioned, deallocated and generalized before an image can be captured from it",
+			instance.name, instance.resourceGroupName,
+		)
+	}
+	return nil
+}
+
+func (c *azureCompute) DeleteImage(id string) error {
+
+	var (
+		err error
+
+		client *armcompute.ImagesClient
+		presp  *runtime.Poller[armcompute.ImagesClientDeleteResponse]
+	)
+
+	if !strings.Contains(strings.ToLower(id), "/providers/microsoft.compute/images/") {
+		return fmt.Errorf("'%s' is not the id of an azure managed image", id)
+	}
+	if client, err = armcompute.NewImagesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return err
+	}
+
+	resourceGroupName := azureResourceGroupName(id)
+	logger.TraceMessage(
+		"Deleting image '%s' in resource group '%s'.",
+		path.Base(id), resourceGroupName,
+	)
+
+	if presp, err = client.BeginDelete(c.ctx, resourceGroupName, path.Base(id), nil); err != nil {
+		return err
+	}
+	return newOperation(defaultImageOpTimeout, func(ctx context.Context) error {
+		_, err := presp.PollUntilDone(ctx, nil)
+		return err
+	}).Wait(context.Background())
+}
@@ -0,0 +1,82 @@
+package cloud
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+// Sets the priority, eviction policy and billing profile of
+// the given azure VM properties to request spot capacity
+// with the given scheduling. On-demand scheduling leaves
+// the properties unchanged.
+func AzureVMScheduling(scheduling InstanceScheduling, props *armcompute.VirtualMachineProperties) {
+
+	if scheduling.PurchaseModel == PurchaseOnDemand {
+		return
+	}
+
+	// a max price of -1 caps the
+	// price at the on-demand price
+	maxPrice := float64(-1)
+	if scheduling.MaxPrice > 0 {
+		maxPrice = scheduling.MaxPrice
+	}
+	evictionPolicy := armcompute.VirtualMachineEvictionPolicyTypesDeallocate
+	if scheduling.InterruptionAction == InterruptTerminate {
+		evictionPolicy = armcompute.VirtualMachineEvictionPolicyTypesDelete
+	}
+
+	props.Priority = to.Ptr(armcompute.VirtualMachinePriorityTypesSpot)
+	props.EvictionPolicy = to.Ptr(evictionPolicy)
+	props.BillingProfile = &armcompute.BillingProfile{
+		MaxPrice: to.Ptr(maxPrice),
+	}
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *azureComputeInstance) Scheduling() (InstanceScheduling, error) {
+
+	var (
+		err error
+
+		client *armcompute.VirtualMachinesClient
+		resp   armcompute.VirtualMachinesClientGetResponse
+	)
+
+	scheduling := InstanceScheduling{
+		PurchaseModel: PurchaseOnDemand,
+	}
+
+	if client, err = armcompute.NewVirtualMachinesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return scheduling, err
+	}
+	if resp, err = client.Get(c.ctx, c.resourceGroupName, c.name, nil); err != nil {
+		return scheduling, err
+	}
+
+	props := resp.Properties
+	if props == nil || props.Priority == nil ||
+		*props.Priority == armcompute.VirtualMachinePriorityTypesRegular {
+		return scheduling, nil
+	}
+	scheduling.PurchaseModel = PurchaseSpot
+	scheduling.InterruptionAction = InterruptStop
+
+	if props.EvictionPolicy != nil &&
+		*props.EvictionPolicy == armcompute.VirtualMachineEvictionPolicyTypesDelete {
+		scheduling.InterruptionAction = InterruptTerminate
+	}
+	if props.BillingProfile != nil && props.BillingProfile.MaxPrice != nil &&
+		*props.BillingProfile.MaxPrice > 0 {
+		scheduling.MaxPrice = *props.BillingProfile.MaxPrice
+	}
+	return scheduling, nil
+}
+
+// Azure only publishes eviction notices of spot VMs via the
+// scheduled events endpoint of the instance metadata service
+// within the VM so an empty notice is always returned.
+func (c *azureComputeInstance) InterruptionNotice() (InterruptionNotice, error) {
+	return InterruptionNotice{}, nil
+}
@@ -0,0 +1,269 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// interface: cloud/Compute implementation
+
+// Azure static IPs are allocated as standard sku public IP
+// addresses. Traffic to a standard sku IP is denied unless
+// allowed by a network security group of the instance.
+func (c *azureCompute) AllocateStaticIP(name string, tags map[string]string) (StaticIP, error) {
+
+	var (
+		err error
+
+		client *armnetwork.PublicIPAddressesClient
+		presp  *runtime.Poller[armnetwork.PublicIPAddressesClientCreateOrUpdateResponse]
+		resp   armnetwork.PublicIPAddressesClientCreateOrUpdateResponse
+	)
+
+	if client, err = armnetwork.NewPublicIPAddressesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return StaticIP{}, err
+	}
+
+	address := armnetwork.PublicIPAddress{
+		Location: to.Ptr(c.locationName),
+		SKU: &armnetwork.PublicIPAddressSKU{
+			Name: to.Ptr(armnetwork.PublicIPAddressSKUNameStandard),
+		},
+		Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+			PublicIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodStatic),
+			PublicIPAddressVersion:   to.Ptr(armnetwork.IPVersionIPv4),
+		},
+		Tags: make(map[string]*string),
+	}
+	for k, v := range tags {
+		address.Tags[k] = to.Ptr(v)
+	}
+
+	logger.TraceMessage(
+		"Allocating static public IP '%s' in resource group '%s'.",
+		name, c.resourceGroupName,
+	)
+
+	if presp, err = client.BeginCreateOrUpdate(c.ctx, c.resourceGroupName, name, address, nil); err != nil {
+		return StaticIP{}, err
+	}
+	if err = newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		var err error
+		resp, err = presp.PollUntilDone(ctx, nil)
+		return err
+	}).Wait(context.Background()); err != nil {
+		return StaticIP{}, err
+	}
+	return c.newAzureStaticIP(&resp.PublicIPAddress)
+}
+
+func (c *azureCompute) ListStaticIPs() ([]StaticIP, error) {
+
+	var (
+		err error
+
+		client *armnetwork.PublicIPAddressesClient
+		ip     StaticIP
+	)
+
+	if client, err = armnetwork.NewPublicIPAddressesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return nil, err
+	}
+
+	ips := []StaticIP{}
+	pager := client.NewListPager(c.resourceGroupName, nil)
+	for pager.More() {
+		nextResult, err := pager.NextPage(c.ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, address := range nextResult.Value {
+			if address.Properties != nil && address.Properties.PublicIPAllocationMethod != nil &&
+				*address.Properties.PublicIPAllocationMethod == armnetwork.IPAllocationMethodStatic {
+
+				if ip, err = c.newAzureStaticIP(address); err != nil {
+					return nil, err
+				}
+				ips = append(ips, ip)
+			}
+		}
+	}
+	return ips, nil
+}
+
+func (c *azureCompute) ReleaseStaticIP(ip StaticIP) error {
+
+	var (
+		err error
+
+		client *armnetwork.PublicIPAddressesClient
+		presp  *runtime.Poller[armnetwork.PublicIPAddressesClientDeleteResponse]
+	)
+
+	if client, err = armnetwork.NewPublicIPAddressesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return err
+	}
+
+	resourceGroupName := azureResourceGroupName(ip.ID)
+	logger.TraceMessage(
+		"Releasing static public IP '%s' in resource group '%s'.",
+		path.Base(ip.ID), resourceGroupName,
+	)
+
+	if presp, err = client.BeginDelete(c.ctx, resourceGroupName, path.Base(ip.ID), nil); err != nil {
+		return err
+	}
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		_, err := presp.PollUntilDone(ctx, nil)
+		return err
+	}).Wait(context.Background())
+}
+
+// returns the static ip of the given public ip address
+// resource with the id of the VM it is associated with
+func (c *azureCompute) newAzureStaticIP(address *armnetwork.PublicIPAddress) (StaticIP, error) {
+
+	var (
+		err error
+
+		itfClient *armnetwork.InterfacesClient
+		itf       armnetwork.InterfacesClientGetResponse
+	)
+
+	ip := StaticIP{
+		ID:   *address.ID,
+		Name: *address.Name,
+	}
+	if address.Properties == nil {
+		return ip, nil
+	}
+	if address.Properties.IPAddress != nil {
+		ip.Address = *address.Properties.IPAddress
+	}
+
+	ipConfig := address.Properties.IPConfiguration
+	if ipConfig == nil || ipConfig.ID == nil {
+		return ip, nil
+	}
+	// * /subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}
+	//     /providers/Microsoft.Network/networkInterfaces/{nicName}/ipConfigurations/{ipConfigName}
+	elems := strings.Split(*ipConfig.ID, "/")
+	if len(elems) < 9 || !strings.EqualFold(elems[7], "networkInterfaces") {
+		// associated with a resource
+		// other than a network interface
+		return ip, nil
+	}
+	if itfClient, err = armnetwork.NewInterfacesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return ip, err
+	}
+	if itf, err = itfClient.Get(c.ctx, elems[4], elems[8], nil); err != nil {
+		return ip, err
+	}
+	if itf.Properties.VirtualMachine != nil && itf.Properties.VirtualMachine.ID != nil {
+		ip.InstanceID = *itf.Properties.VirtualMachine.ID
+	}
+	return ip, nil
+}
+
+// updates the primary ip configuration of the
+// instance's primary network interface
+func (c *azureComputeInstance) updatePrimaryIPConfig(
+	update func(ipConfig *armnetwork.InterfaceIPConfiguration) error,
+) error {
+
+	var (
+		err error
+
+		client *armnetwork.InterfacesClient
+		itf    armnetwork.InterfacesClientGetResponse
+		presp  *runtime.Poller[armnetwork.InterfacesClientCreateOrUpdateResponse]
+
+		primaryIPConfig *armnetwork.InterfaceIPConfiguration
+	)
+
+	if len(c.nicName) == 0 {
+		return fmt.Errorf("VM '%s' does not have a network interface", c.name)
+	}
+	if client, err = armnetwork.NewInterfacesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return err
+	}
+	if itf, err = client.Get(c.ctx, c.resourceGroupName, c.nicName, nil); err != nil {
+		return err
+	}
+	for _, ipConfig := range itf.Properties.IPConfigurations {
+		if primaryIPConfig == nil ||
+			(ipConfig.Properties.Primary != nil && *ipConfig.Properties.Primary) {
+			primaryIPConfig = ipConfig
+		}
+	}
+	if primaryIPConfig == nil {
+		return fmt.Errorf("network interface '%s' has no ip configurations", c.nicName)
+	}
+	if err = update(primaryIPConfig); err != nil {
+		return err
+	}
+
+	if presp, err = client.BeginCreateOrUpdate(c.ctx, c.resourceGroupName, c.nicName, itf.Interface, nil); err != nil {
+		return err
+	}
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		_, err := presp.PollUntilDone(ctx, nil)
+		return err
+	}).Wait(context.Background())
+}
+
+// interface: cloud/ComputeInstance implementation
+
+// The public IP resource previously associated with
+// the VM's network interface is not deleted.
+func (c *azureComputeInstance) AssociateStaticIP(ip StaticIP) error {
+
+	logger.TraceMessage(
+		"Associating static public IP '%s' (%s) with VM '%s'.",
+		path.Base(ip.ID), ip.Address, c.name,
+	)
+
+	if err := c.updatePrimaryIPConfig(func(ipConfig *armnetwork.InterfaceIPConfiguration) error {
+		ipConfig.Properties.PublicIPAddress = &armnetwork.PublicIPAddress{
+			ID: to.Ptr(ip.ID),
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	c.publicIP = ip.Address
+	c.publicDNS = ""
+	return nil
+}
+
+// The VM will not have a public IP
+// once the static IP is disassociated.
+func (c *azureComputeInstance) DisassociateStaticIP(ip StaticIP) error {
+
+	logger.TraceMessage(
+		"Disassociating static public IP '%s' (%s) from VM '%s'.",
+		path.Base(ip.ID), ip.Address, c.name,
+	)
+
+	if err := c.updatePrimaryIPConfig(func(ipConfig *armnetwork.InterfaceIPConfiguration) error {
+		publicIP := ipConfig.Properties.PublicIPAddress
+		if publicIP == nil || publicIP.ID == nil || !strings.EqualFold(*publicIP.ID, ip.ID) {
+			return fmt.Errorf("static IP '%s' is not associated with VM '%s'", ip.Address, c.name)
+		}
+		ipConfig.Properties.PublicIPAddress = nil
+		return nil
+	}); err != nil {
+		return err
+	}
+	c.publicIP = ""
+	c.publicDNS = ""
+	return nil
+}
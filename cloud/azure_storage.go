@@ -8,10 +8,10 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	azruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
@@ -38,7 +38,7 @@ type azureStorage struct {
 	subscriptionID string
 
 	ctx         context.Context	
-	clientCreds *azidentity.ClientSecretCredential
+	clientCreds azcore.TokenCredential
 	clientOpts  *arm.ClientOptions
 
 	props AzureStorageProperties
@@ -58,7 +58,7 @@ const storageURLF = `https://%s.blob.core.windows.net`
 
 func NewAzureStorage(
 	ctx context.Context,
-	clientCreds *azidentity.ClientSecretCredential,
+	clientCreds azcore.TokenCredential,
 	clientOpts *arm.ClientOptions,
 	storageAccountName,
 	resourceGroupName,
@@ -0,0 +1,82 @@
+package cloud
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// interface: cloud/ComputeInstance implementation
+
+// Azure VMs can be bootstrapped with custom data which
+// is only available at provisioning time and cannot
+// be read or changed via the API. The VM's user
+// data is returned and changed instead.
+func (c *azureComputeInstance) UserData() (string, error) {
+
+	var (
+		err error
+
+		client *armcompute.VirtualMachinesClient
+		resp   armcompute.VirtualMachinesClientGetResponse
+		data   []byte
+	)
+
+	if client, err = armcompute.NewVirtualMachinesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return "", err
+	}
+	if resp, err = client.Get(c.ctx, c.resourceGroupName, c.name,
+		&armcompute.VirtualMachinesClientGetOptions{
+			Expand: to.Ptr(armcompute.InstanceViewTypesUserData),
+		},
+	); err != nil {
+		return "", err
+	}
+	if resp.Properties == nil || resp.Properties.UserData == nil {
+		return "", nil
+	}
+	if data, err = base64.StdEncoding.DecodeString(*resp.Properties.UserData); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (c *azureComputeInstance) SetUserData(data string) error {
+
+	var (
+		err error
+
+		client *armcompute.VirtualMachinesClient
+		presp  *runtime.Poller[armcompute.VirtualMachinesClientUpdateResponse]
+	)
+
+	if err = requireStopped(c); err != nil {
+		return err
+	}
+	if client, err = armcompute.NewVirtualMachinesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return err
+	}
+
+	logger.TraceMessage("Setting user data of azure VM '%s' in resource group '%s'.",
+		c.name, c.resourceGroupName)
+
+	if presp, err = client.BeginUpdate(c.ctx, c.resourceGroupName, c.name,
+		armcompute.VirtualMachineUpdate{
+			Properties: &armcompute.VirtualMachineProperties{
+				UserData: to.Ptr(base64.StdEncoding.EncodeToString([]byte(data))),
+			},
+		},
+		nil,
+	); err != nil {
+		return err
+	}
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		_, err := presp.PollUntilDone(ctx, nil)
+		return err
+	}).Wait(context.Background())
+}
@@ -0,0 +1,458 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// default storage type of managed disks
+const azureDefaultVolumeType = armcompute.DiskStorageAccountTypesStandardSSDLRS
+
+type azureVolume struct {
+	disk *armcompute.Disk
+	boot bool
+
+	resourceGroupName,
+	subscriptionID string
+
+	ctx         context.Context
+	clientCreds azcore.TokenCredential
+	clientOpts  *arm.ClientOptions
+
+	props *AzureComputeProperties
+}
+
+// returns the resource group name
+// element of an azure resource id
+func azureResourceGroupName(id string) string {
+
+	// * /subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/...
+	elems := strings.Split(id, "/")
+	if len(elems) > 4 {
+		return elems[4]
+	}
+	return ""
+}
+
+// reloads the managed disk's state
+func (v *azureVolume) refresh() error {
+
+	var (
+		err error
+
+		client *armcompute.DisksClient
+		resp   armcompute.DisksClientGetResponse
+	)
+
+	if client, err = armcompute.NewDisksClient(v.subscriptionID, v.clientCreds, v.clientOpts); err != nil {
+		return err
+	}
+	if resp, err = client.Get(v.ctx, v.resourceGroupName, *v.disk.Name, nil); err != nil {
+		return err
+	}
+	v.disk = &resp.Disk
+	return nil
+}
+
+// updates the data disks attached to the VM
+func (c *azureComputeInstance) updateDataDisks(dataDisks []*armcompute.DataDisk) error {
+
+	var (
+		err error
+
+		client *armcompute.VirtualMachinesClient
+		presp  *runtime.Poller[armcompute.VirtualMachinesClientUpdateResponse]
+	)
+
+	if client, err = armcompute.NewVirtualMachinesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return err
+	}
+	if presp, err = client.BeginUpdate(c.ctx, c.resourceGroupName, c.name,
+		armcompute.VirtualMachineUpdate{
+			Properties: &armcompute.VirtualMachineProperties{
+				StorageProfile: &armcompute.StorageProfile{
+					DataDisks: dataDisks,
+				},
+			},
+		},
+		nil,
+	); err != nil {
+		return err
+	}
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		_, err := presp.PollUntilDone(ctx, nil)
+		return err
+	}).Wait(context.Background())
+}
+
+// returns the VM's storage profile
+func (c *azureComputeInstance) storageProfile() (*armcompute.StorageProfile, error) {
+
+	var (
+		err error
+
+		client *armcompute.VirtualMachinesClient
+		resp   armcompute.VirtualMachinesClientGetResponse
+	)
+
+	if client, err = armcompute.NewVirtualMachinesClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return nil, err
+	}
+	if resp, err = client.Get(c.ctx, c.resourceGroupName, c.name, nil); err != nil {
+		return nil, err
+	}
+	if resp.Properties == nil || resp.Properties.StorageProfile == nil {
+		return nil, fmt.Errorf(
+			"azure VM '%s' in resource group '%s' does not have a storage profile",
+			c.name, c.resourceGroupName,
+		)
+	}
+	return resp.Properties.StorageProfile, nil
+}
+
+// interface: cloud/Compute implementation
+
+func (c *azureCompute) CreateVolume(spec VolumeSpec) (Volume, error) {
+
+	var (
+		err error
+
+		client *armcompute.DisksClient
+		presp  *runtime.Poller[armcompute.DisksClientCreateOrUpdateResponse]
+		resp   armcompute.DisksClientCreateOrUpdateResponse
+	)
+
+	if client, err = armcompute.NewDisksClient(c.subscriptionID, c.clientCreds, c.clientOpts); err != nil {
+		return nil, err
+	}
+
+	volumeType := azureDefaultVolumeType
+	if len(spec.Type) > 0 {
+		volumeType = armcompute.DiskStorageAccountTypes(spec.Type)
+	}
+	disk := armcompute.Disk{
+		Location: to.Ptr(c.locationName),
+		SKU: &armcompute.DiskSKU{
+			Name: to.Ptr(volumeType),
+		},
+		Properties: &armcompute.DiskProperties{
+			CreationData: &armcompute.CreationData{
+				CreateOption: to.Ptr(armcompute.DiskCreateOptionEmpty),
+			},
+			DiskSizeGB: to.Ptr(int32(spec.SizeGB)),
+		},
+		Tags: make(map[string]*string),
+	}
+	if len(spec.Zone) > 0 {
+		disk.Zones = []*string{to.Ptr(spec.Zone)}
+	}
+	for k, v := range spec.Tags {
+		disk.Tags[k] = to.Ptr(v)
+	}
+
+	logger.TraceMessage(
+		"Creating %dGB managed disk '%s' of type '%s' in resource group '%s'.",
+		spec.SizeGB, spec.Name, volumeType, c.resourceGroupName,
+	)
+
+	if presp, err = client.BeginCreateOrUpdate(c.ctx, c.resourceGroupName, spec.Name, disk, nil); err != nil {
+		return nil, err
+	}
+	if err = newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		var err error
+		resp, err = presp.PollUntilDone(ctx, nil)
+		return err
+	}).Wait(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return &azureVolume{
+		disk: &resp.Disk,
+
+		resourceGroupName: c.resourceGroupName,
+		subscriptionID:    c.subscriptionID,
+
+		ctx:         c.ctx,
+		clientCreds: c.clientCreds,
+		clientOpts:  c.clientOpts,
+
+		props: &c.props,
+	}, nil
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *azureComputeInstance) Volumes() ([]Volume, error) {
+
+	var (
+		err error
+
+		storageProfile *armcompute.StorageProfile
+	)
+
+	if storageProfile, err = c.storageProfile(); err != nil {
+		return nil, err
+	}
+
+	volumes := []Volume{}
+	addVolume := func(managedDisk *armcompute.ManagedDiskParameters, boot bool) error {
+		if managedDisk == nil || managedDisk.ID == nil {
+			// unmanaged disks are not supported
+			return nil
+		}
+		v := &azureVolume{
+			disk: &armcompute.Disk{
+				Name: to.Ptr(path.Base(*managedDisk.ID)),
+			},
+			boot: boot,
+
+			resourceGroupName: azureResourceGroupName(*managedDisk.ID),
+			subscriptionID:    c.subscriptionID,
+
+			ctx:         c.ctx,
+			clientCreds: c.clientCreds,
+			clientOpts:  c.clientOpts,
+
+			props: c.props,
+		}
+		if err := v.refresh(); err != nil {
+			return err
+		}
+		volumes = append(volumes, v)
+		return nil
+	}
+
+	if storageProfile.OSDisk != nil {
+		if err = addVolume(storageProfile.OSDisk.ManagedDisk, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, dataDisk := range storageProfile.DataDisks {
+		if err = addVolume(dataDisk.ManagedDisk, false); err != nil {
+			return nil, err
+		}
+	}
+	return volumes, nil
+}
+
+func (c *azureComputeInstance) AttachVolume(volume Volume) error {
+
+	var (
+		err error
+		ok  bool
+
+		v              *azureVolume
+		storageProfile *armcompute.StorageProfile
+	)
+
+	if v, ok = volume.(*azureVolume); !ok {
+		return fmt.Errorf("volume '%s' is not an azure managed disk", volume.Name())
+	}
+	if storageProfile, err = c.storageProfile(); err != nil {
+		return err
+	}
+
+	// attach at the lowest free logical unit number
+	inUse := make(map[int32]bool)
+	for _, dataDisk := range storageProfile.DataDisks {
+		inUse[*dataDisk.Lun] = true
+	}
+	lun := int32(0)
+	for inUse[lun] {
+		lun++
+	}
+
+	logger.TraceMessage(
+		"Attaching managed disk '%s' to azure VM '%s' in resource group '%s' at LUN %d.",
+		v.Name(), c.name, c.resourceGroupName, lun,
+	)
+
+	dataDisks := append(storageProfile.DataDisks, &armcompute.DataDisk{
+		Lun:          to.Ptr(lun),
+		CreateOption: to.Ptr(armcompute.DiskCreateOptionTypesAttach),
+		ManagedDisk: &armcompute.ManagedDiskParameters{
+			ID: v.disk.ID,
+		},
+	})
+	if err = c.updateDataDisks(dataDisks); err != nil {
+		return err
+	}
+	return v.refresh()
+}
+
+func (c *azureComputeInstance) DetachVolume(volume Volume) error {
+
+	var (
+		err error
+		ok  bool
+
+		v              *azureVolume
+		storageProfile *armcompute.StorageProfile
+	)
+
+	if v, ok = volume.(*azureVolume); !ok {
+		return fmt.Errorf("volume '%s' is not an azure managed disk", volume.Name())
+	}
+	if v.boot {
+		return fmt.Errorf("the OS disk of azure VM '%s' cannot be detached", c.name)
+	}
+	if storageProfile, err = c.storageProfile(); err != nil {
+		return err
+	}
+
+	// a non-nil empty list is required
+	// to detach the last data disk
+	dataDisks := make([]*armcompute.DataDisk, 0, len(storageProfile.DataDisks))
+	for _, dataDisk := range storageProfile.DataDisks {
+		if dataDisk.ManagedDisk != nil && dataDisk.ManagedDisk.ID != nil &&
+			strings.EqualFold(*dataDisk.ManagedDisk.ID, *v.disk.ID) {
+			continue
+		}
+		dataDisks = append(dataDisks, dataDisk)
+	}
+	if len(dataDisks) == len(storageProfile.DataDisks) {
+		return fmt.Errorf(
+			"managed disk '%s' is not attached to azure VM '%s'", v.Name(), c.name,
+		)
+	}
+
+	logger.TraceMessage(
+		"Detaching managed disk '%s' from azure VM '%s' in resource group '%s'.",
+		v.Name(), c.name, c.resourceGroupName,
+	)
+
+	if err = c.updateDataDisks(dataDisks); err != nil {
+		return err
+	}
+	return v.refresh()
+}
+
+// interface: cloud/Volume implementation
+
+func (v *azureVolume) ID() string {
+	return *v.disk.ID
+}
+
+func (v *azureVolume) Name() string {
+	return *v.disk.Name
+}
+
+func (v *azureVolume) SizeGB() int {
+	if v.disk.Properties != nil && v.disk.Properties.DiskSizeGB != nil {
+		return int(*v.disk.Properties.DiskSizeGB)
+	}
+	return 0
+}
+
+func (v *azureVolume) Type() string {
+	if v.disk.SKU != nil && v.disk.SKU.Name != nil {
+		return string(*v.disk.SKU.Name)
+	}
+	return ""
+}
+
+func (v *azureVolume) Zone() string {
+	if len(v.disk.Zones) > 0 {
+		return *v.disk.Zones[0]
+	}
+	return ""
+}
+
+func (v *azureVolume) Attached() bool {
+	return v.disk.ManagedBy != nil && len(*v.disk.ManagedBy) > 0
+}
+
+func (v *azureVolume) Boot() bool {
+	return v.boot
+}
+
+func (v *azureVolume) Snapshot() (VolumeSnapshot, error) {
+
+	var (
+		err error
+
+		client *armcompute.SnapshotsClient
+		presp  *runtime.Poller[armcompute.SnapshotsClientCreateOrUpdateResponse]
+		resp   armcompute.SnapshotsClientCreateOrUpdateResponse
+	)
+
+	if client, err = armcompute.NewSnapshotsClient(v.subscriptionID, v.clientCreds, v.clientOpts); err != nil {
+		return VolumeSnapshot{}, err
+	}
+
+	name := snapshotName(v.Name())
+	logger.TraceMessage(
+		"Creating snapshot '%s' of managed disk '%s' in resource group '%s'.",
+		name, v.Name(), v.resourceGroupName,
+	)
+
+	// incremental snapshots only store the changes
+	// since the last snapshot of the same disk
+	if presp, err = client.BeginCreateOrUpdate(v.ctx, v.resourceGroupName, name,
+		armcompute.Snapshot{
+			Location: v.disk.Location,
+			Tags:     v.disk.Tags,
+			Properties: &armcompute.SnapshotProperties{
+				CreationData: &armcompute.CreationData{
+					CreateOption:     to.Ptr(armcompute.DiskCreateOptionCopy),
+					SourceResourceID: v.disk.ID,
+				},
+				Incremental: to.Ptr(true),
+			},
+		},
+		nil,
+	); err != nil {
+		return VolumeSnapshot{}, err
+	}
+	if err = newOperation(v.props.OpTimeout, func(ctx context.Context) error {
+		var err error
+		resp, err = presp.PollUntilDone(ctx, nil)
+		return err
+	}).Wait(context.Background()); err != nil {
+		return VolumeSnapshot{}, err
+	}
+
+	return VolumeSnapshot{
+		ID:       *resp.ID,
+		Name:     name,
+		VolumeID: *v.disk.ID,
+		SizeGB:   v.SizeGB(),
+	}, nil
+}
+
+func (v *azureVolume) Delete() error {
+
+	var (
+		err error
+
+		client *armcompute.DisksClient
+		presp  *runtime.Poller[armcompute.DisksClientDeleteResponse]
+	)
+
+	if client, err = armcompute.NewDisksClient(v.subscriptionID, v.clientCreds, v.clientOpts); err != nil {
+		return err
+	}
+
+	logger.TraceMessage(
+		"Deleting managed disk '%s' in resource group '%s'.",
+		v.Name(), v.resourceGroupName,
+	)
+
+	if presp, err = client.BeginDelete(v.ctx, v.resourceGroupName, v.Name(), nil); err != nil {
+		return err
+	}
+	return newOperation(v.props.OpTimeout, func(ctx context.Context) error {
+		_, err := presp.PollUntilDone(ctx, nil)
+		return err
+	}).Wait(context.Background())
+}
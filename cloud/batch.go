@@ -0,0 +1,92 @@
+package cloud
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// default maximum number of instance operations
+// that will be run concurrently for a batch
+const defaultMaxConcurrency = 10
+
+// in: the compute context to retrieve the instances from
+// in: the ids of the instances to run the operation on
+// in: maximum number of operations to run concurrently
+// in: the operation to run on each instance
+// out: a result for each given id in the same order as ids
+func runBatch(
+	compute Compute,
+	ids []string,
+	maxConcurrency int,
+	run func(instance ComputeInstance) error,
+) ([]InstanceResult, error) {
+
+	var (
+		err error
+		wg  sync.WaitGroup
+
+		instances []ComputeInstance
+	)
+
+	if instances, err = compute.GetInstances(ids); err != nil {
+		return nil, err
+	}
+	instanceMap := make(map[string]ComputeInstance)
+	for _, instance := range instances {
+		instanceMap[instance.ID()] = instance
+	}
+
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultMaxConcurrency
+	}
+	sem := make(chan struct{}, maxConcurrency)
+
+	results := make([]InstanceResult, len(ids))
+	for i, id := range ids {
+		results[i].ID = id
+
+		instance, exists := instanceMap[id]
+		if !exists {
+			results[i].Err = fmt.Errorf("instance with id '%s' was not found", id)
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *InstanceResult, instance ComputeInstance) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if result.Err = run(instance); result.Err != nil {
+				logger.DebugMessage(
+					"Batch operation on instance '%s' failed: %s",
+					result.ID, result.Err.Error(),
+				)
+			}
+		}(&results[i], instance)
+	}
+	wg.Wait()
+
+	return results, batchError(results)
+}
+
+// returns an error summarizing the failed
+// results or nil if all results succeeded
+func batchError(results []InstanceResult) error {
+
+	numFailed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			numFailed++
+		}
+	}
+	if numFailed > 0 {
+		return fmt.Errorf(
+			"%d of %d instance operations failed", numFailed, len(results),
+		)
+	}
+	return nil
+}
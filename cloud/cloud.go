@@ -1,21 +1,40 @@
 package cloud
 
 import (
+	"context"
 	"io"
+	"time"
 )
 
 // instance states
 type InstanceState int
 
 const (
-	StateRunning = InstanceState(0)
-	StateStopped = InstanceState(1)
-	StatePending = InstanceState(2)
-	StateUnknown = InstanceState(3)
+	StateRunning   = InstanceState(0)
+	StateStopped   = InstanceState(1)
+	StatePending   = InstanceState(2)
+	StateUnknown   = InstanceState(3)
+	StateSuspended = InstanceState(4)
 )
 
 func (s InstanceState) String() string {
-	return []string{"running", "stopped", "pending"}[s]
+	return []string{"running", "stopped", "pending", "unknown", "suspended"}[s]
+}
+
+// handle to an asynchronous cloud operation
+type Operation interface {
+
+	// Waits until the operation completes or the
+	// given context is done, whichever is first.
+	Wait(ctx context.Context) error
+
+	// Returns a channel that is closed
+	// when the operation completes
+	Done() <-chan struct{}
+
+	// Returns the error the operation completed
+	// with. It will be nil until it is done.
+	Err() error
 }
 
 // interface for a cloud compute abstraction
@@ -34,6 +53,69 @@ type Compute interface {
 	// Returns a list of all compute instances
 	// within this cloud compute context
 	ListInstances() ([]ComputeInstance, error)
+
+	// Returns the instance sizes (machine types)
+	// available in the current region
+	ListSizes() ([]InstanceSize, error)
+
+	// Starts the instances having the given ids and waits
+	// for them to reach the running state. A result is
+	// returned for each id and the error will be non-nil
+	// if the operation failed for any of the instances.
+	StartInstances(ids []string) ([]InstanceResult, error)
+
+	// Stops the instances having the given ids and
+	// waits for them to reach the stopped state
+	StopInstances(ids []string) ([]InstanceResult, error)
+
+	// Restarts the instances having the given ids and
+	// waits for them to reach the running state
+	RestartInstances(ids []string) ([]InstanceResult, error)
+
+	// Creates an unattached volume (disk) which
+	// can be attached to an instance in the same
+	// zone as the volume.
+	CreateVolume(spec VolumeSpec) (Volume, error)
+
+	// Returns the machine images matching the
+	// given filter sorted newest first
+	FindImages(filter ImageFilter) ([]Image, error)
+
+	// Captures an image of the given instance's boot
+	// volume. The image carries the instance's tags.
+	CreateImage(instance ComputeInstance, name string) (Image, error)
+
+	// Deletes an image created in this
+	// cloud account with CreateImage
+	DeleteImage(id string) error
+
+	// Allocates a static public IP address in the
+	// current region. Unlike an instance's ephemeral
+	// public IP it is retained when the instance
+	// it is associated with is stopped.
+	AllocateStaticIP(name string, tags map[string]string) (StaticIP, error)
+
+	// Returns the static IPs allocated
+	// in the current region
+	ListStaticIPs() ([]StaticIP, error)
+
+	// Releases a static IP. The IP must
+	// not be associated with an instance.
+	ReleaseStaticIP(ip StaticIP) error
+}
+
+// an instance size (machine type)
+type InstanceSize struct {
+	Name     string
+	VCPUs    int
+	MemoryMB int
+}
+
+// result of an operation on an
+// instance within a batch
+type InstanceResult struct {
+	ID  string
+	Err error
 }
 
 type ComputeInstance interface {
@@ -41,6 +123,7 @@ type ComputeInstance interface {
 	Name() string
 	PublicIP() string
 	PublicDNS() string
+	PrivateIP() string
 
 	// Returns the instance's run state
 	State() (InstanceState, error)
@@ -54,9 +137,264 @@ type ComputeInstance interface {
 	// Stop the instance
 	Stop() error
 
+	// Start the instance without waiting
+	// for it to reach the running state
+	StartAsync() (Operation, error)
+
+	// Restart the instance without waiting
+	// for it to reach the running state
+	RestartAsync() (Operation, error)
+
+	// Stop the instance without waiting for
+	// it to reach the stopped state
+	StopAsync() (Operation, error)
+
+	// Returns the optional capabilities
+	// supported by the instance
+	Capabilities() (InstanceCapabilities, error)
+
+	// Suspends the instance preserving its memory
+	// state. This will fail if the instance's
+	// capabilities do not include suspend/resume.
+	Suspend() error
+
+	// Resumes a suspended instance
+	Resume() error
+
+	// Changes the size (machine type) of the instance. If
+	// the cloud requires the instance to be stopped to be
+	// resized and it is running then it will be stopped,
+	// resized and started again only if autoRestart is
+	// true. Otherwise an error is returned.
+	Resize(size string, autoRestart bool) error
+
+	// Returns the boot or serial console
+	// log output of the instance
+	ConsoleOutput() (string, error)
+
+	// Waits until the instance reaches the given state.
+	// If timeout is 0 then the compute context's
+	// operation timeout is used.
+	WaitForState(ctx context.Context, state InstanceState, timeout time.Duration) error
+
 	// Tests connectivity on a
 	// given TCP port accepts
 	CanConnect(port int) bool
+
+	// Runs the given health check
+	// against the instance
+	HealthCheck(ctx context.Context, check HealthCheck) HealthCheckResult
+
+	// Waits until all the given health checks
+	// pass or the given context is done
+	WaitUntilHealthy(ctx context.Context, checks ...HealthCheck) error
+
+	// Returns the volumes attached to the
+	// instance including its boot volume
+	Volumes() ([]Volume, error)
+
+	// Attaches the given volume to the instance
+	// as the next available data disk
+	AttachVolume(volume Volume) error
+
+	// Detaches the given volume from the instance.
+	// The boot volume cannot be detached.
+	DetachVolume(volume Volume) error
+
+	// Returns the purchasing or scheduling
+	// model of the instance
+	Scheduling() (InstanceScheduling, error)
+
+	// Returns whether a spot or preemptible instance has
+	// been marked for interruption or been interrupted.
+	// Instances that cannot be interrupted always
+	// return an empty notice.
+	InterruptionNotice() (InterruptionNotice, error)
+
+	// Returns the ingress firewall rules with
+	// a source address range that apply to
+	// the instance
+	FirewallRules() ([]FirewallRule, error)
+
+	// Adds an ingress rule allowing traffic from
+	// a source address range to a port of the
+	// instance. Rules added with a ttl are removed
+	// by RemoveExpiredFirewallRules once expired.
+	AddIngressRule(spec IngressRuleSpec) (FirewallRule, error)
+
+	// Removes the given firewall rule. A cloud
+	// rule that allows more than one protocol,
+	// port range or source will be removed
+	// in its entirety.
+	RemoveFirewallRule(rule FirewallRule) error
+
+	// Associates a static IP with the instance
+	// replacing its current public IP
+	AssociateStaticIP(ip StaticIP) error
+
+	// Disassociates a static IP from the instance
+	DisassociateStaticIP(ip StaticIP) error
+
+	// Returns the user data (i.e. cloud-init
+	// document or startup script) the instance
+	// is bootstrapped with
+	UserData() (string, error)
+
+	// Replaces the user data of the instance which
+	// is applied the next time it boots. AWS and
+	// Azure instances must be stopped to do so.
+	SetUserData(data string) error
+}
+
+// purchasing or scheduling models of an instance
+type PurchaseModel int
+
+const (
+	PurchaseOnDemand = PurchaseModel(0)
+	PurchaseSpot     = PurchaseModel(1)
+	// legacy gce preemptible instances which
+	// always stop within 24 hours
+	PurchasePreemptible = PurchaseModel(2)
+)
+
+func (p PurchaseModel) String() string {
+	return []string{"on-demand", "spot", "preemptible"}[p]
+}
+
+// normalized actions taken when
+// an instance is interrupted
+const (
+	InterruptTerminate = "terminate"
+	InterruptStop      = "stop"
+	InterruptHibernate = "hibernate"
+)
+
+type InstanceScheduling struct {
+	PurchaseModel PurchaseModel
+
+	// the maximum hourly price for spot capacity. 0
+	// caps the price at the on-demand price. gce
+	// does not support a maximum price.
+	MaxPrice float64
+
+	// the normalized action taken when a
+	// spot or preemptible instance is
+	// interrupted (i.e. InterruptStop)
+	InterruptionAction string
+}
+
+type InterruptionNotice struct {
+	// true if the instance has been marked for
+	// interruption or has been interrupted
+	Interrupted bool
+
+	// the normalized action taken and the
+	// time it takes effect if known
+	Action string
+	Time   time.Time
+
+	Message string
+}
+
+// a static public ip address
+type StaticIP struct {
+	ID      string
+	Name    string
+	Address string
+
+	// id of the instance the ip is associated
+	// with or empty if it is not associated
+	InstanceID string
+}
+
+// optional capabilities of a compute instance
+type InstanceCapabilities struct {
+	// instance can be suspended (hibernated)
+	// and resumed with its memory preserved
+	SuspendResume bool
+}
+
+// specification of a volume to create
+type VolumeSpec struct {
+	Name   string
+	SizeGB int
+
+	// the cloud specific volume type (i.e. "gp3",
+	// "Premium_LRS" or "pd-ssd"). defaults to
+	// the cloud's general purpose SSD type.
+	Type string
+
+	// the zone to create the volume in. if not
+	// provided the first zone of the region is
+	// used. azure disks are regional if a zone
+	// is not given.
+	Zone string
+
+	Tags map[string]string
+}
+
+type Volume interface {
+	ID() string
+	Name() string
+	SizeGB() int
+	Type() string
+	Zone() string
+
+	// whether the volume is attached to
+	// an instance and whether it is the
+	// boot volume of that instance
+	Attached() bool
+	Boot() bool
+
+	// Creates a point-in-time snapshot of the volume.
+	// The snapshot is named after the volume and the
+	// current time and carries the volume's tags.
+	Snapshot() (VolumeSnapshot, error)
+
+	// Deletes the volume. It must
+	// be detached first.
+	Delete() error
+}
+
+type VolumeSnapshot struct {
+	ID,
+	Name,
+	VolumeID string
+
+	SizeGB int
+}
+
+// filter for finding machine images. public images
+// are looked up by their normalized os, version
+// and architecture (i.e. "ubuntu", "22.04" and
+// "x86_64"). images created in the cloud account
+// are looked up by name prefix if owned is true.
+type ImageFilter struct {
+	OS,
+	Version,
+	Arch string
+
+	Owned bool
+	Name  string
+}
+
+type Image struct {
+	// the id of the image that can be used to
+	// launch instances. this will be an ami id, an
+	// azure marketplace urn or managed image id or
+	// a gce image path.
+	ID   string
+	Name string
+
+	// normalized os, version and architecture
+	// which will be empty if not known
+	OS,
+	Version,
+	Arch string
+
+	// creation time which will
+	// be zero if not known
+	Created time.Time
 }
 
 // interface for a cloud object store abstraction
@@ -1,14 +1,17 @@
 package cloud_test
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/gocloud/cloud/cloudinit"
 	"github.com/mevansam/goutils/logger"
 	"github.com/mevansam/goutils/utils"
 
@@ -21,6 +24,473 @@ const (
 	fiveMB = 5 * oneMB
 )
 
+// Common Compute Tests
+
+func testAsyncStopAndStart(instances ...cloud.ComputeInstance) {
+
+	var (
+		err error
+
+		state cloud.InstanceState
+	)
+
+	ops := make([]cloud.Operation, 0, len(instances))
+	for _, instance := range instances {
+		op, err := instance.StopAsync()
+		Expect(err).NotTo(HaveOccurred())
+		ops = append(ops, op)
+	}
+	for _, op := range ops {
+		err = op.Wait(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(op.Done()).To(BeClosed())
+		Expect(op.Err()).NotTo(HaveOccurred())
+	}
+	for _, instance := range instances {
+		state, err = instance.State()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state).To(Equal(cloud.StateStopped))
+	}
+
+	for _, instance := range instances {
+		_, err = instance.StartAsync()
+		Expect(err).NotTo(HaveOccurred())
+	}
+	for _, instance := range instances {
+		err = instance.WaitForState(context.Background(), cloud.StateRunning, 0)
+		Expect(err).NotTo(HaveOccurred())
+	}
+}
+
+func testBatchStopAndStart(compute cloud.Compute, ids []string, unknownID string) {
+
+	var (
+		err error
+
+		results []cloud.InstanceResult
+	)
+
+	results, err = compute.StopInstances(ids)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(len(results)).To(Equal(len(ids)))
+	for i, r := range results {
+		Expect(r.ID).To(Equal(ids[i]))
+		Expect(r.Err).NotTo(HaveOccurred())
+	}
+
+	// an unknown instance should fail without
+	// masking the results of the other instances
+	results, err = compute.StartInstances(append(ids, unknownID))
+	Expect(err).To(HaveOccurred())
+	Expect(len(results)).To(Equal(len(ids) + 1))
+	for _, r := range results[:len(ids)] {
+		Expect(r.Err).NotTo(HaveOccurred())
+	}
+	Expect(results[len(ids)].Err).To(HaveOccurred())
+
+	results, err = compute.RestartInstances(ids)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(len(results)).To(Equal(len(ids)))
+}
+
+func testSuspendAndResume(instance cloud.ComputeInstance) {
+
+	var (
+		err error
+
+		capabilities cloud.InstanceCapabilities
+		state        cloud.InstanceState
+	)
+
+	capabilities, err = instance.Capabilities()
+	Expect(err).NotTo(HaveOccurred())
+	if !capabilities.SuspendResume {
+		err = instance.Suspend()
+		Expect(err).To(HaveOccurred())
+		return
+	}
+
+	err = instance.Suspend()
+	Expect(err).NotTo(HaveOccurred())
+	err = instance.WaitForState(context.Background(), cloud.StateSuspended, 0)
+	Expect(err).NotTo(HaveOccurred())
+	state, err = instance.State()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(state).To(Equal(cloud.StateSuspended))
+
+	err = instance.Resume()
+	Expect(err).NotTo(HaveOccurred())
+	state, err = instance.State()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(state).To(Equal(cloud.StateRunning))
+}
+
+func testResize(
+	compute cloud.Compute,
+	instance cloud.ComputeInstance,
+	currentSize, newSize string,
+) {
+
+	var (
+		err error
+
+		sizes []cloud.InstanceSize
+		state cloud.InstanceState
+	)
+
+	sizes, err = compute.ListSizes()
+	Expect(err).NotTo(HaveOccurred())
+
+	foundSizes := 0
+	for _, size := range sizes {
+		if size.Name == currentSize || size.Name == newSize {
+			Expect(size.VCPUs).To(BeNumerically(">", 0))
+			Expect(size.MemoryMB).To(BeNumerically(">", 0))
+			foundSizes++
+		}
+	}
+	Expect(foundSizes).To(Equal(2))
+
+	err = instance.Resize(newSize, true)
+	Expect(err).NotTo(HaveOccurred())
+	state, err = instance.State()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(state).To(Equal(cloud.StateRunning))
+
+	err = instance.Resize(currentSize, true)
+	Expect(err).NotTo(HaveOccurred())
+	state, err = instance.State()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(state).To(Equal(cloud.StateRunning))
+}
+
+func testVolumes(
+	compute cloud.Compute,
+	instance cloud.ComputeInstance,
+	tags map[string]string,
+) {
+
+	var (
+		err error
+
+		volumes    []cloud.Volume
+		bootVolume cloud.Volume
+	)
+
+	volumes, err = instance.Volumes()
+	Expect(err).NotTo(HaveOccurred())
+	for _, v := range volumes {
+		Expect(v.Attached()).To(BeTrue())
+		if v.Boot() {
+			bootVolume = v
+		}
+	}
+	Expect(bootVolume).NotTo(BeNil())
+	err = instance.DetachVolume(bootVolume)
+	Expect(err).To(HaveOccurred())
+
+	// create the new volume in the
+	// same zone as the instance
+	testVolumeAttachment(compute, instance, bootVolume.Zone(), tags)
+}
+
+// tests the volumes of instances whose
+// boot disk is not returned as a volume
+func testVolumeAttachment(
+	compute cloud.Compute,
+	instance cloud.ComputeInstance,
+	zone string,
+	tags map[string]string,
+) {
+
+	var (
+		err error
+
+		volumes  []cloud.Volume
+		volume   cloud.Volume
+		snapshot cloud.VolumeSnapshot
+	)
+
+	volumes, err = instance.Volumes()
+	Expect(err).NotTo(HaveOccurred())
+	numVolumes := len(volumes)
+
+	volume, err = compute.CreateVolume(cloud.VolumeSpec{
+		Name:   "test-" + uuid.New().String(),
+		SizeGB: 8,
+		Zone:   zone,
+		Tags:   tags,
+	})
+	Expect(err).NotTo(HaveOccurred())
+	defer func() {
+		_ = volume.Delete()
+	}()
+	Expect(volume.SizeGB()).To(Equal(8))
+	Expect(volume.Attached()).To(BeFalse())
+	Expect(volume.Boot()).To(BeFalse())
+
+	err = instance.AttachVolume(volume)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(volume.Attached()).To(BeTrue())
+
+	volumes, err = instance.Volumes()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(len(volumes)).To(Equal(numVolumes + 1))
+	volumeAttached := false
+	for _, v := range volumes {
+		if v.ID() == volume.ID() {
+			volumeAttached = true
+		}
+	}
+	Expect(volumeAttached).To(BeTrue())
+
+	snapshot, err = volume.Snapshot()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(snapshot.VolumeID).To(Equal(volume.ID()))
+	Expect(snapshot.Name).To(HavePrefix(volume.Name()))
+	Expect(len(snapshot.ID)).To(BeNumerically(">", 0))
+
+	err = instance.DetachVolume(volume)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(volume.Attached()).To(BeFalse())
+
+	volumes, err = instance.Volumes()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(len(volumes)).To(Equal(numVolumes))
+
+	err = volume.Delete()
+	Expect(err).NotTo(HaveOccurred())
+}
+
+func testOnDemandScheduling(instance cloud.ComputeInstance) {
+
+	scheduling, err := instance.Scheduling()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(scheduling.PurchaseModel).To(Equal(cloud.PurchaseOnDemand))
+	Expect(scheduling.PurchaseModel.String()).To(Equal("on-demand"))
+
+	notice, err := instance.InterruptionNotice()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(notice.Interrupted).To(BeFalse())
+}
+
+func testFindImages(compute cloud.Compute) {
+
+	var (
+		err error
+
+		images []cloud.Image
+	)
+
+	for _, arch := range []string{cloud.ArchX86_64, cloud.ArchARM64} {
+		images, err = compute.FindImages(cloud.ImageFilter{
+			OS:      "ubuntu",
+			Version: "22.04",
+			Arch:    arch,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(images)).To(BeNumerically(">", 0))
+		for _, image := range images {
+			Expect(len(image.ID)).To(BeNumerically(">", 0))
+			Expect(image.OS).To(Equal("ubuntu"))
+			Expect(image.Version).To(Equal("22.04"))
+			Expect(image.Arch).To(Equal(arch))
+		}
+	}
+
+	_, err = compute.FindImages(cloud.ImageFilter{
+		OS:      "unknown",
+		Version: "1.0",
+	})
+	Expect(err).To(HaveOccurred())
+}
+
+func testCreateAndDeleteImage(compute cloud.Compute, instance cloud.ComputeInstance) {
+
+	var (
+		err error
+
+		image  cloud.Image
+		images []cloud.Image
+	)
+
+	name := "test-" + uuid.New().String()
+	image, err = compute.CreateImage(instance, name)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(image.Name).To(Equal(name))
+	Expect(len(image.ID)).To(BeNumerically(">", 0))
+
+	images, err = compute.FindImages(cloud.ImageFilter{
+		Owned: true,
+		Name:  name,
+	})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(len(images)).To(Equal(1))
+	Expect(images[0].ID).To(Equal(image.ID))
+
+	err = compute.DeleteImage(image.ID)
+	Expect(err).NotTo(HaveOccurred())
+
+	images, err = compute.FindImages(cloud.ImageFilter{
+		Owned: true,
+		Name:  name,
+	})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(len(images)).To(Equal(0))
+}
+
+func testFirewallRules(instance cloud.ComputeInstance) {
+
+	var (
+		err error
+
+		rule  cloud.FirewallRule
+		rules []cloud.FirewallRule
+	)
+
+	findRule := func(id string) *cloud.FirewallRule {
+		rules, err = instance.FirewallRules()
+		Expect(err).NotTo(HaveOccurred())
+		for _, r := range rules {
+			if r.ID == id {
+				return &r
+			}
+		}
+		return nil
+	}
+
+	_, err = instance.AddIngressRule(cloud.IngressRuleSpec{
+		Port: 22,
+		CIDR: "not an address",
+	})
+	Expect(err).To(HaveOccurred())
+
+	rule, err = instance.AddIngressRule(cloud.IngressRuleSpec{
+		Port: 22,
+		CIDR: "203.0.113.10",
+		TTL:  time.Hour,
+	})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rule.Protocol).To(Equal(cloud.ProtocolTCP))
+	Expect(rule.FromPort).To(Equal(22))
+	Expect(rule.ToPort).To(Equal(22))
+	Expect(rule.CIDR).To(Equal("203.0.113.10/32"))
+	Expect(rule.Expires).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+	Expect(rule.Expired()).To(BeFalse())
+
+	r := findRule(rule.ID)
+	Expect(r).NotTo(BeNil())
+	Expect(r.CIDR).To(Equal(rule.CIDR))
+	Expect(r.Expires.Equal(rule.Expires)).To(BeTrue())
+
+	_, err = cloud.RemoveExpiredFirewallRules(instance)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(findRule(rule.ID)).NotTo(BeNil())
+
+	err = instance.RemoveFirewallRule(rule)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(findRule(rule.ID)).To(BeNil())
+}
+
+// Anchored static ips are routed to the instance's own
+// public ip which remains the instance's public ip and
+// they cannot be named so their address is their name.
+func testStaticIPs(
+	compute cloud.Compute,
+	instance cloud.ComputeInstance,
+	tags map[string]string,
+	anchored bool,
+) {
+
+	var (
+		err error
+
+		ip  cloud.StaticIP
+		ips []cloud.StaticIP
+	)
+
+	findIP := func(id string) *cloud.StaticIP {
+		ips, err = compute.ListStaticIPs()
+		Expect(err).NotTo(HaveOccurred())
+		for _, i := range ips {
+			if i.ID == id {
+				return &i
+			}
+		}
+		return nil
+	}
+
+	publicIP := instance.PublicIP()
+
+	name := "test-" + uuid.New().String()
+	ip, err = compute.AllocateStaticIP(name, tags)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(len(ip.Address)).To(BeNumerically(">", 0))
+	if anchored {
+		Expect(ip.Name).To(Equal(ip.Address))
+	} else {
+		Expect(ip.Name).To(Equal(name))
+	}
+
+	i := findIP(ip.ID)
+	Expect(i).NotTo(BeNil())
+	Expect(i.Address).To(Equal(ip.Address))
+	Expect(i.InstanceID).To(BeEmpty())
+
+	err = instance.AssociateStaticIP(ip)
+	Expect(err).NotTo(HaveOccurred())
+	if anchored {
+		Expect(instance.PublicIP()).To(Equal(publicIP))
+	} else {
+		Expect(instance.PublicIP()).To(Equal(ip.Address))
+	}
+	Expect(findIP(ip.ID).InstanceID).To(Equal(instance.ID()))
+
+	err = instance.DisassociateStaticIP(ip)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(instance.PublicIP()).NotTo(Equal(ip.Address))
+	Expect(findIP(ip.ID).InstanceID).To(BeEmpty())
+
+	err = instance.DisassociateStaticIP(ip)
+	Expect(err).To(HaveOccurred())
+
+	err = compute.ReleaseStaticIP(ip)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(findIP(ip.ID)).To(BeNil())
+}
+
+func testUserData(instance cloud.ComputeInstance) {
+
+	var (
+		err error
+
+		data,
+		userData string
+	)
+
+	data, err = cloudinit.New().
+		AddCloudConfig("#cloud-config\npackages:\n  - jq\n").
+		AddScript("hello.sh", "#!/bin/bash\necho hello > /tmp/hello\n").
+		Render()
+	Expect(err).NotTo(HaveOccurred())
+
+	err = instance.Stop()
+	Expect(err).NotTo(HaveOccurred())
+	err = instance.SetUserData(data)
+	Expect(err).NotTo(HaveOccurred())
+	err = instance.Start()
+	Expect(err).NotTo(HaveOccurred())
+
+	userData, err = instance.UserData()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(userData).To(Equal(data))
+
+	doc, err := cloudinit.Parse(userData)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(len(doc.Parts)).To(Equal(2))
+	Expect(doc.Parts[1].Filename).To(Equal("hello.sh"))
+}
+
 // Common Storage Tests
 
 func testInstanceCreation(storage cloud.Storage) {
@@ -0,0 +1,253 @@
+package cloudinit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"unicode/utf8"
+)
+
+// content types of the parts of
+// a multi-part cloud-init document
+const (
+	CloudConfig = "text/cloud-config"
+	ShellScript = "text/x-shellscript"
+	Boothook    = "text/cloud-boothook"
+	IncludeURL  = "text/x-include-url"
+	PartHandler = "text/part-handler"
+	PlainText   = "text/plain"
+)
+
+// the first line prefixes that identify the
+// content type of a single part user data
+var contentTypePrefixes = []struct {
+	prefix      string
+	contentType string
+}{
+	{"#cloud-config", CloudConfig},
+	{"#cloud-boothook", Boothook},
+	{"#include", IncludeURL},
+	{"#part-handler", PartHandler},
+	{"#!", ShellScript},
+}
+
+type Part struct {
+	ContentType string
+
+	// optional file name of the part. parts
+	// are named "part-NNN" if not provided.
+	Filename string
+
+	Content string
+}
+
+// a multi-part cloud-init document
+type Document struct {
+	Parts []Part
+}
+
+func New() *Document {
+	return &Document{
+		Parts: []Part{},
+	}
+}
+
+// Adds a #cloud-config part
+func (d *Document) AddCloudConfig(config string) *Document {
+	return d.AddPart(Part{
+		ContentType: CloudConfig,
+		Filename:    "cloud-config.txt",
+		Content:     config,
+	})
+}
+
+// Adds a script that is run by cloud-init
+// once the instance has booted
+func (d *Document) AddScript(filename, script string) *Document {
+	return d.AddPart(Part{
+		ContentType: ShellScript,
+		Filename:    filename,
+		Content:     script,
+	})
+}
+
+// Adds a part of any content type. If the content
+// type is empty it is detected from the content.
+func (d *Document) AddPart(part Part) *Document {
+	if len(part.ContentType) == 0 {
+		part.ContentType = DetectContentType(part.Content)
+	}
+	d.Parts = append(d.Parts, part)
+	return d
+}
+
+// Renders the document as a MIME multi-part
+// message which can be used as user data
+func (d *Document) Render() (string, error) {
+
+	var (
+		err error
+
+		body bytes.Buffer
+		w    io.Writer
+	)
+
+	if len(d.Parts) == 0 {
+		return "", fmt.Errorf("cloud-init document has no parts")
+	}
+
+	mw := multipart.NewWriter(&body)
+	for i, part := range d.Parts {
+		filename := part.Filename
+		if len(filename) == 0 {
+			filename = fmt.Sprintf("part-%03d", i+1)
+		}
+
+		// content that is not ascii is base64 encoded
+		// as cloud-init decodes parts as us-ascii
+		// unless told otherwise
+		encoding, charset := "7bit", "us-ascii"
+		content := part.Content
+		if !isASCII(content) {
+			encoding, charset = "base64", "utf-8"
+			content = base64.StdEncoding.EncodeToString([]byte(content))
+		}
+
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", mime.FormatMediaType(part.ContentType, map[string]string{"charset": charset}))
+		header.Set("MIME-Version", "1.0")
+		header.Set("Content-Transfer-Encoding", encoding)
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+
+		if w, err = mw.CreatePart(header); err != nil {
+			return "", err
+		}
+		if _, err = io.WriteString(w, content); err != nil {
+			return "", err
+		}
+		if !strings.HasSuffix(content, "\n") {
+			if _, err = io.WriteString(w, "\n"); err != nil {
+				return "", err
+			}
+		}
+	}
+	if err = mw.Close(); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(
+		"Content-Type: %s\nMIME-Version: 1.0\n\n%s",
+		mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mw.Boundary()}),
+		body.String(),
+	), nil
+}
+
+// Parses user data as a cloud-init document. User
+// data that is not a MIME multi-part message is
+// returned as a document with a single part.
+func Parse(data string) (*Document, error) {
+
+	var (
+		err error
+
+		msg       *mail.Message
+		mediaType string
+		params    map[string]string
+		part      *multipart.Part
+		content   []byte
+	)
+
+	d := New()
+	if !strings.HasPrefix(data, "Content-Type:") && !strings.HasPrefix(data, "MIME-Version:") {
+		return d.AddPart(Part{Content: data}), nil
+	}
+
+	if msg, err = mail.ReadMessage(strings.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if mediaType, params, err = mime.ParseMediaType(msg.Header.Get("Content-Type")); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		if content, err = decodeContent(msg.Body, msg.Header.Get("Content-Transfer-Encoding")); err != nil {
+			return nil, err
+		}
+		return d.AddPart(Part{ContentType: mediaType, Content: string(content)}), nil
+	}
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		if part, err = mr.NextPart(); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if content, err = decodeContent(part, part.Header.Get("Content-Transfer-Encoding")); err != nil {
+			return nil, err
+		}
+		contentType := ""
+		if mediaType, _, err = mime.ParseMediaType(part.Header.Get("Content-Type")); err == nil {
+			contentType = mediaType
+		}
+		d.AddPart(Part{
+			ContentType: contentType,
+			Filename:    part.FileName(),
+			Content:     string(content),
+		})
+	}
+	return d, nil
+}
+
+// Returns the content type of single part user data
+// based on its first line. Content that is not
+// recognized is treated as plain text.
+func DetectContentType(content string) string {
+	for _, p := range contentTypePrefixes {
+		if strings.HasPrefix(content, p.prefix) {
+			return p.contentType
+		}
+	}
+	return PlainText
+}
+
+func decodeContent(r io.Reader, encoding string) ([]byte, error) {
+	// quoted-printable parts are
+	// decoded by the multipart reader
+	if strings.EqualFold(encoding, "base64") {
+		r = base64.NewDecoder(base64.StdEncoding, newlineStripper{r})
+	}
+	return io.ReadAll(r)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// removes line breaks from
+// base64 encoded content
+type newlineStripper struct {
+	r io.Reader
+}
+
+func (s newlineStripper) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	j := 0
+	for i := 0; i < n; i++ {
+		if p[i] != '\r' && p[i] != '\n' {
+			p[j] = p[i]
+			j++
+		}
+	}
+	return j, err
+}
@@ -0,0 +1,78 @@
+package cloudinit_test
+
+import (
+	"strings"
+
+	"github.com/mevansam/gocloud/cloud/cloudinit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cloud-Init Documents", func() {
+
+	const cloudConfig = "#cloud-config\npackages:\n  - wireguard\n"
+	const script = "#!/bin/bash\necho \"héllo wörld\" > /tmp/hello\n"
+
+	It("renders a multi-part document that can be parsed back", func() {
+
+		data, err := cloudinit.New().
+			AddCloudConfig(cloudConfig).
+			AddScript("setup.sh", script).
+			AddPart(cloudinit.Part{Content: "#include\nhttps://example.com/bootstrap\n"}).
+			Render()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(HavePrefix("Content-Type: multipart/mixed; boundary="))
+		Expect(data).To(ContainSubstring("Content-Type: text/cloud-config; charset=us-ascii"))
+		Expect(data).To(ContainSubstring("Content-Disposition: attachment; filename=setup.sh"))
+		// non-ascii content is base64 encoded
+		Expect(data).NotTo(ContainSubstring("héllo"))
+
+		doc, err := cloudinit.Parse(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Parts).To(Equal([]cloudinit.Part{
+			{
+				ContentType: cloudinit.CloudConfig,
+				Filename:    "cloud-config.txt",
+				Content:     cloudConfig,
+			},
+			{
+				ContentType: cloudinit.ShellScript,
+				Filename:    "setup.sh",
+				Content:     script,
+			},
+			{
+				ContentType: cloudinit.IncludeURL,
+				Filename:    "part-003",
+				Content:     "#include\nhttps://example.com/bootstrap\n",
+			},
+		}))
+	})
+
+	It("parses single part user data", func() {
+
+		doc, err := cloudinit.Parse(script)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(doc.Parts)).To(Equal(1))
+		Expect(doc.Parts[0].ContentType).To(Equal(cloudinit.ShellScript))
+		Expect(doc.Parts[0].Content).To(Equal(script))
+
+		// parts can be added to existing user data
+		data, err := doc.AddCloudConfig(cloudConfig).Render()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Count(data, "Content-Disposition: attachment")).To(Equal(2))
+	})
+
+	It("detects the content type of user data", func() {
+		Expect(cloudinit.DetectContentType(cloudConfig)).To(Equal(cloudinit.CloudConfig))
+		Expect(cloudinit.DetectContentType(script)).To(Equal(cloudinit.ShellScript))
+		Expect(cloudinit.DetectContentType("#cloud-boothook\necho hi\n")).To(Equal(cloudinit.Boothook))
+		Expect(cloudinit.DetectContentType("#part-handler\n")).To(Equal(cloudinit.PartHandler))
+		Expect(cloudinit.DetectContentType("key=value\n")).To(Equal(cloudinit.PlainText))
+	})
+
+	It("does not render an empty document", func() {
+		_, err := cloudinit.New().Render()
+		Expect(err).To(HaveOccurred())
+	})
+})
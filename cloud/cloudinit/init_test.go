@@ -0,0 +1,17 @@
+package cloudinit_test
+
+import (
+	"testing"
+
+	"github.com/mevansam/goutils/logger"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCloudInit(t *testing.T) {
+	logger.Initialize()
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "cloudinit")
+}
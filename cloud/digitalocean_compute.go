@@ -0,0 +1,613 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/digitalocean/godo"
+
+	"github.com/mevansam/goutils/logger"
+	"github.com/mevansam/goutils/network"
+)
+
+// number of items requested per
+// page of a digitalocean list call
+const digitalOceanPageSize = 200
+
+type DigitalOceanComputeProperties struct {
+	// timeout for start/stop operations
+	OpTimeout time.Duration
+
+	// maximum number of instance operations
+	// to run concurrently for batch operations
+	MaxConcurrency int
+
+	// only droplets with this tag are
+	// listed if it is not empty
+	FilterTag string
+}
+
+type digitalOceanCompute struct {
+	client *godo.Client
+	ctx    context.Context
+
+	region string
+
+	props DigitalOceanComputeProperties
+}
+
+type digitalOceanComputeInstance struct {
+	client *godo.Client
+	ctx    context.Context
+
+	droplet *godo.Droplet
+
+	// guards the droplet detail which is refreshed
+	// by operations running asynchronously
+	mx sync.RWMutex
+
+	props *DigitalOceanComputeProperties
+}
+
+func NewDigitalOceanCompute(client *godo.Client, region string) (Compute, error) {
+
+	return &digitalOceanCompute{
+		client: client,
+		ctx:    context.Background(),
+
+		region: region,
+
+		props: DigitalOceanComputeProperties{
+			OpTimeout:      defaultOpTimeout,
+			MaxConcurrency: defaultMaxConcurrency,
+		},
+	}, nil
+}
+
+func (c *digitalOceanCompute) newDigitalOceanComputeInstance(droplet godo.Droplet) *digitalOceanComputeInstance {
+
+	return &digitalOceanComputeInstance{
+		client: c.client,
+		ctx:    c.ctx,
+
+		droplet: &droplet,
+
+		props: &c.props,
+	}
+}
+
+// returns the droplets in the compute's region
+// having the filter tag if one has been set
+func (c *digitalOceanCompute) listDroplets() ([]godo.Droplet, error) {
+
+	var (
+		err error
+
+		page []godo.Droplet
+		resp *godo.Response
+	)
+
+	droplets := []godo.Droplet{}
+	if err = listDigitalOceanPages(func(opt *godo.ListOptions) (*godo.Response, error) {
+		if len(c.props.FilterTag) > 0 {
+			page, resp, err = c.client.Droplets.ListByTag(c.ctx, c.props.FilterTag, opt)
+		} else {
+			page, resp, err = c.client.Droplets.List(c.ctx, opt)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, droplet := range page {
+			if droplet.Region != nil && droplet.Region.Slug == c.region {
+				droplets = append(droplets, droplet)
+			}
+		}
+		return resp, nil
+	}); err != nil {
+		return nil, err
+	}
+	return droplets, nil
+}
+
+// calls the given list function for each page of results
+// until the last page has been retrieved
+func listDigitalOceanPages(list func(opt *godo.ListOptions) (*godo.Response, error)) error {
+
+	var (
+		err error
+
+		resp *godo.Response
+		page int
+	)
+
+	opt := &godo.ListOptions{
+		PerPage: digitalOceanPageSize,
+	}
+	for {
+		if resp, err = list(opt); err != nil {
+			return err
+		}
+		if resp == nil || resp.Links == nil || resp.Links.IsLastPage() {
+			return nil
+		}
+		if page, err = resp.Links.CurrentPage(); err != nil {
+			return err
+		}
+		opt.Page = page + 1
+	}
+}
+
+// waits for the given droplet, volume or
+// floating ip action to complete
+func waitForDigitalOceanAction(
+	ctx context.Context,
+	client *godo.Client,
+	action *godo.Action,
+) error {
+
+	var (
+		err error
+	)
+
+	for {
+		switch action.Status {
+		case godo.ActionCompleted:
+			return nil
+		case godo.ActionInProgress:
+		default:
+			return fmt.Errorf(
+				"action '%s' (%d) did not complete: %s",
+				action.Type, action.ID, action.Status,
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"timed out waiting for action '%s' (%d) to complete: %s",
+				action.Type, action.ID, ctx.Err(),
+			)
+		case <-time.After(statePollInterval):
+		}
+		if action, _, err = client.Actions.Get(ctx, action.ID); err != nil {
+			return err
+		}
+	}
+}
+
+// returns whether the given error
+// response is a not found error
+func isDigitalOceanNotFound(resp *godo.Response) bool {
+	return resp != nil && resp.Response != nil &&
+		resp.StatusCode == http.StatusNotFound
+}
+
+// interface: cloud/Compute implementation
+
+func (c *digitalOceanCompute) SetProperties(props interface{}) {
+
+	p := props.(DigitalOceanComputeProperties)
+	if p.OpTimeout != 0 {
+		c.props.OpTimeout = p.OpTimeout
+	}
+	if p.MaxConcurrency != 0 {
+		c.props.MaxConcurrency = p.MaxConcurrency
+	}
+	if len(p.FilterTag) > 0 {
+		c.props.FilterTag = p.FilterTag
+	}
+}
+
+func (c *digitalOceanCompute) GetInstance(name string) (ComputeInstance, error) {
+
+	var (
+		err error
+
+		droplets []godo.Droplet
+		instance *digitalOceanComputeInstance
+	)
+
+	if droplets, err = c.listDroplets(); err != nil {
+		return nil, err
+	}
+
+	// droplet names are not unique so the name
+	// must match exactly one droplet in the region
+	for _, droplet := range droplets {
+		if droplet.Name == name {
+			if instance != nil {
+				return nil, fmt.Errorf(
+					"more than one droplet named '%s' was found in region '%s'",
+					name, c.region,
+				)
+			}
+			instance = c.newDigitalOceanComputeInstance(droplet)
+		}
+	}
+	if instance == nil {
+		return nil, fmt.Errorf("droplet '%s' was not found in region '%s'", name, c.region)
+	}
+	return instance, nil
+}
+
+func (c *digitalOceanCompute) GetInstances(ids []string) ([]ComputeInstance, error) {
+
+	var (
+		err error
+
+		dropletID int
+		droplet   *godo.Droplet
+		resp      *godo.Response
+	)
+
+	instances := make([]ComputeInstance, 0, len(ids))
+	for _, id := range ids {
+		if dropletID, err = strconv.Atoi(id); err != nil {
+			return nil, fmt.Errorf("invalid droplet id '%s'", id)
+		}
+		if droplet, resp, err = c.client.Droplets.Get(c.ctx, dropletID); err != nil {
+			if isDigitalOceanNotFound(resp) {
+				continue
+			}
+			return nil, err
+		}
+		instances = append(instances, c.newDigitalOceanComputeInstance(*droplet))
+	}
+	return instances, nil
+}
+
+func (c *digitalOceanCompute) ListInstances() ([]ComputeInstance, error) {
+
+	var (
+		err error
+
+		droplets []godo.Droplet
+	)
+
+	if droplets, err = c.listDroplets(); err != nil {
+		return nil, err
+	}
+	instances := make([]ComputeInstance, 0, len(droplets))
+	for _, droplet := range droplets {
+		instances = append(instances, c.newDigitalOceanComputeInstance(droplet))
+	}
+	return instances, nil
+}
+
+func (c *digitalOceanCompute) ListSizes() ([]InstanceSize, error) {
+
+	var (
+		err error
+	)
+
+	sizes := []InstanceSize{}
+	if err = listDigitalOceanPages(func(opt *godo.ListOptions) (*godo.Response, error) {
+		page, resp, err := c.client.Sizes.List(c.ctx, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range page {
+			if !s.Available {
+				continue
+			}
+			for _, region := range s.Regions {
+				if region == c.region {
+					sizes = append(sizes, InstanceSize{
+						Name:     s.Slug,
+						VCPUs:    s.Vcpus,
+						MemoryMB: s.Memory,
+					})
+					break
+				}
+			}
+		}
+		return resp, nil
+	}); err != nil {
+		return nil, err
+	}
+	sortInstanceSizes(sizes)
+	return sizes, nil
+}
+
+func (c *digitalOceanCompute) StartInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Start()
+	})
+}
+
+func (c *digitalOceanCompute) StopInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Stop()
+	})
+}
+
+func (c *digitalOceanCompute) RestartInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Restart()
+	})
+}
+
+// returns the last retrieved droplet detail
+func (c *digitalOceanComputeInstance) detail() *godo.Droplet {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+	return c.droplet
+}
+
+// replaces the droplet detail with a refreshed copy
+func (c *digitalOceanComputeInstance) setDetail(droplet *godo.Droplet) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.droplet = droplet
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *digitalOceanComputeInstance) ID() string {
+	return strconv.Itoa(c.detail().ID)
+}
+
+func (c *digitalOceanComputeInstance) Name() string {
+	return c.detail().Name
+}
+
+func (c *digitalOceanComputeInstance) PublicIP() string {
+	ip, _ := c.detail().PublicIPv4()
+	return ip
+}
+
+// Droplets do not have a public DNS
+// name so an empty string is returned.
+func (c *digitalOceanComputeInstance) PublicDNS() string {
+	return ""
+}
+
+func (c *digitalOceanComputeInstance) PrivateIP() string {
+	ip, _ := c.detail().PrivateIPv4()
+	return ip
+}
+
+// refreshes the droplet's details
+func (c *digitalOceanComputeInstance) refresh() error {
+
+	droplet, _, err := c.client.Droplets.Get(c.ctx, c.detail().ID)
+	if err != nil {
+		return err
+	}
+	c.setDetail(droplet)
+	return nil
+}
+
+// returns an operation that waits for the
+// given action and refreshes the droplet
+func (c *digitalOceanComputeInstance) actionOperation(action *godo.Action) Operation {
+
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		if err := waitForDigitalOceanAction(ctx, c.client, action); err != nil {
+			return err
+		}
+		return c.refresh()
+	})
+}
+
+func (c *digitalOceanComputeInstance) State() (InstanceState, error) {
+
+	var (
+		err error
+	)
+
+	if err = c.refresh(); err != nil {
+		return StateUnknown, err
+	}
+
+	droplet := c.detail()
+	logger.TraceMessage("Status for droplet '%s' is: %s (locked: %t)",
+		droplet.Name, droplet.Status, droplet.Locked)
+
+	// a droplet is locked while
+	// an action is in progress
+	if droplet.Locked {
+		return StatePending, nil
+	}
+	switch droplet.Status {
+	case "active":
+		return StateRunning, nil
+	case "off":
+		return StateStopped, nil
+	case "new":
+		return StatePending, nil
+	default:
+		return StateUnknown, nil
+	}
+}
+
+func (c *digitalOceanComputeInstance) Start() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.StartAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *digitalOceanComputeInstance) Restart() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.RestartAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *digitalOceanComputeInstance) Stop() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.StopAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *digitalOceanComputeInstance) StartAsync() (Operation, error) {
+
+	var (
+		err error
+
+		action *godo.Action
+	)
+
+	logger.TraceMessage("Powering on droplet '%s'.", c.detail().Name)
+
+	if action, _, err = c.client.DropletActions.PowerOn(c.ctx, c.detail().ID); err != nil {
+		return nil, err
+	}
+	return c.actionOperation(action), nil
+}
+
+func (c *digitalOceanComputeInstance) RestartAsync() (Operation, error) {
+
+	var (
+		err error
+
+		action *godo.Action
+	)
+
+	logger.TraceMessage("Rebooting droplet '%s'.", c.detail().Name)
+
+	if action, _, err = c.client.DropletActions.Reboot(c.ctx, c.detail().ID); err != nil {
+		return nil, err
+	}
+	return c.actionOperation(action), nil
+}
+
+// The droplet is shut down gracefully and is
+// powered off if the shutdown does not complete.
+func (c *digitalOceanComputeInstance) StopAsync() (Operation, error) {
+
+	var (
+		err error
+
+		action *godo.Action
+	)
+
+	logger.TraceMessage("Shutting down droplet '%s'.", c.detail().Name)
+
+	if action, _, err = c.client.DropletActions.Shutdown(c.ctx, c.detail().ID); err != nil {
+		return nil, err
+	}
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+
+		var (
+			err error
+		)
+
+		if err = waitForDigitalOceanAction(ctx, c.client, action); err != nil {
+			logger.DebugMessage(
+				"Shutdown of droplet '%s' failed. Powering it off: %s",
+				c.detail().Name, err.Error(),
+			)
+
+			// the operation's context will have expired if the
+			// shutdown timed out so the power off gets its own
+			powerOffCtx, cancel := context.WithTimeout(context.Background(), c.props.OpTimeout)
+			defer cancel()
+
+			if action, _, err = c.client.DropletActions.PowerOff(powerOffCtx, c.detail().ID); err != nil {
+				return err
+			}
+			if err = waitForDigitalOceanAction(powerOffCtx, c.client, action); err != nil {
+				return err
+			}
+		}
+		return c.refresh()
+	}), nil
+}
+
+func (c *digitalOceanComputeInstance) Capabilities() (InstanceCapabilities, error) {
+	return InstanceCapabilities{}, nil
+}
+
+func (c *digitalOceanComputeInstance) Suspend() error {
+	return fmt.Errorf("droplet '%s' cannot be suspended", c.detail().Name)
+}
+
+func (c *digitalOceanComputeInstance) Resume() error {
+	return fmt.Errorf("droplet '%s' cannot be resumed", c.detail().Name)
+}
+
+// A droplet must be powered off to be resized. Only its
+// cpu and memory are resized so that the droplet can be
+// resized back to a smaller size.
+func (c *digitalOceanComputeInstance) Resize(size string, autoRestart bool) error {
+
+	return resizeInstance(c, autoRestart, true, func() error {
+
+		var (
+			err error
+
+			action *godo.Action
+		)
+
+		logger.TraceMessage("Resizing droplet '%s' to '%s'.", c.detail().Name, size)
+
+		if action, _, err = c.client.DropletActions.Resize(c.ctx, c.detail().ID, size, false); err != nil {
+			return err
+		}
+		return c.actionOperation(action).Wait(context.Background())
+	})
+}
+
+func (c *digitalOceanComputeInstance) ConsoleOutput() (string, error) {
+	return "", fmt.Errorf(
+		"the console output of droplet '%s' is not available via the api",
+		c.detail().Name,
+	)
+}
+
+func (c *digitalOceanComputeInstance) WaitForState(
+	ctx context.Context,
+	state InstanceState,
+	timeout time.Duration,
+) error {
+
+	if timeout == 0 {
+		timeout = c.props.OpTimeout
+	}
+	return waitForState(ctx, c, state, timeout)
+}
+
+func (c *digitalOceanComputeInstance) CanConnect(port int) bool {
+
+	if ip := c.PublicIP(); ip != "" {
+		return network.CanConnect(ip, port)
+	} else {
+		return false
+	}
+}
+
+func (c *digitalOceanComputeInstance) HealthCheck(ctx context.Context, check HealthCheck) HealthCheckResult {
+	return CheckHealth(ctx, c, check)
+}
+
+func (c *digitalOceanComputeInstance) WaitUntilHealthy(ctx context.Context, checks ...HealthCheck) error {
+	return WaitUntilHealthy(ctx, c, checks...)
+}
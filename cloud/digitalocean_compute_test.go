@@ -0,0 +1,227 @@
+package cloud_test
+
+import (
+	"strconv"
+
+	"github.com/digitalocean/godo"
+
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/gocloud/provider"
+	"github.com/mevansam/goutils/logger"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_helpers "github.com/mevansam/gocloud/test/helpers"
+)
+
+var _ = Describe("DigitalOcean Compute Tests", func() {
+
+	var (
+		err error
+
+		digitalOceanProvider provider.CloudProvider
+		digitalOceanCompute  cloud.Compute
+
+		testDroplets map[string]*godo.Droplet
+	)
+
+	BeforeEach(func() {
+
+		digitalOceanProvider, err = provider.NewCloudProvider("digitalocean")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digitalOceanProvider).ToNot(BeNil())
+
+		test_helpers.InitializeDigitalOceanProvider(digitalOceanProvider)
+
+		err = digitalOceanProvider.Connect()
+		Expect(err).NotTo(HaveOccurred())
+
+		digitalOceanCompute, err = digitalOceanProvider.GetCompute()
+		Expect(err).NotTo(HaveOccurred())
+
+		// ensure 2 test droplets have been created for these tests
+		testDroplets = test_helpers.DigitalOceanDeployTestDroplets("test", 2)
+		Expect(len(testDroplets)).To(Equal(2))
+
+		logger.DebugMessage("Test droplets: %# v", testDroplets)
+
+		digitalOceanCompute.SetProperties(cloud.DigitalOceanComputeProperties{
+			FilterTag: "cloudbuilder-test",
+		})
+	})
+
+	Context("Compute resources", func() {
+
+		It("retrieves a list of compute instances", func() {
+
+			instances, err := digitalOceanCompute.ListInstances()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(instances)).To(Equal(len(testDroplets)))
+
+			for _, instance := range instances {
+				droplet, exists := testDroplets[instance.Name()]
+				Expect(exists).To(BeTrue())
+				publicIP, _ := droplet.PublicIPv4()
+				Expect(instance.PublicIP()).To(Equal(publicIP))
+			}
+		})
+
+		It("retrieves a list of compute instances by their ids", func() {
+
+			instanceIds := []string{}
+			for _, droplet := range testDroplets {
+				instanceIds = append(instanceIds, strconv.Itoa(droplet.ID))
+			}
+
+			instances, err := digitalOceanCompute.GetInstances(instanceIds)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(instances)).To(Equal(len(testDroplets)))
+
+			for _, instance := range instances {
+				droplet, exists := testDroplets[instance.Name()]
+				Expect(exists).To(BeTrue())
+				Expect(instance.ID()).To(Equal(strconv.Itoa(droplet.ID)))
+			}
+		})
+
+		It("stops and starts a batch of compute instances", func() {
+
+			instanceIds := []string{}
+			for _, droplet := range testDroplets {
+				instanceIds = append(instanceIds, strconv.Itoa(droplet.ID))
+			}
+			testBatchStopAndStart(digitalOceanCompute, instanceIds, "1")
+		})
+
+		It("retrieves a compute instance", func() {
+
+			_, err := digitalOceanCompute.GetInstance("test-X")
+			Expect(err).To(HaveOccurred())
+
+			instance, err := digitalOceanCompute.GetInstance("test-0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance).ToNot(BeNil())
+		})
+	})
+
+	Context("Compute instance", func() {
+
+		var (
+			instance0 cloud.ComputeInstance
+			instance1 cloud.ComputeInstance
+		)
+
+		BeforeEach(func() {
+			instance0, err = digitalOceanCompute.GetInstance("test-0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance0).ToNot(BeNil())
+
+			instance1, err = digitalOceanCompute.GetInstance("test-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance1).ToNot(BeNil())
+		})
+
+		It("stops and starts a compute instance", func() {
+
+			state, err := instance0.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateRunning))
+			Expect(instance0.CanConnect(22)).To(BeTrue())
+			Expect(instance0.CanConnect(23)).To(BeFalse())
+
+			err = instance0.Stop()
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err = instance0.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateStopped))
+			Expect(instance0.CanConnect(22)).To(BeFalse())
+
+			id, _ := strconv.Atoi(instance0.ID())
+			Expect(test_helpers.DigitalOceanDropletStatus(id)).To(Equal("off"))
+
+			err = instance0.Start()
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err = instance0.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateRunning))
+		})
+
+		It("restarts a compute instance", func() {
+
+			err = instance1.Restart()
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err := instance1.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateRunning))
+		})
+
+		It("stops and starts compute instances asynchronously", func() {
+			testAsyncStopAndStart(instance0, instance1)
+		})
+
+		It("does not suspend a compute instance", func() {
+			testSuspendAndResume(instance0)
+		})
+
+		It("resizes a compute instance", func() {
+			testResize(digitalOceanCompute, instance1, "s-1vcpu-1gb", "s-1vcpu-2gb")
+		})
+
+		It("retrieves the scheduling of a compute instance", func() {
+			testOnDemandScheduling(instance0)
+		})
+
+		It("finds public images", func() {
+
+			images, err := digitalOceanCompute.FindImages(cloud.ImageFilter{
+				OS:      "ubuntu",
+				Version: "22.04",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(images)).To(Equal(1))
+			Expect(images[0].Arch).To(Equal(cloud.ArchX86_64))
+
+			// arm images are not published
+			_, err = digitalOceanCompute.FindImages(cloud.ImageFilter{
+				OS:      "ubuntu",
+				Version: "22.04",
+				Arch:    cloud.ArchARM64,
+			})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("creates and deletes an image of a compute instance", func() {
+			testCreateAndDeleteImage(digitalOceanCompute, instance1)
+		})
+
+		It("adds and removes firewall rules of a compute instance", func() {
+
+			// rules are not added to droplets without a
+			// firewall as they accept all inbound traffic
+			_, err = instance1.AddIngressRule(cloud.IngressRuleSpec{
+				Port: 22,
+				CIDR: "203.0.113.10",
+			})
+			Expect(err).To(HaveOccurred())
+
+			id, err := strconv.Atoi(instance0.ID())
+			Expect(err).NotTo(HaveOccurred())
+			test_helpers.DigitalOceanAddTestFirewall(id)
+			testFirewallRules(instance0)
+		})
+
+		It("reserves, assigns and releases a floating ip", func() {
+			testStaticIPs(digitalOceanCompute, instance1, nil, true)
+		})
+
+		It("creates, attaches, snapshots and detaches a volume", func() {
+			testVolumeAttachment(digitalOceanCompute, instance0, "", map[string]string{
+				"cloudbuilder-test": "",
+			})
+		})
+	})
+})
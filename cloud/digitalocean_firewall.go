@@ -0,0 +1,265 @@
+package cloud
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/godo"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// prefix of the names of firewalls
+// created for ingress rules
+const digitalOceanFirewallPrefix = "gocloud-"
+
+// digitalocean firewalls do not have a description
+// so the expiry of a rule is recorded in its name
+var digitalOceanFirewallExpiry = regexp.MustCompile(`-expires-(\d+)$`)
+
+// returns the firewall rules of a digitalocean firewall
+// which will be more than one if it has multiple inbound
+// rules or an inbound rule has multiple source addresses
+func newDigitalOceanFirewallRules(firewall *godo.Firewall) []FirewallRule {
+
+	expires := time.Time{}
+	if m := digitalOceanFirewallExpiry.FindStringSubmatch(firewall.Name); m != nil {
+		if t, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+			expires = time.Unix(t, 0).UTC()
+		}
+	}
+
+	rules := []FirewallRule{}
+	for _, inbound := range firewall.InboundRules {
+		// "0" and "all" are both all ports
+		// and icmp rules have no ports
+		portRange := inbound.PortRange
+		if portRange == "0" || portRange == "all" {
+			portRange = ""
+		}
+		fromPort, toPort, err := parsePortRange(portRange)
+		if err != nil {
+			logger.DebugMessage(
+				"Ignoring port range '%s' of firewall '%s': %s",
+				inbound.PortRange, firewall.Name, err.Error(),
+			)
+			continue
+		}
+		if inbound.Sources == nil {
+			continue
+		}
+		for _, cidr := range inbound.Sources.Addresses {
+			rules = append(rules, FirewallRule{
+				ID:          firewall.ID,
+				Description: firewall.Name,
+				Protocol:    strings.ToLower(inbound.Protocol),
+				FromPort:    fromPort,
+				ToPort:      toPort,
+				CIDR:        cidr,
+				Expires:     expires,
+			})
+		}
+	}
+	return rules
+}
+
+// returns the inbound rules of a firewall
+// allowing the given spec's traffic
+func digitalOceanInboundRules(spec IngressRuleSpec) []godo.InboundRule {
+
+	sources := &godo.Sources{
+		Addresses: []string{spec.CIDR},
+	}
+	switch spec.Protocol {
+	case ProtocolAll:
+		return []godo.InboundRule{
+			{Protocol: ProtocolTCP, PortRange: "all", Sources: sources},
+			{Protocol: ProtocolUDP, PortRange: "all", Sources: sources},
+			{Protocol: ProtocolICMP, Sources: sources},
+		}
+	case ProtocolICMP:
+		return []godo.InboundRule{
+			{Protocol: ProtocolICMP, Sources: sources},
+		}
+	default:
+		return []godo.InboundRule{
+			{Protocol: spec.Protocol, PortRange: strconv.Itoa(spec.Port), Sources: sources},
+		}
+	}
+}
+
+// returns a copy of the given inbound rule without the given
+// source address and whether the copy has any sources left
+func digitalOceanInboundRuleWithoutSource(inbound godo.InboundRule, cidr string) (godo.InboundRule, bool) {
+
+	if inbound.Sources == nil {
+		return inbound, false
+	}
+	sources := *inbound.Sources
+	sources.Addresses = []string{}
+	for _, address := range inbound.Sources.Addresses {
+		if address != cidr {
+			sources.Addresses = append(sources.Addresses, address)
+		}
+	}
+	inbound.Sources = &sources
+
+	return inbound, len(sources.Addresses) > 0 ||
+		len(sources.Tags) > 0 ||
+		len(sources.DropletIDs) > 0 ||
+		len(sources.LoadBalancerUIDs) > 0 ||
+		len(sources.KubernetesIDs) > 0
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *digitalOceanComputeInstance) FirewallRules() ([]FirewallRule, error) {
+
+	var (
+		err error
+	)
+
+	rules := []FirewallRule{}
+	if err = listDigitalOceanPages(func(opt *godo.ListOptions) (*godo.Response, error) {
+		page, resp, err := c.client.Firewalls.ListByDroplet(c.ctx, c.detail().ID, opt)
+		if err != nil {
+			return nil, err
+		}
+		for i := range page {
+			rules = append(rules, newDigitalOceanFirewallRules(&page[i])...)
+		}
+		return resp, nil
+	}); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Ingress rules are added as firewalls that are applied
+// to the droplet in addition to its existing firewalls. A
+// droplet without a firewall accepts all traffic and adding
+// a firewall to it would deny all other inbound traffic so
+// rules are only added to droplets that have a firewall.
+// The description of the rule is not retained and the rule's
+// description will be the name of its firewall.
+func (c *digitalOceanComputeInstance) AddIngressRule(spec IngressRuleSpec) (FirewallRule, error) {
+
+	var (
+		err error
+
+		firewall *godo.Firewall
+	)
+
+	if spec, err = spec.normalize(); err != nil {
+		return FirewallRule{}, err
+	}
+
+	// firewalls created for other ingress rules may
+	// expire and do not restrict the droplet's traffic
+	hasFirewall := false
+	if err = listDigitalOceanPages(func(opt *godo.ListOptions) (*godo.Response, error) {
+		page, resp, err := c.client.Firewalls.ListByDroplet(c.ctx, c.detail().ID, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range page {
+			if !strings.HasPrefix(f.Name, digitalOceanFirewallPrefix) {
+				hasFirewall = true
+			}
+		}
+		return resp, nil
+	}); err != nil {
+		return FirewallRule{}, err
+	}
+	if !hasFirewall {
+		return FirewallRule{}, fmt.Errorf(
+			"droplet '%s' does not have a firewall so it already accepts all inbound traffic",
+			c.detail().Name,
+		)
+	}
+
+	name := fmt.Sprintf("%s%d-%s",
+		digitalOceanFirewallPrefix, c.detail().ID,
+		strconv.FormatInt(time.Now().UnixNano(), 36),
+	)
+	if spec.TTL > 0 {
+		name = fmt.Sprintf("%s-expires-%d", name, time.Now().Add(spec.TTL).Unix())
+	}
+
+	logger.TraceMessage(
+		"Adding firewall '%s' for %s port %d from '%s' to droplet '%s'.",
+		name, spec.Protocol, spec.Port, spec.CIDR, c.detail().Name,
+	)
+
+	if firewall, _, err = c.client.Firewalls.Create(c.ctx, &godo.FirewallRequest{
+		Name:         name,
+		InboundRules: digitalOceanInboundRules(spec),
+		DropletIDs:   []int{c.detail().ID},
+	}); err != nil {
+		return FirewallRule{}, err
+	}
+	rules := newDigitalOceanFirewallRules(firewall)
+	if len(rules) == 0 {
+		return FirewallRule{}, fmt.Errorf("firewall '%s' was not created as requested", name)
+	}
+	return rules[0], nil
+}
+
+// Firewalls created for ingress rules are deleted. For
+// any other firewall the inbound rule matching the given
+// rule is removed from the firewall.
+func (c *digitalOceanComputeInstance) RemoveFirewallRule(rule FirewallRule) error {
+
+	var (
+		err error
+
+		firewall *godo.Firewall
+	)
+
+	if firewall, _, err = c.client.Firewalls.Get(c.ctx, rule.ID); err != nil {
+		return err
+	}
+	if strings.HasPrefix(firewall.Name, digitalOceanFirewallPrefix) {
+		logger.TraceMessage(
+			"Removing firewall '%s' of droplet '%s'.", firewall.Name, c.detail().Name)
+
+		_, err = c.client.Firewalls.Delete(c.ctx, firewall.ID)
+		return err
+	}
+
+	for _, inbound := range firewall.InboundRules {
+		for _, r := range newDigitalOceanFirewallRules(&godo.Firewall{
+			ID:           firewall.ID,
+			Name:         firewall.Name,
+			InboundRules: []godo.InboundRule{inbound},
+		}) {
+			if r.Protocol == rule.Protocol &&
+				r.FromPort == rule.FromPort && r.ToPort == rule.ToPort &&
+				r.CIDR == rule.CIDR {
+
+				logger.TraceMessage(
+					"Removing source '%s' of %s rule for ports '%s' from firewall '%s' of droplet '%s'.",
+					rule.CIDR, inbound.Protocol, inbound.PortRange, firewall.Name, c.detail().Name,
+				)
+
+				if _, err = c.client.Firewalls.RemoveRules(c.ctx, firewall.ID, &godo.FirewallRulesRequest{
+					InboundRules: []godo.InboundRule{inbound},
+				}); err != nil {
+					return err
+				}
+				// the inbound rule is re-added with
+				// the sources that remain if any
+				if remaining, ok := digitalOceanInboundRuleWithoutSource(inbound, rule.CIDR); ok {
+					_, err = c.client.Firewalls.AddRules(c.ctx, firewall.ID, &godo.FirewallRulesRequest{
+						InboundRules: []godo.InboundRule{remaining},
+					})
+				}
+				return err
+			}
+		}
+	}
+	return fmt.Errorf("rule was not found in firewall '%s' of droplet '%s'", firewall.Name, c.detail().Name)
+}
@@ -0,0 +1,164 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/godo"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+func newDigitalOceanImage(image *godo.Image, source *imageSource) Image {
+
+	i := Image{
+		ID:   strconv.Itoa(image.ID),
+		Name: image.Name,
+	}
+	if source != nil {
+		i.OS = source.os
+		i.Version = source.version
+		i.Arch = source.arch
+	}
+	i.Created, _ = time.Parse(time.RFC3339, image.Created)
+	return i
+}
+
+// interface: cloud/Compute implementation
+
+func (c *digitalOceanCompute) FindImages(filter ImageFilter) ([]Image, error) {
+
+	var (
+		err error
+
+		source *imageSource
+		image  *godo.Image
+	)
+
+	images := []Image{}
+	if filter.Owned {
+		if err = listDigitalOceanPages(func(opt *godo.ListOptions) (*godo.Response, error) {
+			page, resp, err := c.client.Images.ListUser(c.ctx, opt)
+			if err != nil {
+				return nil, err
+			}
+			for i := range page {
+				if strings.HasPrefix(page[i].Name, filter.Name) {
+					images = append(images, newDigitalOceanImage(&page[i], nil))
+				}
+			}
+			return resp, nil
+		}); err != nil {
+			return nil, err
+		}
+		sortImages(images)
+		return images, nil
+	}
+
+	// the distribution image slug always
+	// refers to the latest image release
+	if source, err = lookupImageSource(filter); err != nil {
+		return nil, err
+	}
+	if len(source.digitalOceanSlug) == 0 {
+		return nil, fmt.Errorf(
+			"digitalocean does not publish '%s' images for os '%s' version '%s'",
+			source.arch, source.os, source.version,
+		)
+	}
+	if image, _, err = c.client.Images.GetBySlug(c.ctx, source.digitalOceanSlug); err != nil {
+		return nil, err
+	}
+	return append(images, newDigitalOceanImage(image, source)), nil
+}
+
+// The image is a snapshot of the droplet. Droplet
+// snapshots cannot be tagged when they are created
+// so the droplet's tags are added to the image once
+// the snapshot completes.
+func (c *digitalOceanCompute) CreateImage(instance ComputeInstance, name string) (Image, error) {
+
+	var (
+		err error
+		ok  bool
+
+		doInstance *digitalOceanComputeInstance
+
+		action *godo.Action
+		image  *godo.Image
+	)
+
+	if doInstance, ok = instance.(*digitalOceanComputeInstance); !ok {
+		return Image{}, fmt.Errorf("instance '%s' is not a digitalocean droplet", instance.Name())
+	}
+	droplet := doInstance.detail()
+
+	logger.TraceMessage(
+		"Creating image '%s' from snapshot of droplet '%s'.",
+		name, droplet.Name,
+	)
+
+	if action, _, err = c.client.DropletActions.Snapshot(c.ctx, droplet.ID, name); err != nil {
+		return Image{}, err
+	}
+	if err = newOperation(defaultImageOpTimeout, func(ctx context.Context) error {
+		return waitForDigitalOceanAction(ctx, c.client, action)
+	}).Wait(context.Background()); err != nil {
+		return Image{}, err
+	}
+
+	// the most recent snapshot with
+	// the name is the one created
+	if err = listDigitalOceanPages(func(opt *godo.ListOptions) (*godo.Response, error) {
+		page, resp, err := c.client.Droplets.Snapshots(c.ctx, droplet.ID, opt)
+		if err != nil {
+			return nil, err
+		}
+		for i := range page {
+			if page[i].Name == name &&
+				(image == nil || page[i].Created > image.Created) {
+				image = &page[i]
+			}
+		}
+		return resp, nil
+	}); err != nil {
+		return Image{}, err
+	}
+	if image == nil {
+		return Image{}, fmt.Errorf("snapshot '%s' of droplet '%s' was not found", name, droplet.Name)
+	}
+
+	for _, tag := range droplet.Tags {
+		if _, err = c.client.Tags.TagResources(c.ctx, tag, &godo.TagResourcesRequest{
+			Resources: []godo.Resource{
+				{
+					ID:   strconv.Itoa(image.ID),
+					Type: godo.ImageResourceType,
+				},
+			},
+		}); err != nil {
+			return Image{}, err
+		}
+	}
+	return newDigitalOceanImage(image, nil), nil
+}
+
+func (c *digitalOceanCompute) DeleteImage(id string) error {
+
+	var (
+		err error
+
+		imageID int
+	)
+
+	if imageID, err = strconv.Atoi(id); err != nil {
+		return fmt.Errorf("invalid image id '%s'", id)
+	}
+	logger.TraceMessage("Deleting image '%s'.", id)
+
+	_, err = c.client.Images.Delete(c.ctx, imageID)
+	return err
+}
@@ -0,0 +1,15 @@
+package cloud
+
+// interface: cloud/ComputeInstance implementation
+
+// Droplets do not have a spot or preemptible
+// purchase model so they are always on-demand.
+func (c *digitalOceanComputeInstance) Scheduling() (InstanceScheduling, error) {
+	return InstanceScheduling{
+		PurchaseModel: PurchaseOnDemand,
+	}, nil
+}
+
+func (c *digitalOceanComputeInstance) InterruptionNotice() (InterruptionNotice, error) {
+	return InterruptionNotice{}, nil
+}
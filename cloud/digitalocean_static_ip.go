@@ -0,0 +1,134 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/digitalocean/godo"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// returns the static ip of the given floating ip
+// with the id of the droplet it is assigned to
+func newDigitalOceanStaticIP(floatingIP *godo.FloatingIP) StaticIP {
+
+	ip := StaticIP{
+		ID:      floatingIP.IP,
+		Name:    floatingIP.IP,
+		Address: floatingIP.IP,
+	}
+	if floatingIP.Droplet != nil {
+		ip.InstanceID = strconv.Itoa(floatingIP.Droplet.ID)
+	}
+	return ip
+}
+
+// interface: cloud/Compute implementation
+
+// Static IPs are reserved as floating IPs in the compute's
+// region. Floating IPs cannot be named or tagged so the name
+// and tags are ignored and the address is used as the name.
+func (c *digitalOceanCompute) AllocateStaticIP(name string, tags map[string]string) (StaticIP, error) {
+
+	var (
+		err error
+
+		floatingIP *godo.FloatingIP
+	)
+
+	logger.TraceMessage(
+		"Reserving floating IP for '%s' in region '%s'.", name, c.region)
+
+	if floatingIP, _, err = c.client.FloatingIPs.Create(c.ctx, &godo.FloatingIPCreateRequest{
+		Region: c.region,
+	}); err != nil {
+		return StaticIP{}, err
+	}
+	return newDigitalOceanStaticIP(floatingIP), nil
+}
+
+func (c *digitalOceanCompute) ListStaticIPs() ([]StaticIP, error) {
+
+	var (
+		err error
+	)
+
+	ips := []StaticIP{}
+	if err = listDigitalOceanPages(func(opt *godo.ListOptions) (*godo.Response, error) {
+		page, resp, err := c.client.FloatingIPs.List(c.ctx, opt)
+		if err != nil {
+			return nil, err
+		}
+		for i := range page {
+			if page[i].Region != nil && page[i].Region.Slug == c.region {
+				ips = append(ips, newDigitalOceanStaticIP(&page[i]))
+			}
+		}
+		return resp, nil
+	}); err != nil {
+		return nil, err
+	}
+	return ips, nil
+}
+
+func (c *digitalOceanCompute) ReleaseStaticIP(ip StaticIP) error {
+
+	logger.TraceMessage(
+		"Releasing floating IP '%s' in region '%s'.", ip.Address, c.region)
+
+	_, err := c.client.FloatingIPs.Delete(c.ctx, ip.Address)
+	return err
+}
+
+// interface: cloud/ComputeInstance implementation
+
+// The floating IP is routed to the droplet's anchor IP
+// so the droplet keeps its own public IP which will
+// continue to be returned by PublicIP().
+func (c *digitalOceanComputeInstance) AssociateStaticIP(ip StaticIP) error {
+
+	var (
+		err error
+
+		action *godo.Action
+	)
+
+	logger.TraceMessage(
+		"Assigning floating IP '%s' to droplet '%s'.",
+		ip.Address, c.detail().Name,
+	)
+
+	if action, _, err = c.client.FloatingIPActions.Assign(c.ctx, ip.Address, c.detail().ID); err != nil {
+		return err
+	}
+	return c.actionOperation(action).Wait(context.Background())
+}
+
+func (c *digitalOceanComputeInstance) DisassociateStaticIP(ip StaticIP) error {
+
+	var (
+		err error
+
+		floatingIP *godo.FloatingIP
+		action     *godo.Action
+	)
+
+	if floatingIP, _, err = c.client.FloatingIPs.Get(c.ctx, ip.Address); err != nil {
+		return err
+	}
+	if floatingIP.Droplet == nil || floatingIP.Droplet.ID != c.detail().ID {
+		return fmt.Errorf("static IP '%s' is not associated with droplet '%s'", ip.Address, c.detail().Name)
+	}
+
+	logger.TraceMessage(
+		"Unassigning floating IP '%s' from droplet '%s'.",
+		ip.Address, c.detail().Name,
+	)
+
+	if action, _, err = c.client.FloatingIPActions.Unassign(c.ctx, ip.Address); err != nil {
+		return err
+	}
+	return c.actionOperation(action).Wait(context.Background())
+}
@@ -0,0 +1,123 @@
+package cloud_test
+
+import (
+	"os"
+
+	"github.com/google/uuid"
+
+	"github.com/mevansam/goutils/logger"
+
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/gocloud/provider"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_helpers "github.com/mevansam/gocloud/test/helpers"
+)
+
+var _ = Describe("DigitalOcean Spaces Storage Tests", func() {
+
+	var (
+		err error
+
+		digitalOceanProvider provider.CloudProvider
+		digitalOceanStorage  cloud.Storage
+	)
+
+	BeforeEach(func() {
+
+		digitalOceanProvider, err = provider.NewCloudProvider("digitalocean")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digitalOceanProvider).ToNot(BeNil())
+
+		test_helpers.InitializeDigitalOceanProvider(digitalOceanProvider)
+		if len(os.Getenv("SPACES_ACCESS_KEY_ID")) == 0 {
+			Skip("environment variable named SPACES_ACCESS_KEY_ID was not provided")
+		}
+
+		err = digitalOceanProvider.Connect()
+		Expect(err).NotTo(HaveOccurred())
+
+		digitalOceanStorage, err = digitalOceanProvider.GetStorage()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digitalOceanStorage).ToNot(BeNil())
+
+		digitalOceanStorage.SetProperties(cloud.AWSStorageProperties{
+			BlockSize:    fiveMB,
+			S3Compatible: true,
+		})
+	})
+
+	It("creates, lists and deletes instances", func() {
+		testInstanceCreation(digitalOceanStorage)
+	})
+
+	Context("uploading and downloading data from a container", func() {
+
+		var (
+			storageInstance cloud.StorageInstance
+		)
+
+		BeforeEach(func() {
+			containerName := "test-" + uuid.New().String()
+			storageInstance, err = digitalOceanStorage.NewInstance(containerName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storageInstance.Name()).To(Equal(containerName))
+		})
+
+		AfterEach(func() {
+			err = storageInstance.Delete()
+			if err != nil {
+				logger.DebugMessage(
+					"DigitalOcean spaces storage test tear down error while deleting storage instance with name '%s': %s",
+					storageInstance.Name(), err.Error())
+			}
+		})
+
+		It("uploads a few blobs and validates them", func() {
+			testObjectUploadAndDownload(storageInstance)
+		})
+	})
+
+	Context("uploading and downloading files from a container", func() {
+
+		var (
+			tmpDir      string
+			tmpFiles    map[string]string
+			tmpFileData map[string]string
+
+			containerName   string
+			storageInstance cloud.StorageInstance
+		)
+
+		BeforeEach(func() {
+			tmpDir, err = os.MkdirTemp("", "digitaloceanstoragetest")
+			Expect(err).NotTo(HaveOccurred())
+
+			tmpFiles = make(map[string]string)
+			tmpFileData = make(map[string]string)
+			createTestFiles(tmpDir, tmpFiles, tmpFileData, fiveMB)
+
+			containerName = "test-" + uuid.New().String()
+			storageInstance, err = digitalOceanStorage.NewInstance(containerName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storageInstance.Name()).To(Equal(containerName))
+		})
+
+		AfterEach(func() {
+			err = storageInstance.Delete()
+			if err != nil {
+				logger.DebugMessage(
+					"DigitalOcean spaces storage test tear down error while deleting storage instance with name '%s': %s",
+					storageInstance.Name(), err.Error())
+			}
+
+			os.RemoveAll(tmpDir)
+		})
+
+		It("uploads large files with path names and validates them", func() {
+			testFileUploadAndDownload(storageInstance, tmpFiles, tmpFileData)
+		})
+	})
+})
@@ -0,0 +1,25 @@
+package cloud
+
+import (
+	"fmt"
+)
+
+// interface: cloud/ComputeInstance implementation
+
+// The user data of a droplet is only available
+// via the metadata service within the droplet.
+func (c *digitalOceanComputeInstance) UserData() (string, error) {
+	return "", fmt.Errorf(
+		"the user data of droplet '%s' cannot be retrieved via the api",
+		c.detail().Name,
+	)
+}
+
+// The user data of a droplet can only be
+// provided when the droplet is created.
+func (c *digitalOceanComputeInstance) SetUserData(data string) error {
+	return fmt.Errorf(
+		"the user data of droplet '%s' cannot be changed once it has been created",
+		c.detail().Name,
+	)
+}
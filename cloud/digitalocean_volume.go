@@ -0,0 +1,254 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/digitalocean/godo"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// digitalocean block storage has a single
+// volume type backed by ssds
+const digitalOceanVolumeType = "ssd"
+
+type digitalOceanVolume struct {
+	client *godo.Client
+	ctx    context.Context
+
+	volume *godo.Volume
+
+	props *DigitalOceanComputeProperties
+}
+
+func newDigitalOceanVolume(
+	client *godo.Client,
+	ctx context.Context,
+	volume *godo.Volume,
+	props *DigitalOceanComputeProperties,
+) *digitalOceanVolume {
+
+	return &digitalOceanVolume{
+		client: client,
+		ctx:    ctx,
+		volume: volume,
+
+		props: props,
+	}
+}
+
+// reloads the volume's state
+func (v *digitalOceanVolume) refresh() error {
+
+	volume, _, err := v.client.Storage.GetVolume(v.ctx, v.volume.ID)
+	if err != nil {
+		return err
+	}
+	v.volume = volume
+	return nil
+}
+
+// digitalocean tags are plain names so key
+// value tags are mapped to "key:value" names
+func digitalOceanTags(tags map[string]string) []string {
+
+	names := make([]string, 0, len(tags))
+	for k, v := range tags {
+		if len(v) > 0 {
+			names = append(names, k+":"+v)
+		} else {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// interface: cloud/Compute implementation
+
+// Volumes are regional so the zone of the spec is
+// taken to be the region to create the volume in.
+// The volume type is ignored as there is only one.
+func (c *digitalOceanCompute) CreateVolume(spec VolumeSpec) (Volume, error) {
+
+	var (
+		err error
+
+		volume *godo.Volume
+	)
+
+	region := spec.Zone
+	if len(region) == 0 {
+		region = c.region
+	}
+
+	logger.TraceMessage(
+		"Creating %dGB volume '%s' in region '%s'.",
+		spec.SizeGB, spec.Name, region,
+	)
+
+	if volume, _, err = c.client.Storage.CreateVolume(c.ctx, &godo.VolumeCreateRequest{
+		Region:        region,
+		Name:          spec.Name,
+		SizeGigaBytes: int64(spec.SizeGB),
+		Tags:          digitalOceanTags(spec.Tags),
+	}); err != nil {
+		return nil, err
+	}
+	return newDigitalOceanVolume(c.client, c.ctx, volume, &c.props), nil
+}
+
+// interface: cloud/ComputeInstance implementation
+
+// Only the block storage volumes attached to the droplet
+// are returned as its boot disk is not a volume.
+func (c *digitalOceanComputeInstance) Volumes() ([]Volume, error) {
+
+	var (
+		err error
+
+		volume *godo.Volume
+	)
+
+	if err = c.refresh(); err != nil {
+		return nil, err
+	}
+	volumes := make([]Volume, 0, len(c.detail().VolumeIDs))
+	for _, id := range c.detail().VolumeIDs {
+		if volume, _, err = c.client.Storage.GetVolume(c.ctx, id); err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, newDigitalOceanVolume(c.client, c.ctx, volume, c.props))
+	}
+	return volumes, nil
+}
+
+func (c *digitalOceanComputeInstance) AttachVolume(volume Volume) error {
+
+	var (
+		err error
+		ok  bool
+
+		v      *digitalOceanVolume
+		action *godo.Action
+	)
+
+	if v, ok = volume.(*digitalOceanVolume); !ok {
+		return fmt.Errorf("volume '%s' is not a digitalocean volume", volume.Name())
+	}
+
+	logger.TraceMessage(
+		"Attaching volume '%s' to droplet '%s'.",
+		v.volume.Name, c.detail().Name,
+	)
+
+	if action, _, err = c.client.StorageActions.Attach(c.ctx, v.volume.ID, c.detail().ID); err != nil {
+		return err
+	}
+	if err = c.actionOperation(action).Wait(context.Background()); err != nil {
+		return err
+	}
+	return v.refresh()
+}
+
+func (c *digitalOceanComputeInstance) DetachVolume(volume Volume) error {
+
+	var (
+		err error
+		ok  bool
+
+		v      *digitalOceanVolume
+		action *godo.Action
+	)
+
+	if v, ok = volume.(*digitalOceanVolume); !ok {
+		return fmt.Errorf("volume '%s' is not a digitalocean volume", volume.Name())
+	}
+
+	logger.TraceMessage(
+		"Detaching volume '%s' from droplet '%s'.",
+		v.volume.Name, c.detail().Name,
+	)
+
+	if action, _, err = c.client.StorageActions.DetachByDropletID(c.ctx, v.volume.ID, c.detail().ID); err != nil {
+		return err
+	}
+	if err = c.actionOperation(action).Wait(context.Background()); err != nil {
+		return err
+	}
+	return v.refresh()
+}
+
+// interface: cloud/Volume implementation
+
+func (v *digitalOceanVolume) ID() string {
+	return v.volume.ID
+}
+
+func (v *digitalOceanVolume) Name() string {
+	return v.volume.Name
+}
+
+func (v *digitalOceanVolume) SizeGB() int {
+	return int(v.volume.SizeGigaBytes)
+}
+
+func (v *digitalOceanVolume) Type() string {
+	return digitalOceanVolumeType
+}
+
+// Returns the volume's region as
+// volumes are not zonal.
+func (v *digitalOceanVolume) Zone() string {
+	if v.volume.Region == nil {
+		return ""
+	}
+	return v.volume.Region.Slug
+}
+
+func (v *digitalOceanVolume) Attached() bool {
+	return len(v.volume.DropletIDs) > 0
+}
+
+func (v *digitalOceanVolume) Boot() bool {
+	return false
+}
+
+func (v *digitalOceanVolume) Snapshot() (VolumeSnapshot, error) {
+
+	var (
+		err error
+
+		snapshot *godo.Snapshot
+	)
+
+	name := snapshotName(v.volume.Name)
+
+	logger.TraceMessage(
+		"Creating snapshot '%s' of volume '%s'.", name, v.volume.Name)
+
+	if snapshot, _, err = v.client.Storage.CreateSnapshot(v.ctx, &godo.SnapshotCreateRequest{
+		VolumeID:    v.volume.ID,
+		Name:        name,
+		Description: fmt.Sprintf("snapshot of volume '%s'", v.volume.Name),
+		Tags:        v.volume.Tags,
+	}); err != nil {
+		return VolumeSnapshot{}, err
+	}
+	return VolumeSnapshot{
+		ID:       snapshot.ID,
+		Name:     name,
+		VolumeID: v.volume.ID,
+		SizeGB:   int(v.volume.SizeGigaBytes),
+	}, nil
+}
+
+func (v *digitalOceanVolume) Delete() error {
+
+	logger.TraceMessage("Deleting volume '%s'.", v.volume.Name)
+
+	_, err := v.client.Storage.DeleteVolume(v.ctx, v.volume.ID)
+	return err
+}
@@ -0,0 +1,18 @@
+package cloud
+
+// exports unexported functions so they can
+// be tested from the cloud_test package
+var (
+	NewOperation = newOperation
+	WaitForState = waitForState
+
+	ResizeInstance          = resizeInstance
+	IsAzureSizeNotAvailable = isAzureSizeNotAvailable
+
+	NormalizeIngressRuleSpec = IngressRuleSpec.normalize
+	NormalizeCIDR            = normalizeCIDR
+	ParseFirewallRuleExpiry  = parseFirewallRuleExpiry
+	ParsePortRange           = parsePortRange
+
+	DigitalOceanInboundRuleWithoutSource = digitalOceanInboundRuleWithoutSource
+)
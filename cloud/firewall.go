@@ -0,0 +1,206 @@
+package cloud
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// normalized firewall rule protocols
+const (
+	ProtocolTCP  = "tcp"
+	ProtocolUDP  = "udp"
+	ProtocolICMP = "icmp"
+	ProtocolAll  = "all"
+)
+
+// default description of ingress rules
+const defaultFirewallRuleDescription = "ingress rule added by gocloud"
+
+// clouds do not expire firewall rules so the
+// expiry time of a rule added with a ttl is
+// recorded in the rule's description
+var firewallRuleExpiry = regexp.MustCompile(`\[expires=(\S+)\]`)
+
+// an ingress firewall rule that
+// applies to a compute instance
+type FirewallRule struct {
+	// cloud specific id of the rule
+	ID          string
+	Description string
+
+	// one of "tcp", "udp", "icmp" or "all"
+	Protocol string
+
+	// the destination port range of the rule
+	// which is 0-65535 for all ports
+	FromPort,
+	ToPort int
+
+	// the source address range
+	CIDR string
+
+	// the time the rule expires if it was added
+	// with a ttl otherwise the zero time
+	Expires time.Time
+}
+
+// returns whether the rule has expired
+func (r FirewallRule) Expired() bool {
+	return !r.Expires.IsZero() && time.Now().After(r.Expires)
+}
+
+// specification of an ingress rule to add
+type IngressRuleSpec struct {
+	// one of "tcp", "udp", "icmp" or "all".
+	// defaults to "tcp".
+	Protocol string
+
+	// the destination port which is
+	// required for tcp and udp rules
+	Port int
+
+	// the source address range. a single
+	// ip address is treated as a /32 or
+	// /128 range.
+	CIDR string
+
+	// time to live of the rule. zero if
+	// the rule does not expire.
+	TTL time.Duration
+
+	Description string
+}
+
+// validates the spec and returns a copy with its
+// defaults set and its source address as a cidr
+func (s IngressRuleSpec) normalize() (IngressRuleSpec, error) {
+
+	if len(s.Protocol) == 0 {
+		s.Protocol = ProtocolTCP
+	}
+	s.Protocol = strings.ToLower(s.Protocol)
+
+	switch s.Protocol {
+	case ProtocolTCP, ProtocolUDP:
+		if s.Port < 1 || s.Port > 65535 {
+			return s, fmt.Errorf("invalid port %d for a %s rule", s.Port, s.Protocol)
+		}
+	case ProtocolICMP, ProtocolAll:
+		s.Port = 0
+	default:
+		return s, fmt.Errorf("unsupported protocol '%s'", s.Protocol)
+	}
+
+	cidr, err := normalizeCIDR(s.CIDR)
+	if err != nil {
+		return s, err
+	}
+	s.CIDR = cidr
+
+	if len(s.Description) == 0 {
+		s.Description = defaultFirewallRuleDescription
+	}
+	if s.TTL > 0 {
+		s.Description = fmt.Sprintf("%s [expires=%s]",
+			s.Description, time.Now().Add(s.TTL).UTC().Format(time.RFC3339))
+	}
+	return s, nil
+}
+
+// returns the given address range in cidr notation
+// with a single ip address as a /32 or /128 range
+func normalizeCIDR(cidr string) (string, error) {
+
+	if ip := net.ParseIP(cidr); ip != nil {
+		if ip.To4() != nil {
+			return ip.String() + "/32", nil
+		}
+		return ip.String() + "/128", nil
+	}
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", fmt.Errorf("invalid source address range '%s'", cidr)
+	}
+	return ipNet.String(), nil
+}
+
+// returns the expiry time recorded in a
+// rule description or the zero time
+func parseFirewallRuleExpiry(description string) time.Time {
+	if m := firewallRuleExpiry.FindStringSubmatch(description); m != nil {
+		if expires, err := time.Parse(time.RFC3339, m[1]); err == nil {
+			return expires
+		}
+	}
+	return time.Time{}
+}
+
+// parses a port range of the form "22", "8000-9000"
+// or "*". an empty range or "*" is all ports. ports
+// outside 0-65535 or a reversed range are invalid.
+func parsePortRange(ports string) (int, int, error) {
+
+	var (
+		err error
+
+		fromPort,
+		toPort int
+	)
+
+	if len(ports) == 0 || ports == "*" {
+		return 0, 65535, nil
+	}
+	p := strings.SplitN(ports, "-", 2)
+	if fromPort, err = strconv.Atoi(p[0]); err != nil {
+		return 0, 0, err
+	}
+	toPort = fromPort
+	if len(p) == 2 {
+		if toPort, err = strconv.Atoi(p[1]); err != nil {
+			return 0, 0, err
+		}
+	}
+	if fromPort < 0 || toPort > 65535 || fromPort > toPort {
+		return 0, 0, fmt.Errorf("invalid port range '%s'", ports)
+	}
+	return fromPort, toPort, nil
+}
+
+// Removes the firewall rules of the given instance
+// whose ttl has expired and returns the number of
+// rules removed.
+func RemoveExpiredFirewallRules(instance ComputeInstance) (int, error) {
+
+	var (
+		err error
+
+		rules []FirewallRule
+	)
+
+	if rules, err = instance.FirewallRules(); err != nil {
+		return 0, err
+	}
+
+	// a cloud rule may be returned more than once
+	// if it has multiple ports or sources
+	removed := make(map[string]bool)
+	for _, rule := range rules {
+		if rule.Expired() && !removed[rule.ID] {
+			logger.TraceMessage(
+				"Removing firewall rule '%s' of instance '%s' which expired at %s.",
+				rule.ID, instance.Name(), rule.Expires.Format(time.RFC3339),
+			)
+			if err = instance.RemoveFirewallRule(rule); err != nil {
+				return len(removed), err
+			}
+			removed[rule.ID] = true
+		}
+	}
+	return len(removed), nil
+}
@@ -0,0 +1,182 @@
+package cloud_test
+
+import (
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/mevansam/gocloud/cloud"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	test_mocks "github.com/mevansam/gocloud/test/mocks"
+)
+
+var _ = Describe("Instance Firewall Rules", func() {
+
+	var (
+		instance *test_mocks.FakeComputeInstance
+	)
+
+	BeforeEach(func() {
+		instance = &test_mocks.FakeComputeInstance{}
+		instance.SetValues("test-id", "test", "", cloud.StateRunning)
+	})
+
+	It("removes only the rules whose ttl has expired", func() {
+
+		permanent, err := instance.AddIngressRule(cloud.IngressRuleSpec{
+			Port: 22,
+			CIDR: "203.0.113.10/32",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(permanent.Expired()).To(BeFalse())
+
+		active, err := instance.AddIngressRule(cloud.IngressRuleSpec{
+			Port: 443,
+			CIDR: "203.0.113.10/32",
+			TTL:  time.Hour,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(active.Expired()).To(BeFalse())
+
+		expired, err := instance.AddIngressRule(cloud.IngressRuleSpec{
+			Protocol: cloud.ProtocolUDP,
+			Port:     1194,
+			CIDR:     "203.0.113.10/32",
+			TTL:      -time.Minute,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expired.Expired()).To(BeTrue())
+
+		removed, err := cloud.RemoveExpiredFirewallRules(instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(Equal(1))
+
+		rules, err := instance.FirewallRules()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(ConsistOf(permanent, active))
+
+		removed, err = cloud.RemoveExpiredFirewallRules(instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(Equal(0))
+	})
+
+	DescribeTable("normalizes source address ranges",
+		func(cidr, expected string, valid bool) {
+			normalized, err := cloud.NormalizeCIDR(cidr)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(normalized).To(Equal(expected))
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("an ipv4 address", "203.0.113.10", "203.0.113.10/32", true),
+		Entry("an ipv4 range", "203.0.113.0/24", "203.0.113.0/24", true),
+		Entry("an ipv4 range with host bits", "203.0.113.10/24", "203.0.113.0/24", true),
+		Entry("an ipv6 address", "2001:DB8::1", "2001:db8::1/128", true),
+		Entry("an expanded ipv6 address", "2001:0db8:0000:0000:0000:0000:0000:0001", "2001:db8::1/128", true),
+		Entry("an ipv6 range with host bits", "2001:db8::1/64", "2001:db8::/64", true),
+		Entry("an ipv4 mapped ipv6 address", "::ffff:203.0.113.10", "203.0.113.10/32", true),
+		Entry("all ipv6 addresses", "::/0", "::/0", true),
+		Entry("an empty range", "", "", false),
+		Entry("a range with an invalid prefix", "2001:db8::/129", "", false),
+		Entry("a host name", "example.com", "", false),
+	)
+
+	DescribeTable("parses port ranges",
+		func(ports string, fromPort, toPort int, valid bool) {
+			from, to, err := cloud.ParsePortRange(ports)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(from).To(Equal(fromPort))
+				Expect(to).To(Equal(toPort))
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("an empty range", "", 0, 65535, true),
+		Entry("a wildcard range", "*", 0, 65535, true),
+		Entry("a single port", "22", 22, 22, true),
+		Entry("a port range", "8000-9000", 8000, 9000, true),
+		Entry("the full range", "0-65535", 0, 65535, true),
+		Entry("a port that is not a number", "ssh", 0, 0, false),
+		Entry("a range that is not a number", "22-ssh", 0, 0, false),
+		Entry("a reversed range", "9000-8000", 0, 0, false),
+		Entry("a port out of range", "70000", 0, 0, false),
+		Entry("a negative port", "-22", 0, 0, false),
+	)
+
+	DescribeTable("parses the expiry recorded in a rule description",
+		func(description string, expected time.Time) {
+			Expect(cloud.ParseFirewallRuleExpiry(description)).To(Equal(expected))
+		},
+		Entry("a description without an expiry",
+			"ingress rule added by gocloud", time.Time{}),
+		Entry("a description with an expiry",
+			"ingress rule added by gocloud [expires=2030-01-02T03:04:05Z]",
+			time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Entry("an expiry with a time zone offset",
+			"[expires=2030-01-02T03:04:05+02:00] ssh access",
+			time.Date(2030, 1, 2, 1, 4, 5, 0, time.UTC).In(time.FixedZone("", 2*60*60))),
+		Entry("an expiry that is not a time",
+			"ingress rule added by gocloud [expires=tomorrow]", time.Time{}),
+		Entry("an expiry without a time zone",
+			"ingress rule added by gocloud [expires=2030-01-02T03:04:05]", time.Time{}),
+		Entry("an empty expiry",
+			"ingress rule added by gocloud [expires=]", time.Time{}),
+		Entry("an expiry that is not terminated",
+			"ingress rule added by gocloud [expires=2030-01-02T03:04:05Z", time.Time{}),
+	)
+
+	DescribeTable("removes a source address from a digitalocean inbound rule",
+		func(sources *godo.Sources, cidr string, expectedSources *godo.Sources, expectedRemaining bool) {
+			inbound := godo.InboundRule{Protocol: "tcp", PortRange: "22", Sources: sources}
+
+			remaining, ok := cloud.DigitalOceanInboundRuleWithoutSource(inbound, cidr)
+			Expect(ok).To(Equal(expectedRemaining))
+			Expect(remaining.Protocol).To(Equal("tcp"))
+			Expect(remaining.PortRange).To(Equal("22"))
+			Expect(remaining.Sources).To(Equal(expectedSources))
+		},
+		Entry("keeps other addresses",
+			&godo.Sources{Addresses: []string{"203.0.113.10/32", "198.51.100.0/24"}}, "203.0.113.10/32",
+			&godo.Sources{Addresses: []string{"198.51.100.0/24"}}, true),
+		Entry("drops the rule with its only address",
+			&godo.Sources{Addresses: []string{"203.0.113.10/32"}}, "203.0.113.10/32",
+			&godo.Sources{Addresses: []string{}}, false),
+		Entry("keeps non-address sources",
+			&godo.Sources{Addresses: []string{"203.0.113.10/32"}, Tags: []string{"web"}}, "203.0.113.10/32",
+			&godo.Sources{Addresses: []string{}, Tags: []string{"web"}}, true),
+		Entry("has no sources", nil, "203.0.113.10/32", nil, false),
+	)
+
+	It("records the expiry of a rule added with a ttl in its description", func() {
+
+		spec, err := cloud.NormalizeIngressRuleSpec(cloud.IngressRuleSpec{
+			Port: 22,
+			CIDR: "2001:db8::1",
+			TTL:  time.Hour,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Protocol).To(Equal(cloud.ProtocolTCP))
+		Expect(spec.CIDR).To(Equal("2001:db8::1/128"))
+
+		expires := cloud.ParseFirewallRuleExpiry(spec.Description)
+		Expect(expires).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second*2))
+
+		spec, err = cloud.NormalizeIngressRuleSpec(cloud.IngressRuleSpec{
+			Port: 22,
+			CIDR: "203.0.113.10",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cloud.ParseFirewallRuleExpiry(spec.Description).IsZero()).To(BeTrue())
+
+		_, err = cloud.NormalizeIngressRuleSpec(cloud.IngressRuleSpec{
+			CIDR: "203.0.113.10",
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -1,6 +1,7 @@
 package cloud
 
 import (
+	"context"
 	"fmt"
 	"path"
 	"strconv"
@@ -12,7 +13,6 @@ import (
 
 	"github.com/mevansam/goutils/logger"
 	"github.com/mevansam/goutils/network"
-	"github.com/mevansam/goutils/utils"
 )
 
 type GoogleComputeProperties struct {
@@ -22,6 +22,10 @@ type GoogleComputeProperties struct {
 	// timeout for start/stop operations
 	OpTimeout time.Duration
 
+	// maximum number of instance operations
+	// to run concurrently for batch operations
+	MaxConcurrency int
+
 	FilterLabels map[string]string
 }
 
@@ -36,6 +40,10 @@ type googleComputeInstance struct {
 	service  *compute.Service
 	instance *compute.Instance
 
+	// guards the instance detail which is refreshed
+	// by operations running asynchronously
+	mx sync.RWMutex
+
 	projectID string
 	zone      string
 
@@ -57,7 +65,8 @@ func NewGoogleCompute(
 
 			// 5 minute timeout for
 			// start/stop operatons
-			OpTimeout: time.Minute * 5,
+			OpTimeout:      defaultOpTimeout,
+			MaxConcurrency: defaultMaxConcurrency,
 
 			FilterLabels: make(map[string]string),
 		},
@@ -91,46 +100,88 @@ func (c *googleCompute) zoneList() ([]string, error) {
 	return zones, nil
 }
 
-func (c *googleComputeInstance) waitForState(state, etag string) error {
+func (c *googleComputeInstance) waitForStatus(
+	ctx context.Context,
+	status, etag string,
+) error {
 
 	var (
 		err error
-		wg  sync.WaitGroup
+
+		instance *compute.Instance
 	)
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-
-		var instance *compute.Instance
-		
-		for {
-			if instance, err = c.service.Instances.Get(
-				c.projectID,
-				c.zone,
-				c.instance.Name,
-			).IfNoneMatch(etag).Do(); err != nil {
-				return
-			}
-			if instance.Status == state {
-				c.instance = instance
-				break
+	timedOut := func() error {
+		return fmt.Errorf(
+			"instance '%s' timed out waiting for state '%s'",
+			c.detail().Name, status,
+		)
+	}
+
+	for {
+		if instance, err = c.service.Instances.Get(
+			c.projectID,
+			c.zone,
+			c.detail().Name,
+		).IfNoneMatch(etag).Context(ctx).Do(); err != nil {
+
+			if ctx.Err() != nil {
+				return timedOut()
 			}
-			// pause for 1s
-			time.Sleep(time.Second)
+			return err
+		}
+		if instance.Status == status {
+			c.setDetail(instance)
+			return nil
 		}
-	}()
 
-	if utils.WaitTimeout(&wg, c.props.OpTimeout) {
-		return err
-	} else {
+		// pause for 1s
+		select {
+		case <-ctx.Done():
+			return timedOut()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// waits for the given zonal, regional or global operation to complete
+func waitForGoogleOperation(
+	ctx context.Context,
+	service *compute.Service,
+	projectID string,
+	operation *compute.Operation,
+) error {
+
+	var (
+		err error
+	)
+
+	for operation.Status != "DONE" {
+		switch {
+		case len(operation.Zone) > 0:
+			operation, err = service.ZoneOperations.Wait(
+				projectID, path.Base(operation.Zone), operation.Name,
+			).Context(ctx).Do()
+		case len(operation.Region) > 0:
+			operation, err = service.RegionOperations.Wait(
+				projectID, path.Base(operation.Region), operation.Name,
+			).Context(ctx).Do()
+		default:
+			operation, err = service.GlobalOperations.Wait(
+				projectID, operation.Name,
+			).Context(ctx).Do()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if operation.Error != nil && len(operation.Error.Errors) > 0 {
 		return fmt.Errorf(
-			fmt.Sprintf(
-				"instance '%s' timed out waiting for state '%s'",
-				c.instance.Name, state,
-			),
+			"operation '%s' failed: %s",
+			operation.Name, operation.Error.Errors[0].Message,
 		)
 	}
+	return nil
 }
 
 // interface: cloud/Compute implementation
@@ -145,6 +196,9 @@ func (c *googleCompute) SetProperties(props interface{}) {
 	if p.OpTimeout != 0 {
 		c.props.OpTimeout = p.OpTimeout
 	}
+	if p.MaxConcurrency != 0 {
+		c.props.MaxConcurrency = p.MaxConcurrency
+	}
 	if p.FilterLabels != nil {
 		c.props.FilterLabels = p.FilterLabels
 	}
@@ -260,22 +314,96 @@ func (c *googleCompute) listInstances(filter string) ([]ComputeInstance, error)
 	return instances, nil
 }
 
+func (c *googleCompute) ListSizes() ([]InstanceSize, error) {
+
+	var (
+		err error
+
+		zones []string
+	)
+
+	if zones, err = c.zoneList(); err != nil {
+		return nil, err
+	}
+
+	// machine types are per zone so return the
+	// unique types across all zones in the region
+	sizeMap := make(map[string]InstanceSize)
+	for _, z := range zones {
+		if err = c.service.MachineTypes.List(c.projectID, z).
+			Pages(context.Background(),
+				func(page *compute.MachineTypeList) error {
+					for _, t := range page.Items {
+						sizeMap[t.Name] = InstanceSize{
+							Name:     t.Name,
+							VCPUs:    int(t.GuestCpus),
+							MemoryMB: int(t.MemoryMb),
+						}
+					}
+					return nil
+				},
+			); err != nil {
+			return nil, err
+		}
+	}
+
+	sizes := make([]InstanceSize, 0, len(sizeMap))
+	for _, size := range sizeMap {
+		sizes = append(sizes, size)
+	}
+	sortInstanceSizes(sizes)
+	return sizes, nil
+}
+
+func (c *googleCompute) StartInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Start()
+	})
+}
+
+func (c *googleCompute) StopInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Stop()
+	})
+}
+
+func (c *googleCompute) RestartInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Restart()
+	})
+}
+
+// returns the last retrieved instance detail
+func (c *googleComputeInstance) detail() *compute.Instance {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+	return c.instance
+}
+
+// replaces the instance detail with a refreshed copy
+func (c *googleComputeInstance) setDetail(instance *compute.Instance) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.instance = instance
+}
+
 // interface: cloud/ComputeInstance implementation
 
 func (c *googleComputeInstance) ID() string {
-	return strconv.FormatUint(c.instance.Id, 10)
+	return strconv.FormatUint(c.detail().Id, 10)
 }
 
 func (c *googleComputeInstance) Name() string {
-	return c.instance.Name
+	return c.detail().Name
 }
 
 func (c *googleComputeInstance) PublicIP() string {
 
-	if len(c.instance.NetworkInterfaces) > 0 &&
-		len(c.instance.NetworkInterfaces[0].AccessConfigs) > 0 {
+	instance := c.detail()
+	if len(instance.NetworkInterfaces) > 0 &&
+		len(instance.NetworkInterfaces[0].AccessConfigs) > 0 {
 
-		return c.instance.NetworkInterfaces[0].AccessConfigs[0].NatIP
+		return instance.NetworkInterfaces[0].AccessConfigs[0].NatIP
 	} else {
 		return ""
 	}
@@ -285,6 +413,16 @@ func (c *googleComputeInstance) PublicDNS() string {
 	return ""
 }
 
+func (c *googleComputeInstance) PrivateIP() string {
+
+	instance := c.detail()
+	if len(instance.NetworkInterfaces) > 0 {
+		return instance.NetworkInterfaces[0].NetworkIP
+	} else {
+		return ""
+	}
+}
+
 func (c *googleComputeInstance) State() (InstanceState, error) {
 
 	var (
@@ -297,20 +435,23 @@ func (c *googleComputeInstance) State() (InstanceState, error) {
 	if instance, err = c.service.Instances.Get(
 		c.projectID,
 		c.zone,
-		c.instance.Name,
+		c.detail().Name,
 	).Do(); err != nil {
 		return StateUnknown, err
 	}
 
-	c.instance = instance
+	c.setDetail(instance)
 	switch instance.Status {
 	case "RUNNING":
 		return StateRunning, nil
 	case "TERMINATED":
 		return StateStopped, nil
+	case "SUSPENDED":
+		return StateSuspended, nil
 	case "PROVISIONING",
 		"STAGING",
 		"STOPPING",
+		"SUSPENDING",
 		"REPAIRING":
 		return StatePending, nil
 	default:
@@ -320,6 +461,48 @@ func (c *googleComputeInstance) State() (InstanceState, error) {
 
 func (c *googleComputeInstance) Start() error {
 
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.StartAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *googleComputeInstance) Restart() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.RestartAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *googleComputeInstance) Stop() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.StopAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *googleComputeInstance) StartAsync() (Operation, error) {
+
 	var (
 		err error
 
@@ -327,21 +510,23 @@ func (c *googleComputeInstance) Start() error {
 	)
 
 	logger.TraceMessage(
-		"Starting instance '%s'.", c.instance.Name)
+		"Starting instance '%s'.", c.detail().Name)
 
 	if operation, err = c.service.Instances.Start(
 		c.projectID,
 		c.zone,
-		c.instance.Name,
+		c.detail().Name,
 	).Do(); err != nil {
-		return err
+		return nil, err
 	}
 
-	return c.waitForState(
-		"RUNNING", operation.Header.Get("Etag"))
+	etag := operation.Header.Get("Etag")
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return c.waitForStatus(ctx, "RUNNING", etag)
+	}), nil
 }
 
-func (c *googleComputeInstance) Restart() error {
+func (c *googleComputeInstance) RestartAsync() (Operation, error) {
 
 	var (
 		err error
@@ -350,21 +535,23 @@ func (c *googleComputeInstance) Restart() error {
 	)
 
 	logger.TraceMessage(
-		"Restarting instance '%s'.", c.instance.Name)
+		"Restarting instance '%s'.", c.detail().Name)
 
 	if operation, err = c.service.Instances.Reset(
 		c.projectID,
 		c.zone,
-		c.instance.Name,
+		c.detail().Name,
 	).Do(); err != nil {
-		return err
+		return nil, err
 	}
 
-	return c.waitForState(
-		"RUNNING", operation.Header.Get("Etag"))
+	etag := operation.Header.Get("Etag")
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return c.waitForStatus(ctx, "RUNNING", etag)
+	}), nil
 }
 
-func (c *googleComputeInstance) Stop() error {
+func (c *googleComputeInstance) StopAsync() (Operation, error) {
 
 	var (
 		err error
@@ -373,18 +560,167 @@ func (c *googleComputeInstance) Stop() error {
 	)
 
 	logger.TraceMessage(
-		"Stopping instance '%s'.", c.instance.Name)
+		"Stopping instance '%s'.", c.detail().Name)
 
 	if operation, err = c.service.Instances.Stop(
 		c.projectID,
 		c.zone,
-		c.instance.Name,
+		c.detail().Name,
 	).Do(); err != nil {
+		return nil, err
+	}
+
+	etag := operation.Header.Get("Etag")
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return c.waitForStatus(ctx, "TERMINATED", etag)
+	}), nil
+}
+
+func (c *googleComputeInstance) Capabilities() (InstanceCapabilities, error) {
+
+	var (
+		err error
+	)
+
+	// refresh instance detail
+	if _, err = c.State(); err != nil {
+		return InstanceCapabilities{}, err
+	}
+
+	// instances with GPUs or local
+	// SSDs cannot be suspended
+	suspendResume := len(c.detail().GuestAccelerators) == 0
+	for _, disk := range c.detail().Disks {
+		if disk.Type == "SCRATCH" {
+			suspendResume = false
+			break
+		}
+	}
+	return InstanceCapabilities{
+		SuspendResume: suspendResume,
+	}, nil
+}
+
+func (c *googleComputeInstance) Suspend() error {
+
+	var (
+		err error
+
+		capabilities InstanceCapabilities
+		operation    *compute.Operation
+	)
+
+	if capabilities, err = c.Capabilities(); err != nil {
 		return err
 	}
+	if !capabilities.SuspendResume {
+		return fmt.Errorf(
+			"instance '%s' cannot be suspended as it has GPUs or local SSDs attached",
+			c.detail().Name,
+		)
+	}
+
+	logger.TraceMessage(
+		"Suspending instance '%s'.", c.detail().Name)
 
-	return c.waitForState(
-		"TERMINATED", operation.Header.Get("Etag"))
+	if operation, err = c.service.Instances.Suspend(
+		c.projectID,
+		c.zone,
+		c.detail().Name,
+	).Do(); err != nil {
+		return err
+	}
+
+	etag := operation.Header.Get("Etag")
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return c.waitForStatus(ctx, "SUSPENDED", etag)
+	}).Wait(context.Background())
+}
+
+func (c *googleComputeInstance) Resume() error {
+
+	var (
+		err error
+
+		operation *compute.Operation
+	)
+
+	logger.TraceMessage(
+		"Resuming instance '%s'.", c.detail().Name)
+
+	if operation, err = c.service.Instances.Resume(
+		c.projectID,
+		c.zone,
+		c.detail().Name,
+	).Do(); err != nil {
+		return err
+	}
+
+	etag := operation.Header.Get("Etag")
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return c.waitForStatus(ctx, "RUNNING", etag)
+	}).Wait(context.Background())
+}
+
+func (c *googleComputeInstance) Resize(size string, autoRestart bool) error {
+
+	return resizeInstance(c, autoRestart, true, func() error {
+
+		var (
+			err error
+
+			operation *compute.Operation
+		)
+
+		logger.TraceMessage(
+			"Changing machine type of instance '%s' to '%s'.",
+			c.detail().Name, size,
+		)
+
+		if operation, err = c.service.Instances.SetMachineType(
+			c.projectID,
+			c.zone,
+			c.detail().Name,
+			&compute.InstancesSetMachineTypeRequest{
+				MachineType: fmt.Sprintf("zones/%s/machineTypes/%s", c.zone, size),
+			},
+		).Do(); err != nil {
+			return err
+		}
+		return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+			return waitForGoogleOperation(ctx, c.service, c.projectID, operation)
+		}).Wait(context.Background())
+	})
+}
+
+func (c *googleComputeInstance) ConsoleOutput() (string, error) {
+
+	var (
+		err error
+
+		output *compute.SerialPortOutput
+	)
+
+	if output, err = c.service.Instances.GetSerialPortOutput(
+		c.projectID,
+		c.zone,
+		c.detail().Name,
+	).Do(); err != nil {
+		return "", err
+	}
+	return output.Contents, nil
+}
+
+func (c *googleComputeInstance) WaitForState(
+	ctx context.Context,
+	state InstanceState,
+	timeout time.Duration,
+) error {
+
+	if timeout == 0 {
+		timeout = c.props.OpTimeout
+	}
+	return waitForState(ctx, c, state, timeout)
 }
 
 func (c *googleComputeInstance) CanConnect(port int) bool {
@@ -396,3 +732,11 @@ func (c *googleComputeInstance) CanConnect(port int) bool {
 		return false
 	}
 }
+
+func (c *googleComputeInstance) HealthCheck(ctx context.Context, check HealthCheck) HealthCheckResult {
+	return CheckHealth(ctx, c, check)
+}
+
+func (c *googleComputeInstance) WaitUntilHealthy(ctx context.Context, checks ...HealthCheck) error {
+	return WaitUntilHealthy(ctx, c, checks...)
+}
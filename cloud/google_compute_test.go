@@ -88,6 +88,15 @@ var _ = Describe("Google Compute Tests", func() {
 			}
 		})
 
+		It("stops and starts a batch of compute instances", func() {
+
+			instanceIds := []string{}
+			for _, googleInstance := range testInstances {
+				instanceIds = append(instanceIds, strconv.FormatUint(googleInstance.Id, 10))
+			}
+			testBatchStopAndStart(googleCompute, instanceIds, "0")
+		})
+
 		It("retrieves a compute instance", func() {
 
 			_, err := googleCompute.GetInstance("test-X")
@@ -156,5 +165,70 @@ var _ = Describe("Google Compute Tests", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(state).To(Equal(cloud.StateRunning))
 		})
+
+		It("stops and starts compute instances asynchronously", func() {
+			testAsyncStopAndStart(instance0, instance1)
+		})
+
+		It("suspends and resumes a compute instance if supported", func() {
+			testSuspendAndResume(instance0)
+		})
+
+		It("retrieves the console output of a compute instance", func() {
+
+			output, err := instance0.ConsoleOutput()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(output)).To(BeNumerically(">", 0))
+		})
+
+		It("resizes a compute instance", func() {
+			testResize(googleCompute, instance1, "n1-standard-1", "n1-standard-2")
+		})
+
+		It("retrieves the scheduling of a compute instance", func() {
+			testOnDemandScheduling(instance0)
+
+			Expect(cloud.GoogleScheduling(cloud.InstanceScheduling{})).To(BeNil())
+
+			scheduling := cloud.GoogleScheduling(cloud.InstanceScheduling{
+				PurchaseModel: cloud.PurchaseSpot,
+			})
+			Expect(scheduling.ProvisioningModel).To(Equal("SPOT"))
+			Expect(scheduling.InstanceTerminationAction).To(Equal("STOP"))
+			Expect(*scheduling.AutomaticRestart).To(BeFalse())
+
+			scheduling = cloud.GoogleScheduling(cloud.InstanceScheduling{
+				PurchaseModel: cloud.PurchasePreemptible,
+			})
+			Expect(scheduling.Preemptible).To(BeTrue())
+		})
+
+		It("finds public images", func() {
+			testFindImages(googleCompute)
+		})
+
+		It("creates and deletes an image of a compute instance", func() {
+			testCreateAndDeleteImage(googleCompute, instance1)
+		})
+
+		It("adds and removes firewall rules of a compute instance", func() {
+			testFirewallRules(instance0)
+		})
+
+		It("sets and retrieves the user data of a compute instance", func() {
+			testUserData(instance1)
+		})
+
+		It("allocates, associates and releases a static ip", func() {
+			testStaticIPs(googleCompute, instance1, map[string]string{
+				"role": "cloudbuilder-test",
+			}, false)
+		})
+
+		It("creates, attaches, snapshots and detaches a volume", func() {
+			testVolumes(googleCompute, instance0, map[string]string{
+				"role": "cloudbuilder-test",
+			})
+		})
 	})
 })
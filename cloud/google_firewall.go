@@ -0,0 +1,254 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// returns the firewall rules of a gce firewall which will
+// be more than one if it allows multiple protocols, port
+// ranges or source ranges
+func newGoogleFirewallRules(firewall *compute.Firewall) []FirewallRule {
+
+	rules := []FirewallRule{}
+	for _, allowed := range firewall.Allowed {
+		protocol := strings.ToLower(allowed.IPProtocol)
+
+		ports := allowed.Ports
+		if len(ports) == 0 {
+			ports = []string{""}
+		}
+		for _, portRange := range ports {
+			fromPort, toPort, err := parsePortRange(portRange)
+			if err != nil {
+				logger.DebugMessage(
+					"Ignoring port range '%s' of firewall '%s': %s",
+					portRange, firewall.Name, err.Error(),
+				)
+				continue
+			}
+			for _, cidr := range firewall.SourceRanges {
+				rules = append(rules, FirewallRule{
+					ID:          firewall.Name,
+					Description: firewall.Description,
+					Protocol:    protocol,
+					FromPort:    fromPort,
+					ToPort:      toPort,
+					CIDR:        cidr,
+					Expires:     parseFirewallRuleExpiry(firewall.Description),
+				})
+			}
+		}
+	}
+	return rules
+}
+
+// returns the network tag used to target
+// firewall rules added to the instance
+func (c *googleComputeInstance) firewallTag() string {
+
+	// the tag is limited to 49 characters so that firewall
+	// names derived from it are within the 63 character
+	// limit of gce resource names
+	tag := "gocloud-" + c.detail().Name
+	if len(tag) > 49 {
+		tag = tag[:49]
+	}
+	return strings.TrimRight(tag, "-")
+}
+
+// returns whether the given firewall
+// applies to traffic to the instance
+func (c *googleComputeInstance) firewallApplies(firewall *compute.Firewall) bool {
+
+	instance := c.detail()
+	if firewall.Disabled || firewall.Direction != "INGRESS" ||
+		len(instance.NetworkInterfaces) == 0 ||
+		path.Base(firewall.Network) != path.Base(instance.NetworkInterfaces[0].Network) {
+		return false
+	}
+	if len(firewall.TargetTags) == 0 && len(firewall.TargetServiceAccounts) == 0 {
+		return true
+	}
+	if instance.Tags != nil {
+		for _, target := range firewall.TargetTags {
+			for _, tag := range instance.Tags.Items {
+				if target == tag {
+					return true
+				}
+			}
+		}
+	}
+	for _, target := range firewall.TargetServiceAccounts {
+		for _, serviceAccount := range instance.ServiceAccounts {
+			if target == serviceAccount.Email {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// adds the firewall tag to the instance's
+// network tags if it does not have it
+func (c *googleComputeInstance) addFirewallTag(tag string) error {
+
+	var (
+		err error
+
+		instance  *compute.Instance
+		operation *compute.Operation
+	)
+
+	if instance, err = c.service.Instances.Get(c.projectID, c.zone, c.detail().Name).Do(); err != nil {
+		return err
+	}
+
+	tags := &compute.Tags{}
+	if instance.Tags != nil {
+		for _, t := range instance.Tags.Items {
+			if t == tag {
+				c.setDetail(instance)
+				return nil
+			}
+		}
+		tags.Fingerprint = instance.Tags.Fingerprint
+		tags.Items = append(tags.Items, instance.Tags.Items...)
+	}
+	tags.Items = append(tags.Items, tag)
+
+	logger.TraceMessage(
+		"Adding network tag '%s' to instance '%s'.", tag, instance.Name)
+
+	if operation, err = c.service.Instances.SetTags(c.projectID, c.zone, instance.Name, tags).Do(); err != nil {
+		return err
+	}
+	if err = newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return waitForGoogleOperation(ctx, c.service, c.projectID, operation)
+	}).Wait(context.Background()); err != nil {
+		return err
+	}
+	instance.Tags = tags
+	c.setDetail(instance)
+	return nil
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *googleComputeInstance) FirewallRules() ([]FirewallRule, error) {
+
+	var (
+		err error
+	)
+
+	rules := []FirewallRule{}
+	if err = c.service.Firewalls.List(c.projectID).Pages(
+		context.Background(),
+		func(page *compute.FirewallList) error {
+			for _, firewall := range page.Items {
+				if c.firewallApplies(firewall) {
+					rules = append(rules, newGoogleFirewallRules(firewall)...)
+				}
+			}
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Ingress rules are added as firewalls that target a
+// network tag unique to the instance. The tag is added
+// to the instance if it does not already have it.
+func (c *googleComputeInstance) AddIngressRule(spec IngressRuleSpec) (FirewallRule, error) {
+
+	var (
+		err error
+
+		operation *compute.Operation
+		firewall  *compute.Firewall
+	)
+
+	if spec, err = spec.normalize(); err != nil {
+		return FirewallRule{}, err
+	}
+	instance := c.detail()
+	if len(instance.NetworkInterfaces) == 0 {
+		return FirewallRule{}, fmt.Errorf("instance '%s' has no network interfaces", instance.Name)
+	}
+	network := instance.NetworkInterfaces[0].Network
+
+	tag := c.firewallTag()
+	if err = c.addFirewallTag(tag); err != nil {
+		return FirewallRule{}, err
+	}
+
+	allowed := &compute.FirewallAllowed{
+		IPProtocol: spec.Protocol,
+	}
+	if spec.Port > 0 {
+		allowed.Ports = []string{strconv.Itoa(spec.Port)}
+	}
+	name := fmt.Sprintf("%s-%s", tag, strconv.FormatInt(time.Now().UnixNano(), 36))
+
+	logger.TraceMessage(
+		"Adding firewall '%s' for %s port %d from '%s' to instance '%s'.",
+		name, spec.Protocol, spec.Port, spec.CIDR, c.detail().Name,
+	)
+
+	if operation, err = c.service.Firewalls.Insert(c.projectID,
+		&compute.Firewall{
+			Name:         name,
+			Description:  spec.Description,
+			Network:      network,
+			Direction:    "INGRESS",
+			Allowed:      []*compute.FirewallAllowed{allowed},
+			SourceRanges: []string{spec.CIDR},
+			TargetTags:   []string{tag},
+		},
+	).Do(); err != nil {
+		return FirewallRule{}, err
+	}
+	if err = newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return waitForGoogleOperation(ctx, c.service, c.projectID, operation)
+	}).Wait(context.Background()); err != nil {
+		return FirewallRule{}, err
+	}
+
+	if firewall, err = c.service.Firewalls.Get(c.projectID, name).Do(); err != nil {
+		return FirewallRule{}, err
+	}
+	rules := newGoogleFirewallRules(firewall)
+	if len(rules) == 0 {
+		return FirewallRule{}, fmt.Errorf("firewall '%s' was not created as requested", name)
+	}
+	return rules[0], nil
+}
+
+func (c *googleComputeInstance) RemoveFirewallRule(rule FirewallRule) error {
+
+	var (
+		err error
+
+		operation *compute.Operation
+	)
+
+	logger.TraceMessage(
+		"Removing firewall '%s' of instance '%s'.", rule.ID, c.detail().Name)
+
+	if operation, err = c.service.Firewalls.Delete(c.projectID, rule.ID).Do(); err != nil {
+		return err
+	}
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return waitForGoogleOperation(ctx, c.service, c.projectID, operation)
+	}).Wait(context.Background())
+}
@@ -0,0 +1,143 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+func newGoogleImage(image *compute.Image, projectID string, source *imageSource) Image {
+
+	i := Image{
+		ID:   fmt.Sprintf("projects/%s/global/images/%s", projectID, image.Name),
+		Name: image.Name,
+	}
+	if source != nil {
+		i.OS = source.os
+		i.Version = source.version
+		i.Arch = source.arch
+	}
+	i.Created, _ = time.Parse(time.RFC3339, image.CreationTimestamp)
+	return i
+}
+
+// interface: cloud/Compute implementation
+
+func (c *googleCompute) FindImages(filter ImageFilter) ([]Image, error) {
+
+	var (
+		err error
+
+		source *imageSource
+		image  *compute.Image
+	)
+
+	images := []Image{}
+	if filter.Owned {
+		if err = c.service.Images.List(c.projectID).Pages(
+			context.Background(),
+			func(imageList *compute.ImageList) error {
+				for _, image := range imageList.Items {
+					if strings.HasPrefix(image.Name, filter.Name) {
+						images = append(images, newGoogleImage(image, c.projectID, nil))
+					}
+				}
+				return nil
+			},
+		); err != nil {
+			return nil, err
+		}
+		sortImages(images)
+		return images, nil
+	}
+
+	// the latest non-deprecated image
+	// of the os family is returned
+	if source, err = lookupImageSource(filter); err != nil {
+		return nil, err
+	}
+	if image, err = c.service.Images.GetFromFamily(source.googleProject, source.googleFamily).Do(); err != nil {
+		return nil, err
+	}
+	return append(images, newGoogleImage(image, source.googleProject, source)), nil
+}
+
+func (c *googleCompute) CreateImage(instance ComputeInstance, name string) (Image, error) {
+
+	var (
+		err error
+		ok  bool
+
+		googleInstance *googleComputeInstance
+
+		operation *compute.Operation
+		image     *compute.Image
+	)
+
+	if googleInstance, ok = instance.(*googleComputeInstance); !ok {
+		return Image{}, fmt.Errorf("instance '%s' is not a google compute instance", instance.Name())
+	}
+
+	detail := googleInstance.detail()
+
+	sourceDisk := ""
+	for _, attachedDisk := range detail.Disks {
+		if attachedDisk.Boot {
+			sourceDisk = attachedDisk.Source
+			break
+		}
+	}
+	if len(sourceDisk) == 0 {
+		return Image{}, fmt.Errorf("instance '%s' does not have a boot disk", instance.Name())
+	}
+
+	logger.TraceMessage(
+		"Creating image '%s' from boot disk '%s' of instance '%s'.",
+		name, path.Base(sourceDisk), instance.Name(),
+	)
+
+	// the image is force created from the boot disk
+	// while the instance may be running so writes that
+	// have not been flushed to the disk are not captured
+	if operation, err = c.service.Images.Insert(c.projectID, &compute.Image{
+		Name:       name,
+		SourceDisk: sourceDisk,
+		Labels:     detail.Labels,
+	}).ForceCreate(true).Do(); err != nil {
+		return Image{}, err
+	}
+	if err = newOperation(defaultImageOpTimeout, func(ctx context.Context) error {
+		return waitForGoogleOperation(ctx, c.service, c.projectID, operation)
+	}).Wait(context.Background()); err != nil {
+		return Image{}, err
+	}
+	if image, err = c.service.Images.Get(c.projectID, name).Do(); err != nil {
+		return Image{}, err
+	}
+	return newGoogleImage(image, c.projectID, nil), nil
+}
+
+func (c *googleCompute) DeleteImage(id string) error {
+
+	var (
+		err error
+
+		operation *compute.Operation
+	)
+
+	name := path.Base(id)
+	logger.TraceMessage("Deleting image '%s'.", name)
+
+	if operation, err = c.service.Images.Delete(c.projectID, name).Do(); err != nil {
+		return err
+	}
+	return newOperation(defaultImageOpTimeout, func(ctx context.Context) error {
+		return waitForGoogleOperation(ctx, c.service, c.projectID, operation)
+	}).Wait(context.Background())
+}
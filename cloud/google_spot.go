@@ -0,0 +1,124 @@
+package cloud
+
+import (
+	"fmt"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// Returns the scheduling options to request spot or
+// preemptible capacity when launching a gce instance
+// with the given scheduling. Returns nil for on-demand
+// capacity.
+func GoogleScheduling(scheduling InstanceScheduling) *compute.Scheduling {
+
+	switch scheduling.PurchaseModel {
+	case PurchaseSpot:
+		terminationAction := "STOP"
+		if scheduling.InterruptionAction == InterruptTerminate {
+			terminationAction = "DELETE"
+		}
+		return &compute.Scheduling{
+			ProvisioningModel:         "SPOT",
+			InstanceTerminationAction: terminationAction,
+			AutomaticRestart:          googleapi.Bool(false),
+			OnHostMaintenance:         "TERMINATE",
+		}
+	case PurchasePreemptible:
+		return &compute.Scheduling{
+			Preemptible:       true,
+			AutomaticRestart:  googleapi.Bool(false),
+			OnHostMaintenance: "TERMINATE",
+		}
+	}
+	return nil
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *googleComputeInstance) Scheduling() (InstanceScheduling, error) {
+
+	scheduling := InstanceScheduling{
+		PurchaseModel: PurchaseOnDemand,
+	}
+
+	s := c.detail().Scheduling
+	switch {
+	case s == nil:
+		return scheduling, nil
+	case s.ProvisioningModel == "SPOT":
+		scheduling.PurchaseModel = PurchaseSpot
+	case s.Preemptible:
+		scheduling.PurchaseModel = PurchasePreemptible
+	default:
+		return scheduling, nil
+	}
+
+	scheduling.InterruptionAction = InterruptStop
+	if s.InstanceTerminationAction == "DELETE" {
+		scheduling.InterruptionAction = InterruptTerminate
+	}
+	return scheduling, nil
+}
+
+func (c *googleComputeInstance) InterruptionNotice() (InterruptionNotice, error) {
+
+	var (
+		err error
+
+		scheduling InstanceScheduling
+		instance   *compute.Instance
+		operations *compute.OperationList
+
+		preemptedTime,
+		lastStartTime time.Time
+	)
+
+	notice := InterruptionNotice{}
+	if scheduling, err = c.Scheduling(); err != nil || scheduling.PurchaseModel == PurchaseOnDemand {
+		return notice, err
+	}
+	if instance, err = c.service.Instances.Get(c.projectID, c.zone, c.detail().Name).Do(); err != nil {
+		return notice, err
+	}
+	c.setDetail(instance)
+
+	// a preempted instance has a system event
+	// operation recording the preemption
+	if operations, err = c.service.ZoneOperations.List(c.projectID, c.zone).
+		Filter(fmt.Sprintf(
+			`(operationType = "compute.instances.preempted") AND (targetId = %d)`,
+			instance.Id,
+		)).
+		OrderBy("creationTimestamp desc").
+		Do(); err != nil {
+		return notice, err
+	}
+	if len(operations.Items) == 0 {
+		return notice, nil
+	}
+	preemption := operations.Items[0]
+	if preemptedTime, err = time.Parse(time.RFC3339, preemption.InsertTime); err != nil {
+		return notice, err
+	}
+
+	// ignore preemptions before the instance was
+	// last started as it has since been restarted
+	if len(instance.LastStartTimestamp) > 0 {
+		if lastStartTime, err = time.Parse(time.RFC3339, instance.LastStartTimestamp); err != nil {
+			return notice, err
+		}
+		if preemptedTime.Before(lastStartTime) {
+			return notice, nil
+		}
+	}
+
+	return InterruptionNotice{
+		Interrupted: true,
+		Action:      scheduling.InterruptionAction,
+		Time:        preemptedTime,
+		Message:     preemption.StatusMessage,
+	}, nil
+}
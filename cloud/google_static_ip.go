@@ -0,0 +1,222 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	compute "google.golang.org/api/compute/v1"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// name of the access config that
+// maps an external ip to an instance
+const googleAccessConfigName = "External NAT"
+
+// returns the static ip of the given address
+// with the id of the instance using it
+func (c *googleCompute) newGoogleStaticIP(address *compute.Address) (StaticIP, error) {
+
+	var (
+		err error
+
+		instance *compute.Instance
+	)
+
+	ip := StaticIP{
+		ID:      fmt.Sprintf("projects/%s/regions/%s/addresses/%s", c.projectID, path.Base(address.Region), address.Name),
+		Name:    address.Name,
+		Address: address.Address,
+	}
+	for _, user := range address.Users {
+		// * https://www.googleapis.com/compute/v1/projects/{project}/zones/{zone}/instances/{name}
+		elems := strings.Split(user, "/")
+		if len(elems) < 4 || elems[len(elems)-2] != "instances" {
+			continue
+		}
+		if instance, err = c.service.Instances.Get(
+			c.projectID, elems[len(elems)-3], elems[len(elems)-1],
+		).Do(); err != nil {
+			return ip, err
+		}
+		ip.InstanceID = strconv.FormatUint(instance.Id, 10)
+		break
+	}
+	return ip, nil
+}
+
+// interface: cloud/Compute implementation
+
+// Static IPs are reserved as external regional addresses.
+// GCE addresses cannot be labelled so tags are ignored.
+func (c *googleCompute) AllocateStaticIP(name string, tags map[string]string) (StaticIP, error) {
+
+	var (
+		err error
+
+		operation *compute.Operation
+		address   *compute.Address
+	)
+
+	logger.TraceMessage(
+		"Reserving static external address '%s' in region '%s'.", name, c.props.Region)
+
+	if operation, err = c.service.Addresses.Insert(c.projectID, c.props.Region, &compute.Address{
+		Name:        name,
+		AddressType: "EXTERNAL",
+	}).Do(); err != nil {
+		return StaticIP{}, err
+	}
+	if err = newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return waitForGoogleOperation(ctx, c.service, c.projectID, operation)
+	}).Wait(context.Background()); err != nil {
+		return StaticIP{}, err
+	}
+
+	if address, err = c.service.Addresses.Get(c.projectID, c.props.Region, name).Do(); err != nil {
+		return StaticIP{}, err
+	}
+	return c.newGoogleStaticIP(address)
+}
+
+func (c *googleCompute) ListStaticIPs() ([]StaticIP, error) {
+
+	var (
+		err error
+
+		ip StaticIP
+	)
+
+	ips := []StaticIP{}
+	if err = c.service.Addresses.List(c.projectID, c.props.Region).
+		Filter(`addressType = "EXTERNAL"`).
+		Pages(context.Background(), func(page *compute.AddressList) error {
+			for _, address := range page.Items {
+				if ip, err = c.newGoogleStaticIP(address); err != nil {
+					return err
+				}
+				ips = append(ips, ip)
+			}
+			return nil
+		}); err != nil {
+		return nil, err
+	}
+	return ips, nil
+}
+
+func (c *googleCompute) ReleaseStaticIP(ip StaticIP) error {
+
+	var (
+		err error
+
+		operation *compute.Operation
+	)
+
+	// * projects/{project}/regions/{region}/addresses/{name}
+	elems := strings.Split(ip.ID, "/")
+	if len(elems) != 6 || elems[4] != "addresses" {
+		return fmt.Errorf("'%s' is not the id of a gce address", ip.ID)
+	}
+
+	logger.TraceMessage(
+		"Releasing static external address '%s' in region '%s'.", elems[5], elems[3])
+
+	if operation, err = c.service.Addresses.Delete(c.projectID, elems[3], elems[5]).Do(); err != nil {
+		return err
+	}
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return waitForGoogleOperation(ctx, c.service, c.projectID, operation)
+	}).Wait(context.Background())
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *googleComputeInstance) AssociateStaticIP(ip StaticIP) error {
+
+	var (
+		err error
+	)
+
+	instance := c.detail()
+	if len(instance.NetworkInterfaces) == 0 {
+		return fmt.Errorf("instance '%s' has no network interfaces", instance.Name)
+	}
+	nic := instance.NetworkInterfaces[0]
+
+	logger.TraceMessage(
+		"Associating static external address '%s' (%s) with instance '%s'.",
+		ip.Name, ip.Address, c.detail().Name,
+	)
+
+	// the instance's ephemeral external
+	// address needs to be removed first
+	for _, accessConfig := range nic.AccessConfigs {
+		if err = c.updateInstance(func() (*compute.Operation, error) {
+			return c.service.Instances.DeleteAccessConfig(
+				c.projectID, c.zone, c.detail().Name, accessConfig.Name, nic.Name,
+			).Do()
+		}); err != nil {
+			return err
+		}
+	}
+	return c.updateInstance(func() (*compute.Operation, error) {
+		return c.service.Instances.AddAccessConfig(
+			c.projectID, c.zone, c.detail().Name, nic.Name,
+			&compute.AccessConfig{
+				Name:  googleAccessConfigName,
+				Type:  "ONE_TO_ONE_NAT",
+				NatIP: ip.Address,
+			},
+		).Do()
+	})
+}
+
+// On disassociation the instance is assigned a
+// new ephemeral external address.
+func (c *googleComputeInstance) DisassociateStaticIP(ip StaticIP) error {
+
+	var (
+		err error
+
+		accessConfig *compute.AccessConfig
+	)
+
+	instance := c.detail()
+	if len(instance.NetworkInterfaces) == 0 {
+		return fmt.Errorf("instance '%s' has no network interfaces", instance.Name)
+	}
+	nic := instance.NetworkInterfaces[0]
+	for _, ac := range nic.AccessConfigs {
+		if ac.NatIP == ip.Address {
+			accessConfig = ac
+		}
+	}
+	if accessConfig == nil {
+		return fmt.Errorf("static IP '%s' is not associated with instance '%s'", ip.Address, c.detail().Name)
+	}
+
+	logger.TraceMessage(
+		"Disassociating static external address '%s' (%s) from instance '%s'.",
+		ip.Name, ip.Address, c.detail().Name,
+	)
+
+	if err = c.updateInstance(func() (*compute.Operation, error) {
+		return c.service.Instances.DeleteAccessConfig(
+			c.projectID, c.zone, c.detail().Name, accessConfig.Name, nic.Name,
+		).Do()
+	}); err != nil {
+		return err
+	}
+	return c.updateInstance(func() (*compute.Operation, error) {
+		return c.service.Instances.AddAccessConfig(
+			c.projectID, c.zone, c.detail().Name, nic.Name,
+			&compute.AccessConfig{
+				Name: googleAccessConfigName,
+				Type: "ONE_TO_ONE_NAT",
+			},
+		).Do()
+	})
+}
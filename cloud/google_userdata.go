@@ -0,0 +1,92 @@
+package cloud
+
+import (
+	compute "google.golang.org/api/compute/v1"
+
+	"github.com/mevansam/gocloud/cloud/cloudinit"
+	"github.com/mevansam/goutils/logger"
+)
+
+// instance metadata keys read by cloud-init
+// and the gce guest agent respectively
+const (
+	googleUserDataKey      = "user-data"
+	googleStartupScriptKey = "startup-script"
+)
+
+// interface: cloud/ComputeInstance implementation
+
+// Returns the instance's cloud-init user data or its
+// startup script if it does not have any user data.
+func (c *googleComputeInstance) UserData() (string, error) {
+
+	var (
+		err error
+
+		instance *compute.Instance
+	)
+
+	if instance, err = c.service.Instances.Get(c.projectID, c.zone, c.detail().Name).Do(); err != nil {
+		return "", err
+	}
+	c.setDetail(instance)
+
+	values := make(map[string]string)
+	if instance.Metadata != nil {
+		for _, item := range instance.Metadata.Items {
+			if item.Value != nil {
+				values[item.Key] = *item.Value
+			}
+		}
+	}
+	if data, ok := values[googleUserDataKey]; ok {
+		return data, nil
+	}
+	return values[googleStartupScriptKey], nil
+}
+
+// Shell scripts are set as the instance's startup script
+// which is run by the guest agent on every boot. Any
+// other data is set as the user data consumed by
+// cloud-init. The other metadata key is removed so
+// that only the given data is applied.
+func (c *googleComputeInstance) SetUserData(data string) error {
+
+	var (
+		err error
+
+		instance *compute.Instance
+	)
+
+	if instance, err = c.service.Instances.Get(c.projectID, c.zone, c.detail().Name).Do(); err != nil {
+		return err
+	}
+	c.setDetail(instance)
+
+	key, otherKey := googleUserDataKey, googleStartupScriptKey
+	if cloudinit.DetectContentType(data) == cloudinit.ShellScript {
+		key, otherKey = googleStartupScriptKey, googleUserDataKey
+	}
+
+	metadata := &compute.Metadata{
+		Items: []*compute.MetadataItems{},
+	}
+	if instance.Metadata != nil {
+		metadata.Fingerprint = instance.Metadata.Fingerprint
+		for _, item := range instance.Metadata.Items {
+			if item.Key != key && item.Key != otherKey {
+				metadata.Items = append(metadata.Items, item)
+			}
+		}
+	}
+	metadata.Items = append(metadata.Items, &compute.MetadataItems{
+		Key:   key,
+		Value: &data,
+	})
+
+	logger.TraceMessage("Setting metadata '%s' of instance '%s'.", key, instance.Name)
+
+	return c.updateInstance(func() (*compute.Operation, error) {
+		return c.service.Instances.SetMetadata(c.projectID, c.zone, instance.Name, metadata).Do()
+	})
+}
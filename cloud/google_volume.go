@@ -0,0 +1,335 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	compute "google.golang.org/api/compute/v1"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// default type of persistent disks
+const googleDefaultVolumeType = "pd-balanced"
+
+type googleVolume struct {
+	service *compute.Service
+	disk    *compute.Disk
+	boot    bool
+
+	projectID string
+	zone      string
+
+	props *GoogleComputeProperties
+}
+
+// reloads the disk's state
+func (v *googleVolume) refresh() error {
+
+	var (
+		err error
+
+		disk *compute.Disk
+	)
+
+	if disk, err = v.service.Disks.Get(v.projectID, v.zone, v.disk.Name).Do(); err != nil {
+		return err
+	}
+	v.disk = disk
+	return nil
+}
+
+// runs the given operation on the
+// instance and reloads the instance
+func (c *googleComputeInstance) updateInstance(call func() (*compute.Operation, error)) error {
+
+	var (
+		err error
+
+		operation *compute.Operation
+		instance  *compute.Instance
+	)
+
+	if operation, err = call(); err != nil {
+		return err
+	}
+	if err = newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return waitForGoogleOperation(ctx, c.service, c.projectID, operation)
+	}).Wait(context.Background()); err != nil {
+		return err
+	}
+	if instance, err = c.service.Instances.Get(c.projectID, c.zone, c.detail().Name).Do(); err != nil {
+		return err
+	}
+	c.setDetail(instance)
+	return nil
+}
+
+// interface: cloud/Compute implementation
+
+func (c *googleCompute) CreateVolume(spec VolumeSpec) (Volume, error) {
+
+	var (
+		err error
+
+		zones     []string
+		operation *compute.Operation
+	)
+
+	zone := spec.Zone
+	if len(zone) == 0 {
+		if zones, err = c.zoneList(); err != nil {
+			return nil, err
+		}
+		if len(zones) == 0 {
+			return nil, fmt.Errorf("no zones found in region '%s'", c.props.Region)
+		}
+		zone = zones[0]
+	}
+	volumeType := spec.Type
+	if len(volumeType) == 0 {
+		volumeType = googleDefaultVolumeType
+	}
+
+	logger.TraceMessage(
+		"Creating %dGB disk '%s' of type '%s' in zone '%s'.",
+		spec.SizeGB, spec.Name, volumeType, zone,
+	)
+
+	if operation, err = c.service.Disks.Insert(c.projectID, zone, &compute.Disk{
+		Name:   spec.Name,
+		SizeGb: int64(spec.SizeGB),
+		Type:   fmt.Sprintf("zones/%s/diskTypes/%s", zone, volumeType),
+		Labels: spec.Tags,
+	}).Do(); err != nil {
+		return nil, err
+	}
+	if err = newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return waitForGoogleOperation(ctx, c.service, c.projectID, operation)
+	}).Wait(context.Background()); err != nil {
+		return nil, err
+	}
+
+	v := &googleVolume{
+		service: c.service,
+		disk:    &compute.Disk{Name: spec.Name},
+
+		projectID: c.projectID,
+		zone:      zone,
+
+		props: &c.props,
+	}
+	if err = v.refresh(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *googleComputeInstance) Volumes() ([]Volume, error) {
+
+	var (
+		err error
+
+		instance *compute.Instance
+	)
+
+	if instance, err = c.service.Instances.Get(c.projectID, c.zone, c.detail().Name).Do(); err != nil {
+		return nil, err
+	}
+	c.setDetail(instance)
+
+	volumes := []Volume{}
+	for _, attachedDisk := range instance.Disks {
+		if strings.Contains(attachedDisk.Source, "/regions/") {
+			// regional disks are not supported
+			continue
+		}
+		v := &googleVolume{
+			service: c.service,
+			disk:    &compute.Disk{Name: path.Base(attachedDisk.Source)},
+			boot:    attachedDisk.Boot,
+
+			projectID: c.projectID,
+			zone:      c.zone,
+
+			props: c.props,
+		}
+		if err = v.refresh(); err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, v)
+	}
+	return volumes, nil
+}
+
+func (c *googleComputeInstance) AttachVolume(volume Volume) error {
+
+	var (
+		err error
+		ok  bool
+
+		v *googleVolume
+	)
+
+	if v, ok = volume.(*googleVolume); !ok {
+		return fmt.Errorf("volume '%s' is not a google persistent disk", volume.Name())
+	}
+
+	logger.TraceMessage(
+		"Attaching disk '%s' to instance '%s'.", v.disk.Name, c.detail().Name)
+
+	// the device name is assigned by
+	// gce when the disk is attached
+	if err = c.updateInstance(func() (*compute.Operation, error) {
+		return c.service.Instances.AttachDisk(
+			c.projectID,
+			c.zone,
+			c.detail().Name,
+			&compute.AttachedDisk{
+				Source: v.disk.SelfLink,
+			},
+		).Do()
+	}); err != nil {
+		return err
+	}
+	return v.refresh()
+}
+
+func (c *googleComputeInstance) DetachVolume(volume Volume) error {
+
+	var (
+		err error
+		ok  bool
+
+		v *googleVolume
+	)
+
+	if v, ok = volume.(*googleVolume); !ok {
+		return fmt.Errorf("volume '%s' is not a google persistent disk", volume.Name())
+	}
+	if v.boot {
+		return fmt.Errorf("the boot disk of instance '%s' cannot be detached", c.detail().Name)
+	}
+
+	deviceName := ""
+	for _, attachedDisk := range c.detail().Disks {
+		if path.Base(attachedDisk.Source) == v.disk.Name {
+			deviceName = attachedDisk.DeviceName
+			break
+		}
+	}
+	if len(deviceName) == 0 {
+		return fmt.Errorf(
+			"disk '%s' is not attached to instance '%s'", v.disk.Name, c.detail().Name,
+		)
+	}
+
+	logger.TraceMessage(
+		"Detaching disk '%s' from instance '%s'.", v.disk.Name, c.detail().Name)
+
+	if err = c.updateInstance(func() (*compute.Operation, error) {
+		return c.service.Instances.DetachDisk(
+			c.projectID,
+			c.zone,
+			c.detail().Name,
+			deviceName,
+		).Do()
+	}); err != nil {
+		return err
+	}
+	return v.refresh()
+}
+
+// interface: cloud/Volume implementation
+
+func (v *googleVolume) ID() string {
+	return strconv.FormatUint(v.disk.Id, 10)
+}
+
+func (v *googleVolume) Name() string {
+	return v.disk.Name
+}
+
+func (v *googleVolume) SizeGB() int {
+	return int(v.disk.SizeGb)
+}
+
+func (v *googleVolume) Type() string {
+	return path.Base(v.disk.Type)
+}
+
+func (v *googleVolume) Zone() string {
+	return v.zone
+}
+
+func (v *googleVolume) Attached() bool {
+	return len(v.disk.Users) > 0
+}
+
+func (v *googleVolume) Boot() bool {
+	return v.boot
+}
+
+func (v *googleVolume) Snapshot() (VolumeSnapshot, error) {
+
+	var (
+		err error
+
+		operation *compute.Operation
+		snapshot  *compute.Snapshot
+	)
+
+	name := snapshotName(v.disk.Name)
+	logger.TraceMessage(
+		"Creating snapshot '%s' of disk '%s'.", name, v.disk.Name)
+
+	if operation, err = v.service.Disks.CreateSnapshot(
+		v.projectID,
+		v.zone,
+		v.disk.Name,
+		&compute.Snapshot{
+			Name:   name,
+			Labels: v.disk.Labels,
+		},
+	).Do(); err != nil {
+		return VolumeSnapshot{}, err
+	}
+	if err = newOperation(v.props.OpTimeout, func(ctx context.Context) error {
+		return waitForGoogleOperation(ctx, v.service, v.projectID, operation)
+	}).Wait(context.Background()); err != nil {
+		return VolumeSnapshot{}, err
+	}
+	if snapshot, err = v.service.Snapshots.Get(v.projectID, name).Do(); err != nil {
+		return VolumeSnapshot{}, err
+	}
+
+	return VolumeSnapshot{
+		ID:       strconv.FormatUint(snapshot.Id, 10),
+		Name:     name,
+		VolumeID: v.ID(),
+		SizeGB:   int(snapshot.DiskSizeGb),
+	}, nil
+}
+
+func (v *googleVolume) Delete() error {
+
+	var (
+		err error
+
+		operation *compute.Operation
+	)
+
+	logger.TraceMessage("Deleting disk '%s'.", v.disk.Name)
+
+	if operation, err = v.service.Disks.Delete(v.projectID, v.zone, v.disk.Name).Do(); err != nil {
+		return err
+	}
+	return newOperation(v.props.OpTimeout, func(ctx context.Context) error {
+		return waitForGoogleOperation(ctx, v.service, v.projectID, operation)
+	}).Wait(context.Background())
+}
@@ -0,0 +1,315 @@
+package cloud
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+type HealthCheckType int
+
+const (
+	HealthCheckTCP HealthCheckType = iota + 1
+	HealthCheckHTTP
+	HealthCheckHTTPS
+	HealthCheckTLS
+	HealthCheckSSH
+)
+
+func (t HealthCheckType) String() string {
+	switch t {
+	case HealthCheckTCP:
+		return "tcp"
+	case HealthCheckHTTP:
+		return "http"
+	case HealthCheckHTTPS:
+		return "https"
+	case HealthCheckTLS:
+		return "tls"
+	case HealthCheckSSH:
+		return "ssh"
+	}
+	return "unknown"
+}
+
+// the instance address a health check
+// should be run against
+type HealthCheckTarget int
+
+const (
+	// use the public address if the instance
+	// has one otherwise the private address
+	TargetAny HealthCheckTarget = iota
+	TargetPublic
+	TargetPrivate
+)
+
+// default timeout of a single health check
+const defaultHealthCheckTimeout = time.Second * 5
+
+// interval at which health checks are
+// retried when waiting for an instance
+// to become healthy
+const healthCheckRetryInterval = time.Second * 5
+
+type HealthCheck struct {
+	Type HealthCheckType
+	Port int
+
+	Target HealthCheckTarget
+
+	// timeout of the check. defaults to 5s.
+	Timeout time.Duration
+
+	// the url path and the expected response
+	// status for http/https checks. defaults
+	// to "/" and 200.
+	Path           string
+	ExpectedStatus int
+
+	// tls options for https/tls checks. if server
+	// name is not provided then the address
+	// checked will be used.
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+type HealthCheckResult struct {
+	Check   HealthCheck
+	Address string
+
+	Healthy bool
+	Latency time.Duration
+	Err     error
+
+	// response status of an http/https check
+	StatusCode int
+	// expiry of the leaf certificate
+	// presented in an https/tls check
+	CertificateExpiry time.Time
+	// banner returned by an ssh check
+	Banner string
+}
+
+// implemented by instances whose ports are
+// reachable via other ports of their public ip
+type publicAddressResolver interface {
+	publicAddress(port int) (string, int)
+}
+
+// returns the host and port the given port of the
+// instance is reachable at via its public ip
+func publicAddress(instance ComputeInstance, port int) (string, int) {
+	if resolver, ok := instance.(publicAddressResolver); ok {
+		return resolver.publicAddress(port)
+	}
+	return instance.PublicIP(), port
+}
+
+// Runs the given health check against the instance
+func CheckHealth(ctx context.Context, instance ComputeInstance, check HealthCheck) HealthCheckResult {
+
+	var (
+		host string
+	)
+
+	result := HealthCheckResult{Check: check}
+	if check.Timeout == 0 {
+		check.Timeout = defaultHealthCheckTimeout
+	}
+
+	port := check.Port
+	switch check.Target {
+	case TargetPublic:
+		host, port = publicAddress(instance, check.Port)
+	case TargetPrivate:
+		host = instance.PrivateIP()
+	default:
+		if host, port = publicAddress(instance, check.Port); len(host) == 0 {
+			host, port = instance.PrivateIP(), check.Port
+		}
+	}
+	if len(host) == 0 {
+		result.Err = fmt.Errorf(
+			"instance '%s' does not have an address to run the %s health check against",
+			instance.Name(), check.Type,
+		)
+		return result
+	}
+	result.Address = net.JoinHostPort(host, strconv.Itoa(port))
+
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	switch check.Type {
+	case HealthCheckTCP:
+		result.Err = checkTCP(ctx, &result)
+	case HealthCheckHTTP, HealthCheckHTTPS:
+		result.Err = checkHTTP(ctx, &result, check)
+	case HealthCheckTLS:
+		result.Err = checkTLS(ctx, &result, check)
+	case HealthCheckSSH:
+		result.Err = checkSSH(ctx, &result)
+	default:
+		result.Err = fmt.Errorf("unknown health check type %d", check.Type)
+	}
+	result.Latency = time.Since(start)
+	result.Healthy = result.Err == nil
+
+	logger.TraceMessage(
+		"Health check %s of instance '%s' at '%s' healthy: %t",
+		check.Type, instance.Name(), result.Address, result.Healthy,
+	)
+	return result
+}
+
+// Waits until all the given health checks
+// pass or the given context is done
+func WaitUntilHealthy(ctx context.Context, instance ComputeInstance, checks ...HealthCheck) error {
+
+	for {
+		var failed *HealthCheckResult
+		for _, check := range checks {
+			if result := CheckHealth(ctx, instance, check); !result.Healthy {
+				failed = &result
+				break
+			}
+		}
+		if failed == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"timed out waiting for instance '%s' to become healthy: %s health check of '%s' failed: %s",
+				instance.Name(), failed.Check.Type, failed.Address, failed.Err.Error(),
+			)
+		case <-time.After(healthCheckRetryInterval):
+		}
+	}
+}
+
+func checkTCP(ctx context.Context, result *HealthCheckResult) error {
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", result.Address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func checkHTTP(ctx context.Context, result *HealthCheckResult, check HealthCheck) error {
+
+	var (
+		err error
+
+		req  *http.Request
+		resp *http.Response
+	)
+
+	scheme := "http"
+	if check.Type == HealthCheckHTTPS {
+		scheme = "https"
+	}
+	path := check.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	expectedStatus := check.ExpectedStatus
+	if expectedStatus == 0 {
+		expectedStatus = http.StatusOK
+	}
+
+	client := &http.Client{
+		// each probe uses its own transport so its
+		// connection must not be kept alive after it
+		Transport: &http.Transport{
+			TLSClientConfig:   tlsConfig(check),
+			DisableKeepAlives: true,
+		},
+		// health check the response
+		// without following redirects
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+result.Address+path, nil); err != nil {
+		return err
+	}
+	if resp, err = client.Do(req); err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		result.CertificateExpiry = resp.TLS.PeerCertificates[0].NotAfter
+	}
+	if resp.StatusCode != expectedStatus {
+		return fmt.Errorf("expected status %d but got %d", expectedStatus, resp.StatusCode)
+	}
+	return nil
+}
+
+func checkTLS(ctx context.Context, result *HealthCheckResult, check HealthCheck) error {
+
+	dialer := &tls.Dialer{Config: tlsConfig(check)}
+	conn, err := dialer.DialContext(ctx, "tcp", result.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	if len(state.PeerCertificates) > 0 {
+		result.CertificateExpiry = state.PeerCertificates[0].NotAfter
+	}
+	return nil
+}
+
+func checkSSH(ctx context.Context, result *HealthCheckResult) error {
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", result.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetReadDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	// the server identification string is "SSH-protoversion-softwareversion"
+	// but may be preceded by other lines of text (RFC 4253 section 4.2)
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if line = strings.TrimRight(line, "\r\n"); strings.HasPrefix(line, "SSH-") {
+			result.Banner = line
+			return nil
+		}
+	}
+}
+
+func tlsConfig(check HealthCheck) *tls.Config {
+	return &tls.Config{
+		ServerName:         check.ServerName,
+		InsecureSkipVerify: check.InsecureSkipVerify,
+	}
+}
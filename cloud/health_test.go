@@ -0,0 +1,188 @@
+package cloud_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mevansam/gocloud/cloud"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_helpers "github.com/mevansam/gocloud/test/helpers"
+	test_mocks "github.com/mevansam/gocloud/test/mocks"
+)
+
+var _ = Describe("Instance Health Checks", func() {
+
+	var (
+		instance *test_mocks.FakeComputeInstance
+	)
+
+	serverPort := func(serverURL string) int {
+		u, err := url.Parse(serverURL)
+		Expect(err).NotTo(HaveOccurred())
+		port, err := strconv.Atoi(u.Port())
+		Expect(err).NotTo(HaveOccurred())
+		return port
+	}
+
+	BeforeEach(func() {
+		instance = &test_mocks.FakeComputeInstance{}
+		instance.SetValues("test-id", "test", "", cloud.StateRunning)
+		instance.SetPrivateIP("127.0.0.1")
+	})
+
+	It("checks tcp connectivity against the selected address", func() {
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+		port := listener.Addr().(*net.TCPAddr).Port
+
+		result := instance.HealthCheck(context.Background(), cloud.HealthCheck{
+			Type: cloud.HealthCheckTCP,
+			Port: port,
+		})
+		Expect(result.Err).NotTo(HaveOccurred())
+		Expect(result.Healthy).To(BeTrue())
+		Expect(result.Address).To(Equal(net.JoinHostPort("127.0.0.1", strconv.Itoa(port))))
+
+		result = instance.HealthCheck(context.Background(), cloud.HealthCheck{
+			Type:   cloud.HealthCheckTCP,
+			Port:   port,
+			Target: cloud.TargetPublic,
+		})
+		Expect(result.Healthy).To(BeFalse())
+		Expect(result.Err).To(HaveOccurred())
+
+		listener.Close()
+		result = instance.HealthCheck(context.Background(), cloud.HealthCheck{
+			Type:    cloud.HealthCheckTCP,
+			Port:    port,
+			Target:  cloud.TargetPrivate,
+			Timeout: time.Second,
+		})
+		Expect(result.Healthy).To(BeFalse())
+		Expect(result.Err).To(HaveOccurred())
+	})
+
+	It("checks http and https endpoints and reports certificate expiry", func() {
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/health" {
+				w.WriteHeader(http.StatusOK)
+			} else {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+
+		httpServer := httptest.NewServer(handler)
+		defer httpServer.Close()
+
+		result := instance.HealthCheck(context.Background(), cloud.HealthCheck{
+			Type: cloud.HealthCheckHTTP,
+			Port: serverPort(httpServer.URL),
+			Path: "/health",
+		})
+		Expect(result.Err).NotTo(HaveOccurred())
+		Expect(result.StatusCode).To(Equal(http.StatusOK))
+
+		result = instance.HealthCheck(context.Background(), cloud.HealthCheck{
+			Type: cloud.HealthCheckHTTP,
+			Port: serverPort(httpServer.URL),
+			Path: "/other",
+		})
+		Expect(result.Healthy).To(BeFalse())
+		Expect(result.StatusCode).To(Equal(http.StatusNotFound))
+
+		result = instance.HealthCheck(context.Background(), cloud.HealthCheck{
+			Type:           cloud.HealthCheckHTTP,
+			Port:           serverPort(httpServer.URL),
+			Path:           "/other",
+			ExpectedStatus: http.StatusNotFound,
+		})
+		Expect(result.Healthy).To(BeTrue())
+
+		httpsServer := httptest.NewTLSServer(handler)
+		defer httpsServer.Close()
+
+		result = instance.HealthCheck(context.Background(), cloud.HealthCheck{
+			Type: cloud.HealthCheckHTTPS,
+			Port: serverPort(httpsServer.URL),
+			Path: "/health",
+		})
+		Expect(result.Healthy).To(BeFalse())
+		Expect(result.Err).To(HaveOccurred())
+
+		result = instance.HealthCheck(context.Background(), cloud.HealthCheck{
+			Type:               cloud.HealthCheckHTTPS,
+			Port:               serverPort(httpsServer.URL),
+			Path:               "/health",
+			InsecureSkipVerify: true,
+		})
+		Expect(result.Err).NotTo(HaveOccurred())
+		Expect(result.CertificateExpiry).To(Equal(httpsServer.Certificate().NotAfter))
+
+		result = instance.HealthCheck(context.Background(), cloud.HealthCheck{
+			Type:               cloud.HealthCheckTLS,
+			Port:               serverPort(httpsServer.URL),
+			InsecureSkipVerify: true,
+		})
+		Expect(result.Err).NotTo(HaveOccurred())
+		Expect(result.CertificateExpiry).To(Equal(httpsServer.Certificate().NotAfter))
+	})
+
+	It("checks the ssh banner", func() {
+
+		_, authorizedKey := test_helpers.GenerateSSHKey()
+		server := test_helpers.StartSSHServer(authorizedKey)
+		defer server.Stop()
+
+		result := instance.HealthCheck(context.Background(), cloud.HealthCheck{
+			Type: cloud.HealthCheckSSH,
+			Port: server.Port(),
+		})
+		Expect(result.Err).NotTo(HaveOccurred())
+		Expect(result.Banner).To(HavePrefix("SSH-2.0-"))
+	})
+
+	It("waits until an instance is healthy", func() {
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		port := listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+		defer cancel()
+		err = instance.WaitUntilHealthy(ctx, cloud.HealthCheck{
+			Type: cloud.HealthCheckTCP,
+			Port: port,
+		})
+		Expect(err).To(HaveOccurred())
+
+		_, authorizedKey := test_helpers.GenerateSSHKey()
+		server := test_helpers.StartSSHServer(authorizedKey)
+		defer server.Stop()
+
+		ctx, cancel = context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+		err = instance.WaitUntilHealthy(ctx,
+			cloud.HealthCheck{
+				Type: cloud.HealthCheckTCP,
+				Port: server.Port(),
+			},
+			cloud.HealthCheck{
+				Type: cloud.HealthCheckSSH,
+				Port: server.Port(),
+			},
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,132 @@
+package cloud
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// normalized image architectures
+const (
+	ArchX86_64 = "x86_64"
+	ArchARM64  = "arm64"
+)
+
+// timeout for image create and delete operations
+// which take much longer than instance operations
+const defaultImageOpTimeout = time.Minute * 30
+
+// locations of the public images of a normalized
+// os, version and architecture in each cloud
+type imageSource struct {
+	os, version, arch string
+
+	// owner account and name pattern of ec2 amis
+	awsOwner, awsName string
+
+	// azure marketplace image
+	azurePublisher, azureOffer, azureSKU string
+
+	// gce image project and family
+	googleProject, googleFamily string
+
+	// digitalocean distribution image slug
+	// which is only published for x86_64
+	digitalOceanSlug string
+}
+
+var imageSources = []imageSource{
+	{
+		os: "ubuntu", version: "20.04", arch: ArchX86_64,
+		awsOwner: "099720109477", awsName: "ubuntu/images/hvm-ssd/ubuntu-focal-20.04-amd64-server-*",
+		azurePublisher: "Canonical", azureOffer: "0001-com-ubuntu-server-focal", azureSKU: "20_04-lts-gen2",
+		googleProject: "ubuntu-os-cloud", googleFamily: "ubuntu-2004-lts",
+		digitalOceanSlug: "ubuntu-20-04-x64",
+	},
+	{
+		os: "ubuntu", version: "20.04", arch: ArchARM64,
+		awsOwner: "099720109477", awsName: "ubuntu/images/hvm-ssd/ubuntu-focal-20.04-arm64-server-*",
+		azurePublisher: "Canonical", azureOffer: "0001-com-ubuntu-server-focal", azureSKU: "20_04-lts-arm64",
+		googleProject: "ubuntu-os-cloud", googleFamily: "ubuntu-2004-lts-arm64",
+	},
+	{
+		os: "ubuntu", version: "22.04", arch: ArchX86_64,
+		awsOwner: "099720109477", awsName: "ubuntu/images/hvm-ssd/ubuntu-jammy-22.04-amd64-server-*",
+		azurePublisher: "Canonical", azureOffer: "0001-com-ubuntu-server-jammy", azureSKU: "22_04-lts-gen2",
+		googleProject: "ubuntu-os-cloud", googleFamily: "ubuntu-2204-lts",
+		digitalOceanSlug: "ubuntu-22-04-x64",
+	},
+	{
+		os: "ubuntu", version: "22.04", arch: ArchARM64,
+		awsOwner: "099720109477", awsName: "ubuntu/images/hvm-ssd/ubuntu-jammy-22.04-arm64-server-*",
+		azurePublisher: "Canonical", azureOffer: "0001-com-ubuntu-server-jammy", azureSKU: "22_04-lts-arm64",
+		googleProject: "ubuntu-os-cloud", googleFamily: "ubuntu-2204-lts-arm64",
+	},
+	{
+		os: "debian", version: "11", arch: ArchX86_64,
+		awsOwner: "136693071363", awsName: "debian-11-amd64-*",
+		azurePublisher: "Debian", azureOffer: "debian-11", azureSKU: "11-gen2",
+		googleProject: "debian-cloud", googleFamily: "debian-11",
+		digitalOceanSlug: "debian-11-x64",
+	},
+	{
+		os: "debian", version: "11", arch: ArchARM64,
+		awsOwner: "136693071363", awsName: "debian-11-arm64-*",
+		azurePublisher: "Debian", azureOffer: "debian-11", azureSKU: "11-arm64",
+		googleProject: "debian-cloud", googleFamily: "debian-11-arm64",
+	},
+	{
+		os: "debian", version: "12", arch: ArchX86_64,
+		awsOwner: "136693071363", awsName: "debian-12-amd64-*",
+		azurePublisher: "Debian", azureOffer: "debian-12", azureSKU: "12-gen2",
+		googleProject: "debian-cloud", googleFamily: "debian-12",
+		digitalOceanSlug: "debian-12-x64",
+	},
+	{
+		os: "debian", version: "12", arch: ArchARM64,
+		awsOwner: "136693071363", awsName: "debian-12-arm64-*",
+		azurePublisher: "Debian", azureOffer: "debian-12", azureSKU: "12-arm64",
+		googleProject: "debian-cloud", googleFamily: "debian-12-arm64",
+	},
+}
+
+// returns the public image source matching the
+// os, version and architecture of the filter
+func lookupImageSource(filter ImageFilter) (*imageSource, error) {
+
+	arch := filter.Arch
+	if len(arch) == 0 {
+		arch = ArchX86_64
+	}
+	for i, source := range imageSources {
+		if source.os == strings.ToLower(filter.OS) &&
+			source.version == filter.Version &&
+			source.arch == arch {
+			return &imageSources[i], nil
+		}
+	}
+	return nil, fmt.Errorf(
+		"no public image found for os '%s' version '%s' and architecture '%s'",
+		filter.OS, filter.Version, arch,
+	)
+}
+
+// normalizes cloud specific architecture names
+func normalizeArch(arch string) string {
+
+	switch strings.ToLower(arch) {
+	case "x86_64", "amd64", "x64":
+		return ArchX86_64
+	case "arm64", "aarch64":
+		return ArchARM64
+	}
+	return strings.ToLower(arch)
+}
+
+// sorts images newest first
+func sortImages(images []Image) {
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].Created.After(images[j].Created)
+	})
+}
@@ -32,5 +32,8 @@ var _ = AfterSuite(func() {
 	test_helpers.CleanUpAWSTestData()
 	test_helpers.CleanUpAzureTestData()
 	test_helpers.CleanUpGoogleTestData()
+	test_helpers.CleanUpDigitalOceanTestData()
+	test_helpers.CleanUpOpenStackTestData()
+	test_helpers.CleanUpLocalTestData()
 	gexec.CleanupBuildArtifacts()
 })
@@ -0,0 +1,159 @@
+package cloud
+
+import (
+	"fmt"
+	"time"
+)
+
+type LocalComputeProperties struct {
+	// timeout for start/stop operations
+	OpTimeout time.Duration
+
+	// maximum number of instance operations
+	// to run concurrently for batch operations
+	MaxConcurrency int
+
+	// only docker containers having all of
+	// these labels are listed if it is set
+	FilterLabels map[string]string
+}
+
+// Local instances do not have machine types so these
+// sizes set the cpu and memory limits of containers.
+var localInstanceSizes = []InstanceSize{
+	{Name: "large", VCPUs: 4, MemoryMB: 4096},
+	{Name: "medium", VCPUs: 2, MemoryMB: 2048},
+	{Name: "small", VCPUs: 1, MemoryMB: 1024},
+}
+
+// address via which local instances are reached
+// when they do not have an address of their own
+const localHostIP = "127.0.0.1"
+
+// base local compute implementation of the cloud
+// resources that do not exist on the local host
+type localCompute struct {
+	props LocalComputeProperties
+}
+
+// base local compute instance implementation of the
+// cloud resources that do not exist on the local host
+type localComputeInstance struct {
+	name string
+
+	props *LocalComputeProperties
+}
+
+// interface: cloud/Compute implementation
+
+func (c *localCompute) SetProperties(props interface{}) {
+
+	p := props.(LocalComputeProperties)
+	if p.OpTimeout != 0 {
+		c.props.OpTimeout = p.OpTimeout
+	}
+	if p.MaxConcurrency != 0 {
+		c.props.MaxConcurrency = p.MaxConcurrency
+	}
+	if p.FilterLabels != nil {
+		c.props.FilterLabels = p.FilterLabels
+	}
+}
+
+func (c *localCompute) CreateVolume(spec VolumeSpec) (Volume, error) {
+	return nil, fmt.Errorf("volumes cannot be created for local instances")
+}
+
+func (c *localCompute) FindImages(filter ImageFilter) ([]Image, error) {
+	return nil, fmt.Errorf("images of local instances are not managed")
+}
+
+func (c *localCompute) CreateImage(instance ComputeInstance, name string) (Image, error) {
+	return Image{}, fmt.Errorf("images of local instances are not managed")
+}
+
+func (c *localCompute) DeleteImage(id string) error {
+	return fmt.Errorf("images of local instances are not managed")
+}
+
+func (c *localCompute) AllocateStaticIP(name string, tags map[string]string) (StaticIP, error) {
+	return StaticIP{}, fmt.Errorf("static IPs cannot be allocated for local instances")
+}
+
+func (c *localCompute) ListStaticIPs() ([]StaticIP, error) {
+	return []StaticIP{}, nil
+}
+
+func (c *localCompute) ReleaseStaticIP(ip StaticIP) error {
+	return fmt.Errorf("static IPs cannot be allocated for local instances")
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *localComputeInstance) Name() string {
+	return c.name
+}
+
+// Local instances do not have a public DNS
+// name so an empty string is returned.
+func (c *localComputeInstance) PublicDNS() string {
+	return ""
+}
+
+// Local instances have no volumes of
+// their own so the list is always empty.
+func (c *localComputeInstance) Volumes() ([]Volume, error) {
+	return []Volume{}, nil
+}
+
+func (c *localComputeInstance) AttachVolume(volume Volume) error {
+	return fmt.Errorf("volumes cannot be attached to local instance '%s'", c.name)
+}
+
+func (c *localComputeInstance) DetachVolume(volume Volume) error {
+	return fmt.Errorf("volumes cannot be detached from local instance '%s'", c.name)
+}
+
+// Local instances are never interrupted
+// so they are always on-demand.
+func (c *localComputeInstance) Scheduling() (InstanceScheduling, error) {
+	return InstanceScheduling{
+		PurchaseModel: PurchaseOnDemand,
+	}, nil
+}
+
+func (c *localComputeInstance) InterruptionNotice() (InterruptionNotice, error) {
+	return InterruptionNotice{}, nil
+}
+
+// Local instances are not behind a cloud
+// firewall so they do not have any rules.
+func (c *localComputeInstance) FirewallRules() ([]FirewallRule, error) {
+	return []FirewallRule{}, nil
+}
+
+func (c *localComputeInstance) AddIngressRule(spec IngressRuleSpec) (FirewallRule, error) {
+	return FirewallRule{}, fmt.Errorf("firewall rules cannot be added to local instance '%s'", c.name)
+}
+
+func (c *localComputeInstance) RemoveFirewallRule(rule FirewallRule) error {
+	return fmt.Errorf("firewall rules cannot be removed from local instance '%s'", c.name)
+}
+
+func (c *localComputeInstance) AssociateStaticIP(ip StaticIP) error {
+	return fmt.Errorf("static IPs cannot be associated with local instance '%s'", c.name)
+}
+
+func (c *localComputeInstance) DisassociateStaticIP(ip StaticIP) error {
+	return fmt.Errorf("static IPs cannot be disassociated from local instance '%s'", c.name)
+}
+
+// Local instances are not bootstrapped
+// with user data so it is not available.
+func (c *localComputeInstance) UserData() (string, error) {
+	return "", fmt.Errorf("local instance '%s' does not have user data", c.name)
+}
+
+func (c *localComputeInstance) SetUserData(data string) error {
+	return fmt.Errorf("local instance '%s' does not have user data", c.name)
+}
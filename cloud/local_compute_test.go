@@ -0,0 +1,185 @@
+package cloud_test
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/gocloud/provider"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_helpers "github.com/mevansam/gocloud/test/helpers"
+)
+
+var _ = Describe("Local Process Compute Tests", func() {
+
+	var (
+		err error
+
+		localProvider provider.CloudProvider
+		localCompute  cloud.Compute
+
+		testProcesses []string
+	)
+
+	BeforeEach(func() {
+
+		localProvider, err = provider.NewCloudProvider("local")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(localProvider).ToNot(BeNil())
+
+		test_helpers.InitializeLocalProvider(localProvider)
+
+		err = localProvider.Connect()
+		Expect(err).NotTo(HaveOccurred())
+
+		localCompute, err = localProvider.GetCompute()
+		Expect(err).NotTo(HaveOccurred())
+
+		// ensure 2 test processes are running for these tests
+		testProcesses = test_helpers.LocalDeployTestProcesses("test", 2)
+		results, err := localCompute.StartInstances(testProcesses)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(results)).To(Equal(2))
+	})
+
+	AfterEach(func() {
+		_, err = localCompute.StopInstances(testProcesses)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("Compute resources", func() {
+
+		It("retrieves a list of compute instances", func() {
+
+			instances, err := localCompute.ListInstances()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(instances)).To(Equal(len(testProcesses)))
+
+			for _, instance := range instances {
+				Expect(testProcesses).To(ContainElement(instance.ID()))
+				Expect(instance.PublicIP()).To(Equal("127.0.0.1"))
+			}
+		})
+
+		It("stops and starts a batch of compute instances", func() {
+			testBatchStopAndStart(localCompute, testProcesses, "unknown")
+		})
+
+		It("retrieves a compute instance", func() {
+
+			_, err := localCompute.GetInstance("test-X")
+			Expect(err).To(HaveOccurred())
+
+			instance, err := localCompute.GetInstance("test-0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance).ToNot(BeNil())
+		})
+	})
+
+	Context("Compute instance", func() {
+
+		var (
+			instance0 cloud.ComputeInstance
+			instance1 cloud.ComputeInstance
+		)
+
+		BeforeEach(func() {
+			instance0, err = localCompute.GetInstance("test-0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance0).ToNot(BeNil())
+
+			instance1, err = localCompute.GetInstance("test-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance1).ToNot(BeNil())
+		})
+
+		It("stops and starts a compute instance", func() {
+
+			state, err := instance0.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateRunning))
+
+			err = instance0.Stop()
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err = instance0.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateStopped))
+
+			err = instance0.Start()
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err = instance0.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateRunning))
+
+			output, err := instance0.ConsoleOutput()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(ContainSubstring("started test-0"))
+		})
+
+		It("stops and starts compute instances asynchronously", func() {
+			testAsyncStopAndStart(instance0, instance1)
+		})
+
+		It("suspends and resumes a compute instance", func() {
+			testSuspendAndResume(instance0)
+		})
+
+		It("does not signal a process that reuses the id of its process", func() {
+
+			err = instance0.Stop()
+			Expect(err).NotTo(HaveOccurred())
+
+			// a process that was not started by the
+			// instance is recorded as its process
+			cmd := exec.Command("sleep", "60")
+			err = cmd.Start()
+			Expect(err).NotTo(HaveOccurred())
+			defer func() {
+				_ = cmd.Process.Kill()
+				_ = cmd.Wait()
+			}()
+
+			pid := []byte(strconv.Itoa(cmd.Process.Pid))
+			for _, identity := range [][]byte{nil, []byte("stale")} {
+				err = os.WriteFile(test_helpers.LocalProcessInstanceFile("test-0", "pid"), pid, 0644)
+				Expect(err).NotTo(HaveOccurred())
+				if identity != nil {
+					err = os.WriteFile(test_helpers.LocalProcessInstanceFile("test-0", "identity"), identity, 0644)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				err = instance0.Suspend()
+				Expect(err).To(HaveOccurred())
+				err = instance0.Stop()
+				Expect(err).NotTo(HaveOccurred())
+
+				state, err := instance0.State()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state).To(Equal(cloud.StateStopped))
+				Expect(cmd.Process.Signal(syscall.Signal(0))).To(Succeed())
+			}
+
+			err = instance0.Start()
+			Expect(err).NotTo(HaveOccurred())
+			state, err := instance0.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateRunning))
+		})
+
+		It("does not resize a compute instance", func() {
+			err = instance1.Resize("small", true)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("retrieves the scheduling of a compute instance", func() {
+			testOnDemandScheduling(instance0)
+		})
+	})
+})
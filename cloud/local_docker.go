@@ -0,0 +1,715 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mevansam/goutils/logger"
+	"github.com/mevansam/goutils/network"
+)
+
+// seconds the docker daemon waits for a container
+// to stop before it is killed
+const dockerStopTimeout = 10
+
+// time after which a request to the docker engine
+// api is abandoned if the daemon does not respond.
+// it exceeds the stop timeout as stop requests are
+// only answered once the container has stopped.
+const dockerRequestTimeout = time.Minute
+
+// client of the docker engine api
+type dockerClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+// error returned by the docker engine api
+type dockerError struct {
+	StatusCode int
+	Message    string `json:"message"`
+}
+
+// the details of a container returned
+// by the docker engine inspect api
+type dockerContainer struct {
+	ID   string `json:"Id"`
+	Name string
+
+	State struct {
+		Status string
+		Paused bool
+	}
+	Config struct {
+		Tty    bool
+		Labels map[string]string
+	}
+	NetworkSettings struct {
+		IPAddress string
+		Networks  map[string]struct {
+			IPAddress string
+		}
+		// host addresses the container's ports are
+		// published on keyed by "<port>/<protocol>"
+		Ports map[string][]struct {
+			HostIP   string `json:"HostIp"`
+			HostPort string
+		}
+	}
+}
+
+type localDockerCompute struct {
+	localCompute
+
+	client *dockerClient
+}
+
+type localDockerInstance struct {
+	localComputeInstance
+
+	client    *dockerClient
+	container *dockerContainer
+
+	// guards the container detail which is
+	// refreshed by operations running
+	// asynchronously
+	mx sync.RWMutex
+}
+
+// in: address of the docker engine api which is
+// either a unix socket (i.e. unix:///var/run/docker.sock)
+// or a tcp address (i.e. tcp://localhost:2375)
+func newDockerClient(host string) (*dockerClient, error) {
+
+	var (
+		err error
+
+		hostURL *url.URL
+	)
+
+	if hostURL, err = url.Parse(host); err != nil {
+		return nil, err
+	}
+	switch hostURL.Scheme {
+	case "unix":
+		socket := hostURL.Path
+		return &dockerClient{
+			client: &http.Client{
+				Timeout: dockerRequestTimeout,
+				Transport: &http.Transport{
+					DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+						var dialer net.Dialer
+						return dialer.DialContext(ctx, "unix", socket)
+					},
+				},
+			},
+			// the host is ignored when
+			// dialing the unix socket
+			baseURL: "http://docker",
+		}, nil
+	case "tcp", "http":
+		return &dockerClient{
+			client: &http.Client{
+				Timeout: dockerRequestTimeout,
+			},
+			baseURL: "http://" + hostURL.Host,
+		}, nil
+	default:
+		return nil, fmt.Errorf("docker host '%s' is not a unix socket or tcp address", host)
+	}
+}
+
+// sends a request to the docker engine api and returns
+// the response if its status is not an error status
+func (d *dockerClient) do(
+	ctx context.Context,
+	method, path string,
+	query url.Values,
+	body interface{},
+) (*http.Response, error) {
+
+	var (
+		err error
+
+		data     []byte
+		request  *http.Request
+		response *http.Response
+	)
+
+	reqURL := d.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	if body != nil {
+		if data, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+	if request, err = http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if response, err = d.client.Do(request); err != nil {
+		return nil, err
+	}
+	if response.StatusCode >= 400 {
+		defer response.Body.Close()
+
+		dockerErr := &dockerError{StatusCode: response.StatusCode}
+		_ = json.NewDecoder(response.Body).Decode(dockerErr)
+		return nil, dockerErr
+	}
+	return response, nil
+}
+
+// sends a request to the docker engine api and decodes
+// the response into the given result if it is not nil
+func (d *dockerClient) request(
+	ctx context.Context,
+	method, path string,
+	query url.Values,
+	body, result interface{},
+) error {
+
+	response, err := d.do(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	// a status of not modified is returned if a
+	// container is already in the requested state
+	if result == nil || response.StatusCode == http.StatusNoContent ||
+		response.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// returns the details of the container with the given name or id
+func (d *dockerClient) inspect(ctx context.Context, nameOrID string) (*dockerContainer, error) {
+
+	container := &dockerContainer{}
+	if err := d.request(ctx, http.MethodGet, "/containers/"+url.PathEscape(nameOrID)+"/json", nil, nil, container); err != nil {
+		return nil, err
+	}
+	return container, nil
+}
+
+func (e *dockerError) Error() string {
+	return fmt.Sprintf("docker engine api error (%d): %s", e.StatusCode, e.Message)
+}
+
+// returns whether the given error
+// is a not found error
+func isDockerNotFound(err error) bool {
+	var dockerErr *dockerError
+	return errors.As(err, &dockerErr) && dockerErr.StatusCode == http.StatusNotFound
+}
+
+// in: address of the docker engine api
+func NewLocalDockerCompute(host string) (Compute, error) {
+
+	var (
+		err error
+
+		client *dockerClient
+	)
+
+	if client, err = newDockerClient(host); err != nil {
+		return nil, err
+	}
+	if err = client.request(context.Background(), http.MethodGet, "/_ping", nil, nil, nil); err != nil {
+		return nil, fmt.Errorf("unable to reach the docker engine at '%s': %s", host, err.Error())
+	}
+
+	return &localDockerCompute{
+		localCompute: localCompute{
+			props: LocalComputeProperties{
+				OpTimeout:      defaultOpTimeout,
+				MaxConcurrency: defaultMaxConcurrency,
+			},
+		},
+		client: client,
+	}, nil
+}
+
+func (c *localDockerCompute) newLocalDockerInstance(container *dockerContainer) *localDockerInstance {
+
+	return &localDockerInstance{
+		localComputeInstance: localComputeInstance{
+			name: strings.TrimPrefix(container.Name, "/"),
+
+			props: &c.props,
+		},
+		client:    c.client,
+		container: container,
+	}
+}
+
+// returns whether the container has
+// the filter labels if they have been set
+func (c *localDockerCompute) hasFilterLabels(container *dockerContainer) bool {
+
+	for k, v := range c.props.FilterLabels {
+		if container.Config.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// interface: cloud/Compute implementation
+
+func (c *localDockerCompute) GetInstance(name string) (ComputeInstance, error) {
+
+	container, err := c.client.inspect(context.Background(), name)
+	if err != nil {
+		if isDockerNotFound(err) {
+			return nil, fmt.Errorf("container '%s' was not found", name)
+		}
+		return nil, err
+	}
+	if !c.hasFilterLabels(container) {
+		return nil, fmt.Errorf("container '%s' was not found", name)
+	}
+	return c.newLocalDockerInstance(container), nil
+}
+
+func (c *localDockerCompute) GetInstances(ids []string) ([]ComputeInstance, error) {
+
+	var (
+		err error
+
+		container *dockerContainer
+	)
+
+	instances := make([]ComputeInstance, 0, len(ids))
+	for _, id := range ids {
+		if container, err = c.client.inspect(context.Background(), id); err != nil {
+			if isDockerNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		// containers without the filter labels
+		// are not returned as they are not listed
+		if !c.hasFilterLabels(container) {
+			continue
+		}
+		instances = append(instances, c.newLocalDockerInstance(container))
+	}
+	return instances, nil
+}
+
+func (c *localDockerCompute) ListInstances() ([]ComputeInstance, error) {
+
+	var (
+		err error
+
+		filters []byte
+		list    []struct {
+			ID string `json:"Id"`
+		}
+		container *dockerContainer
+	)
+
+	query := url.Values{
+		"all": []string{"true"},
+	}
+	if len(c.props.FilterLabels) > 0 {
+		labels := []string{}
+		for k, v := range c.props.FilterLabels {
+			labels = append(labels, fmt.Sprintf("%s=%s", k, v))
+		}
+		if filters, err = json.Marshal(map[string][]string{"label": labels}); err != nil {
+			return nil, err
+		}
+		query.Set("filters", string(filters))
+	}
+	if err = c.client.request(context.Background(), http.MethodGet, "/containers/json", query, nil, &list); err != nil {
+		return nil, err
+	}
+
+	instances := make([]ComputeInstance, 0, len(list))
+	for _, l := range list {
+		if container, err = c.client.inspect(context.Background(), l.ID); err != nil {
+			// the container may have
+			// been removed since listed
+			if isDockerNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		instances = append(instances, c.newLocalDockerInstance(container))
+	}
+	return instances, nil
+}
+
+func (c *localDockerCompute) ListSizes() ([]InstanceSize, error) {
+	sizes := append([]InstanceSize{}, localInstanceSizes...)
+	sortInstanceSizes(sizes)
+	return sizes, nil
+}
+
+func (c *localDockerCompute) StartInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Start()
+	})
+}
+
+func (c *localDockerCompute) StopInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Stop()
+	})
+}
+
+func (c *localDockerCompute) RestartInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Restart()
+	})
+}
+
+// returns the container's details
+func (c *localDockerInstance) detail() *dockerContainer {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+	return c.container
+}
+
+// sets the container's details
+func (c *localDockerInstance) setDetail(container *dockerContainer) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.container = container
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *localDockerInstance) ID() string {
+	return c.detail().ID
+}
+
+// Containers are reachable from the host via the ports
+// they publish on the loopback address. A container's own
+// network address is only reachable from a linux host as
+// docker desktop runs containers in a VM. Containers that
+// share the host's network are reached via the loopback
+// address.
+func (c *localDockerInstance) PublicIP() string {
+	if len(c.detail().NetworkSettings.Ports) > 0 {
+		return localHostIP
+	}
+	ip := c.PrivateIP()
+	if ip == "" {
+		return localHostIP
+	}
+	if runtime.GOOS == "linux" {
+		return ip
+	}
+	return ""
+}
+
+// returns the host and port the given tcp port of
+// the container can be reached at from the host or
+// an empty host if the port is not reachable
+func (c *localDockerInstance) publicAddress(port int) (string, int) {
+
+	for _, binding := range c.detail().NetworkSettings.Ports[fmt.Sprintf("%d/tcp", port)] {
+		// ports published on ipv6 or other host
+		// addresses are not reachable via loopback
+		switch binding.HostIP {
+		case "", "0.0.0.0", localHostIP:
+		default:
+			continue
+		}
+		if hostPort, err := strconv.Atoi(binding.HostPort); err == nil {
+			return localHostIP, hostPort
+		}
+	}
+	ip := c.PrivateIP()
+	if ip == "" {
+		return localHostIP, port
+	}
+	if runtime.GOOS == "linux" {
+		return ip, port
+	}
+	return "", port
+}
+
+// Returns the container's address on the default
+// bridge network or its address on the first of
+// the networks it is connected to by name.
+func (c *localDockerInstance) PrivateIP() string {
+
+	settings := c.detail().NetworkSettings
+	if settings.IPAddress != "" {
+		return settings.IPAddress
+	}
+	networks := make([]string, 0, len(settings.Networks))
+	for name := range settings.Networks {
+		networks = append(networks, name)
+	}
+	sort.Strings(networks)
+	for _, name := range networks {
+		if ip := settings.Networks[name].IPAddress; ip != "" {
+			return ip
+		}
+	}
+	return ""
+}
+
+// refreshes the container's details
+func (c *localDockerInstance) refresh() error {
+
+	container, err := c.client.inspect(context.Background(), c.detail().ID)
+	if err != nil {
+		return err
+	}
+	c.setDetail(container)
+	return nil
+}
+
+// returns an operation that sends the given request
+// for the container and waits for it to reach the
+// given state. the request is sent asynchronously as
+// stopping a container blocks until it has stopped.
+func (c *localDockerInstance) requestOperation(action string, query url.Values, state InstanceState) Operation {
+
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		if err := c.client.request(
+			ctx,
+			http.MethodPost,
+			"/containers/"+c.detail().ID+"/"+action,
+			query, nil, nil,
+		); err != nil {
+			return err
+		}
+		return waitForState(ctx, c, state, c.props.OpTimeout)
+	})
+}
+
+func (c *localDockerInstance) State() (InstanceState, error) {
+
+	var (
+		err error
+	)
+
+	if err = c.refresh(); err != nil {
+		return StateUnknown, err
+	}
+
+	container := c.detail()
+	logger.TraceMessage("Status for container '%s' is: %s",
+		c.name, container.State.Status)
+
+	if container.State.Paused {
+		return StateSuspended, nil
+	}
+	switch container.State.Status {
+	case "running":
+		return StateRunning, nil
+	case "created", "exited", "dead":
+		return StateStopped, nil
+	case "restarting", "removing":
+		return StatePending, nil
+	default:
+		return StateUnknown, nil
+	}
+}
+
+func (c *localDockerInstance) Start() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.StartAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *localDockerInstance) Restart() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.RestartAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *localDockerInstance) Stop() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.StopAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *localDockerInstance) StartAsync() (Operation, error) {
+
+	logger.TraceMessage("Starting container '%s'.", c.name)
+
+	return c.requestOperation("start", nil, StateRunning), nil
+}
+
+func (c *localDockerInstance) RestartAsync() (Operation, error) {
+
+	logger.TraceMessage("Restarting container '%s'.", c.name)
+
+	return c.requestOperation("restart", url.Values{
+		"t": []string{fmt.Sprint(dockerStopTimeout)},
+	}, StateRunning), nil
+}
+
+func (c *localDockerInstance) StopAsync() (Operation, error) {
+
+	logger.TraceMessage("Stopping container '%s'.", c.name)
+
+	return c.requestOperation("stop", url.Values{
+		"t": []string{fmt.Sprint(dockerStopTimeout)},
+	}, StateStopped), nil
+}
+
+// Containers are suspended by pausing
+// all of the processes within them.
+func (c *localDockerInstance) Capabilities() (InstanceCapabilities, error) {
+	return InstanceCapabilities{
+		SuspendResume: true,
+	}, nil
+}
+
+func (c *localDockerInstance) Suspend() error {
+
+	logger.TraceMessage("Pausing container '%s'.", c.name)
+
+	return c.requestOperation("pause", nil, StateSuspended).Wait(context.Background())
+}
+
+func (c *localDockerInstance) Resume() error {
+
+	logger.TraceMessage("Unpausing container '%s'.", c.name)
+
+	return c.requestOperation("unpause", nil, StateRunning).Wait(context.Background())
+}
+
+// A container is resized by updating its cpu
+// and memory limits which does not require it
+// to be stopped.
+func (c *localDockerInstance) Resize(size string, autoRestart bool) error {
+
+	return resizeInstance(c, autoRestart, false, func() error {
+
+		for _, s := range localInstanceSizes {
+			if s.Name == size {
+
+				logger.TraceMessage("Resizing container '%s' to '%s'.", c.name, size)
+
+				return c.client.request(context.Background(), http.MethodPost, "/containers/"+c.detail().ID+"/update", nil,
+					map[string]int64{
+						"NanoCpus":   int64(s.VCPUs) * 1e9,
+						"Memory":     int64(s.MemoryMB) * 1024 * 1024,
+						"MemorySwap": -1,
+					}, nil,
+				)
+			}
+		}
+		return fmt.Errorf("'%s' is not a valid local instance size", size)
+	})
+}
+
+// Returns the container's logs. The stdout and stderr
+// streams of containers without a tty are multiplexed
+// so each frame's header is removed.
+func (c *localDockerInstance) ConsoleOutput() (string, error) {
+
+	var (
+		err error
+
+		response *http.Response
+		output   strings.Builder
+	)
+
+	container := c.detail()
+	if response, err = c.client.do(context.Background(), http.MethodGet, "/containers/"+container.ID+"/logs", url.Values{
+		"stdout": []string{"true"},
+		"stderr": []string{"true"},
+	}, nil); err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if container.Config.Tty {
+		_, err = io.Copy(&output, response.Body)
+		return output.String(), err
+	}
+
+	header := make([]byte, 8)
+	for {
+		if _, err = io.ReadFull(response.Body, header); err != nil {
+			if err == io.EOF {
+				return output.String(), nil
+			}
+			return output.String(), err
+		}
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		if _, err = io.CopyN(&output, response.Body, size); err != nil {
+			return output.String(), err
+		}
+	}
+}
+
+func (c *localDockerInstance) WaitForState(
+	ctx context.Context,
+	state InstanceState,
+	timeout time.Duration,
+) error {
+
+	if timeout == 0 {
+		timeout = c.props.OpTimeout
+	}
+	return waitForState(ctx, c, state, timeout)
+}
+
+func (c *localDockerInstance) CanConnect(port int) bool {
+	host, port := c.publicAddress(port)
+	return host != "" && network.CanConnect(host, port)
+}
+
+func (c *localDockerInstance) HealthCheck(ctx context.Context, check HealthCheck) HealthCheckResult {
+	return CheckHealth(ctx, c, check)
+}
+
+func (c *localDockerInstance) WaitUntilHealthy(ctx context.Context, checks ...HealthCheck) error {
+	return WaitUntilHealthy(ctx, c, checks...)
+}
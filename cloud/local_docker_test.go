@@ -0,0 +1,417 @@
+package cloud_test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/gocloud/provider"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_helpers "github.com/mevansam/gocloud/test/helpers"
+)
+
+// container kept by the fake docker engine
+type fakeContainer struct {
+	ID   string `json:"Id"`
+	Name string
+
+	State struct {
+		Status string
+		Paused bool
+	}
+	Config struct {
+		Tty    bool
+		Labels map[string]string
+	}
+	NetworkSettings struct {
+		IPAddress string
+		Ports     map[string][]map[string]string
+	}
+	HostConfig struct {
+		NanoCpus int64
+		Memory   int64
+	}
+}
+
+// fake docker engine api serving the
+// requests sent for local docker instances
+type fakeDockerEngine struct {
+	mx sync.Mutex
+
+	containers map[string]*fakeContainer
+	logs       string
+
+	// stop requests are not answered
+	// until the client abandons them
+	hangOnStop bool
+}
+
+func newFakeDockerEngine() *fakeDockerEngine {
+
+	engine := &fakeDockerEngine{
+		containers: make(map[string]*fakeContainer),
+		logs:       "started test-0\n",
+	}
+	for i, name := range []string{"test-0", "test-1", "other"} {
+		c := &fakeContainer{
+			ID:   strings.Repeat(string(rune('a'+i)), 12),
+			Name: "/" + name,
+		}
+		c.State.Status = "running"
+		c.NetworkSettings.IPAddress = "172.17.0." + string(rune('2'+i))
+		if name != "other" {
+			c.Config.Labels = map[string]string{"cloudbuilder-test": "true"}
+		}
+		engine.containers[c.ID] = c
+	}
+	return engine
+}
+
+// returns the container with the given name or id
+func (e *fakeDockerEngine) container(nameOrID string) *fakeContainer {
+
+	for _, c := range e.containers {
+		if c.ID == nameOrID || c.Name == "/"+nameOrID {
+			return c
+		}
+	}
+	return nil
+}
+
+func (e *fakeDockerEngine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if r.URL.Path == "/_ping" {
+		_, _ = w.Write([]byte("OK"))
+		return
+	}
+	if r.URL.Path == "/containers/json" {
+		e.list(w, r)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/containers/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	e.mx.Lock()
+	if parts[1] == "stop" && e.hangOnStop {
+		e.mx.Unlock()
+		<-r.Context().Done()
+		return
+	}
+	defer e.mx.Unlock()
+
+	c := e.container(parts[0])
+	if c == nil {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"message": "No such container: " + parts[0],
+		})
+		return
+	}
+
+	switch parts[1] {
+	case "json":
+		_ = json.NewEncoder(w).Encode(c)
+		return
+	case "start", "restart", "unpause":
+		c.State.Status = "running"
+		c.State.Paused = false
+	case "stop":
+		c.State.Status = "exited"
+		c.State.Paused = false
+	case "pause":
+		c.State.Paused = true
+	case "update":
+		_ = json.NewDecoder(r.Body).Decode(&c.HostConfig)
+		_ = json.NewEncoder(w).Encode(map[string][]string{"Warnings": {}})
+		return
+	case "logs":
+		// the stdout stream of a container without
+		// a tty is multiplexed in framed chunks
+		for _, line := range strings.SplitAfter(e.logs, "\n") {
+			header := make([]byte, 8)
+			header[0] = 1
+			binary.BigEndian.PutUint32(header[4:], uint32(len(line)))
+			_, _ = w.Write(append(header, line...))
+		}
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (e *fakeDockerEngine) list(w http.ResponseWriter, r *http.Request) {
+
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	labels := []string{}
+	if filters := r.URL.Query().Get("filters"); len(filters) > 0 {
+		f := map[string][]string{}
+		_ = json.Unmarshal([]byte(filters), &f)
+		labels = f["label"]
+	}
+
+	list := []map[string]string{}
+	for _, c := range e.containers {
+		matches := true
+		for _, label := range labels {
+			kv := strings.SplitN(label, "=", 2)
+			if c.Config.Labels[kv[0]] != kv[1] {
+				matches = false
+			}
+		}
+		if matches {
+			list = append(list, map[string]string{"Id": c.ID})
+		}
+	}
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+var _ = Describe("Local Docker Compute Tests", func() {
+
+	var (
+		err error
+
+		socketDir string
+		engine    *fakeDockerEngine
+		server    *httptest.Server
+
+		localProvider provider.CloudProvider
+		localCompute  cloud.Compute
+	)
+
+	BeforeEach(func() {
+
+		socketDir, err = os.MkdirTemp("", "docker")
+		Expect(err).NotTo(HaveOccurred())
+		socket := filepath.Join(socketDir, "docker.sock")
+
+		engine = newFakeDockerEngine()
+		server = httptest.NewUnstartedServer(engine)
+		server.Listener, err = net.Listen("unix", socket)
+		Expect(err).NotTo(HaveOccurred())
+		server.Start()
+
+		localProvider, err = provider.NewCloudProvider("local")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(localProvider).ToNot(BeNil())
+
+		test_helpers.InitializeLocalDockerProvider(localProvider, "unix://"+socket)
+
+		err = localProvider.Connect()
+		Expect(err).NotTo(HaveOccurred())
+
+		localCompute, err = localProvider.GetCompute()
+		Expect(err).NotTo(HaveOccurred())
+
+		localCompute.SetProperties(cloud.LocalComputeProperties{
+			FilterLabels: map[string]string{
+				"cloudbuilder-test": "true",
+			},
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(socketDir)
+	})
+
+	Context("Compute resources", func() {
+
+		It("retrieves a list of compute instances", func() {
+
+			instances, err := localCompute.ListInstances()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(instances)).To(Equal(2))
+
+			for _, instance := range instances {
+				Expect(instance.Name()).To(BeElementOf("test-0", "test-1"))
+				Expect(instance.PrivateIP()).To(HavePrefix("172.17.0."))
+			}
+		})
+
+		It("retrieves a list of compute instances by their ids", func() {
+
+			instances, err := localCompute.GetInstances([]string{
+				"aaaaaaaaaaaa", "bbbbbbbbbbbb", "cccccccccccc", "unknown",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(instances)).To(Equal(2))
+			Expect(instances[0].Name()).To(Equal("test-0"))
+			Expect(instances[1].Name()).To(Equal("test-1"))
+		})
+
+		It("stops and starts a batch of compute instances", func() {
+			testBatchStopAndStart(localCompute, []string{"aaaaaaaaaaaa", "bbbbbbbbbbbb"}, "unknown")
+		})
+
+		It("retrieves a compute instance", func() {
+
+			_, err := localCompute.GetInstance("test-X")
+			Expect(err).To(HaveOccurred())
+
+			// containers without the filter
+			// labels are not instances
+			_, err = localCompute.GetInstance("other")
+			Expect(err).To(HaveOccurred())
+
+			instance, err := localCompute.GetInstance("test-0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance).ToNot(BeNil())
+			Expect(instance.ID()).To(Equal("aaaaaaaaaaaa"))
+		})
+	})
+
+	Context("Compute instance", func() {
+
+		var (
+			instance0 cloud.ComputeInstance
+			instance1 cloud.ComputeInstance
+		)
+
+		BeforeEach(func() {
+			instance0, err = localCompute.GetInstance("test-0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance0).ToNot(BeNil())
+
+			instance1, err = localCompute.GetInstance("test-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance1).ToNot(BeNil())
+		})
+
+		It("stops and starts a compute instance", func() {
+
+			state, err := instance0.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateRunning))
+
+			err = instance0.Stop()
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err = instance0.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateStopped))
+
+			err = instance0.Start()
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err = instance0.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateRunning))
+
+			output, err := instance0.ConsoleOutput()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal("started test-0\n"))
+		})
+
+		It("restarts a compute instance", func() {
+
+			err = instance1.Restart()
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err := instance1.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateRunning))
+		})
+
+		It("stops and starts compute instances asynchronously", func() {
+			testAsyncStopAndStart(instance0, instance1)
+		})
+
+		It("suspends and resumes a compute instance", func() {
+			testSuspendAndResume(instance0)
+		})
+
+		It("resizes a compute instance", func() {
+
+			err = instance1.Resize("small", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			engine.mx.Lock()
+			hostConfig := engine.container("test-1").HostConfig
+			engine.mx.Unlock()
+			Expect(hostConfig.NanoCpus).To(Equal(int64(1e9)))
+			Expect(hostConfig.Memory).To(Equal(int64(1024 * 1024 * 1024)))
+
+			err = instance1.Resize("unknown", true)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("abandons a request the docker engine does not answer", func() {
+
+			localCompute.SetProperties(cloud.LocalComputeProperties{
+				OpTimeout: time.Second,
+			})
+			engine.mx.Lock()
+			engine.hangOnStop = true
+			engine.mx.Unlock()
+
+			start := time.Now()
+			err = instance0.Stop()
+			Expect(err).To(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically("<", 10*time.Second))
+		})
+
+		It("retrieves the scheduling of a compute instance", func() {
+			testOnDemandScheduling(instance0)
+		})
+
+		It("connects to the published ports of a compute instance via the loopback address", func() {
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+			hostPort := listener.Addr().(*net.TCPAddr).Port
+
+			engine.mx.Lock()
+			engine.container("test-0").NetworkSettings.Ports = map[string][]map[string]string{
+				"22/tcp": {
+					{"HostIp": "::", "HostPort": "1"},
+					{"HostIp": "0.0.0.0", "HostPort": strconv.Itoa(hostPort)},
+				},
+			}
+			engine.mx.Unlock()
+
+			instance, err := localCompute.GetInstance("test-0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance.PublicIP()).To(Equal("127.0.0.1"))
+			Expect(instance.PrivateIP()).To(HavePrefix("172.17.0."))
+			Expect(instance.CanConnect(22)).To(BeTrue())
+
+			result := instance.HealthCheck(context.Background(), cloud.HealthCheck{
+				Type:   cloud.HealthCheckTCP,
+				Port:   22,
+				Target: cloud.TargetPublic,
+			})
+			Expect(result.Err).NotTo(HaveOccurred())
+			Expect(result.Address).To(Equal(net.JoinHostPort("127.0.0.1", strconv.Itoa(hostPort))))
+
+			// containers without published ports are only
+			// reachable via their own address on linux
+			if runtime.GOOS == "linux" {
+				Expect(instance1.PublicIP()).To(Equal(instance1.PrivateIP()))
+			} else {
+				Expect(instance1.PublicIP()).To(BeEmpty())
+			}
+		})
+	})
+})
@@ -0,0 +1,570 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mevansam/goutils/logger"
+	"github.com/mevansam/goutils/network"
+)
+
+// time a process is given to exit once
+// terminated before it is killed
+const localProcessStopTimeout = 10 * time.Second
+
+// files kept in the directory of a process instance
+const (
+	localProcessSpecFile      = "spec.json"
+	localProcessPIDFile       = "pid"
+	localProcessIdentityFile  = "identity"
+	localProcessSuspendedFile = "suspended"
+	localProcessConsoleFile   = "console.log"
+)
+
+// signals sent to local processes
+type processSignal int
+
+const (
+	signalTerminate = processSignal(0)
+	signalKill      = processSignal(1)
+	signalStop      = processSignal(2)
+	signalContinue  = processSignal(3)
+)
+
+// specification of the
+// process of an instance
+type LocalProcessSpec struct {
+	// the command and its arguments
+	Command []string `json:"command"`
+
+	// the working directory of the process. it
+	// defaults to the instance's directory.
+	Dir string `json:"dir,omitempty"`
+
+	// additional environment variables
+	// of the form "key=value"
+	Env []string `json:"env,omitempty"`
+}
+
+type localProcessCompute struct {
+	localCompute
+
+	root string
+}
+
+type localProcessInstance struct {
+	localComputeInstance
+
+	dir  string
+	spec LocalProcessSpec
+}
+
+// Saves the spec of the process instance with the given
+// name to its directory within the given root directory
+// which is created if it does not exist. The process is
+// started the next time the instance is started.
+func SaveLocalProcessSpec(root, name string, spec LocalProcessSpec) error {
+
+	var (
+		err error
+
+		data []byte
+	)
+
+	if len(name) == 0 || name != filepath.Base(name) || name == ".." {
+		return fmt.Errorf("'%s' is not a valid process instance name", name)
+	}
+	if len(spec.Command) == 0 {
+		return fmt.Errorf("the spec of process instance '%s' does not have a command", name)
+	}
+	dir := filepath.Join(root, name)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if data, err = json.MarshalIndent(spec, "", "  "); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, localProcessSpecFile), data, 0644)
+}
+
+// in: the directory in which each process instance
+// is kept as a sub-directory containing its spec
+func NewLocalProcessCompute(root string) (Compute, error) {
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &localProcessCompute{
+		localCompute: localCompute{
+			props: LocalComputeProperties{
+				OpTimeout:      defaultOpTimeout,
+				MaxConcurrency: defaultMaxConcurrency,
+			},
+		},
+		root: root,
+	}, nil
+}
+
+// returns the process instance with the given name
+// or nil if an instance with that name does not exist
+func (c *localProcessCompute) loadInstance(name string) (*localProcessInstance, error) {
+
+	var (
+		err error
+
+		data []byte
+		spec LocalProcessSpec
+	)
+
+	if len(name) == 0 || name != filepath.Base(name) || name == ".." {
+		return nil, nil
+	}
+	dir := filepath.Join(c.root, name)
+	if data, err = os.ReadFile(filepath.Join(dir, localProcessSpecFile)); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("the spec of process instance '%s' is not valid: %s", name, err.Error())
+	}
+
+	return &localProcessInstance{
+		localComputeInstance: localComputeInstance{
+			name: name,
+
+			props: &c.props,
+		},
+		dir:  dir,
+		spec: spec,
+	}, nil
+}
+
+// interface: cloud/Compute implementation
+
+func (c *localProcessCompute) GetInstance(name string) (ComputeInstance, error) {
+
+	instance, err := c.loadInstance(name)
+	if err != nil {
+		return nil, err
+	}
+	if instance == nil {
+		return nil, fmt.Errorf("process instance '%s' was not found", name)
+	}
+	return instance, nil
+}
+
+// The ids of process instances are their names.
+func (c *localProcessCompute) GetInstances(ids []string) ([]ComputeInstance, error) {
+
+	var (
+		err error
+
+		instance *localProcessInstance
+	)
+
+	instances := make([]ComputeInstance, 0, len(ids))
+	for _, id := range ids {
+		if instance, err = c.loadInstance(id); err != nil {
+			return nil, err
+		}
+		if instance != nil {
+			instances = append(instances, instance)
+		}
+	}
+	return instances, nil
+}
+
+func (c *localProcessCompute) ListInstances() ([]ComputeInstance, error) {
+
+	var (
+		err error
+
+		entries  []fs.DirEntry
+		instance *localProcessInstance
+	)
+
+	if entries, err = os.ReadDir(c.root); err != nil {
+		return nil, err
+	}
+	instances := []ComputeInstance{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if instance, err = c.loadInstance(entry.Name()); err != nil {
+			return nil, err
+		}
+		if instance != nil {
+			instances = append(instances, instance)
+		}
+	}
+	return instances, nil
+}
+
+// Processes are not limited to
+// a size so there are no sizes.
+func (c *localProcessCompute) ListSizes() ([]InstanceSize, error) {
+	return []InstanceSize{}, nil
+}
+
+func (c *localProcessCompute) StartInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Start()
+	})
+}
+
+func (c *localProcessCompute) StopInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Stop()
+	})
+}
+
+func (c *localProcessCompute) RestartInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Restart()
+	})
+}
+
+// returns the id of the instance's process if it is
+// running. the process with the recorded id is only
+// taken to be the instance's process if its identity
+// matches the identity recorded when it was started
+// as the id may have been reused since it exited. the
+// files recording the state of a process that has
+// exited are removed.
+func (c *localProcessInstance) pid() (int, bool) {
+
+	data, err := os.ReadFile(filepath.Join(c.dir, localProcessPIDFile))
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err == nil && processAlive(pid) {
+		identity, _ := os.ReadFile(filepath.Join(c.dir, localProcessIdentityFile))
+		if current, err := processIdentity(pid); err == nil && current == string(identity) {
+			return pid, true
+		}
+		logger.DebugMessage(
+			"Process %d is not the process of instance '%s' as its identity does not match.",
+			pid, c.name,
+		)
+	}
+	_ = os.Remove(filepath.Join(c.dir, localProcessPIDFile))
+	_ = os.Remove(filepath.Join(c.dir, localProcessIdentityFile))
+	_ = os.Remove(filepath.Join(c.dir, localProcessSuspendedFile))
+	return 0, false
+}
+
+// starts the instance's process with its output
+// appended to the instance's console log
+func (c *localProcessInstance) startProcess() error {
+
+	var (
+		err error
+
+		console *os.File
+	)
+
+	if console, err = os.OpenFile(
+		filepath.Join(c.dir, localProcessConsoleFile),
+		os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644,
+	); err != nil {
+		return err
+	}
+
+	cmd := exec.Command(c.spec.Command[0], c.spec.Command[1:]...)
+	cmd.Dir = c.spec.Dir
+	if len(cmd.Dir) == 0 {
+		cmd.Dir = c.dir
+	}
+	cmd.Env = append(os.Environ(), c.spec.Env...)
+	cmd.Stdout = console
+	cmd.Stderr = console
+	detachProcess(cmd)
+
+	if err = cmd.Start(); err != nil {
+		console.Close()
+		return err
+	}
+	// the identity of the process is recorded along
+	// with its id so that a process that reuses the
+	// id once it has exited is not taken to be it
+	identity, identityErr := processIdentity(cmd.Process.Pid)
+
+	// the process is waited on so that it does not
+	// remain as a zombie if it exits before the
+	// process that started it
+	go func() {
+		_ = cmd.Wait()
+		console.Close()
+	}()
+
+	if identityErr != nil {
+		_ = signalProcessGroup(cmd.Process.Pid, signalKill)
+		return fmt.Errorf(
+			"the identity of the process of instance '%s' could not be determined: %s",
+			c.name, identityErr.Error(),
+		)
+	}
+	if err = os.WriteFile(
+		filepath.Join(c.dir, localProcessIdentityFile),
+		[]byte(identity),
+		0644,
+	); err != nil {
+		return err
+	}
+	return os.WriteFile(
+		filepath.Join(c.dir, localProcessPIDFile),
+		[]byte(strconv.Itoa(cmd.Process.Pid)),
+		0644,
+	)
+}
+
+// returns an operation that waits for
+// the process to reach the given state
+func (c *localProcessInstance) stateOperation(state InstanceState) Operation {
+
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return waitForState(ctx, c, state, c.props.OpTimeout)
+	})
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *localProcessInstance) ID() string {
+	return c.name
+}
+
+// Processes are reached via the loopback address.
+func (c *localProcessInstance) PublicIP() string {
+	return localHostIP
+}
+
+func (c *localProcessInstance) PrivateIP() string {
+	return localHostIP
+}
+
+func (c *localProcessInstance) State() (InstanceState, error) {
+
+	if _, running := c.pid(); !running {
+		return StateStopped, nil
+	}
+	if _, err := os.Stat(filepath.Join(c.dir, localProcessSuspendedFile)); err == nil {
+		return StateSuspended, nil
+	}
+	return StateRunning, nil
+}
+
+func (c *localProcessInstance) Start() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.StartAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *localProcessInstance) Restart() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.RestartAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *localProcessInstance) Stop() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.StopAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+// A suspended process is resumed
+// when the instance is started.
+func (c *localProcessInstance) StartAsync() (Operation, error) {
+
+	if _, running := c.pid(); running {
+		if state, _ := c.State(); state == StateSuspended {
+			if err := c.Resume(); err != nil {
+				return nil, err
+			}
+		}
+		return c.stateOperation(StateRunning), nil
+	}
+
+	logger.TraceMessage("Starting process of instance '%s'.", c.name)
+
+	if err := c.startProcess(); err != nil {
+		return nil, err
+	}
+	return c.stateOperation(StateRunning), nil
+}
+
+func (c *localProcessInstance) RestartAsync() (Operation, error) {
+
+	logger.TraceMessage("Restarting process of instance '%s'.", c.name)
+
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+
+		var (
+			err error
+
+			op Operation
+		)
+
+		if op, err = c.StopAsync(); err != nil {
+			return err
+		}
+		if err = op.Wait(ctx); err != nil {
+			return err
+		}
+		if op, err = c.StartAsync(); err != nil {
+			return err
+		}
+		return op.Wait(ctx)
+	}), nil
+}
+
+// The process group of the instance is terminated and
+// killed if it does not exit within the stop timeout.
+func (c *localProcessInstance) StopAsync() (Operation, error) {
+
+	pid, running := c.pid()
+	if !running {
+		return c.stateOperation(StateStopped), nil
+	}
+
+	logger.TraceMessage("Stopping process %d of instance '%s'.", pid, c.name)
+
+	// a suspended process needs to be
+	// continued for it to be terminated
+	_ = signalProcessGroup(pid, signalContinue)
+	_ = os.Remove(filepath.Join(c.dir, localProcessSuspendedFile))
+
+	if err := signalProcessGroup(pid, signalTerminate); err != nil {
+		return nil, err
+	}
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		if err := waitForState(ctx, c, StateStopped, localProcessStopTimeout); err == nil {
+			return nil
+		}
+
+		logger.TraceMessage(
+			"Killing process %d of instance '%s' as it did not exit once terminated.",
+			pid, c.name,
+		)
+
+		if err := signalProcessGroup(pid, signalKill); err != nil {
+			return err
+		}
+		return waitForState(ctx, c, StateStopped, c.props.OpTimeout)
+	}), nil
+}
+
+func (c *localProcessInstance) Capabilities() (InstanceCapabilities, error) {
+	return InstanceCapabilities{
+		SuspendResume: processSuspendResume,
+	}, nil
+}
+
+// The process group of the instance is
+// suspended by stopping its processes.
+func (c *localProcessInstance) Suspend() error {
+
+	pid, running := c.pid()
+	if !running {
+		return fmt.Errorf("process instance '%s' is not running", c.name)
+	}
+
+	logger.TraceMessage("Suspending process %d of instance '%s'.", pid, c.name)
+
+	if err := signalProcessGroup(pid, signalStop); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.dir, localProcessSuspendedFile), []byte{}, 0644)
+}
+
+func (c *localProcessInstance) Resume() error {
+
+	pid, running := c.pid()
+	if !running {
+		return fmt.Errorf("process instance '%s' is not running", c.name)
+	}
+
+	logger.TraceMessage("Resuming process %d of instance '%s'.", pid, c.name)
+
+	if err := signalProcessGroup(pid, signalContinue); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(c.dir, localProcessSuspendedFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *localProcessInstance) Resize(size string, autoRestart bool) error {
+	return fmt.Errorf("process instance '%s' cannot be resized", c.name)
+}
+
+// Returns the output the instance's
+// processes have written to its log.
+func (c *localProcessInstance) ConsoleOutput() (string, error) {
+
+	data, err := os.ReadFile(filepath.Join(c.dir, localProcessConsoleFile))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (c *localProcessInstance) WaitForState(
+	ctx context.Context,
+	state InstanceState,
+	timeout time.Duration,
+) error {
+
+	if timeout == 0 {
+		timeout = c.props.OpTimeout
+	}
+	return waitForState(ctx, c, state, timeout)
+}
+
+func (c *localProcessInstance) CanConnect(port int) bool {
+	return network.CanConnect(c.PublicIP(), port)
+}
+
+func (c *localProcessInstance) HealthCheck(ctx context.Context, check HealthCheck) HealthCheckResult {
+	return CheckHealth(ctx, c, check)
+}
+
+func (c *localProcessInstance) WaitUntilHealthy(ctx context.Context, checks ...HealthCheck) error {
+	return WaitUntilHealthy(ctx, c, checks...)
+}
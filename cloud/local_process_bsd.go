@@ -0,0 +1,25 @@
+//go:build !linux && !windows
+
+package cloud
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Returns the time the process with the given
+// id started at which identifies the process.
+func processIdentity(pid int) (string, error) {
+
+	output, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return "", err
+	}
+	identity := strings.TrimSpace(string(output))
+	if len(identity) == 0 {
+		return "", fmt.Errorf("the start time of process %d could not be determined", pid)
+	}
+	return identity, nil
+}
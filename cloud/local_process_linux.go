@@ -0,0 +1,42 @@
+//go:build linux
+
+package cloud
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Returns the boot id of the host and the start time of
+// the process with the given id in clock ticks since the
+// host booted which together identify the process.
+func processIdentity(pid int) (string, error) {
+
+	var (
+		err error
+
+		bootID,
+		stat []byte
+	)
+
+	if bootID, err = os.ReadFile("/proc/sys/kernel/random/boot_id"); err != nil {
+		return "", err
+	}
+	if stat, err = os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat"); err != nil {
+		return "", err
+	}
+	// the command name of the process is enclosed in
+	// parentheses and may contain spaces so the fields
+	// following it start at the process' state (3)
+	i := strings.LastIndexByte(string(stat), ')')
+	if i < 0 {
+		return "", fmt.Errorf("the status of process %d could not be parsed", pid)
+	}
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) < 20 {
+		return "", fmt.Errorf("the status of process %d could not be parsed", pid)
+	}
+	return strings.TrimSpace(string(bootID)) + ":" + fields[19], nil
+}
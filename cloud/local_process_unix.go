@@ -0,0 +1,38 @@
+//go:build !windows
+
+package cloud
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// processes are suspended by
+// stopping them with SIGSTOP
+const processSuspendResume = true
+
+var processSignals = map[processSignal]syscall.Signal{
+	signalTerminate: syscall.SIGTERM,
+	signalKill:      syscall.SIGKILL,
+	signalStop:      syscall.SIGSTOP,
+	signalContinue:  syscall.SIGCONT,
+}
+
+// starts the process in its own process group so that
+// it and its children can be signalled together and it
+// is not signalled along with the process starting it
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// returns whether a process with the given id exists
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// sends the given signal to the process group
+// of the process with the given id
+func signalProcessGroup(pid int, sig processSignal) error {
+	return syscall.Kill(-pid, processSignals[sig])
+}
@@ -0,0 +1,70 @@
+//go:build windows
+
+package cloud
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// processes cannot be stopped
+// and continued on windows
+const processSuspendResume = false
+
+// processes are not started in a new process
+// group as they are only signalled to exit
+func detachProcess(cmd *exec.Cmd) {
+}
+
+// returns whether a process with the given id exists
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = process.Release()
+	return true
+}
+
+// Returns the time the process with the given
+// id was created at which identifies the process.
+func processIdentity(pid int) (string, error) {
+
+	var (
+		err error
+
+		handle syscall.Handle
+
+		creation,
+		exit,
+		kernel,
+		user syscall.Filetime
+	)
+
+	if handle, err = syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid)); err != nil {
+		return "", err
+	}
+	defer syscall.CloseHandle(handle)
+
+	if err = syscall.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(creation.Nanoseconds(), 10), nil
+}
+
+// Processes are killed when they are terminated
+// as they cannot be sent signals on windows.
+func signalProcessGroup(pid int, sig processSignal) error {
+
+	if sig == signalStop || sig == signalContinue {
+		return fmt.Errorf("processes cannot be suspended or resumed on windows")
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
@@ -0,0 +1,307 @@
+package cloud
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+type LocalStorageProperties struct {
+	// size of buffer used when
+	// downloading an object
+	BlockSize int
+}
+
+type localStorage struct {
+	root string
+
+	props LocalStorageProperties
+}
+
+type localStorageInstance struct {
+	name string
+	path string
+
+	props *LocalStorageProperties
+}
+
+// in: the directory in which each storage
+// instance is kept as a sub-directory
+func NewLocalStorage(root string) (Storage, error) {
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &localStorage{
+		root: root,
+
+		props: LocalStorageProperties{
+			BlockSize: 5 * 1024 * 1024, // 5MB
+		},
+	}, nil
+}
+
+func (s *localStorage) newLocalStorageInstance(name string) *localStorageInstance {
+
+	return &localStorageInstance{
+		name: name,
+		path: filepath.Join(s.root, name),
+
+		props: &s.props,
+	}
+}
+
+// returns the path of the file of the given object
+// ensuring that the object's name does not resolve
+// to a path outside of the storage instance
+func (s *localStorageInstance) objectPath(name string) (string, error) {
+
+	path := filepath.Join(s.path, filepath.FromSlash(name))
+	if !strings.HasPrefix(path, s.path+string(filepath.Separator)) {
+		return "", fmt.Errorf("'%s' is not a valid object name", name)
+	}
+	return path, nil
+}
+
+// interface: cloud/Storage implementation
+
+func (s *localStorage) SetProperties(props interface{}) {
+
+	p := props.(LocalStorageProperties)
+	if p.BlockSize > 0 {
+		s.props.BlockSize = p.BlockSize
+	}
+}
+
+func (s *localStorage) NewInstance(name string) (StorageInstance, error) {
+
+	if len(name) == 0 || name != filepath.Base(name) || name == ".." {
+		return nil, fmt.Errorf("'%s' is not a valid storage instance name", name)
+	}
+	instance := s.newLocalStorageInstance(name)
+
+	logger.TraceMessage(
+		"Creating directory '%s' for storage instance '%s' if it does not exist.",
+		instance.path, name)
+
+	if err := os.MkdirAll(instance.path, 0755); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+func (s *localStorage) ListInstances() ([]StorageInstance, error) {
+
+	var (
+		err error
+
+		entries []fs.DirEntry
+	)
+
+	if entries, err = os.ReadDir(s.root); err != nil {
+		return nil, err
+	}
+	instances := []StorageInstance{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			instances = append(instances, s.newLocalStorageInstance(entry.Name()))
+		}
+	}
+	return instances, nil
+}
+
+// interface: cloud/StorageInstance implementation
+
+func (s *localStorageInstance) Name() string {
+	return s.name
+}
+
+// The storage instance must not contain any
+// objects for it to be deleted. Directories
+// left empty by deleted objects are removed.
+func (s *localStorageInstance) Delete() error {
+
+	var (
+		err error
+
+		objects []string
+	)
+
+	if objects, err = s.ListObjects(""); err != nil {
+		return err
+	}
+	if len(objects) > 0 {
+		return fmt.Errorf("storage instance '%s' is not empty", s.name)
+	}
+
+	logger.TraceMessage("Deleting directory of storage instance '%s'.", s.name)
+
+	return os.RemoveAll(s.path)
+}
+
+func (s *localStorageInstance) ListObjects(path string) ([]string, error) {
+
+	var (
+		err error
+	)
+
+	objectList := []string{}
+	if err = filepath.WalkDir(s.path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+		name, err := filepath.Rel(s.path, file)
+		if err != nil {
+			return err
+		}
+		if name = filepath.ToSlash(name); strings.HasPrefix(name, path) {
+			objectList = append(objectList, name)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Strings(objectList)
+
+	logger.TraceMessage(
+		"Retrieved list of objects in storage instance '%s' filtered by path '%s': %# v",
+		s.name, path, objectList)
+
+	return objectList, nil
+}
+
+func (s *localStorageInstance) DeleteObject(name string) error {
+
+	var (
+		err error
+
+		path string
+	)
+
+	if path, err = s.objectPath(name); err != nil {
+		return err
+	}
+
+	logger.TraceMessage(
+		"Deleting object '%s' in storage instance '%s'.",
+		name, s.name)
+
+	return os.Remove(path)
+}
+
+// The object's data is written to a temporary file
+// which is renamed once it has been written so that
+// a partially uploaded object is never visible.
+func (s *localStorageInstance) Upload(name, contentType string, data io.Reader, size int64) error {
+
+	var (
+		err error
+
+		path string
+		file *os.File
+	)
+
+	if path, err = s.objectPath(name); err != nil {
+		return err
+	}
+
+	logger.TraceMessage(
+		"Uploading object with name '%s' of size %d to storage instance '%s'.",
+		name, size, s.name)
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if file, err = os.CreateTemp(filepath.Dir(path), ".upload-"); err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = io.Copy(file, data); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (s *localStorageInstance) UploadFile(name, contentType, path string) error {
+
+	var (
+		err error
+
+		file     *os.File
+		fileInfo os.FileInfo
+	)
+
+	if file, err = os.Open(path); err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if fileInfo, err = file.Stat(); err != nil {
+		return err
+	}
+	return s.Upload(name, contentType, file, fileInfo.Size())
+}
+
+func (s *localStorageInstance) Download(name string, data io.Writer) error {
+
+	var (
+		err error
+
+		path string
+		file *os.File
+	)
+
+	if path, err = s.objectPath(name); err != nil {
+		return err
+	}
+
+	logger.TraceMessage(
+		"Downloading object with name '%s' from storage instance '%s'.",
+		name, s.name)
+
+	if file, err = os.Open(path); err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.CopyBuffer(
+		data,
+		file,
+		make([]byte, s.props.BlockSize),
+	)
+	return err
+}
+
+func (s *localStorageInstance) DownloadFile(name, path string) error {
+
+	var (
+		err error
+
+		file *os.File
+	)
+
+	if file, err = os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0644); err != nil {
+		return err
+	}
+	defer file.Close()
+
+	logger.TraceMessage(
+		"Downloading object with name '%s' from storage instance '%s' to path '%s'.",
+		name, s.name, path)
+
+	return s.Download(name, file)
+}
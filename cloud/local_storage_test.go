@@ -0,0 +1,135 @@
+package cloud_test
+
+import (
+	"os"
+
+	"github.com/google/uuid"
+
+	"github.com/mevansam/goutils/logger"
+
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/gocloud/provider"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_helpers "github.com/mevansam/gocloud/test/helpers"
+)
+
+var _ = Describe("Local Storage Tests", func() {
+
+	var (
+		err error
+
+		localProvider provider.CloudProvider
+		localStorage  cloud.Storage
+	)
+
+	BeforeEach(func() {
+
+		localProvider, err = provider.NewCloudProvider("local")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(localProvider).ToNot(BeNil())
+
+		test_helpers.InitializeLocalProvider(localProvider)
+
+		err = localProvider.Connect()
+		Expect(err).NotTo(HaveOccurred())
+
+		localStorage, err = localProvider.GetStorage()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(localStorage).ToNot(BeNil())
+	})
+
+	It("creates, lists and deletes instances", func() {
+		testInstanceCreation(localStorage)
+	})
+
+	It("does not delete instances that are not empty", func() {
+
+		storageInstance, err := localStorage.NewInstance("test-" + uuid.New().String())
+		Expect(err).NotTo(HaveOccurred())
+
+		err = storageInstance.UploadFile("aa/file", "text/plain", os.Args[0])
+		Expect(err).NotTo(HaveOccurred())
+		err = storageInstance.Delete()
+		Expect(err).To(HaveOccurred())
+
+		err = storageInstance.DeleteObject("aa/file")
+		Expect(err).NotTo(HaveOccurred())
+		err = storageInstance.Delete()
+		Expect(err).NotTo(HaveOccurred())
+
+		// object names cannot refer to
+		// paths outside of the instance
+		err = storageInstance.DeleteObject("../file")
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("uploading and downloading data from a storage instance", func() {
+
+		var (
+			storageInstance cloud.StorageInstance
+		)
+
+		BeforeEach(func() {
+			instanceName := "test-" + uuid.New().String()
+			storageInstance, err = localStorage.NewInstance(instanceName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storageInstance.Name()).To(Equal(instanceName))
+		})
+
+		AfterEach(func() {
+			err = storageInstance.Delete()
+			if err != nil {
+				logger.DebugMessage(
+					"Local storage test tear down error while deleting storage instance with name '%s': %s",
+					storageInstance.Name(), err.Error())
+			}
+		})
+
+		It("uploads a few blobs and validates them", func() {
+			testObjectUploadAndDownload(storageInstance)
+		})
+	})
+
+	Context("uploading and downloading files from a storage instance", func() {
+
+		var (
+			tmpDir      string
+			tmpFiles    map[string]string
+			tmpFileData map[string]string
+
+			storageInstance cloud.StorageInstance
+		)
+
+		BeforeEach(func() {
+			tmpDir, err = os.MkdirTemp("", "localstoragetest")
+			Expect(err).NotTo(HaveOccurred())
+
+			tmpFiles = make(map[string]string)
+			tmpFileData = make(map[string]string)
+			createTestFiles(tmpDir, tmpFiles, tmpFileData, oneMB)
+
+			instanceName := "test-" + uuid.New().String()
+			storageInstance, err = localStorage.NewInstance(instanceName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storageInstance.Name()).To(Equal(instanceName))
+		})
+
+		AfterEach(func() {
+			err = storageInstance.Delete()
+			if err != nil {
+				logger.DebugMessage(
+					"Local storage test tear down error while deleting storage instance with name '%s': %s",
+					storageInstance.Name(), err.Error())
+			}
+
+			os.RemoveAll(tmpDir)
+		})
+
+		It("uploads large files with path names and validates them", func() {
+			testFileUploadAndDownload(storageInstance, tmpFiles, tmpFileData)
+		})
+	})
+})
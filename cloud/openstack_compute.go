@@ -0,0 +1,707 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/pauseunpause"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/startstop"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/suspendresume"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/flavors"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/pagination"
+
+	"github.com/mevansam/goutils/logger"
+	"github.com/mevansam/goutils/network"
+)
+
+type OpenStackComputeProperties struct {
+	// timeout for start/stop operations
+	OpTimeout time.Duration
+
+	// maximum number of instance operations
+	// to run concurrently for batch operations
+	MaxConcurrency int
+
+	// name or id of the external network static
+	// ips are allocated from. if not set the first
+	// external network found is used.
+	FloatingIPNetwork string
+
+	// only servers having all of this
+	// metadata are listed if it is set
+	FilterMetadata map[string]string
+}
+
+// the authenticated client and the region used to
+// create the openstack service clients. only the
+// compute service is required so the clients of
+// other services are created when needed.
+type openStackClients struct {
+	provider *gophercloud.ProviderClient
+	endpoint gophercloud.EndpointOpts
+
+	compute *gophercloud.ServiceClient
+}
+
+type openStackCompute struct {
+	clients *openStackClients
+
+	props OpenStackComputeProperties
+}
+
+type openStackComputeInstance struct {
+	clients *openStackClients
+
+	server *servers.Server
+
+	// guards the server detail which is refreshed
+	// by operations running asynchronously
+	mx sync.RWMutex
+
+	props *OpenStackComputeProperties
+}
+
+func NewOpenStackCompute(provider *gophercloud.ProviderClient, region string) (Compute, error) {
+
+	var (
+		err error
+
+		computeClient *gophercloud.ServiceClient
+	)
+
+	endpoint := gophercloud.EndpointOpts{
+		Region: region,
+	}
+	if computeClient, err = openstack.NewComputeV2(provider, endpoint); err != nil {
+		return nil, err
+	}
+
+	return &openStackCompute{
+		clients: &openStackClients{
+			provider: provider,
+			endpoint: endpoint,
+
+			compute: computeClient,
+		},
+
+		props: OpenStackComputeProperties{
+			OpTimeout:      defaultOpTimeout,
+			MaxConcurrency: defaultMaxConcurrency,
+		},
+	}, nil
+}
+
+// returns a client for the networking (neutron) service
+func (c *openStackClients) network() (*gophercloud.ServiceClient, error) {
+	return openstack.NewNetworkV2(c.provider, c.endpoint)
+}
+
+// returns a client for the block storage (cinder) service
+func (c *openStackClients) volume() (*gophercloud.ServiceClient, error) {
+	return openstack.NewBlockStorageV3(c.provider, c.endpoint)
+}
+
+// returns a client for the image (glance) service
+func (c *openStackClients) image() (*gophercloud.ServiceClient, error) {
+	return openstack.NewImageServiceV2(c.provider, c.endpoint)
+}
+
+func (c *openStackCompute) newOpenStackComputeInstance(server servers.Server) *openStackComputeInstance {
+
+	return &openStackComputeInstance{
+		clients: c.clients,
+
+		server: &server,
+
+		props: &c.props,
+	}
+}
+
+// returns the servers having the filter
+// metadata if it has been set
+func (c *openStackCompute) listServers(opts servers.ListOpts) ([]servers.Server, error) {
+
+	var (
+		err error
+
+		page pagination.Page
+		list []servers.Server
+	)
+
+	if page, err = servers.List(c.clients.compute, opts).AllPages(); err != nil {
+		return nil, err
+	}
+	if list, err = servers.ExtractServers(page); err != nil {
+		return nil, err
+	}
+
+	filtered := make([]servers.Server, 0, len(list))
+	for _, server := range list {
+		match := true
+		for k, v := range c.props.FilterMetadata {
+			if server.Metadata[k] != v {
+				match = false
+				break
+			}
+		}
+		if match {
+			filtered = append(filtered, server)
+		}
+	}
+	return filtered, nil
+}
+
+// returns the first ip address of the server having the given
+// address type which is either "fixed" or "floating"
+func openStackServerAddress(server *servers.Server, addrType string) string {
+
+	for _, addresses := range server.Addresses {
+		list, ok := addresses.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, a := range list {
+			address, ok := a.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if t, _ := address["OS-EXT-IPS:type"].(string); t != addrType {
+				continue
+			}
+			if v, _ := address["version"].(float64); v != 4 {
+				continue
+			}
+			if addr, ok := address["addr"].(string); ok {
+				return addr
+			}
+		}
+	}
+	return ""
+}
+
+// returns the id of the flavor of the given server
+func openStackServerFlavorID(server *servers.Server) string {
+	id, _ := server.Flavor["id"].(string)
+	return id
+}
+
+// returns the id of the flavor with the given name
+func openStackFlavorID(client *gophercloud.ServiceClient, name string) (string, error) {
+
+	var (
+		err error
+
+		page pagination.Page
+		list []flavors.Flavor
+	)
+
+	if page, err = flavors.ListDetail(client, nil).AllPages(); err != nil {
+		return "", err
+	}
+	if list, err = flavors.ExtractFlavors(page); err != nil {
+		return "", err
+	}
+	for _, f := range list {
+		if f.Name == name {
+			return f.ID, nil
+		}
+	}
+	return "", fmt.Errorf("flavor '%s' was not found", name)
+}
+
+// interface: cloud/Compute implementation
+
+func (c *openStackCompute) SetProperties(props interface{}) {
+
+	p := props.(OpenStackComputeProperties)
+	if p.OpTimeout != 0 {
+		c.props.OpTimeout = p.OpTimeout
+	}
+	if p.MaxConcurrency != 0 {
+		c.props.MaxConcurrency = p.MaxConcurrency
+	}
+	if len(p.FloatingIPNetwork) > 0 {
+		c.props.FloatingIPNetwork = p.FloatingIPNetwork
+	}
+	if p.FilterMetadata != nil {
+		c.props.FilterMetadata = p.FilterMetadata
+	}
+}
+
+func (c *openStackCompute) GetInstance(name string) (ComputeInstance, error) {
+
+	var (
+		err error
+
+		list     []servers.Server
+		instance *openStackComputeInstance
+	)
+
+	// the name filter of the list api is
+	// a regular expression so the names of
+	// the returned servers are matched exactly
+	if list, err = c.listServers(servers.ListOpts{
+		Name: fmt.Sprintf("^%s$", regexp.QuoteMeta(name)),
+	}); err != nil {
+		return nil, err
+	}
+	for _, server := range list {
+		if server.Name == name {
+			if instance != nil {
+				return nil, fmt.Errorf("more than one server named '%s' was found", name)
+			}
+			instance = c.newOpenStackComputeInstance(server)
+		}
+	}
+	if instance == nil {
+		return nil, fmt.Errorf("server '%s' was not found", name)
+	}
+	return instance, nil
+}
+
+func (c *openStackCompute) GetInstances(ids []string) ([]ComputeInstance, error) {
+
+	var (
+		err error
+
+		server *servers.Server
+	)
+
+	instances := make([]ComputeInstance, 0, len(ids))
+	for _, id := range ids {
+		if server, err = servers.Get(c.clients.compute, id).Extract(); err != nil {
+			if isOpenStackNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		instances = append(instances, c.newOpenStackComputeInstance(*server))
+	}
+	return instances, nil
+}
+
+func (c *openStackCompute) ListInstances() ([]ComputeInstance, error) {
+
+	var (
+		err error
+
+		list []servers.Server
+	)
+
+	if list, err = c.listServers(servers.ListOpts{}); err != nil {
+		return nil, err
+	}
+	instances := make([]ComputeInstance, 0, len(list))
+	for _, server := range list {
+		instances = append(instances, c.newOpenStackComputeInstance(server))
+	}
+	return instances, nil
+}
+
+func (c *openStackCompute) ListSizes() ([]InstanceSize, error) {
+
+	var (
+		err error
+
+		page pagination.Page
+		list []flavors.Flavor
+	)
+
+	if page, err = flavors.ListDetail(c.clients.compute, nil).AllPages(); err != nil {
+		return nil, err
+	}
+	if list, err = flavors.ExtractFlavors(page); err != nil {
+		return nil, err
+	}
+	sizes := make([]InstanceSize, 0, len(list))
+	for _, f := range list {
+		sizes = append(sizes, InstanceSize{
+			Name:     f.Name,
+			VCPUs:    f.VCPUs,
+			MemoryMB: f.RAM,
+		})
+	}
+	sortInstanceSizes(sizes)
+	return sizes, nil
+}
+
+func (c *openStackCompute) StartInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Start()
+	})
+}
+
+func (c *openStackCompute) StopInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Stop()
+	})
+}
+
+func (c *openStackCompute) RestartInstances(ids []string) ([]InstanceResult, error) {
+	return runBatch(c, ids, c.props.MaxConcurrency, func(instance ComputeInstance) error {
+		return instance.Restart()
+	})
+}
+
+// returns the last retrieved server detail
+func (c *openStackComputeInstance) detail() *servers.Server {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+	return c.server
+}
+
+// replaces the server detail with a refreshed copy
+func (c *openStackComputeInstance) setDetail(server *servers.Server) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.server = server
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *openStackComputeInstance) ID() string {
+	return c.detail().ID
+}
+
+func (c *openStackComputeInstance) Name() string {
+	return c.detail().Name
+}
+
+// Returns the floating IP of the server or its
+// access IP if it does not have a floating IP.
+func (c *openStackComputeInstance) PublicIP() string {
+	if ip := openStackServerAddress(c.detail(), "floating"); ip != "" {
+		return ip
+	}
+	return c.detail().AccessIPv4
+}
+
+// Servers do not have a public DNS
+// name so an empty string is returned.
+func (c *openStackComputeInstance) PublicDNS() string {
+	return ""
+}
+
+func (c *openStackComputeInstance) PrivateIP() string {
+	return openStackServerAddress(c.detail(), "fixed")
+}
+
+// refreshes the server's details
+func (c *openStackComputeInstance) refresh() error {
+
+	server, err := servers.Get(c.clients.compute, c.detail().ID).Extract()
+	if err != nil {
+		return err
+	}
+	c.setDetail(server)
+	return nil
+}
+
+// returns an operation that waits for
+// the server to reach the given state
+func (c *openStackComputeInstance) stateOperation(state InstanceState) Operation {
+
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		return waitForState(ctx, c, state, c.props.OpTimeout)
+	})
+}
+
+func (c *openStackComputeInstance) State() (InstanceState, error) {
+
+	var (
+		err error
+	)
+
+	if err = c.refresh(); err != nil {
+		return StateUnknown, err
+	}
+
+	server := c.detail()
+	logger.TraceMessage("Status for server '%s' is: %s",
+		server.Name, server.Status)
+
+	switch server.Status {
+	case "ACTIVE":
+		return StateRunning, nil
+	case "SHUTOFF", "SHELVED", "SHELVED_OFFLOADED":
+		return StateStopped, nil
+	case "SUSPENDED", "PAUSED":
+		return StateSuspended, nil
+	case "BUILD", "REBOOT", "HARD_REBOOT", "REBUILD", "PASSWORD",
+		"RESIZE", "VERIFY_RESIZE", "REVERT_RESIZE", "MIGRATING":
+		return StatePending, nil
+	default:
+		return StateUnknown, nil
+	}
+}
+
+func (c *openStackComputeInstance) Start() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.StartAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *openStackComputeInstance) Restart() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.RestartAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *openStackComputeInstance) Stop() error {
+
+	var (
+		err error
+
+		op Operation
+	)
+
+	if op, err = c.StopAsync(); err != nil {
+		return err
+	}
+	return op.Wait(context.Background())
+}
+
+func (c *openStackComputeInstance) StartAsync() (Operation, error) {
+
+	logger.TraceMessage("Starting server '%s'.", c.detail().Name)
+
+	if err := startstop.Start(c.clients.compute, c.detail().ID).ExtractErr(); err != nil {
+		return nil, err
+	}
+	return c.stateOperation(StateRunning), nil
+}
+
+func (c *openStackComputeInstance) RestartAsync() (Operation, error) {
+
+	logger.TraceMessage("Rebooting server '%s'.", c.detail().Name)
+
+	if err := servers.Reboot(c.clients.compute, c.detail().ID, servers.RebootOpts{
+		Type: servers.SoftReboot,
+	}).ExtractErr(); err != nil {
+		return nil, err
+	}
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		// the server's status changes to
+		// REBOOT once the reboot starts
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"timed out waiting for server '%s' to reboot: %s",
+				c.detail().Name, ctx.Err(),
+			)
+		case <-time.After(statePollInterval):
+		}
+		return waitForState(ctx, c, StateRunning, c.props.OpTimeout)
+	}), nil
+}
+
+func (c *openStackComputeInstance) StopAsync() (Operation, error) {
+
+	logger.TraceMessage("Stopping server '%s'.", c.detail().Name)
+
+	if err := startstop.Stop(c.clients.compute, c.detail().ID).ExtractErr(); err != nil {
+		return nil, err
+	}
+	return c.stateOperation(StateStopped), nil
+}
+
+// Whether a server can be suspended depends on the
+// hypervisor and policy of the cloud which cannot be
+// queried so all servers are assumed to support it.
+func (c *openStackComputeInstance) Capabilities() (InstanceCapabilities, error) {
+	return InstanceCapabilities{
+		SuspendResume: true,
+	}, nil
+}
+
+func (c *openStackComputeInstance) Suspend() error {
+
+	logger.TraceMessage("Suspending server '%s'.", c.detail().Name)
+
+	if err := suspendresume.Suspend(c.clients.compute, c.detail().ID).ExtractErr(); err != nil {
+		return err
+	}
+	return c.stateOperation(StateSuspended).Wait(context.Background())
+}
+
+// Paused servers are also reported as suspended
+// so they are unpaused instead of being resumed.
+func (c *openStackComputeInstance) Resume() error {
+
+	var (
+		err error
+	)
+
+	if err = c.refresh(); err != nil {
+		return err
+	}
+	if c.detail().Status == "PAUSED" {
+		logger.TraceMessage("Unpausing server '%s'.", c.detail().Name)
+		err = pauseunpause.Unpause(c.clients.compute, c.detail().ID).ExtractErr()
+	} else {
+		logger.TraceMessage("Resuming server '%s'.", c.detail().Name)
+		err = suspendresume.Resume(c.clients.compute, c.detail().ID).ExtractErr()
+	}
+	if err != nil {
+		return err
+	}
+	return c.stateOperation(StateRunning).Wait(context.Background())
+}
+
+// Servers are resized without being stopped. The resize
+// is confirmed once the server has been migrated to the
+// new flavor so that it cannot be reverted.
+func (c *openStackComputeInstance) Resize(size string, autoRestart bool) error {
+
+	return resizeInstance(c, autoRestart, false, func() error {
+
+		var (
+			err error
+
+			flavorID string
+			resizing,
+			confirmed bool
+		)
+
+		if flavorID, err = openStackFlavorID(c.clients.compute, size); err != nil {
+			return err
+		}
+		oldFlavorID := openStackServerFlavorID(c.detail())
+
+		logger.TraceMessage("Resizing server '%s' to '%s'.", c.detail().Name, size)
+
+		if err = servers.Resize(c.clients.compute, c.detail().ID, servers.ResizeOpts{
+			FlavorRef: flavorID,
+		}).ExtractErr(); err != nil {
+			return err
+		}
+		if err = newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+			for {
+				if err := c.refresh(); err != nil {
+					return err
+				}
+				server := c.detail()
+				switch server.Status {
+				case "VERIFY_RESIZE":
+					return nil
+				case "ERROR":
+					return fmt.Errorf("resize of server '%s' failed", server.Name)
+				case "ACTIVE":
+					// the server remains active until the resize
+					// starts and returns to active with its old
+					// flavor if the resize could not be completed
+					if resizing {
+						switch openStackServerFlavorID(server) {
+						case oldFlavorID:
+							return fmt.Errorf(
+								"resize of server '%s' was reverted to its original flavor", server.Name)
+						case flavorID:
+							// the cloud confirmed the resize
+							confirmed = true
+							return nil
+						}
+					}
+				default:
+					resizing = true
+				}
+
+				select {
+				case <-ctx.Done():
+					return fmt.Errorf(
+						"timed out waiting for server '%s' to be resized: %s",
+						c.detail().Name, ctx.Err(),
+					)
+				case <-time.After(statePollInterval):
+				}
+			}
+		}).Wait(context.Background()); err != nil {
+			return err
+		}
+
+		if confirmed {
+			return nil
+		}
+		if err = servers.ConfirmResize(c.clients.compute, c.detail().ID).ExtractErr(); err != nil {
+			return err
+		}
+		return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+			for {
+				state, err := c.State()
+				if err != nil {
+					return err
+				}
+				if state != StatePending {
+					return nil
+				}
+
+				select {
+				case <-ctx.Done():
+					return fmt.Errorf(
+						"timed out waiting for resize of server '%s' to be confirmed: %s",
+						c.detail().Name, ctx.Err(),
+					)
+				case <-time.After(statePollInterval):
+				}
+			}
+		}).Wait(context.Background())
+	})
+}
+
+func (c *openStackComputeInstance) ConsoleOutput() (string, error) {
+	return servers.ShowConsoleOutput(
+		c.clients.compute,
+		c.detail().ID,
+		servers.ShowConsoleOutputOpts{},
+	).Extract()
+}
+
+func (c *openStackComputeInstance) WaitForState(
+	ctx context.Context,
+	state InstanceState,
+	timeout time.Duration,
+) error {
+
+	if timeout == 0 {
+		timeout = c.props.OpTimeout
+	}
+	return waitForState(ctx, c, state, timeout)
+}
+
+func (c *openStackComputeInstance) CanConnect(port int) bool {
+
+	if ip := c.PublicIP(); ip != "" {
+		return network.CanConnect(ip, port)
+	} else {
+		return false
+	}
+}
+
+func (c *openStackComputeInstance) HealthCheck(ctx context.Context, check HealthCheck) HealthCheckResult {
+	return CheckHealth(ctx, c, check)
+}
+
+func (c *openStackComputeInstance) WaitUntilHealthy(ctx context.Context, checks ...HealthCheck) error {
+	return WaitUntilHealthy(ctx, c, checks...)
+}
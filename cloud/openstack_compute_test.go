@@ -0,0 +1,217 @@
+package cloud_test
+
+import (
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/gocloud/provider"
+	"github.com/mevansam/goutils/logger"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_helpers "github.com/mevansam/gocloud/test/helpers"
+)
+
+var _ = Describe("OpenStack Compute Tests", func() {
+
+	var (
+		err error
+
+		openStackProvider provider.CloudProvider
+		openStackCompute  cloud.Compute
+
+		testServers map[string]*servers.Server
+	)
+
+	BeforeEach(func() {
+
+		openStackProvider, err = provider.NewCloudProvider("openstack")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(openStackProvider).ToNot(BeNil())
+
+		test_helpers.InitializeOpenStackProvider(openStackProvider)
+
+		err = openStackProvider.Connect()
+		Expect(err).NotTo(HaveOccurred())
+
+		openStackCompute, err = openStackProvider.GetCompute()
+		Expect(err).NotTo(HaveOccurred())
+
+		// ensure 2 test servers have been created for these tests
+		testServers = test_helpers.OpenStackDeployTestServers("test", 2)
+		Expect(len(testServers)).To(Equal(2))
+
+		logger.DebugMessage("Test servers: %# v", testServers)
+
+		openStackCompute.SetProperties(cloud.OpenStackComputeProperties{
+			FilterMetadata: map[string]string{
+				"cloudbuilder-test": "true",
+			},
+		})
+	})
+
+	Context("Compute resources", func() {
+
+		It("retrieves a list of compute instances", func() {
+
+			instances, err := openStackCompute.ListInstances()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(instances)).To(Equal(len(testServers)))
+
+			for _, instance := range instances {
+				server, exists := testServers[instance.Name()]
+				Expect(exists).To(BeTrue())
+				Expect(instance.ID()).To(Equal(server.ID))
+			}
+		})
+
+		It("retrieves a list of compute instances by their ids", func() {
+
+			instanceIds := []string{}
+			for _, server := range testServers {
+				instanceIds = append(instanceIds, server.ID)
+			}
+
+			instances, err := openStackCompute.GetInstances(instanceIds)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(instances)).To(Equal(len(testServers)))
+
+			for _, instance := range instances {
+				server, exists := testServers[instance.Name()]
+				Expect(exists).To(BeTrue())
+				Expect(instance.ID()).To(Equal(server.ID))
+			}
+		})
+
+		It("stops and starts a batch of compute instances", func() {
+
+			instanceIds := []string{}
+			for _, server := range testServers {
+				instanceIds = append(instanceIds, server.ID)
+			}
+			testBatchStopAndStart(openStackCompute, instanceIds, "1")
+		})
+
+		It("retrieves a compute instance", func() {
+
+			_, err := openStackCompute.GetInstance("test-X")
+			Expect(err).To(HaveOccurred())
+
+			instance, err := openStackCompute.GetInstance("test-0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance).ToNot(BeNil())
+		})
+	})
+
+	Context("Compute instance", func() {
+
+		var (
+			instance0 cloud.ComputeInstance
+			instance1 cloud.ComputeInstance
+		)
+
+		BeforeEach(func() {
+			instance0, err = openStackCompute.GetInstance("test-0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance0).ToNot(BeNil())
+
+			instance1, err = openStackCompute.GetInstance("test-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance1).ToNot(BeNil())
+		})
+
+		It("stops and starts a compute instance", func() {
+
+			state, err := instance0.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateRunning))
+
+			err = instance0.Stop()
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err = instance0.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateStopped))
+			Expect(test_helpers.OpenStackServerStatus(instance0.ID())).To(Equal("SHUTOFF"))
+
+			err = instance0.Start()
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err = instance0.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateRunning))
+		})
+
+		It("restarts a compute instance", func() {
+
+			err = instance1.Restart()
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err := instance1.State()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(cloud.StateRunning))
+		})
+
+		It("stops and starts compute instances asynchronously", func() {
+			testAsyncStopAndStart(instance0, instance1)
+		})
+
+		It("suspends and resumes a compute instance", func() {
+			testSuspendAndResume(instance0)
+		})
+
+		It("resizes a compute instance", func() {
+			currentSize, newSize := test_helpers.OpenStackTestFlavors()
+			testResize(openStackCompute, instance1, currentSize, newSize)
+		})
+
+		It("retrieves the scheduling of a compute instance", func() {
+			testOnDemandScheduling(instance0)
+		})
+
+		It("finds public images", func() {
+
+			images, err := openStackCompute.FindImages(cloud.ImageFilter{
+				OS:      "ubuntu",
+				Version: "22.04",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(images)).To(BeNumerically(">", 0))
+			for _, image := range images {
+				Expect(len(image.ID)).To(BeNumerically(">", 0))
+				Expect(image.OS).To(Equal("ubuntu"))
+				Expect(image.Version).To(Equal("22.04"))
+				Expect(image.Arch).To(Equal(cloud.ArchX86_64))
+			}
+
+			_, err = openStackCompute.FindImages(cloud.ImageFilter{
+				OS:      "unknown",
+				Version: "1.0",
+			})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("creates and deletes an image of a compute instance", func() {
+			testCreateAndDeleteImage(openStackCompute, instance1)
+		})
+
+		It("adds and removes firewall rules of a compute instance", func() {
+			testFirewallRules(instance0)
+		})
+
+		It("sets and retrieves the user data of a compute instance", func() {
+			testUserData(instance1)
+		})
+
+		It("allocates, associates and releases a static ip", func() {
+			testStaticIPs(openStackCompute, instance1, nil, false)
+		})
+
+		It("creates, attaches, snapshots and detaches a volume", func() {
+			testVolumeAttachment(openStackCompute, instance0, "", map[string]string{
+				"cloudbuilder-test": "true",
+			})
+		})
+	})
+})
@@ -0,0 +1,188 @@
+package cloud
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	"github.com/gophercloud/gophercloud/pagination"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+func newOpenStackFirewallRule(rule *rules.SecGroupRule) (FirewallRule, bool) {
+
+	r := FirewallRule{
+		ID:          rule.ID,
+		Description: rule.Description,
+		Protocol:    rule.Protocol,
+		FromPort:    rule.PortRangeMin,
+		ToPort:      rule.PortRangeMax,
+		CIDR:        rule.RemoteIPPrefix,
+	}
+	if len(r.CIDR) == 0 {
+		// rules with a security group as
+		// their source are not returned
+		if len(rule.RemoteGroupID) > 0 {
+			return r, false
+		}
+		r.CIDR = "0.0.0.0/0"
+		if rule.EtherType == string(rules.EtherType6) {
+			r.CIDR = "::/0"
+		}
+	}
+
+	// an empty protocol is all protocols
+	// and no port range is all ports
+	if len(r.Protocol) == 0 {
+		r.Protocol = ProtocolAll
+	}
+	if r.FromPort == 0 && r.ToPort == 0 {
+		r.ToPort = 65535
+	}
+	r.Expires = parseFirewallRuleExpiry(r.Description)
+	return r, true
+}
+
+// returns the ids of the security groups of the server's ports
+func (c *openStackComputeInstance) securityGroupIDs(client *gophercloud.ServiceClient) ([]string, error) {
+
+	var (
+		err error
+
+		serverPorts []ports.Port
+	)
+
+	if serverPorts, err = openStackServerPorts(client, c.detail().ID); err != nil {
+		return nil, err
+	}
+	groupIDs := []string{}
+	seen := make(map[string]bool)
+	for _, port := range serverPorts {
+		for _, id := range port.SecurityGroups {
+			if !seen[id] {
+				groupIDs = append(groupIDs, id)
+				seen[id] = true
+			}
+		}
+	}
+	return groupIDs, nil
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *openStackComputeInstance) FirewallRules() ([]FirewallRule, error) {
+
+	var (
+		err error
+
+		client   *gophercloud.ServiceClient
+		groupIDs []string
+	)
+
+	if client, err = c.clients.network(); err != nil {
+		return nil, err
+	}
+	if groupIDs, err = c.securityGroupIDs(client); err != nil {
+		return nil, err
+	}
+	firewallRules := []FirewallRule{}
+	for _, groupID := range groupIDs {
+		if err = rules.List(client, rules.ListOpts{
+			SecGroupID: groupID,
+			Direction:  string(rules.DirIngress),
+		}).EachPage(func(page pagination.Page) (bool, error) {
+			list, err := rules.ExtractRules(page)
+			if err != nil {
+				return false, err
+			}
+			for i := range list {
+				if r, ok := newOpenStackFirewallRule(&list[i]); ok {
+					firewallRules = append(firewallRules, r)
+				}
+			}
+			return true, nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return firewallRules, nil
+}
+
+// Ingress rules are added to the first security group
+// of the server so they will also apply to any other
+// servers that share that group.
+func (c *openStackComputeInstance) AddIngressRule(spec IngressRuleSpec) (FirewallRule, error) {
+
+	var (
+		err error
+
+		client   *gophercloud.ServiceClient
+		groupIDs []string
+		rule     *rules.SecGroupRule
+	)
+
+	if spec, err = spec.normalize(); err != nil {
+		return FirewallRule{}, err
+	}
+	if client, err = c.clients.network(); err != nil {
+		return FirewallRule{}, err
+	}
+	if groupIDs, err = c.securityGroupIDs(client); err != nil {
+		return FirewallRule{}, err
+	}
+	if len(groupIDs) == 0 {
+		return FirewallRule{}, fmt.Errorf("server '%s' has no security groups", c.detail().Name)
+	}
+
+	opts := rules.CreateOpts{
+		Direction:      rules.DirIngress,
+		Description:    spec.Description,
+		EtherType:      rules.EtherType4,
+		SecGroupID:     groupIDs[0],
+		RemoteIPPrefix: spec.CIDR,
+	}
+	if ip, _, _ := net.ParseCIDR(spec.CIDR); ip.To4() == nil {
+		opts.EtherType = rules.EtherType6
+	}
+	switch spec.Protocol {
+	case ProtocolAll:
+	case ProtocolICMP:
+		opts.Protocol = rules.ProtocolICMP
+	default:
+		opts.Protocol = rules.RuleProtocol(spec.Protocol)
+		opts.PortRangeMin = spec.Port
+		opts.PortRangeMax = spec.Port
+	}
+
+	logger.TraceMessage(
+		"Adding ingress rule for %s port %d from '%s' to security group '%s' of server '%s'.",
+		spec.Protocol, spec.Port, spec.CIDR, groupIDs[0], c.detail().Name,
+	)
+
+	if rule, err = rules.Create(client, opts).Extract(); err != nil {
+		return FirewallRule{}, err
+	}
+	r, _ := newOpenStackFirewallRule(rule)
+	return r, nil
+}
+
+func (c *openStackComputeInstance) RemoveFirewallRule(rule FirewallRule) error {
+
+	var (
+		err error
+
+		client *gophercloud.ServiceClient
+	)
+
+	if client, err = c.clients.network(); err != nil {
+		return err
+	}
+
+	logger.TraceMessage(
+		"Removing ingress rule '%s' of server '%s'.", rule.ID, c.detail().Name)
+
+	return rules.Delete(client, rule.ID).ExtractErr()
+}
@@ -0,0 +1,202 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/imageservice/v2/images"
+	"github.com/gophercloud/gophercloud/pagination"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// returns the image with its os, version and architecture
+// taken from the standard glance image properties
+func newOpenStackImage(image *images.Image) Image {
+
+	property := func(name string) string {
+		value, _ := image.Properties[name].(string)
+		return value
+	}
+	i := Image{
+		ID:      image.ID,
+		Name:    image.Name,
+		OS:      strings.ToLower(property("os_distro")),
+		Version: property("os_version"),
+		Created: image.CreatedAt,
+	}
+	if arch := property("architecture"); len(arch) > 0 {
+		i.Arch = normalizeArch(arch)
+	}
+	return i
+}
+
+// returns the images matching the given list options
+func listOpenStackImages(client *gophercloud.ServiceClient, opts images.ListOpts) ([]images.Image, error) {
+
+	var (
+		err error
+
+		list []images.Image
+	)
+
+	if err = images.List(client, opts).EachPage(func(page pagination.Page) (bool, error) {
+		p, err := images.ExtractImages(page)
+		if err != nil {
+			return false, err
+		}
+		list = append(list, p...)
+		return true, nil
+	}); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// interface: cloud/Compute implementation
+
+// The public images of an OpenStack cloud are specific to
+// that cloud so they are matched by their "os_distro",
+// "os_version" and "architecture" properties. Images
+// without an architecture are taken to be x86_64 images.
+func (c *openStackCompute) FindImages(filter ImageFilter) ([]Image, error) {
+
+	var (
+		err error
+
+		client *gophercloud.ServiceClient
+		list   []images.Image
+	)
+
+	if client, err = c.clients.image(); err != nil {
+		return nil, err
+	}
+
+	result := []Image{}
+	if filter.Owned {
+		if list, err = listOpenStackImages(client, images.ListOpts{
+			Visibility: images.ImageVisibilityPrivate,
+		}); err != nil {
+			return nil, err
+		}
+		for i := range list {
+			if strings.HasPrefix(list[i].Name, filter.Name) {
+				result = append(result, newOpenStackImage(&list[i]))
+			}
+		}
+		sortImages(result)
+		return result, nil
+	}
+
+	arch := filter.Arch
+	if len(arch) == 0 {
+		arch = ArchX86_64
+	}
+	if list, err = listOpenStackImages(client, images.ListOpts{
+		Visibility: images.ImageVisibilityPublic,
+		Status:     images.ImageStatusActive,
+	}); err != nil {
+		return nil, err
+	}
+	for i := range list {
+		image := newOpenStackImage(&list[i])
+		if len(image.Arch) == 0 {
+			image.Arch = ArchX86_64
+		}
+		if image.OS == strings.ToLower(filter.OS) &&
+			image.Version == filter.Version &&
+			image.Arch == arch {
+			result = append(result, image)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf(
+			"no public image found for os '%s' version '%s' and architecture '%s'",
+			filter.OS, filter.Version, arch,
+		)
+	}
+	sortImages(result)
+	return result, nil
+}
+
+// The image is a snapshot of the server which carries
+// the server's metadata as its image properties.
+func (c *openStackCompute) CreateImage(instance ComputeInstance, name string) (Image, error) {
+
+	var (
+		err error
+		ok  bool
+
+		osInstance *openStackComputeInstance
+
+		client  *gophercloud.ServiceClient
+		imageID string
+		image   *images.Image
+	)
+
+	if osInstance, ok = instance.(*openStackComputeInstance); !ok {
+		return Image{}, fmt.Errorf("instance '%s' is not an openstack server", instance.Name())
+	}
+	server := osInstance.detail()
+
+	if client, err = c.clients.image(); err != nil {
+		return Image{}, err
+	}
+
+	logger.TraceMessage(
+		"Creating image '%s' from snapshot of server '%s'.",
+		name, server.Name,
+	)
+
+	if imageID, err = servers.CreateImage(c.clients.compute, server.ID, servers.CreateImageOpts{
+		Name:     name,
+		Metadata: server.Metadata,
+	}).ExtractImageID(); err != nil {
+		return Image{}, err
+	}
+	if err = newOperation(defaultImageOpTimeout, func(ctx context.Context) error {
+		for {
+			if image, err = images.Get(client, imageID).Extract(); err != nil {
+				return err
+			}
+			switch image.Status {
+			case images.ImageStatusActive:
+				return nil
+			case images.ImageStatusKilled, images.ImageStatusDeleted:
+				return fmt.Errorf("image '%s' could not be created: %s", name, image.Status)
+			}
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf(
+					"timed out waiting for image '%s' to be created: %s",
+					name, ctx.Err(),
+				)
+			case <-time.After(statePollInterval):
+			}
+		}
+	}).Wait(context.Background()); err != nil {
+		return Image{}, err
+	}
+	return newOpenStackImage(image), nil
+}
+
+func (c *openStackCompute) DeleteImage(id string) error {
+
+	var (
+		err error
+
+		client *gophercloud.ServiceClient
+	)
+
+	if client, err = c.clients.image(); err != nil {
+		return err
+	}
+	logger.TraceMessage("Deleting image '%s'.", id)
+
+	return images.Delete(client, id).ExtractErr()
+}
@@ -0,0 +1,15 @@
+package cloud
+
+// interface: cloud/ComputeInstance implementation
+
+// OpenStack servers do not have a spot or preemptible
+// purchase model so they are always on-demand.
+func (c *openStackComputeInstance) Scheduling() (InstanceScheduling, error) {
+	return InstanceScheduling{
+		PurchaseModel: PurchaseOnDemand,
+	}, nil
+}
+
+func (c *openStackComputeInstance) InterruptionNotice() (InterruptionNotice, error) {
+	return InterruptionNotice{}, nil
+}
@@ -0,0 +1,274 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/external"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	"github.com/gophercloud/gophercloud/pagination"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// returns the static ip of the given floating ip with the
+// id of the server the port it is associated with belongs to
+func newOpenStackStaticIP(client *gophercloud.ServiceClient, floatingIP *floatingips.FloatingIP) (StaticIP, error) {
+
+	ip := StaticIP{
+		ID:      floatingIP.ID,
+		Name:    floatingIP.Description,
+		Address: floatingIP.FloatingIP,
+	}
+	if len(floatingIP.PortID) > 0 {
+		port, err := ports.Get(client, floatingIP.PortID).Extract()
+		if err != nil {
+			return ip, err
+		}
+		ip.InstanceID = port.DeviceID
+	}
+	return ip, nil
+}
+
+// returns the network ports of the given server
+func openStackServerPorts(client *gophercloud.ServiceClient, serverID string) ([]ports.Port, error) {
+
+	var (
+		err error
+
+		page pagination.Page
+	)
+
+	if page, err = ports.List(client, ports.ListOpts{
+		DeviceID: serverID,
+	}).AllPages(); err != nil {
+		return nil, err
+	}
+	return ports.ExtractPorts(page)
+}
+
+// returns the id of the external network to allocate floating
+// ips from which is the network having the given name or id
+// or the first external network if a network is not given
+func (c *openStackCompute) floatingIPNetworkID(client *gophercloud.ServiceClient) (string, error) {
+
+	var (
+		err error
+
+		page pagination.Page
+		list []networks.Network
+	)
+
+	isExternal := true
+	if page, err = networks.List(client, external.ListOptsExt{
+		ListOptsBuilder: networks.ListOpts{},
+		External:        &isExternal,
+	}).AllPages(); err != nil {
+		return "", err
+	}
+	if list, err = networks.ExtractNetworks(page); err != nil {
+		return "", err
+	}
+	for _, n := range list {
+		if len(c.props.FloatingIPNetwork) == 0 ||
+			n.ID == c.props.FloatingIPNetwork || n.Name == c.props.FloatingIPNetwork {
+			return n.ID, nil
+		}
+	}
+	if len(c.props.FloatingIPNetwork) > 0 {
+		return "", fmt.Errorf("external network '%s' was not found", c.props.FloatingIPNetwork)
+	}
+	return "", fmt.Errorf("no external network was found to allocate floating ips from")
+}
+
+// interface: cloud/Compute implementation
+
+// Static IPs are allocated as floating IPs from the external
+// network. Floating IPs do not have a name so the name is
+// saved as the floating IP's description. The tags are
+// ignored as tagging requires the neutron tag extension.
+func (c *openStackCompute) AllocateStaticIP(name string, tags map[string]string) (StaticIP, error) {
+
+	var (
+		err error
+
+		client     *gophercloud.ServiceClient
+		networkID  string
+		floatingIP *floatingips.FloatingIP
+	)
+
+	if client, err = c.clients.network(); err != nil {
+		return StaticIP{}, err
+	}
+	if networkID, err = c.floatingIPNetworkID(client); err != nil {
+		return StaticIP{}, err
+	}
+
+	logger.TraceMessage(
+		"Allocating floating IP '%s' from network '%s'.", name, networkID)
+
+	if floatingIP, err = floatingips.Create(client, floatingips.CreateOpts{
+		FloatingNetworkID: networkID,
+		Description:       name,
+	}).Extract(); err != nil {
+		return StaticIP{}, err
+	}
+	return newOpenStackStaticIP(client, floatingIP)
+}
+
+func (c *openStackCompute) ListStaticIPs() ([]StaticIP, error) {
+
+	var (
+		err error
+
+		client *gophercloud.ServiceClient
+		page   pagination.Page
+		list   []floatingips.FloatingIP
+		ip     StaticIP
+	)
+
+	if client, err = c.clients.network(); err != nil {
+		return nil, err
+	}
+	if page, err = floatingips.List(client, floatingips.ListOpts{}).AllPages(); err != nil {
+		return nil, err
+	}
+	if list, err = floatingips.ExtractFloatingIPs(page); err != nil {
+		return nil, err
+	}
+	ips := make([]StaticIP, 0, len(list))
+	for i := range list {
+		if ip, err = newOpenStackStaticIP(client, &list[i]); err != nil {
+			return nil, err
+		}
+		ips = append(ips, ip)
+	}
+	return ips, nil
+}
+
+func (c *openStackCompute) ReleaseStaticIP(ip StaticIP) error {
+
+	var (
+		err error
+
+		client *gophercloud.ServiceClient
+	)
+
+	if client, err = c.clients.network(); err != nil {
+		return err
+	}
+
+	logger.TraceMessage("Releasing floating IP '%s'.", ip.Address)
+
+	return floatingips.Delete(client, ip.ID).ExtractErr()
+}
+
+// interface: cloud/ComputeInstance implementation
+
+// The floating IP is associated with the first network
+// port of the server and replaces any floating IP the
+// server may already have.
+func (c *openStackComputeInstance) AssociateStaticIP(ip StaticIP) error {
+
+	var (
+		err error
+
+		client      *gophercloud.ServiceClient
+		serverPorts []ports.Port
+	)
+
+	if client, err = c.clients.network(); err != nil {
+		return err
+	}
+	if serverPorts, err = openStackServerPorts(client, c.detail().ID); err != nil {
+		return err
+	}
+	if len(serverPorts) == 0 {
+		return fmt.Errorf("server '%s' has no network ports", c.detail().Name)
+	}
+
+	logger.TraceMessage(
+		"Associating floating IP '%s' with server '%s'.",
+		ip.Address, c.detail().Name,
+	)
+
+	if _, err = floatingips.Update(client, ip.ID, floatingips.UpdateOpts{
+		PortID: &serverPorts[0].ID,
+	}).Extract(); err != nil {
+		return err
+	}
+	return c.waitForPublicIP(func(publicIP string) bool {
+		return publicIP == ip.Address
+	})
+}
+
+func (c *openStackComputeInstance) DisassociateStaticIP(ip StaticIP) error {
+
+	var (
+		err error
+
+		client     *gophercloud.ServiceClient
+		floatingIP *floatingips.FloatingIP
+		port       *ports.Port
+	)
+
+	if client, err = c.clients.network(); err != nil {
+		return err
+	}
+	if floatingIP, err = floatingips.Get(client, ip.ID).Extract(); err != nil {
+		return err
+	}
+	if len(floatingIP.PortID) > 0 {
+		if port, err = ports.Get(client, floatingIP.PortID).Extract(); err != nil {
+			return err
+		}
+	}
+	if port == nil || port.DeviceID != c.detail().ID {
+		return fmt.Errorf("static IP '%s' is not associated with server '%s'", ip.Address, c.detail().Name)
+	}
+
+	logger.TraceMessage(
+		"Disassociating floating IP '%s' from server '%s'.",
+		ip.Address, c.detail().Name,
+	)
+
+	noPort := ""
+	if _, err = floatingips.Update(client, ip.ID, floatingips.UpdateOpts{
+		PortID: &noPort,
+	}).Extract(); err != nil {
+		return err
+	}
+	return c.waitForPublicIP(func(publicIP string) bool {
+		return publicIP != ip.Address
+	})
+}
+
+// the addresses of a server are updated asynchronously once
+// a floating ip has been associated with or disassociated
+// from it so they are polled until the change is reflected
+func (c *openStackComputeInstance) waitForPublicIP(updated func(publicIP string) bool) error {
+
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		for {
+			if err := c.refresh(); err != nil {
+				return err
+			}
+			if updated(c.PublicIP()) {
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf(
+					"timed out waiting for the addresses of server '%s' to be updated: %s",
+					c.detail().Name, ctx.Err(),
+				)
+			case <-time.After(statePollInterval):
+			}
+		}
+	}).Wait(context.Background())
+}
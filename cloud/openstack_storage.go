@@ -0,0 +1,249 @@
+package cloud
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/objectstorage/v1/containers"
+	"github.com/gophercloud/gophercloud/openstack/objectstorage/v1/objects"
+	"github.com/gophercloud/gophercloud/pagination"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+type OpenStackStorageProperties struct {
+	// size of buffer used when
+	// downloading an object
+	BlockSize int
+}
+
+type openStackStorage struct {
+	client *gophercloud.ServiceClient
+
+	props OpenStackStorageProperties
+}
+
+type openStackStorageInstance struct {
+	name   string
+	client *gophercloud.ServiceClient
+
+	props *OpenStackStorageProperties
+}
+
+func NewOpenStackStorage(client *gophercloud.ServiceClient) (Storage, error) {
+
+	return &openStackStorage{
+		client: client,
+
+		props: OpenStackStorageProperties{
+			BlockSize: 5 * 1024 * 1024, // 5MB
+		},
+	}, nil
+}
+
+// returns whether the given error
+// is a not found error
+func isOpenStackNotFound(err error) bool {
+	var notFound gophercloud.ErrDefault404
+	return errors.As(err, &notFound)
+}
+
+// interface: cloud/Storage implementation
+
+func (s *openStackStorage) SetProperties(props interface{}) {
+
+	p := props.(OpenStackStorageProperties)
+	if p.BlockSize > 0 {
+		s.props.BlockSize = p.BlockSize
+	}
+}
+
+func (s *openStackStorage) NewInstance(name string) (StorageInstance, error) {
+
+	var (
+		err error
+	)
+
+	if _, err = containers.Get(s.client, name, nil).Extract(); err != nil {
+		if !isOpenStackNotFound(err) {
+			return nil, err
+		}
+
+		logger.TraceMessage(
+			"Container '%s' was not found so creating it.",
+			name)
+
+		if _, err = containers.Create(s.client, name, nil).Extract(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &openStackStorageInstance{
+		name:   name,
+		client: s.client,
+
+		props: &s.props,
+	}, nil
+}
+
+func (s *openStackStorage) ListInstances() ([]StorageInstance, error) {
+
+	var (
+		err error
+	)
+
+	instances := []StorageInstance{}
+	if err = containers.List(s.client, nil).EachPage(func(page pagination.Page) (bool, error) {
+		names, err := containers.ExtractNames(page)
+		if err != nil {
+			return false, err
+		}
+		for _, name := range names {
+			instances = append(instances, &openStackStorageInstance{
+				name:   name,
+				client: s.client,
+
+				props: &s.props,
+			})
+		}
+		return true, nil
+	}); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// interface: cloud/StorageInstance implementation
+
+func (s *openStackStorageInstance) Name() string {
+	return s.name
+}
+
+// The container must be empty
+// for it to be deleted.
+func (s *openStackStorageInstance) Delete() error {
+
+	logger.TraceMessage("Deleting container '%s'.", s.name)
+
+	_, err := containers.Delete(s.client, s.name).Extract()
+	return err
+}
+
+func (s *openStackStorageInstance) ListObjects(path string) ([]string, error) {
+
+	var (
+		err error
+	)
+
+	objectList := []string{}
+	if err = objects.List(s.client, s.name, objects.ListOpts{
+		Prefix: path,
+	}).EachPage(func(page pagination.Page) (bool, error) {
+		names, err := objects.ExtractNames(page)
+		if err != nil {
+			return false, err
+		}
+		objectList = append(objectList, names...)
+		return true, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.TraceMessage(
+		"Retrieved list of objects in container '%s' filtered by path '%s': %# v",
+		s.name, path, objectList)
+
+	return objectList, nil
+}
+
+func (s *openStackStorageInstance) DeleteObject(name string) error {
+
+	logger.TraceMessage(
+		"Deleting object '%s' in container '%s'.",
+		name, s.name)
+
+	_, err := objects.Delete(s.client, s.name, name, nil).Extract()
+	return err
+}
+
+// Objects are uploaded in a single request so
+// the size of an object is limited to the
+// cluster's maximum object size (5GB).
+func (s *openStackStorageInstance) Upload(name, contentType string, data io.Reader, size int64) error {
+
+	logger.TraceMessage(
+		"Uploading object with name '%s' of size %d to container '%s'.",
+		name, size, s.name)
+
+	_, err := objects.Create(s.client, s.name, name, objects.CreateOpts{
+		Content:       data,
+		ContentType:   contentType,
+		ContentLength: size,
+	}).Extract()
+	return err
+}
+
+func (s *openStackStorageInstance) UploadFile(name, contentType, path string) error {
+
+	var (
+		err error
+
+		file     *os.File
+		fileInfo os.FileInfo
+	)
+
+	if file, err = os.Open(path); err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if fileInfo, err = file.Stat(); err != nil {
+		return err
+	}
+	return s.Upload(name, contentType, file, fileInfo.Size())
+}
+
+func (s *openStackStorageInstance) Download(name string, data io.Writer) error {
+
+	var (
+		err error
+	)
+	logger.TraceMessage(
+		"Downloading object with name '%s' from container '%s'.",
+		name, s.name)
+
+	result := objects.Download(s.client, s.name, name, nil)
+	if result.Err != nil {
+		return result.Err
+	}
+	defer result.Body.Close()
+
+	_, err = io.CopyBuffer(
+		data,
+		result.Body,
+		make([]byte, s.props.BlockSize),
+	)
+	return err
+}
+
+func (s *openStackStorageInstance) DownloadFile(name, path string) error {
+
+	var (
+		err error
+
+		file *os.File
+	)
+
+	if file, err = os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0644); err != nil {
+		return err
+	}
+	defer file.Close()
+
+	logger.TraceMessage(
+		"Downloading object with name '%s' from container '%s' to path '%s'.",
+		name, s.name, path)
+
+	return s.Download(name, file)
+}
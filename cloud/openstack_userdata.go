@@ -0,0 +1,135 @@
+package cloud
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// compute api microversions that return the
+// user data of a server and that allow it to
+// be replaced when the server is rebuilt
+const (
+	openStackUserDataMicroversion        = "2.3"
+	openStackRebuildUserDataMicroversion = "2.57"
+)
+
+// rebuild options that include the user data
+// which gophercloud's rebuild options do not
+type openStackRebuildOpts struct {
+	servers.RebuildOpts
+
+	UserData string
+}
+
+func (opts openStackRebuildOpts) ToServerRebuildMap() (map[string]interface{}, error) {
+
+	b, err := opts.RebuildOpts.ToServerRebuildMap()
+	if err != nil {
+		return nil, err
+	}
+	b["rebuild"].(map[string]interface{})["user_data"] =
+		base64.StdEncoding.EncodeToString([]byte(opts.UserData))
+	return b, nil
+}
+
+// returns a copy of the compute client
+// that uses the given api microversion
+func (c *openStackComputeInstance) computeClient(microversion string) *gophercloud.ServiceClient {
+	client := *c.clients.compute
+	client.Microversion = microversion
+	return &client
+}
+
+// interface: cloud/ComputeInstance implementation
+
+// The user data of a server is only returned by
+// the compute api to administrators of the cloud.
+func (c *openStackComputeInstance) UserData() (string, error) {
+
+	var (
+		err error
+
+		data []byte
+	)
+
+	var result struct {
+		Server struct {
+			UserData *string `json:"OS-EXT-SRV-ATTR:user_data"`
+		} `json:"server"`
+	}
+	if err = servers.Get(
+		c.computeClient(openStackUserDataMicroversion),
+		c.detail().ID,
+	).ExtractInto(&result); err != nil {
+		return "", err
+	}
+	if result.Server.UserData == nil {
+		return "", fmt.Errorf(
+			"the user data of server '%s' is only returned to administrators of the cloud",
+			c.detail().Name,
+		)
+	}
+	if data, err = base64.StdEncoding.DecodeString(*result.Server.UserData); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// The user data of a server can only be changed by
+// rebuilding it from its image which replaces its
+// disk. Servers booted from volumes cannot be rebuilt.
+func (c *openStackComputeInstance) SetUserData(data string) error {
+
+	var (
+		err error
+
+		state InstanceState
+	)
+
+	server := c.detail()
+	imageID, _ := server.Image["id"].(string)
+	if len(imageID) == 0 {
+		return fmt.Errorf(
+			"the user data of server '%s' cannot be changed as it was not booted from an image",
+			server.Name,
+		)
+	}
+	if state, err = c.State(); err != nil {
+		return err
+	}
+
+	logger.TraceMessage("Rebuilding server '%s' with new user data.", server.Name)
+
+	if err = servers.Rebuild(
+		c.computeClient(openStackRebuildUserDataMicroversion),
+		server.ID,
+		openStackRebuildOpts{
+			RebuildOpts: servers.RebuildOpts{
+				ImageRef: imageID,
+			},
+			UserData: data,
+		},
+	).Err; err != nil {
+		return err
+	}
+	return newOperation(c.props.OpTimeout, func(ctx context.Context) error {
+		// the server's status changes to
+		// REBUILD once the rebuild starts
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"timed out waiting for server '%s' to be rebuilt: %s",
+				server.Name, ctx.Err(),
+			)
+		case <-time.After(statePollInterval):
+		}
+		return waitForState(ctx, c, state, c.props.OpTimeout)
+	}).Wait(context.Background())
+}
@@ -0,0 +1,285 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/snapshots"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/volumeattach"
+	"github.com/gophercloud/gophercloud/pagination"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+type openStackVolume struct {
+	client *gophercloud.ServiceClient
+
+	volume *volumes.Volume
+
+	props *OpenStackComputeProperties
+}
+
+func newOpenStackVolume(
+	client *gophercloud.ServiceClient,
+	volume *volumes.Volume,
+	props *OpenStackComputeProperties,
+) *openStackVolume {
+
+	return &openStackVolume{
+		client: client,
+		volume: volume,
+
+		props: props,
+	}
+}
+
+// reloads the volume's state
+func (v *openStackVolume) refresh() error {
+
+	volume, err := volumes.Get(v.client, v.volume.ID).Extract()
+	if err != nil {
+		return err
+	}
+	v.volume = volume
+	return nil
+}
+
+// waits for the volume to reach the given status
+func (v *openStackVolume) waitForStatus(status string) error {
+
+	return newOperation(v.props.OpTimeout, func(ctx context.Context) error {
+		for {
+			if err := v.refresh(); err != nil {
+				return err
+			}
+			switch v.volume.Status {
+			case status:
+				return nil
+			case "error", "error_deleting", "error_extending":
+				return fmt.Errorf(
+					"volume '%s' is in status '%s'",
+					v.volume.Name, v.volume.Status,
+				)
+			}
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf(
+					"volume '%s' timed out waiting for status '%s': %s",
+					v.volume.Name, status, ctx.Err(),
+				)
+			case <-time.After(statePollInterval):
+			}
+		}
+	}).Wait(context.Background())
+}
+
+// interface: cloud/Compute implementation
+
+// The volume is created in the cloud's default availability
+// zone if a zone is not given. If a type is not given the
+// cloud's default volume type is used.
+func (c *openStackCompute) CreateVolume(spec VolumeSpec) (Volume, error) {
+
+	var (
+		err error
+
+		client *gophercloud.ServiceClient
+		volume *volumes.Volume
+	)
+
+	if client, err = c.clients.volume(); err != nil {
+		return nil, err
+	}
+
+	logger.TraceMessage(
+		"Creating %dGB volume '%s' in zone '%s'.",
+		spec.SizeGB, spec.Name, spec.Zone,
+	)
+
+	if volume, err = volumes.Create(client, volumes.CreateOpts{
+		Name:             spec.Name,
+		Size:             spec.SizeGB,
+		VolumeType:       spec.Type,
+		AvailabilityZone: spec.Zone,
+		Metadata:         spec.Tags,
+	}).Extract(); err != nil {
+		return nil, err
+	}
+	v := newOpenStackVolume(client, volume, &c.props)
+	if err = v.waitForStatus("available"); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// interface: cloud/ComputeInstance implementation
+
+func (c *openStackComputeInstance) Volumes() ([]Volume, error) {
+
+	var (
+		err error
+
+		client      *gophercloud.ServiceClient
+		page        pagination.Page
+		attachments []volumeattach.VolumeAttachment
+		volume      *volumes.Volume
+	)
+
+	if client, err = c.clients.volume(); err != nil {
+		return nil, err
+	}
+	if page, err = volumeattach.List(c.clients.compute, c.detail().ID).AllPages(); err != nil {
+		return nil, err
+	}
+	if attachments, err = volumeattach.ExtractVolumeAttachments(page); err != nil {
+		return nil, err
+	}
+	vols := make([]Volume, 0, len(attachments))
+	for _, attachment := range attachments {
+		if volume, err = volumes.Get(client, attachment.VolumeID).Extract(); err != nil {
+			return nil, err
+		}
+		vols = append(vols, newOpenStackVolume(client, volume, c.props))
+	}
+	return vols, nil
+}
+
+func (c *openStackComputeInstance) AttachVolume(volume Volume) error {
+
+	var (
+		err error
+		ok  bool
+
+		v *openStackVolume
+	)
+
+	if v, ok = volume.(*openStackVolume); !ok {
+		return fmt.Errorf("volume '%s' is not an openstack volume", volume.Name())
+	}
+
+	logger.TraceMessage(
+		"Attaching volume '%s' to server '%s'.",
+		v.volume.Name, c.detail().Name,
+	)
+
+	if _, err = volumeattach.Create(c.clients.compute, c.detail().ID, volumeattach.CreateOpts{
+		VolumeID: v.volume.ID,
+	}).Extract(); err != nil {
+		return err
+	}
+	return v.waitForStatus("in-use")
+}
+
+func (c *openStackComputeInstance) DetachVolume(volume Volume) error {
+
+	var (
+		err error
+		ok  bool
+
+		v *openStackVolume
+	)
+
+	if v, ok = volume.(*openStackVolume); !ok {
+		return fmt.Errorf("volume '%s' is not an openstack volume", volume.Name())
+	}
+	if v.Boot() {
+		return fmt.Errorf("the boot volume of server '%s' cannot be detached", c.detail().Name)
+	}
+
+	logger.TraceMessage(
+		"Detaching volume '%s' from server '%s'.",
+		v.volume.Name, c.detail().Name,
+	)
+
+	if err = volumeattach.Delete(c.clients.compute, c.detail().ID, v.volume.ID).ExtractErr(); err != nil {
+		return err
+	}
+	return v.waitForStatus("available")
+}
+
+// interface: cloud/Volume implementation
+
+func (v *openStackVolume) ID() string {
+	return v.volume.ID
+}
+
+func (v *openStackVolume) Name() string {
+	return v.volume.Name
+}
+
+func (v *openStackVolume) SizeGB() int {
+	return v.volume.Size
+}
+
+func (v *openStackVolume) Type() string {
+	return v.volume.VolumeType
+}
+
+func (v *openStackVolume) Zone() string {
+	return v.volume.AvailabilityZone
+}
+
+func (v *openStackVolume) Attached() bool {
+	return len(v.volume.Attachments) > 0
+}
+
+// A volume is the boot volume of the server it is
+// attached to if it is bootable and it is attached as
+// the server's root device. Servers booted from an
+// image have an ephemeral root disk that is not a
+// volume.
+func (v *openStackVolume) Boot() bool {
+	if v.volume.Bootable != "true" {
+		return false
+	}
+	for _, attachment := range v.volume.Attachments {
+		switch attachment.Device {
+		case "/dev/vda", "/dev/sda", "/dev/xvda":
+			return true
+		}
+	}
+	return false
+}
+
+// The snapshot is forced so that volumes attached
+// to a running server can be snapshotted.
+func (v *openStackVolume) Snapshot() (VolumeSnapshot, error) {
+
+	var (
+		err error
+
+		snapshot *snapshots.Snapshot
+	)
+
+	name := snapshotName(v.volume.Name)
+
+	logger.TraceMessage(
+		"Creating snapshot '%s' of volume '%s'.", name, v.volume.Name)
+
+	if snapshot, err = snapshots.Create(v.client, snapshots.CreateOpts{
+		VolumeID:    v.volume.ID,
+		Name:        name,
+		Description: fmt.Sprintf("snapshot of volume '%s'", v.volume.Name),
+		Metadata:    v.volume.Metadata,
+		Force:       true,
+	}).Extract(); err != nil {
+		return VolumeSnapshot{}, err
+	}
+	return VolumeSnapshot{
+		ID:       snapshot.ID,
+		Name:     name,
+		VolumeID: v.volume.ID,
+		SizeGB:   v.volume.Size,
+	}, nil
+}
+
+func (v *openStackVolume) Delete() error {
+
+	logger.TraceMessage("Deleting volume '%s'.", v.volume.Name)
+
+	return volumes.Delete(v.client, v.volume.ID, nil).ExtractErr()
+}
@@ -0,0 +1,105 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// default timeout for compute
+// operations for all clouds
+const defaultOpTimeout = time.Minute * 5
+
+// interval at which an instance's
+// state is polled when waiting on it
+const statePollInterval = time.Second * 5
+
+type operation struct {
+	done chan struct{}
+	err  error
+}
+
+// in: timeout after which the operation's context is cancelled
+// in: the function to run asynchronously
+// out: a handle to the running operation
+func newOperation(
+	timeout time.Duration,
+	run func(ctx context.Context) error,
+) Operation {
+
+	op := &operation{
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(op.done)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		op.err = run(ctx)
+	}()
+	return op
+}
+
+// polls the state of the given instance until it reaches the
+// given state, the timeout expires or the context is cancelled
+func waitForState(
+	ctx context.Context,
+	instance ComputeInstance,
+	state InstanceState,
+	timeout time.Duration,
+) error {
+
+	var (
+		err error
+
+		currentState InstanceState
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		if currentState, err = instance.State(); err != nil {
+			return err
+		}
+		if currentState == state {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"instance '%s' timed out waiting for state '%s': %s",
+				instance.Name(), state, ctx.Err(),
+			)
+		case <-time.After(statePollInterval):
+		}
+	}
+}
+
+// interface: cloud/Operation implementation
+
+func (o *operation) Wait(ctx context.Context) error {
+
+	select {
+	case <-o.done:
+		return o.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (o *operation) Done() <-chan struct{} {
+	return o.done
+}
+
+func (o *operation) Err() error {
+
+	select {
+	case <-o.done:
+		return o.err
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,129 @@
+package cloud_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/mevansam/gocloud/cloud"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_mocks "github.com/mevansam/gocloud/test/mocks"
+)
+
+var _ = Describe("Asynchronous Operations", func() {
+
+	It("returns the result of the operation once it is done", func() {
+
+		release := make(chan struct{})
+		op := cloud.NewOperation(time.Minute, func(ctx context.Context) error {
+			<-release
+			return fmt.Errorf("operation failed")
+		})
+		Consistently(op.Done(), "100ms").ShouldNot(BeClosed())
+		Expect(op.Err()).NotTo(HaveOccurred())
+
+		close(release)
+		Eventually(op.Done()).Should(BeClosed())
+		Expect(op.Err()).To(MatchError("operation failed"))
+		Expect(op.Wait(context.Background())).To(MatchError("operation failed"))
+
+		op = cloud.NewOperation(time.Minute, func(ctx context.Context) error {
+			return nil
+		})
+		Expect(op.Wait(context.Background())).To(Succeed())
+		Expect(op.Err()).NotTo(HaveOccurred())
+	})
+
+	It("cancels the context of the operation once it times out", func() {
+
+		op := cloud.NewOperation(time.Millisecond*100, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		Expect(op.Wait(context.Background())).To(MatchError(context.DeadlineExceeded))
+		Expect(op.Err()).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("stops waiting without cancelling the operation", func() {
+
+		var completed int32
+
+		release := make(chan struct{})
+		op := cloud.NewOperation(time.Minute, func(ctx context.Context) error {
+			select {
+			case <-release:
+				atomic.StoreInt32(&completed, 1)
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		Expect(op.Wait(ctx)).To(MatchError(context.DeadlineExceeded))
+		Expect(op.Err()).NotTo(HaveOccurred())
+
+		close(release)
+		Expect(op.Wait(context.Background())).To(Succeed())
+		Expect(atomic.LoadInt32(&completed)).To(Equal(int32(1)))
+	})
+
+	It("can be waited on concurrently", func() {
+
+		op := cloud.NewOperation(time.Minute, func(ctx context.Context) error {
+			time.Sleep(time.Millisecond * 50)
+			return fmt.Errorf("operation failed")
+		})
+
+		errs := make(chan error, 5)
+		for i := 0; i < 5; i++ {
+			go func() {
+				errs <- op.Wait(context.Background())
+			}()
+		}
+		for i := 0; i < 5; i++ {
+			Eventually(errs).Should(Receive(MatchError("operation failed")))
+		}
+	})
+
+	Context("waiting for the state of an instance", func() {
+
+		var (
+			instance *test_mocks.FakeComputeInstance
+		)
+
+		BeforeEach(func() {
+			instance = &test_mocks.FakeComputeInstance{}
+			instance.SetValues("test-id", "test", "", cloud.StateRunning)
+		})
+
+		It("returns once the instance is in the state", func() {
+			err := cloud.WaitForState(context.Background(), instance, cloud.StateRunning, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("times out if the instance does not reach the state", func() {
+			err := cloud.WaitForState(context.Background(), instance, cloud.StateStopped, time.Millisecond*100)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("timed out waiting for state 'stopped'"))
+		})
+
+		It("returns once the context of the operation is cancelled", func() {
+
+			op := cloud.NewOperation(time.Millisecond*100, func(ctx context.Context) error {
+				return cloud.WaitForState(ctx, instance, cloud.StateStopped, time.Minute)
+			})
+
+			start := time.Now()
+			err := op.Wait(context.Background())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(context.DeadlineExceeded.Error()))
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second*5))
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package cloud
+
+import (
+	"fmt"
+	"sort"
+)
+
+// in: the instance to resize
+// in: whether the instance can be stopped and restarted to resize it
+// in: whether the cloud requires the instance to be stopped to resize it
+// in: function that changes the size of the instance
+func resizeInstance(
+	instance ComputeInstance,
+	autoRestart, requiresStop bool,
+	resize func() error,
+) error {
+
+	var (
+		err error
+
+		state InstanceState
+	)
+
+	if state, err = instance.State(); err != nil {
+		return err
+	}
+	if state == StatePending {
+		return fmt.Errorf(
+			"instance '%s' cannot be resized while it is transitioning between states",
+			instance.Name(),
+		)
+	}
+
+	restart := state == StateRunning && requiresStop
+	if restart {
+		if !autoRestart {
+			return fmt.Errorf(
+				"instance '%s' must be stopped before it can be resized",
+				instance.Name(),
+			)
+		}
+		if err = instance.Stop(); err != nil {
+			return err
+		}
+	}
+	if err = resize(); err != nil {
+		if restart {
+			// restart the instance with its original
+			// size so it is not left stopped
+			if startErr := instance.Start(); startErr != nil {
+				return fmt.Errorf(
+					"%s: instance '%s' could not be restarted after the resize failed: %s",
+					err.Error(), instance.Name(), startErr.Error(),
+				)
+			}
+		}
+		return err
+	}
+	if restart {
+		return instance.Start()
+	}
+	return nil
+}
+
+// sorts the given instance sizes
+// in ascending order of name
+func sortInstanceSizes(sizes []InstanceSize) {
+	sort.Slice(sizes, func(i, j int) bool {
+		return sizes[i].Name < sizes[j].Name
+	})
+}
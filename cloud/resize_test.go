@@ -0,0 +1,152 @@
+package cloud_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/mevansam/gocloud/cloud"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	test_mocks "github.com/mevansam/gocloud/test/mocks"
+)
+
+// fake instance that records
+// the stops and starts to resize it
+type fakeResizeInstance struct {
+	*test_mocks.FakeComputeInstance
+
+	state    cloud.InstanceState
+	startErr error
+
+	calls []string
+}
+
+func (i *fakeResizeInstance) State() (cloud.InstanceState, error) {
+	return i.state, nil
+}
+
+func (i *fakeResizeInstance) Stop() error {
+	i.calls = append(i.calls, "stop")
+	i.state = cloud.StateStopped
+	return nil
+}
+
+func (i *fakeResizeInstance) Start() error {
+	i.calls = append(i.calls, "start")
+	if i.startErr != nil {
+		return i.startErr
+	}
+	i.state = cloud.StateRunning
+	return nil
+}
+
+var _ = Describe("Instance Resizing", func() {
+
+	var (
+		instance *fakeResizeInstance
+	)
+
+	BeforeEach(func() {
+		instance = &fakeResizeInstance{
+			FakeComputeInstance: &test_mocks.FakeComputeInstance{},
+			state:               cloud.StateRunning,
+		}
+		instance.SetValues("test-id", "test", "", cloud.StateRunning)
+	})
+
+	resize := func() error {
+		instance.calls = append(instance.calls, "resize")
+		return nil
+	}
+	failedResize := func() error {
+		instance.calls = append(instance.calls, "resize")
+		return fmt.Errorf("size is not available")
+	}
+
+	It("stops and restarts a running instance to resize it", func() {
+		err := cloud.ResizeInstance(instance, true, true, resize)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instance.calls).To(Equal([]string{"stop", "resize", "start"}))
+		Expect(instance.state).To(Equal(cloud.StateRunning))
+	})
+
+	It("does not stop a running instance that does not need to be stopped", func() {
+		err := cloud.ResizeInstance(instance, true, false, resize)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instance.calls).To(Equal([]string{"resize"}))
+	})
+
+	It("does not stop a running instance without auto restart", func() {
+		err := cloud.ResizeInstance(instance, false, true, resize)
+		Expect(err).To(HaveOccurred())
+		Expect(instance.calls).To(BeEmpty())
+	})
+
+	It("does not resize an instance that is transitioning between states", func() {
+		instance.state = cloud.StatePending
+		err := cloud.ResizeInstance(instance, true, true, resize)
+		Expect(err).To(HaveOccurred())
+		Expect(instance.calls).To(BeEmpty())
+	})
+
+	It("restarts the instance when the resize fails", func() {
+		err := cloud.ResizeInstance(instance, true, true, failedResize)
+		Expect(err).To(MatchError("size is not available"))
+		Expect(instance.calls).To(Equal([]string{"stop", "resize", "start"}))
+		Expect(instance.state).To(Equal(cloud.StateRunning))
+	})
+
+	It("returns both errors when the instance cannot be restarted after the resize fails", func() {
+		instance.startErr = fmt.Errorf("start failed")
+		err := cloud.ResizeInstance(instance, true, true, failedResize)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("size is not available"))
+		Expect(err.Error()).To(ContainSubstring("start failed"))
+	})
+
+	It("does not start a stopped instance when the resize fails", func() {
+		instance.state = cloud.StateStopped
+		err := cloud.ResizeInstance(instance, true, true, failedResize)
+		Expect(err).To(MatchError("size is not available"))
+		Expect(instance.calls).To(Equal([]string{"resize"}))
+	})
+
+	DescribeTable("detects azure sizes that are not available on a VM's hardware cluster",
+		func(status int, body string, expected bool) {
+			err := runtime.NewResponseError(&http.Response{
+				StatusCode: status,
+				Status:     http.StatusText(status),
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request: &http.Request{
+					Method: http.MethodPatch,
+					URL:    &url.URL{Scheme: "https", Host: "management.azure.com", Path: "/vm"},
+				},
+			})
+			Expect(cloud.IsAzureSizeNotAvailable(err)).To(Equal(expected))
+		},
+		Entry("size not on the hardware cluster", http.StatusConflict,
+			`{"error":{"code":"OperationNotAllowed","message":"The requested VM size Standard_D4s_v3 is not available in the current hardware cluster."}}`,
+			true),
+		Entry("allocation failure", http.StatusConflict,
+			`{"error":{"code":"AllocationFailed","message":"Allocation failed."}}`,
+			true),
+		Entry("other operation not allowed", http.StatusConflict,
+			`{"error":{"code":"OperationNotAllowed","message":"Operation is not allowed while the VM is being updated."}}`,
+			false),
+		Entry("invalid size", http.StatusBadRequest,
+			`{"error":{"code":"InvalidParameter","message":"The value Standard_X of parameter vmSize is not allowed."}}`,
+			false),
+	)
+
+	It("does not detect other errors as unavailable azure sizes", func() {
+		Expect(cloud.IsAzureSizeNotAvailable(fmt.Errorf("hardware cluster"))).To(BeFalse())
+	})
+})
@@ -0,0 +1,119 @@
+package cloud_test
+
+import (
+	"os"
+
+	"github.com/google/uuid"
+
+	"github.com/mevansam/goutils/logger"
+
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/gocloud/provider"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_helpers "github.com/mevansam/gocloud/test/helpers"
+)
+
+var _ = Describe("S3 Compatible Storage Tests", func() {
+
+	var (
+		err error
+
+		s3CompatibleProvider provider.CloudProvider
+		s3CompatibleStorage  cloud.Storage
+	)
+
+	BeforeEach(func() {
+
+		s3CompatibleProvider, err = provider.NewCloudProvider("s3compatible")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s3CompatibleProvider).ToNot(BeNil())
+
+		test_helpers.InitializeS3CompatibleProvider(s3CompatibleProvider)
+
+		err = s3CompatibleProvider.Connect()
+		Expect(err).NotTo(HaveOccurred())
+
+		s3CompatibleStorage, err = s3CompatibleProvider.GetStorage()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s3CompatibleStorage).ToNot(BeNil())
+
+		s3CompatibleStorage.SetProperties(cloud.AWSStorageProperties{
+			BlockSize: fiveMB,
+		})
+	})
+
+	It("creates, lists and deletes instances", func() {
+		testInstanceCreation(s3CompatibleStorage)
+	})
+
+	Context("uploading and downloading data from a container", func() {
+
+		var (
+			storageInstance cloud.StorageInstance
+		)
+
+		BeforeEach(func() {
+			containerName := "test-" + uuid.New().String()
+			storageInstance, err = s3CompatibleStorage.NewInstance(containerName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storageInstance.Name()).To(Equal(containerName))
+		})
+
+		AfterEach(func() {
+			err = storageInstance.Delete()
+			if err != nil {
+				logger.DebugMessage(
+					"S3 compatible storage test tear down error while deleting storage instance with name '%s': %s",
+					storageInstance.Name(), err.Error())
+			}
+		})
+
+		It("uploads a few blobs and validates them", func() {
+			testObjectUploadAndDownload(storageInstance)
+		})
+	})
+
+	Context("uploading and downloading files from a container", func() {
+
+		var (
+			tmpDir      string
+			tmpFiles    map[string]string
+			tmpFileData map[string]string
+
+			containerName   string
+			storageInstance cloud.StorageInstance
+		)
+
+		BeforeEach(func() {
+			tmpDir, err = os.MkdirTemp("", "s3compatiblestoragetest")
+			Expect(err).NotTo(HaveOccurred())
+
+			tmpFiles = make(map[string]string)
+			tmpFileData = make(map[string]string)
+			createTestFiles(tmpDir, tmpFiles, tmpFileData, fiveMB)
+
+			containerName = "test-" + uuid.New().String()
+			storageInstance, err = s3CompatibleStorage.NewInstance(containerName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storageInstance.Name()).To(Equal(containerName))
+		})
+
+		AfterEach(func() {
+			err = storageInstance.Delete()
+			if err != nil {
+				logger.DebugMessage(
+					"S3 compatible storage test tear down error while deleting storage instance with name '%s': %s",
+					storageInstance.Name(), err.Error())
+			}
+
+			os.RemoveAll(tmpDir)
+		})
+
+		It("uploads large files with path names and validates them", func() {
+			testFileUploadAndDownload(storageInstance, tmpFiles, tmpFileData)
+		})
+	})
+})
@@ -0,0 +1,116 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	cryptossh "golang.org/x/crypto/ssh"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// Forwards connections to the given local address to the
+// remote address via the instance until the context is
+// done. The remote address is resolved on the instance
+// so "localhost:<port>" will reach a service listening
+// only on the instance's loopback interface. The
+// returned listener's address can be used to
+// determine the local port if port 0 was given.
+func (c *Client) Forward(ctx context.Context, localAddress, remoteAddress string) (net.Listener, error) {
+
+	var (
+		err error
+
+		client   *cryptossh.Client
+		listener net.Listener
+	)
+
+	// the listener is closed when the client is
+	// closed and the connection it was created with
+	// is used for all of its forwarded connections
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if client = c.client; client == nil {
+		return nil, fmt.Errorf(
+			"ssh client is not connected to instance '%s'", c.instance.Name(),
+		)
+	}
+	if listener, err = net.Listen("tcp", localAddress); err != nil {
+		return nil, err
+	}
+	c.listeners = append(c.listeners, listener)
+
+	go func() {
+		<-ctx.Done()
+		c.removeListener(listener)
+	}()
+
+	go func() {
+		for {
+			localConn, err := listener.Accept()
+			if err != nil {
+				logger.TraceMessage(
+					"Stopped forwarding '%s' to '%s' via instance '%s': %s",
+					listener.Addr().String(), remoteAddress, c.instance.Name(), err.Error(),
+				)
+				return
+			}
+			go c.forward(client, localConn, remoteAddress)
+		}
+	}()
+
+	return listener, nil
+}
+
+func (c *Client) forward(client *cryptossh.Client, localConn net.Conn, remoteAddress string) {
+
+	var (
+		err error
+		wg  sync.WaitGroup
+
+		remoteConn net.Conn
+	)
+	defer localConn.Close()
+
+	if remoteConn, err = client.Dial("tcp", remoteAddress); err != nil {
+		logger.DebugMessage(
+			"Failed to connect to '%s' via instance '%s': %s",
+			remoteAddress, c.instance.Name(), err.Error(),
+		)
+		return
+	}
+	defer remoteConn.Close()
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(remoteConn, localConn)
+		remoteConn.Close()
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(localConn, remoteConn)
+		localConn.Close()
+	}()
+	wg.Wait()
+}
+
+// closes the given forwarding listener and
+// removes it from the listeners of the client
+func (c *Client) removeListener(listener net.Listener) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	listener.Close()
+	for i, l := range c.listeners {
+		if l == listener {
+			c.listeners = append(c.listeners[:i], c.listeners[i+1:]...)
+			break
+		}
+	}
+}
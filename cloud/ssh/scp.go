@@ -0,0 +1,213 @@
+package ssh
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	cryptossh "golang.org/x/crypto/ssh"
+
+	"github.com/mevansam/goutils/logger"
+)
+
+// Uploads the data read from the given reader to the remote
+// path on the instance using the scp protocol. The remote
+// scp program must be available on the instance.
+func (c *Client) Upload(data io.Reader, size int64, remotePath string, mode os.FileMode) error {
+
+	var (
+		err error
+
+		session *cryptossh.Session
+
+		stdin  io.WriteCloser
+		stdout io.Reader
+	)
+
+	if session, err = c.newSession(); err != nil {
+		return err
+	}
+	defer session.Close()
+
+	if stdin, err = session.StdinPipe(); err != nil {
+		return err
+	}
+	if stdout, err = session.StdoutPipe(); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(stdout)
+
+	logger.TraceMessage(
+		"Uploading %d bytes to '%s' on instance '%s'.",
+		size, remotePath, c.instance.Name(),
+	)
+
+	if err = session.Start("scp -qt " + shellQuote(path.Dir(remotePath))); err != nil {
+		return err
+	}
+	if err = scpAck(reader); err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(stdin, "C%04o %d %s\n", mode.Perm(), size, path.Base(remotePath)); err != nil {
+		return err
+	}
+	if err = scpAck(reader); err != nil {
+		return err
+	}
+	if _, err = io.CopyN(stdin, data, size); err != nil {
+		return err
+	}
+	if _, err = stdin.Write([]byte{0}); err != nil {
+		return err
+	}
+	if err = scpAck(reader); err != nil {
+		return err
+	}
+	stdin.Close()
+
+	return session.Wait()
+}
+
+// Uploads the local file at the given path to
+// the remote path on the instance
+func (c *Client) UploadFile(localPath, remotePath string) error {
+
+	var (
+		err error
+
+		file *os.File
+		info os.FileInfo
+	)
+
+	if file, err = os.Open(localPath); err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if info, err = file.Stat(); err != nil {
+		return err
+	}
+	return c.Upload(file, info.Size(), remotePath, info.Mode())
+}
+
+// Downloads the file at the remote path on the
+// instance writing it to the given writer
+func (c *Client) Download(remotePath string, data io.Writer) error {
+
+	var (
+		err error
+
+		session *cryptossh.Session
+
+		stdin  io.WriteCloser
+		stdout io.Reader
+
+		header string
+		size   int64
+	)
+
+	if session, err = c.newSession(); err != nil {
+		return err
+	}
+	defer session.Close()
+
+	if stdin, err = session.StdinPipe(); err != nil {
+		return err
+	}
+	if stdout, err = session.StdoutPipe(); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(stdout)
+
+	logger.TraceMessage(
+		"Downloading '%s' from instance '%s'.",
+		remotePath, c.instance.Name(),
+	)
+
+	if err = session.Start("scp -qf " + shellQuote(remotePath)); err != nil {
+		return err
+	}
+	if _, err = stdin.Write([]byte{0}); err != nil {
+		return err
+	}
+
+	// file header is "C<mode> <size> <name>\n"
+	if header, err = reader.ReadString('\n'); err != nil {
+		return err
+	}
+	if header[0] != 'C' {
+		return fmt.Errorf("scp download of '%s' failed: %s", remotePath, strings.TrimSpace(header[1:]))
+	}
+	fields := strings.SplitN(strings.TrimSpace(header), " ", 3)
+	if len(fields) != 3 {
+		return fmt.Errorf("invalid scp file header: %s", header)
+	}
+	if size, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+		return err
+	}
+	if _, err = stdin.Write([]byte{0}); err != nil {
+		return err
+	}
+
+	if _, err = io.CopyN(data, reader, size); err != nil {
+		return err
+	}
+	if err = scpAck(reader); err != nil {
+		return err
+	}
+	if _, err = stdin.Write([]byte{0}); err != nil {
+		return err
+	}
+	stdin.Close()
+
+	return session.Wait()
+}
+
+// Downloads the file at the remote path on the
+// instance to the given local path
+func (c *Client) DownloadFile(remotePath, localPath string) error {
+
+	var (
+		err error
+
+		file *os.File
+	)
+
+	if file, err = os.Create(localPath); err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return c.Download(remotePath, file)
+}
+
+// reads an scp acknowledgement which is a 0 byte
+// on success or an error code followed by a message
+func scpAck(reader *bufio.Reader) error {
+
+	var (
+		err error
+
+		code    byte
+		message string
+	)
+
+	if code, err = reader.ReadByte(); err != nil {
+		return err
+	}
+	if code == 0 {
+		return nil
+	}
+	if message, err = reader.ReadString('\n'); err != nil {
+		return err
+	}
+	return fmt.Errorf("scp error: %s", strings.TrimSpace(message))
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
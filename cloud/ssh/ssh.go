@@ -0,0 +1,331 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	cryptossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/goutils/logger"
+)
+
+type Config struct {
+	User string
+
+	// ssh port of the instance.
+	// defaults to port 22.
+	Port int
+
+	// PEM encoded private key and optional
+	// passphrase used to authenticate
+	PrivateKey []byte
+	Passphrase []byte
+
+	// authenticate using the ssh agent
+	// listening on SSH_AUTH_SOCK
+	UseAgent bool
+
+	// the SHA256 fingerprint of the instance's host key
+	// (i.e. "SHA256:...") which it is pinned to. this
+	// must be provided unless InsecureIgnoreHostKey is
+	// set.
+	HostKeyFingerprint    string
+	InsecureIgnoreHostKey bool
+
+	// timeout for establishing a connection
+	// to the instance. defaults to 10s.
+	Timeout time.Duration
+}
+
+// ssh client for running commands and
+// transferring files to an instance
+type Client struct {
+	instance cloud.ComputeInstance
+	config   Config
+
+	clientConfig *cryptossh.ClientConfig
+
+	// guards the connection to the instance and
+	// the resources that depend on it as they are
+	// used by forwarding goroutines
+	mx        sync.Mutex
+	client    *cryptossh.Client
+	agentConn net.Conn
+	listeners []net.Listener
+
+	hostKeyMismatch bool
+}
+
+// error returned when the instance's host
+// key does not match the pinned fingerprint
+var ErrHostKeyMismatch = errors.New("ssh host key does not match pinned fingerprint")
+
+// interval at which connection attempts
+// are retried when waiting for ssh
+const connectRetryInterval = time.Second * 5
+
+// in: the instance to connect to
+// in: the ssh client configuration
+// out: an ssh client which is not yet connected
+func NewClient(instance cloud.ComputeInstance, config Config) (*Client, error) {
+
+	var (
+		err error
+
+		signer cryptossh.Signer
+	)
+
+	if len(config.User) == 0 {
+		return nil, fmt.Errorf("an ssh user must be provided")
+	}
+	if config.Port == 0 {
+		config.Port = 22
+	}
+	if config.Timeout == 0 {
+		config.Timeout = time.Second * 10
+	}
+
+	authMethods := []cryptossh.AuthMethod{}
+	if len(config.PrivateKey) > 0 {
+		if len(config.Passphrase) > 0 {
+			signer, err = cryptossh.ParsePrivateKeyWithPassphrase(config.PrivateKey, config.Passphrase)
+		} else {
+			signer, err = cryptossh.ParsePrivateKey(config.PrivateKey)
+		}
+		if err != nil {
+			return nil, err
+		}
+		authMethods = append(authMethods, cryptossh.PublicKeys(signer))
+	}
+	// the ssh agent is connected to
+	// when connecting to the instance
+	if config.UseAgent && len(os.Getenv("SSH_AUTH_SOCK")) == 0 {
+		return nil, fmt.Errorf("the ssh agent cannot be used as SSH_AUTH_SOCK is not set")
+	}
+	if len(authMethods) == 0 && !config.UseAgent {
+		return nil, fmt.Errorf("either a private key or the ssh agent must be used to authenticate")
+	}
+
+	if len(config.HostKeyFingerprint) == 0 && !config.InsecureIgnoreHostKey {
+		return nil, fmt.Errorf("a host key fingerprint must be provided to verify the instance")
+	}
+
+	client := &Client{
+		instance: instance,
+		config:   config,
+	}
+	client.clientConfig = &cryptossh.ClientConfig{
+		User:            config.User,
+		Auth:            authMethods,
+		HostKeyCallback: client.verifyHostKey,
+		Timeout:         config.Timeout,
+	}
+	return client, nil
+}
+
+// verifies the host key presented by the
+// instance against the pinned fingerprint
+func (c *Client) verifyHostKey(hostname string, remote net.Addr, key cryptossh.PublicKey) error {
+
+	if c.config.InsecureIgnoreHostKey {
+		return nil
+	}
+	if fingerprint := cryptossh.FingerprintSHA256(key); fingerprint != c.config.HostKeyFingerprint {
+		logger.DebugMessage(
+			"Host key fingerprint '%s' of '%s' does not match pinned fingerprint '%s'.",
+			fingerprint, hostname, c.config.HostKeyFingerprint,
+		)
+		c.hostKeyMismatch = true
+		return ErrHostKeyMismatch
+	}
+	return nil
+}
+
+// address of the instance's ssh service
+func (c *Client) address() (string, error) {
+
+	host := c.instance.PublicIP()
+	if len(host) == 0 {
+		if host = c.instance.PublicDNS(); len(host) == 0 {
+			return "", fmt.Errorf(
+				"instance '%s' does not have a public address", c.instance.Name(),
+			)
+		}
+	}
+	return net.JoinHostPort(host, strconv.Itoa(c.config.Port)), nil
+}
+
+// Connects to the instance. This is a no-op
+// if the client is already connected.
+func (c *Client) Connect() error {
+
+	var (
+		err error
+
+		address   string
+		agentConn net.Conn
+		client    *cryptossh.Client
+	)
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.client != nil {
+		return nil
+	}
+	if address, err = c.address(); err != nil {
+		return err
+	}
+
+	clientConfig := *c.clientConfig
+	if c.config.UseAgent {
+		if agentConn, err = net.Dial("unix", os.Getenv("SSH_AUTH_SOCK")); err != nil {
+			return err
+		}
+		clientConfig.Auth = append(
+			append([]cryptossh.AuthMethod{}, c.clientConfig.Auth...),
+			cryptossh.PublicKeysCallback(agent.NewClient(agentConn).Signers),
+		)
+	}
+	if client, err = cryptossh.Dial("tcp", address, &clientConfig); err != nil {
+		if agentConn != nil {
+			agentConn.Close()
+		}
+		if c.hostKeyMismatch {
+			// the ssh library does not wrap the
+			// error returned by the host key check
+			return ErrHostKeyMismatch
+		}
+		return err
+	}
+	c.client = client
+	c.agentConn = agentConn
+	return nil
+}
+
+// Waits until a connection to the instance's ssh service
+// can be established or the given context is done. A
+// host key mismatch will fail immediately.
+func (c *Client) WaitUntilReady(ctx context.Context) error {
+
+	var (
+		err error
+	)
+
+	for {
+		if err = c.Connect(); err == nil {
+			return nil
+		}
+		if errors.Is(err, ErrHostKeyMismatch) {
+			return err
+		}
+		logger.TraceMessage(
+			"Waiting for ssh on instance '%s' to be ready: %s",
+			c.instance.Name(), err.Error(),
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"timed out waiting for ssh on instance '%s' to be ready: %s",
+				c.instance.Name(), err.Error(),
+			)
+		case <-time.After(connectRetryInterval):
+		}
+	}
+}
+
+// Runs the given command on the instance streaming
+// its output to the given writers. The returned
+// error will be nil if the command ran but exited
+// with a non-zero exit code.
+func (c *Client) Run(cmd string, stdout, stderr io.Writer) (int, error) {
+
+	var (
+		err error
+
+		session *cryptossh.Session
+	)
+
+	if session, err = c.newSession(); err != nil {
+		return -1, err
+	}
+	defer session.Close()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	logger.TraceMessage(
+		"Running command on instance '%s': %s",
+		c.instance.Name(), cmd,
+	)
+
+	if err = session.Run(cmd); err != nil {
+		exitErr := &cryptossh.ExitError{}
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitStatus(), nil
+		}
+		return -1, err
+	}
+	return 0, nil
+}
+
+// Closes the connection to the instance. Any
+// forwarding listeners are closed first so that
+// they stop accepting connections to forward.
+func (c *Client) Close() error {
+
+	var (
+		err error
+	)
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	for _, listener := range c.listeners {
+		listener.Close()
+	}
+	c.listeners = nil
+
+	if c.client != nil {
+		err = c.client.Close()
+		c.client = nil
+	}
+	if c.agentConn != nil {
+		c.agentConn.Close()
+		c.agentConn = nil
+	}
+	return err
+}
+
+// returns the connection to the instance
+func (c *Client) connection() (*cryptossh.Client, error) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.client == nil {
+		return nil, fmt.Errorf(
+			"ssh client is not connected to instance '%s'", c.instance.Name(),
+		)
+	}
+	return c.client, nil
+}
+
+func (c *Client) newSession() (*cryptossh.Session, error) {
+
+	client, err := c.connection()
+	if err != nil {
+		return nil, err
+	}
+	return client.NewSession()
+}
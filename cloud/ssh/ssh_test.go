@@ -0,0 +1,229 @@
+package ssh_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cryptossh "golang.org/x/crypto/ssh"
+
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/gocloud/cloud/ssh"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_helpers "github.com/mevansam/gocloud/test/helpers"
+	test_mocks "github.com/mevansam/gocloud/test/mocks"
+)
+
+var _ = Describe("SSH Client Tests", func() {
+
+	var (
+		err error
+
+		privateKey []byte
+		server     *test_helpers.SSHServer
+		instance   *test_mocks.FakeComputeInstance
+		client     *ssh.Client
+	)
+
+	BeforeEach(func() {
+		var authorizedKey cryptossh.PublicKey
+		privateKey, authorizedKey = test_helpers.GenerateSSHKey()
+		server = test_helpers.StartSSHServer(authorizedKey)
+
+		instance = &test_mocks.FakeComputeInstance{}
+		instance.SetValues("test-id", "test", "127.0.0.1", cloud.StateRunning)
+
+		client, err = ssh.NewClient(instance, ssh.Config{
+			User:               "test",
+			Port:               server.Port(),
+			PrivateKey:         privateKey,
+			HostKeyFingerprint: server.HostKeyFingerprint,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+		err = client.WaitUntilReady(ctx)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		server.Stop()
+	})
+
+	It("fails to connect when the host key does not match", func() {
+
+		c, err := ssh.NewClient(instance, ssh.Config{
+			User:               "test",
+			Port:               server.Port(),
+			PrivateKey:         privateKey,
+			HostKeyFingerprint: "SHA256:invalid",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+		err = c.WaitUntilReady(ctx)
+		Expect(err).To(MatchError(ssh.ErrHostKeyMismatch))
+	})
+
+	It("runs commands streaming their output and returning exit codes", func() {
+
+		var stdout, stderr strings.Builder
+
+		exitCode, err := client.Run("echo hello && echo world >&2", &stdout, &stderr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exitCode).To(Equal(0))
+		Expect(stdout.String()).To(Equal("hello\n"))
+		Expect(stderr.String()).To(Equal("world\n"))
+
+		exitCode, err = client.Run("exit 3", io.Discard, io.Discard)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exitCode).To(Equal(3))
+	})
+
+	It("uploads and downloads files", func() {
+
+		tmpDir, err := os.MkdirTemp("", "ssh-test")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+
+		data := "some test data\nto upload\n"
+		remotePath := filepath.Join(tmpDir, "uploaded.txt")
+		err = client.Upload(strings.NewReader(data), int64(len(data)), remotePath, 0600)
+		Expect(err).NotTo(HaveOccurred())
+
+		content, err := os.ReadFile(remotePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(Equal(data))
+
+		var downloaded strings.Builder
+		err = client.Download(remotePath, &downloaded)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(downloaded.String()).To(Equal(data))
+
+		localPath := filepath.Join(tmpDir, "downloaded.txt")
+		err = client.DownloadFile(remotePath, localPath)
+		Expect(err).NotTo(HaveOccurred())
+		content, err = os.ReadFile(localPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(Equal(data))
+
+		err = client.Download(filepath.Join(tmpDir, "does-not-exist"), io.Discard)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("forwards a local port to a remote address", func() {
+
+		echoListener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer echoListener.Close()
+		go func() {
+			for {
+				conn, err := echoListener.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer conn.Close()
+					_, _ = io.Copy(conn, conn)
+				}()
+			}
+		}()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		listener, err := client.Forward(ctx, "127.0.0.1:0", echoListener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		_, err = fmt.Fprint(conn, "ping")
+		Expect(err).NotTo(HaveOccurred())
+		reply := make([]byte, 4)
+		_, err = io.ReadFull(conn, reply)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(reply)).To(Equal("ping"))
+	})
+
+	It("stops forwarding when the client is closed", func() {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		listener, err := client.Forward(ctx, "127.0.0.1:0", "127.0.0.1:1")
+		Expect(err).NotTo(HaveOccurred())
+
+		// connections accepted while the client is
+		// closed must not use the closed connection
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 20; i++ {
+				if conn, err := net.Dial("tcp", listener.Addr().String()); err == nil {
+					conn.Close()
+				}
+			}
+		}()
+		err = client.Close()
+		Expect(err).NotTo(HaveOccurred())
+		<-done
+
+		_, err = net.Dial("tcp", listener.Addr().String())
+		Expect(err).To(HaveOccurred())
+
+		_, err = client.Forward(ctx, "127.0.0.1:0", "127.0.0.1:1")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("closes its connection to the ssh agent", func() {
+
+		sshAgent := test_helpers.StartSSHAgent(privateKey)
+		defer sshAgent.Stop()
+
+		authSock := os.Getenv("SSH_AUTH_SOCK")
+		os.Setenv("SSH_AUTH_SOCK", sshAgent.Path)
+		defer os.Setenv("SSH_AUTH_SOCK", authSock)
+
+		c, err := ssh.NewClient(instance, ssh.Config{
+			User:               "test",
+			Port:               server.Port(),
+			UseAgent:           true,
+			HostKeyFingerprint: "SHA256:invalid",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		err = c.Connect()
+		Expect(err).To(MatchError(ssh.ErrHostKeyMismatch))
+		Eventually(sshAgent.OpenConnections).Should(Equal(0))
+
+		c, err = ssh.NewClient(instance, ssh.Config{
+			User:               "test",
+			Port:               server.Port(),
+			UseAgent:           true,
+			HostKeyFingerprint: server.HostKeyFingerprint,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		err = c.Connect()
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(sshAgent.OpenConnections).Should(Equal(1))
+
+		_, err = c.Run("echo hello", io.Discard, io.Discard)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = c.Close()
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(sshAgent.OpenConnections).Should(Equal(0))
+	})
+})
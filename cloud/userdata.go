@@ -0,0 +1,27 @@
+package cloud
+
+import (
+	"fmt"
+)
+
+// returns an error if the instance is not stopped
+// as some clouds require to change its user data
+func requireStopped(instance ComputeInstance) error {
+
+	var (
+		err error
+
+		state InstanceState
+	)
+
+	if state, err = instance.State(); err != nil {
+		return err
+	}
+	if state != StateStopped {
+		return fmt.Errorf(
+			"instance '%s' must be stopped before its user data can be changed",
+			instance.Name(),
+		)
+	}
+	return nil
+}
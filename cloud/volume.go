@@ -0,0 +1,23 @@
+package cloud
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maximum length of a snapshot name that
+// is valid for all clouds (gce limit)
+const maxSnapshotNameLen = 63
+
+// returns a name for a snapshot of the given
+// volume which is unique to the second
+func snapshotName(volumeName string) string {
+
+	suffix := time.Now().UTC().Format("-20060102-150405")
+	name := strings.ToLower(volumeName)
+	if len(name)+len(suffix) > maxSnapshotNameLen {
+		name = name[:maxSnapshotNameLen-len(suffix)]
+	}
+	return fmt.Sprintf("%s%s", name, suffix)
+}
@@ -1,12 +1,19 @@
 package provider
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/mevansam/gocloud/cloud"
 	"github.com/mevansam/goforms/config"
 	"github.com/mevansam/goforms/forms"
@@ -23,6 +30,10 @@ type awsProvider struct {
 	isInitialized bool
 
 	session *session.Session
+
+	// supplies the mfa code required
+	// to assume the configured role
+	mfaTokenProvider MFATokenProvider
 }
 
 type awsProviderConfig struct {
@@ -30,6 +41,15 @@ type awsProviderConfig struct {
 	SecretKey *string `json:"secret_key,omitempty" form_field:"secret_key"`
 	Region    *string `json:"region,omitempty" form_field:"region"`
 	Token     *string `json:"token,omitempty" form_field:"token"`
+	Profile   *string `json:"profile,omitempty" form_field:"profile"`
+
+	TokenExpiry *string `json:"token_expiry,omitempty" form_field:"token_expiry"`
+
+	RoleARN     *string `json:"role_arn,omitempty" form_field:"role_arn"`
+	ExternalID  *string `json:"external_id,omitempty" form_field:"external_id"`
+	SessionName *string `json:"session_name,omitempty" form_field:"session_name"`
+	Duration    *string `json:"duration,omitempty" form_field:"duration"`
+	MFASerial   *string `json:"mfa_serial,omitempty" form_field:"mfa_serial"`
 }
 
 func newAWSProvider() (CloudProvider, error) {
@@ -109,6 +129,78 @@ func (p *awsProvider) createAWSInputForm() error {
 	}); err != nil {
 		return err
 	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "token_expiry",
+		DisplayName: "Token Expiry",
+		Description: "The time the session token expires i.e. \"2006-01-02T15:04:05Z\". The provider reconnects with credentials from the credential supplier before it expires.",
+		InputType:   forms.String,
+		EnvVars: []string{
+			"AWS_CREDENTIAL_EXPIRATION",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "profile",
+		DisplayName: "Profile",
+		Description: "The shared configuration profile to source credentials from if an access key is not provided.",
+		InputType:   forms.String,
+		EnvVars: []string{
+			"AWS_PROFILE",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	// assumed role fields are not sourced from
+	// the environment as AWS_ROLE_ARN is used by
+	// the sdk for web identity credentials
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "role_arn",
+		DisplayName: "Role ARN",
+		Description: "The ARN of a role to assume using the base credentials.",
+		InputType:   forms.String,
+		Tags:        []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "external_id",
+		DisplayName: "External ID",
+		Description: "The external ID required by the trust policy of the role to assume.",
+		InputType:   forms.String,
+		Tags:        []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "session_name",
+		DisplayName: "Session Name",
+		Description: "The name of the assumed role session.",
+		InputType:   forms.String,
+		Tags:        []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "duration",
+		DisplayName: "Session Duration",
+		Description: "The duration of the assumed role session i.e. \"1h\". Defaults to 15 minutes.",
+		InputType:   forms.String,
+		Tags:        []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "mfa_serial",
+		DisplayName: "MFA Serial",
+		Description: "The serial number or ARN of the MFA device required to assume the role. The token code is retrieved via the MFA token provider set on the provider.",
+		InputType:   forms.String,
+		Tags:        []string{"provider"},
+	}); err != nil {
+		return err
+	}
 	if _, err = form.NewInputField(forms.FieldAttributes{
 		Name:         "region",
 		DisplayName:  "Region",
@@ -151,6 +243,13 @@ func (p *awsProvider) Copy() (config.Configurable, error) {
 	configCopy.SecretKey = utils.CopyStrPtr(config.SecretKey)
 	configCopy.Region = utils.CopyStrPtr(config.Region)
 	configCopy.Token = utils.CopyStrPtr(config.Token)
+	configCopy.TokenExpiry = utils.CopyStrPtr(config.TokenExpiry)
+	configCopy.Profile = utils.CopyStrPtr(config.Profile)
+	configCopy.RoleARN = utils.CopyStrPtr(config.RoleARN)
+	configCopy.ExternalID = utils.CopyStrPtr(config.ExternalID)
+	configCopy.SessionName = utils.CopyStrPtr(config.SessionName)
+	configCopy.Duration = utils.CopyStrPtr(config.Duration)
+	configCopy.MFASerial = utils.CopyStrPtr(config.MFASerial)
 
 	return copy, nil
 }
@@ -160,19 +259,51 @@ func (p *awsProvider) IsValid() bool {
 	config := p.cloudProvider.
 		config.(*awsProviderConfig)
 
+	// credentials are resolved via the default
+	// credential chain if keys are not provided
+	hasAccessKey := config.AccessKey != nil && len(*config.AccessKey) > 0
+	hasSecretKey := config.SecretKey != nil && len(*config.SecretKey) > 0
+
+	if config.Duration != nil && len(*config.Duration) > 0 {
+		if _, err := time.ParseDuration(*config.Duration); err != nil {
+			return false
+		}
+	}
+	if config.TokenExpiry != nil && len(*config.TokenExpiry) > 0 {
+		if _, err := time.Parse(time.RFC3339, *config.TokenExpiry); err != nil {
+			return false
+		}
+	}
 	return config.Region != nil && len(*config.Region) > 0 &&
-		config.AccessKey != nil && len(*config.AccessKey) > 0 &&
-		config.SecretKey != nil && len(*config.SecretKey) > 0
+		hasAccessKey == hasSecretKey
 }
 
 // interface: config/provider/CloudProvider functions
 
 func (p *awsProvider) Connect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	return p.connectLocked()
+}
+
+// connects if the provider has not been connected or its
+// clients are stale. the caller must hold the connect lock.
+func (p *awsProvider) connectLocked() error {
 
 	var (
 		err error
 	)
 
+	if p.isInitialized && p.isStale() {
+		// rebuild the clients using
+		// the current configuration
+		p.isInitialized = false
+	}
+	if !p.isInitialized {
+		if err = p.supplyCredentials(p); err != nil {
+			return err
+		}
+	}
 	if !p.IsValid() {
 		return fmt.Errorf("provider configuration is not valid")
 	}
@@ -180,27 +311,263 @@ func (p *awsProvider) Connect() error {
 		config := p.cloudProvider.
 			config.(*awsProviderConfig)
 
-		token := ""
-		if config.Token != nil {
-			token = *config.Token
+		// the token code cannot be read from stdin as
+		// that would block callers that are not interactive
+		if p.isAssumingRole() && config.MFASerial != nil && len(*config.MFASerial) > 0 &&
+			p.mfaTokenProvider == nil {
+			return fmt.Errorf(
+				"the role '%s' requires an MFA token but an MFA token provider has not been set",
+				*config.RoleARN,
+			)
 		}
 
-		if p.session, err = session.NewSession(&aws.Config{
-			Region: aws.String(*config.Region),
-			Credentials: credentials.NewStaticCredentials(
-				*config.AccessKey,
-				*config.SecretKey,
-				token,
-			),
-		}); err != nil {
+		if p.session, err = session.NewSessionWithOptions(p.sessionOptions()); err != nil {
 			return err
 		}
+		if p.isAssumingRole() {
+			// the assumed role credentials are
+			// refreshed automatically on expiry
+			p.session = p.session.Copy(&aws.Config{
+				Credentials: stscreds.NewCredentials(p.session, *config.RoleARN, p.assumeRoleOptions),
+			})
+		}
+		// resolve credentials so that a
+		// misconfiguration fails early
+		if _, err = p.session.Config.Credentials.Get(); err != nil {
+			return fmt.Errorf("unable to retrieve aws credentials: %s", err.Error())
+		}
 
+		p.connected(p.sessionExpiry())
 		p.isInitialized = true
 	}
 	return nil
 }
 
+// Returns when the credentials the provider connected with
+// expire. Only a static session token is not refreshed so
+// only its configured expiry is returned. The credentials of
+// an assumed role are refreshed by the session on expiry and
+// reconnecting for them would prompt for a new MFA token.
+func (p *awsProvider) sessionExpiry() time.Time {
+
+	var (
+		expiry time.Time
+	)
+
+	config := p.cloudProvider.
+		config.(*awsProviderConfig)
+
+	if config.AccessKey != nil && len(*config.AccessKey) > 0 &&
+		config.Token != nil && len(*config.Token) > 0 &&
+		config.TokenExpiry != nil && len(*config.TokenExpiry) > 0 {
+
+		// validated by IsValid()
+		expiry, _ = time.Parse(time.RFC3339, *config.TokenExpiry)
+	}
+	return expiry
+}
+
+// Compute and storage entities retrieved before the
+// provider reconnects continue to use the previous
+// clients and need to be retrieved again.
+func (p *awsProvider) Reconnect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	p.isInitialized = false
+	return p.connectLocked()
+}
+
+func (p *awsProvider) Validate(ctx context.Context) (Identity, error) {
+
+	var (
+		err error
+
+		callerIdentity *sts.GetCallerIdentityOutput
+		expires        time.Time
+	)
+
+	if err = p.Connect(); err != nil {
+		return Identity{}, err
+	}
+	svc := sts.New(p.session)
+
+	if callerIdentity, err = svc.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{}); err != nil {
+		return Identity{}, err
+	}
+	identity := Identity{
+		AccountID: aws.StringValue(callerIdentity.Account),
+		Principal: aws.StringValue(callerIdentity.Arn),
+	}
+	// static credentials do not expire
+	if expires, err = p.session.Config.Credentials.ExpiresAt(); err == nil {
+		identity.Expires = expires
+	}
+	return identity, nil
+}
+
+// Actions are checked by simulating the IAM policies of the
+// principal the credentials authenticate as. For an assumed
+// role session the role's policies are simulated. The account
+// root user is permitted all actions.
+func (p *awsProvider) CheckPermissions(actions []string) ([]string, error) {
+
+	var (
+		err error
+
+		identity Identity
+	)
+
+	if identity, err = p.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	svc := iam.New(p.session)
+
+	policySourceARN, isRoot, err := awsPolicySourceARN(identity.Principal,
+		func(role string) (string, error) {
+			// the assumed role arn does not include the role's
+			// path so the role's actual arn is looked up
+			resp, err := svc.GetRole(&iam.GetRoleInput{
+				RoleName: aws.String(role),
+			})
+			if err != nil {
+				return "", err
+			}
+			return aws.StringValue(resp.Role.Arn), nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if isRoot {
+		return []string{}, nil
+	}
+
+	denied := []string{}
+	if err = svc.SimulatePrincipalPolicyPages(
+		&iam.SimulatePrincipalPolicyInput{
+			PolicySourceArn: aws.String(policySourceARN),
+			ActionNames:     aws.StringSlice(actions),
+		},
+		func(page *iam.SimulatePolicyResponse, lastPage bool) bool {
+			for _, result := range page.EvaluationResults {
+				if aws.StringValue(result.EvalDecision) != iam.PolicyEvaluationDecisionTypeAllowed {
+					denied = append(denied, aws.StringValue(result.EvalActionName))
+				}
+			}
+			return true
+		},
+	); err != nil {
+		return nil, err
+	}
+	return denied, nil
+}
+
+// in: the arn of the caller
+// in: function that returns the arn of the role with the given name
+// out: the arn of the iam user or role whose policies apply to the caller
+// out: whether the caller is the account's root user
+func awsPolicySourceARN(
+	callerARN string,
+	roleARN func(role string) (string, error),
+) (string, bool, error) {
+
+	// * arn:{partition}:iam::{account}:root
+	// * arn:{partition}:iam::{account}:user/{path}/{name}
+	// * arn:{partition}:sts::{account}:assumed-role/{role}/{session}
+	elems := strings.SplitN(callerARN, ":", 6)
+	if len(elems) != 6 {
+		return callerARN, false, nil
+	}
+	if elems[5] == "root" {
+		return callerARN, true, nil
+	}
+	if elems[2] == "sts" && strings.HasPrefix(elems[5], "assumed-role/") {
+		resource := strings.Split(elems[5], "/")
+		if len(resource) < 2 || len(resource[1]) == 0 {
+			return "", false, fmt.Errorf("invalid assumed role arn '%s'", callerARN)
+		}
+		arn, err := roleARN(resource[1])
+		if err != nil {
+			return "", false, fmt.Errorf(
+				"unable to retrieve the arn of role '%s': %s",
+				resource[1], err.Error(),
+			)
+		}
+		return arn, false, nil
+	}
+	return callerARN, false, nil
+}
+
+// returns the session options for the provider config. Static keys
+// take precedence. Otherwise credentials are resolved via the sdk's
+// default chain which sources them from the environment, the shared
+// config and credentials files (including SSO profiles), a web
+// identity token and the EC2 or ECS instance metadata service.
+func (p *awsProvider) sessionOptions() session.Options {
+
+	config := p.cloudProvider.
+		config.(*awsProviderConfig)
+
+	opts := session.Options{
+		Config: aws.Config{
+			Region: aws.String(*config.Region),
+		},
+		SharedConfigState: session.SharedConfigEnable,
+	}
+	if config.AccessKey != nil && len(*config.AccessKey) > 0 {
+		token := ""
+		if config.Token != nil {
+			token = *config.Token
+		}
+		opts.Config.Credentials = credentials.NewStaticCredentials(
+			*config.AccessKey,
+			*config.SecretKey,
+			token,
+		)
+	} else if config.Profile != nil {
+		opts.Profile = *config.Profile
+	}
+	if p.mfaTokenProvider != nil {
+		// shared config profiles can
+		// assume a role requiring mfa
+		opts.AssumeRoleTokenProvider = p.mfaTokenProvider
+	}
+	return opts
+}
+
+// returns whether the provider is
+// configured to assume a role
+func (p *awsProvider) isAssumingRole() bool {
+
+	config := p.cloudProvider.
+		config.(*awsProviderConfig)
+
+	return config.RoleARN != nil && len(*config.RoleARN) > 0
+}
+
+// applies the assumed role configuration
+// to the assume role credential provider
+func (p *awsProvider) assumeRoleOptions(provider *stscreds.AssumeRoleProvider) {
+
+	config := p.cloudProvider.
+		config.(*awsProviderConfig)
+
+	if config.ExternalID != nil && len(*config.ExternalID) > 0 {
+		provider.ExternalID = aws.String(*config.ExternalID)
+	}
+	if config.SessionName != nil && len(*config.SessionName) > 0 {
+		provider.RoleSessionName = *config.SessionName
+	}
+	if config.Duration != nil && len(*config.Duration) > 0 {
+		// validated by IsValid()
+		provider.Duration, _ = time.ParseDuration(*config.Duration)
+	}
+	if config.MFASerial != nil && len(*config.MFASerial) > 0 {
+		provider.SerialNumber = aws.String(*config.MFASerial)
+		provider.TokenProvider = p.mfaTokenProvider
+	}
+}
+
 func (p *awsProvider) Region() *string {
 
 	config := p.cloudProvider.
@@ -223,6 +590,78 @@ func (p *awsProvider) GetRegions() []RegionInfo {
 	return regionInfoList
 }
 
+func (p *awsProvider) GetZones(region string) ([]ZoneInfo, error) {
+
+	var (
+		err error
+
+		zonesResult *ec2.DescribeAvailabilityZonesOutput
+	)
+
+	if !p.isInitialized {
+		return nil, fmt.Errorf("aws provider has not been initialized")
+	}
+	if region, err = zoneRegion(region, p.Region()); err != nil {
+		return nil, err
+	}
+	svc := ec2.New(p.session, aws.NewConfig().WithRegion(region))
+
+	// local and wavelength zones are excluded
+	if zonesResult, err = svc.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("zone-type"),
+				Values: []*string{aws.String("availability-zone")},
+			},
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	zones := make([]ZoneInfo, 0, len(zonesResult.AvailabilityZones))
+	for _, z := range zonesResult.AvailabilityZones {
+		state := aws.StringValue(z.State)
+		zones = append(zones, ZoneInfo{
+			Name:      aws.StringValue(z.ZoneName),
+			Region:    aws.StringValue(z.RegionName),
+			Available: state == ec2.AvailabilityZoneStateAvailable,
+			Status:    state,
+		})
+	}
+	sortZones(zones)
+	return zones, nil
+}
+
+// When a role is assumed the temporary credentials of the
+// assumed role session are exported in place of the base
+// credentials so that they can be used by Terraform.
+func (p *awsProvider) GetVars(vars map[string]string) error {
+
+	var (
+		err error
+
+		creds credentials.Value
+	)
+
+	if err = p.cloudProvider.GetVars(vars); err != nil {
+		return err
+	}
+	if !p.isAssumingRole() {
+		return nil
+	}
+	if err = p.Connect(); err != nil {
+		return err
+	}
+	if creds, err = p.session.Config.Credentials.Get(); err != nil {
+		return err
+	}
+	delete(vars, "AWS_PROFILE")
+	vars["AWS_ACCESS_KEY_ID"] = creds.AccessKeyID
+	vars["AWS_SECRET_ACCESS_KEY"] = creds.SecretAccessKey
+	vars["AWS_SESSION_TOKEN"] = creds.SessionToken
+	return nil
+}
+
 func (p *awsProvider) GetCompute() (cloud.Compute, error) {
 
 	if !p.isInitialized {
@@ -248,3 +687,15 @@ func (p *awsProvider) GetStorage() (cloud.Storage, error) {
 		*config.Region,
 	)
 }
+
+// interface: config/provider/MFAConfigurable functions
+
+// Sets the hook that supplies the MFA code when the role
+// to assume requires one. The provider reconnects using
+// the hook the next time it connects.
+func (p *awsProvider) SetMFATokenProvider(tokenProvider MFATokenProvider) {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	p.mfaTokenProvider = tokenProvider
+	p.isInitialized = false
+}
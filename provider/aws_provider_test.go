@@ -1,7 +1,10 @@
 package provider_test
 
 import (
+	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mevansam/gocloud/provider"
 	"github.com/mevansam/goforms/forms"
@@ -12,6 +15,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	test_data "github.com/mevansam/gocloud/test/data"
+	test_helpers "github.com/mevansam/gocloud/test/helpers"
 )
 
 var _ = Describe("AWS Provider Tests", func() {
@@ -92,6 +96,21 @@ var _ = Describe("AWS Provider Tests", func() {
 				Expect(found).To(BeTrue())
 			}
 		})
+
+		It("retrieves the zones of the configured region", func() {
+			test_helpers.InitializeAWSProvider(awsProvider)
+			testZones(awsProvider)
+		})
+
+		It("validates the credentials and retrieves the identity they authenticate as", func() {
+			test_helpers.InitializeAWSProvider(awsProvider)
+			testValidate(awsProvider)
+		})
+
+		It("checks the permissions of the predefined permission sets", func() {
+			test_helpers.InitializeAWSProvider(awsProvider)
+			testCheckPermissions(awsProvider)
+		})
 	})
 
 	Context("aws provider config inputs", func() {
@@ -114,6 +133,184 @@ var _ = Describe("AWS Provider Tests", func() {
 			test_data.ParseConfigDocument(awsProvider, awsConfigDocument, "awsProvider")
 			test_data.MarshalConfigDocumentAndValidate(awsProvider, "awsProvider", awsConfigDocument)
 		})
+
+		It("validates configurations that source credentials from the default credential chain", func() {
+
+			var (
+				inputForm forms.InputForm
+			)
+
+			inputForm, err = awsProvider.InputForm()
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("region", "us-east-1")
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("profile", "cloudbuilder")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(awsProvider.IsValid()).To(BeTrue())
+
+			// an access key without a secret key is not valid
+			err = inputForm.SetFieldValue("access_key", "83BFAD5B-FEAC-4019-A645-3858847CB3ED")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(awsProvider.IsValid()).To(BeFalse())
+
+			err = inputForm.SetFieldValue("secret_key", "3BA9D494-5D49-4F1A-84CA-70D10A08ACDE")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(awsProvider.IsValid()).To(BeTrue())
+		})
+
+		It("validates the assumed role session duration", func() {
+
+			var (
+				inputForm forms.InputForm
+			)
+
+			test_data.ParseConfigDocument(awsProvider, awsConfigDocument, "awsProvider")
+			Expect(awsProvider.IsValid()).To(BeTrue())
+
+			inputForm, err = awsProvider.InputForm()
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("duration", "one hour")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(awsProvider.IsValid()).To(BeFalse())
+		})
+	})
+
+	Context("connecting with a session token", func() {
+
+		var (
+			supplied int
+		)
+
+		BeforeEach(func() {
+
+			inputForm, err := awsProvider.InputForm()
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("region", "us-east-1")
+			Expect(err).NotTo(HaveOccurred())
+
+			supplied = 0
+			awsProvider.SetCredentialSupplier(func(cloudProvider provider.CloudProvider) error {
+				supplied++
+
+				// the first token supplied expires
+				// within the credentials expiry window
+				expiry := time.Now().Add(time.Minute)
+				if supplied > 1 {
+					expiry = time.Now().Add(time.Hour)
+				}
+
+				inputForm, err := cloudProvider.InputForm()
+				if err != nil {
+					return err
+				}
+				for name, value := range map[string]string{
+					"access_key":   "83BFAD5B-FEAC-4019-A645-3858847CB3ED",
+					"secret_key":   "3BA9D494-5D49-4F1A-84CA-70D10A08ACDE",
+					"token":        fmt.Sprintf("session-token-%d", supplied),
+					"token_expiry": expiry.UTC().Format(time.RFC3339),
+				} {
+					if err = inputForm.SetFieldValue(name, value); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+
+		It("reconnects with credentials from the credential supplier before the session token expires", func() {
+
+			err = awsProvider.Connect()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(supplied).To(Equal(1))
+			Expect(awsProvider.CredentialsExpiry()).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second*2))
+
+			// the token expires within the expiry window
+			// so the provider reconnects with a new token
+			err = awsProvider.Connect()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(supplied).To(Equal(2))
+			Expect(awsProvider.CredentialsExpiry()).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second*2))
+
+			err = awsProvider.Connect()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(supplied).To(Equal(2))
+
+			token, err := awsProvider.GetValue("token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*token).To(Equal("session-token-2"))
+		})
+
+		It("connects and reconnects concurrently", func() {
+
+			var (
+				wg sync.WaitGroup
+			)
+
+			errs := make(chan error, 20)
+			for i := 0; i < 10; i++ {
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					errs <- awsProvider.Connect()
+					_ = awsProvider.CredentialsExpiry()
+				}()
+				go func() {
+					defer wg.Done()
+					errs <- awsProvider.Reconnect()
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(supplied).To(BeNumerically(">=", 10))
+		})
+	})
+
+	Context("assuming a role that requires an mfa token", func() {
+
+		var (
+			inputForm forms.InputForm
+		)
+
+		BeforeEach(func() {
+			inputForm, err = awsProvider.InputForm()
+			Expect(err).NotTo(HaveOccurred())
+
+			for name, value := range map[string]string{
+				"region":     "us-east-1",
+				"access_key": "83BFAD5B-FEAC-4019-A645-3858847CB3ED",
+				"secret_key": "3BA9D494-5D49-4F1A-84CA-70D10A08ACDE",
+				"role_arn":   "arn:aws:iam::123456789012:role/cloudbuilder",
+				"mfa_serial": "arn:aws:iam::123456789012:mfa/cloudbuilder",
+			} {
+				err = inputForm.SetFieldValue(name, value)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(awsProvider.IsValid()).To(BeTrue())
+		})
+
+		It("fails to connect if an mfa token provider has not been set", func() {
+			err = awsProvider.Connect()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("MFA token provider has not been set"))
+		})
+
+		It("retrieves the mfa token via the mfa token provider", func() {
+
+			tokenRequests := 0
+			awsProvider.(provider.MFAConfigurable).SetMFATokenProvider(func() (string, error) {
+				tokenRequests++
+				return "", fmt.Errorf("mfa token was not entered")
+			})
+
+			err = awsProvider.Connect()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("mfa token was not entered"))
+			Expect(tokenRequests).To(Equal(1))
+		})
 	})
 
 	It("creates a copy of itself", func() {
@@ -129,14 +326,33 @@ Amazon Web Services Cloud Platform
 
 ` + term.ITALIC + `CONFIGURATION DATA INPUT REFERENCE` + term.NC + `
 
-* Access Key - The AWS account's access key id. It will be sourced from the
-               environment variable AWS_ACCESS_KEY_ID if not provided.
-* Secret Key - The AWS account's secret key. It will be sourced from the
-               environment variable AWS_SECRET_ACCESS_KEY if not provided.
-* Token      - AWS multi-factor authentication token. It will be sourced from
-               the environment variable AWS_SESSION_TOKEN if not provided.
-* Region     - The AWS region to create resources in. It will be sourced from
-               the environment variable AWS_DEFAULT_REGION if not provided.`
+* Access Key       - The AWS account's access key id. It will be sourced from
+                     the environment variable AWS_ACCESS_KEY_ID if not provided.
+* Secret Key       - The AWS account's secret key. It will be sourced from the
+                     environment variable AWS_SECRET_ACCESS_KEY if not provided.
+* Token            - AWS multi-factor authentication token. It will be sourced
+                     from the environment variable AWS_SESSION_TOKEN if not
+                     provided.
+* Token Expiry     - The time the session token expires i.e.
+                     "2006-01-02T15:04:05Z". The provider reconnects with
+                     credentials from the credential supplier before it expires.
+                     It will be sourced from the environment variable
+                     AWS_CREDENTIAL_EXPIRATION if not provided.
+* Profile          - The shared configuration profile to source credentials from
+                     if an access key is not provided. It will be sourced from
+                     the environment variable AWS_PROFILE if not provided.
+* Role ARN         - The ARN of a role to assume using the base credentials.
+* External ID      - The external ID required by the trust policy of the role to
+                     assume.
+* Session Name     - The name of the assumed role session.
+* Session Duration - The duration of the assumed role session i.e. "1h".
+                     Defaults to 15 minutes.
+* MFA Serial       - The serial number or ARN of the MFA device required to
+                     assume the role. The token code is retrieved via the MFA
+                     token provider set on the provider.
+* Region           - The AWS region to create resources in. It will be sourced
+                     from the environment variable AWS_DEFAULT_REGION if not
+                     provided.`
 
 const awsConfigDocument = `
 {
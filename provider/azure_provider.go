@@ -2,12 +2,16 @@ package provider
 
 import (
 	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
-	"net/url"
+	"os"
 	"regexp"
 	"strings"
+	"time"
 
-	"github.com/mevansam/goutils/rest"
 	"github.com/mevansam/goutils/utils"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
@@ -16,6 +20,8 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
 	"github.com/google/uuid"
@@ -50,7 +56,7 @@ type azureProvider struct {
 	// been prepared to make API requests
 	isInitialized bool
 
-	clientCreds   *azidentity.ClientSecretCredential
+	clientCreds   azcore.TokenCredential
 	clientOpts    *arm.ClientOptions
 	defaultResGrp armresources.ResourceGroup
 
@@ -58,13 +64,36 @@ type azureProvider struct {
 }
 
 type azureProviderConfig struct {
-	Environment          *string `json:"environment,omitempty" form_field:"environment"`
-	SubscriptionID       *string `json:"subscription_id,omitempty" form_field:"subscription_id"`
-	ClientID             *string `json:"client_id,omitempty" form_field:"client_id"`
-	ClientSecret         *string `json:"client_secret,omitempty" form_field:"client_secret"`
-	TenantID             *string `json:"tenant_id,omitempty" form_field:"tenant_id"`
-	DefaultResourceGroup *string `json:"default_resource_group,omitempty" form_field:"default_resource_group"`
-	DefaultLocation      *string `json:"default_location,omitempty" form_field:"default_location"`
+	Environment               *string `json:"environment,omitempty" form_field:"environment"`
+	SubscriptionID            *string `json:"subscription_id,omitempty" form_field:"subscription_id"`
+	AuthMode                  *string `json:"auth_mode,omitempty" form_field:"auth_mode"`
+	ClientID                  *string `json:"client_id,omitempty" form_field:"client_id"`
+	ClientSecret              *string `json:"client_secret,omitempty" form_field:"client_secret"`
+	ClientCertificatePath     *string `json:"client_certificate_path,omitempty" form_field:"client_certificate_path"`
+	ClientCertificatePassword *string `json:"client_certificate_password,omitempty" form_field:"client_certificate_password"`
+	FederatedTokenFile        *string `json:"federated_token_file,omitempty" form_field:"federated_token_file"`
+	TenantID                  *string `json:"tenant_id,omitempty" form_field:"tenant_id"`
+	DefaultResourceGroup      *string `json:"default_resource_group,omitempty" form_field:"default_resource_group"`
+	DefaultLocation           *string `json:"default_location,omitempty" form_field:"default_location"`
+}
+
+// azure authentication modes
+const (
+	azureAuthClientSecret      = "client_secret"
+	azureAuthClientCertificate = "client_certificate"
+	azureAuthManagedIdentity   = "managed_identity"
+	azureAuthCLI               = "cli"
+	azureAuthWorkloadIdentity  = "workload_identity"
+	azureAuthDefault           = "default"
+)
+
+var azureAuthModes = []string{
+	azureAuthClientSecret,
+	azureAuthClientCertificate,
+	azureAuthManagedIdentity,
+	azureAuthCLI,
+	azureAuthWorkloadIdentity,
+	azureAuthDefault,
 }
 
 var environments = map[string]azcloud.Configuration{
@@ -147,10 +176,23 @@ func (p *azureProvider) createAzureInputForm() error {
 	}); err != nil {
 		return err
 	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "auth_mode",
+		DisplayName:  "Authentication Mode",
+		Description:  "How to authenticate with Azure. One of \"client_secret\", \"client_certificate\", \"managed_identity\", \"cli\", \"workload_identity\" or \"default\".",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr(azureAuthClientSecret),
+		Tags:         []string{"provider"},
+
+		AcceptedValues:             azureAuthModes,
+		AcceptedValuesErrorMessage: "Not a valid Azure authentication mode.",
+	}); err != nil {
+		return err
+	}
 	if _, err = form.NewInputField(forms.FieldAttributes{
 		Name:        "client_id",
 		DisplayName: "Client ID",
-		Description: "The Client ID or Application ID of the Azure Service Principal.",
+		Description: "The Client ID or Application ID of the Azure Service Principal or user-assigned managed identity.",
 		InputType:   forms.String,
 		EnvVars: []string{
 			"ARM_CLIENT_ID",
@@ -172,6 +214,44 @@ func (p *azureProvider) createAzureInputForm() error {
 	}); err != nil {
 		return err
 	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "client_certificate_path",
+		DisplayName: "Client Certificate Path",
+		Description: "Path to the PEM or PKCS#12 certificate of the Azure Service Principal.",
+		InputType:   forms.String,
+		EnvVars: []string{
+			"ARM_CLIENT_CERTIFICATE_PATH",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "client_certificate_password",
+		DisplayName: "Client Certificate Password",
+		Description: "The password of a PKCS#12 client certificate.",
+		InputType:   forms.String,
+		Sensitive:   true,
+		EnvVars: []string{
+			"ARM_CLIENT_CERTIFICATE_PASSWORD",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "federated_token_file",
+		DisplayName: "Federated Token File",
+		Description: "Path to the federated token of a workload identity.",
+		InputType:   forms.String,
+		EnvVars: []string{
+			"AZURE_FEDERATED_TOKEN_FILE",
+			"ARM_OIDC_TOKEN_FILE_PATH",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
 	if _, err = form.NewInputField(forms.FieldAttributes{
 		Name:        "tenant_id",
 		DisplayName: "Tenant ID",
@@ -223,82 +303,185 @@ func (p *azureProvider) connect(config *azureProviderConfig) error {
 		err error
 		ok  bool
 
-		env     azcloud.Configuration
-		options azidentity.ClientSecretCredentialOptions
-
+		env   azcloud.Configuration
 		token azcore.AccessToken
 	)
 
 	if env, ok = environments[*config.Environment]; !ok {
 		env = azcloud.AzurePublic
 	}
-	options.ClientOptions = azcore.ClientOptions{
+	clientOpts := azcore.ClientOptions{
 		Cloud: env,
 	}
 	p.clientOpts = &arm.ClientOptions{
-		ClientOptions: options.ClientOptions,
+		ClientOptions: clientOpts,
 	}
 
-	if p.clientCreds, err = azidentity.NewClientSecretCredential(
-		*config.TenantID, 
-		*config.ClientID, 
-		*config.ClientSecret, 
-		&options,
-	); err != nil {
+	if p.clientCreds, err = newAzureTokenCredential(config, clientOpts); err != nil {
 		return err
 	}
 
 	// To assign roles/permissions the principal/object id of
-	// the service principal (i.e. client_id) used is required.
-	// There is no azure sdk function to retrieve this so a
-	// direct rest api call is made to the Microsoft Graph API.
-	// Once azure SDK implements a suitable function to the
-	// following should be refactored.
+	// the identity authenticated with is required. This is
+	// the "oid" claim of the resource manager access token,
+	// which unlike a Microsoft Graph API lookup by client id
+	// works for all authentication modes.
 	//
 	// Equivalent CLI cmd:
 	//
-	// az ad sp show --id $ARM_CLIENT_ID
+	// az ad sp show --id $ARM_CLIENT_ID --query id
 
 	if token, err = p.clientCreds.GetToken(p.ctx, policy.TokenRequestOptions{
-		Scopes: []string{"https://graph.microsoft.com//.default"},
+		Scopes: []string{env.Services[azcloud.ResourceManager].Audience + "/.default"},
 	}); err != nil {
 		return err
 	}
-	appResp := struct {
-		Value []struct {
-			Id string `json:"id,omitempty"`
-		}
-	}{}
-	response := &rest.Response{
-		Body: &appResp,
-		Error: struct {
-			Message *string `json:"message,omitempty"`
-		}{},
-	}	
-	restApiClient := rest.NewRestApiClient(p.ctx, "https://graph.microsoft.com")
-	err = restApiClient.NewRequest(
-		&rest.Request{
-			Path: "/v1.0/servicePrincipals",
-			Headers: rest.NV{
-				"Authorization": fmt.Sprintf("Bearer %s", token.Token),
-			},
-			RawQuery: fmt.Sprintf("$filter=%s",
-				url.PathEscape(fmt.Sprintf("servicePrincipalNames/any(c:c eq '%s')", *config.ClientID)),
-			),					
-		},
-	).DoGet(response)
-	if err != nil {
+	if p.servicePrincipalID, err = azureTokenPrincipalID(token.Token); err != nil {
 		return err
 	}
+	return nil
+}
+
+// returns the authentication mode of the given config
+// which defaults to a service principal's client secret
+func azureAuthMode(config *azureProviderConfig) string {
+	if config.AuthMode == nil || len(*config.AuthMode) == 0 {
+		return azureAuthClientSecret
+	}
+	return *config.AuthMode
+}
+
+// returns the token credential for the
+// configured authentication mode
+func newAzureTokenCredential(
+	config *azureProviderConfig,
+	clientOpts azcore.ClientOptions,
+) (azcore.TokenCredential, error) {
+
+	var (
+		err error
+
+		certData []byte
+		certs    []*x509.Certificate
+		key      crypto.PrivateKey
+		password []byte
+	)
+
+	tenantID := ""
+	if config.TenantID != nil {
+		tenantID = *config.TenantID
+	}
+	clientID := ""
+	if config.ClientID != nil {
+		clientID = *config.ClientID
+	}
+
+	switch azureAuthMode(config) {
+	case azureAuthClientSecret:
+		return azidentity.NewClientSecretCredential(
+			tenantID,
+			clientID,
+			*config.ClientSecret,
+			&azidentity.ClientSecretCredentialOptions{
+				ClientOptions: clientOpts,
+			},
+		)
+
+	case azureAuthClientCertificate:
+		if certData, err = os.ReadFile(*config.ClientCertificatePath); err != nil {
+			return nil, err
+		}
+		if config.ClientCertificatePassword != nil && len(*config.ClientCertificatePassword) > 0 {
+			password = []byte(*config.ClientCertificatePassword)
+		}
+		if certs, key, err = azidentity.ParseCertificates(certData, password); err != nil {
+			return nil, err
+		}
+		return azidentity.NewClientCertificateCredential(
+			tenantID,
+			clientID,
+			certs,
+			key,
+			&azidentity.ClientCertificateCredentialOptions{
+				ClientOptions: clientOpts,
+			},
+		)
 
-	if len(appResp.Value) == 0 {
-		return fmt.Errorf(
-			"unable to determine service principal for client id '%s'", 
-			*config.ClientID,
+	case azureAuthManagedIdentity:
+		// the system-assigned identity is used
+		// if a client id is not provided
+		options := &azidentity.ManagedIdentityCredentialOptions{
+			ClientOptions: clientOpts,
+		}
+		if len(clientID) > 0 {
+			options.ID = azidentity.ClientID(clientID)
+		}
+		return azidentity.NewManagedIdentityCredential(options)
+
+	case azureAuthCLI:
+		return azidentity.NewAzureCLICredential(
+			&azidentity.AzureCLICredentialOptions{
+				TenantID: tenantID,
+			},
+		)
+
+	case azureAuthWorkloadIdentity:
+		// the token file is read on every token request
+		// as it is rotated by the workload's platform
+		tokenFile := *config.FederatedTokenFile
+		return azidentity.NewClientAssertionCredential(
+			tenantID,
+			clientID,
+			func(context.Context) (string, error) {
+				token, err := os.ReadFile(tokenFile)
+				if err != nil {
+					return "", err
+				}
+				return strings.TrimSpace(string(token)), nil
+			},
+			&azidentity.ClientAssertionCredentialOptions{
+				ClientOptions: clientOpts,
+			},
+		)
+
+	case azureAuthDefault:
+		return azidentity.NewDefaultAzureCredential(
+			&azidentity.DefaultAzureCredentialOptions{
+				ClientOptions: clientOpts,
+				TenantID:      tenantID,
+			},
 		)
 	}
-	p.servicePrincipalID = appResp.Value[0].Id
-	return nil
+	return nil, fmt.Errorf("unknown azure authentication mode '%s'", azureAuthMode(config))
+}
+
+// returns the object id of the principal
+// an access token was issued to
+func azureTokenPrincipalID(token string) (string, error) {
+
+	var (
+		err error
+
+		payload []byte
+	)
+
+	elems := strings.Split(token, ".")
+	if len(elems) != 3 {
+		return "", fmt.Errorf("access token is not a jwt")
+	}
+	if payload, err = base64.RawURLEncoding.DecodeString(elems[1]); err != nil {
+		return "", err
+	}
+	claims := struct {
+		ObjectID string `json:"oid"`
+	}{}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return "", err
+	}
+	if len(claims.ObjectID) == 0 {
+		return "", fmt.Errorf("unable to determine the principal the access token was issued to")
+	}
+	return claims.ObjectID, nil
 }
 
 // Azure Provider helper function specific to the azure
@@ -339,7 +522,11 @@ func (p *azureProvider) Copy() (config.Configurable, error) {
 	configCopy.Environment = utils.CopyStrPtr(config.Environment)
 	configCopy.SubscriptionID = utils.CopyStrPtr(config.SubscriptionID)
 	configCopy.ClientID = utils.CopyStrPtr(config.ClientID)
+	configCopy.AuthMode = utils.CopyStrPtr(config.AuthMode)
 	configCopy.ClientSecret = utils.CopyStrPtr(config.ClientSecret)
+	configCopy.ClientCertificatePath = utils.CopyStrPtr(config.ClientCertificatePath)
+	configCopy.ClientCertificatePassword = utils.CopyStrPtr(config.ClientCertificatePassword)
+	configCopy.FederatedTokenFile = utils.CopyStrPtr(config.FederatedTokenFile)
 	configCopy.TenantID = utils.CopyStrPtr(config.TenantID)
 	configCopy.DefaultResourceGroup = utils.CopyStrPtr(config.DefaultResourceGroup)
 	configCopy.DefaultLocation = utils.CopyStrPtr(config.DefaultLocation)
@@ -352,16 +539,37 @@ func (p *azureProvider) IsValid() bool {
 	config := p.cloudProvider.
 		config.(*azureProviderConfig)
 
-	return config.Environment != nil && len(*config.Environment) > 0 &&
-		config.SubscriptionID != nil && len(*config.SubscriptionID) > 0 &&
-		config.ClientID != nil && len(*config.ClientID) > 0 &&
-		config.ClientSecret != nil && len(*config.ClientSecret) > 0 &&
-		config.TenantID != nil && len(*config.TenantID) > 0
+	isSet := func(value *string) bool {
+		return value != nil && len(*value) > 0
+	}
+	if !isSet(config.Environment) || !isSet(config.SubscriptionID) {
+		return false
+	}
+
+	switch azureAuthMode(config) {
+	case azureAuthClientSecret:
+		return isSet(config.ClientID) && isSet(config.ClientSecret) && isSet(config.TenantID)
+	case azureAuthClientCertificate:
+		return isSet(config.ClientID) && isSet(config.ClientCertificatePath) && isSet(config.TenantID)
+	case azureAuthWorkloadIdentity:
+		return isSet(config.ClientID) && isSet(config.FederatedTokenFile) && isSet(config.TenantID)
+	case azureAuthManagedIdentity, azureAuthCLI, azureAuthDefault:
+		return true
+	}
+	return false
 }
 
 // interface: config/provider/CloudProvider functions
 
 func (p *azureProvider) Connect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	return p.connectLocked()
+}
+
+// connects if the provider has not been connected or its
+// clients are stale. the caller must hold the connect lock.
+func (p *azureProvider) connectLocked() error {
 
 	var (
 		err error
@@ -372,6 +580,16 @@ func (p *azureProvider) Connect() error {
 		crtresp armresources.ResourceGroupsClientCreateOrUpdateResponse
 	)
 
+	if p.isInitialized && p.isStale() {
+		// rebuild the clients using
+		// the current configuration
+		p.isInitialized = false
+	}
+	if !p.isInitialized {
+		if err = p.supplyCredentials(p); err != nil {
+			return err
+		}
+	}
 	if !p.IsValid() {
 		return fmt.Errorf("provider configuration is not valid")
 	}
@@ -410,11 +628,127 @@ func (p *azureProvider) Connect() error {
 			p.defaultResGrp = getresp.ResourceGroup
 		}
 
+		// the sdk refreshes the access tokens
+		p.connected(time.Time{})
 		p.isInitialized = true
 	}
 	return nil
 }
 
+// Compute and storage entities retrieved before the
+// provider reconnects continue to use the previous
+// clients and need to be retrieved again.
+func (p *azureProvider) Reconnect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	p.isInitialized = false
+	return p.connectLocked()
+}
+
+func (p *azureProvider) Validate(ctx context.Context) (Identity, error) {
+
+	var (
+		err error
+
+		client *armsubscriptions.Client
+		token  azcore.AccessToken
+		resp   armsubscriptions.ClientGetResponse
+	)
+
+	if err = p.Connect(); err != nil {
+		return Identity{}, err
+	}
+
+	config := p.cloudProvider.
+		config.(*azureProviderConfig)
+
+	if token, err = p.clientCreds.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{p.clientOpts.Cloud.Services[azcloud.ResourceManager].Audience + "/.default"},
+	}); err != nil {
+		return Identity{}, err
+	}
+	identity := Identity{
+		Expires: token.ExpiresOn,
+	}
+	if identity.Principal, err = azureTokenPrincipalID(token.Token); err != nil {
+		return Identity{}, err
+	}
+
+	// ensure the principal has access
+	// to the configured subscription
+	if client, err = armsubscriptions.NewClient(p.clientCreds, p.clientOpts); err != nil {
+		return Identity{}, err
+	}
+	if resp, err = client.Get(ctx, *config.SubscriptionID, nil); err != nil {
+		return Identity{}, err
+	}
+	identity.AccountID = *resp.SubscriptionID
+	return identity, nil
+}
+
+// Actions are checked against the permissions the principal
+// has been granted on the default resource group. Data
+// actions are not listed and will be reported as denied.
+func (p *azureProvider) CheckPermissions(actions []string) ([]string, error) {
+
+	var (
+		err error
+
+		client      *armauthorization.PermissionsClient
+		permissions []*armauthorization.Permission
+	)
+
+	if err = p.Connect(); err != nil {
+		return nil, err
+	}
+
+	config := p.cloudProvider.
+		config.(*azureProviderConfig)
+
+	if client, err = armauthorization.NewPermissionsClient(*config.SubscriptionID, p.clientCreds, p.clientOpts); err != nil {
+		return nil, err
+	}
+	pager := client.NewListForResourceGroupPager(*config.DefaultResourceGroup, nil)
+	for pager.More() {
+		nextResult, err := pager.NextPage(p.ctx)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, nextResult.Value...)
+	}
+
+	denied := []string{}
+	for _, action := range actions {
+		permitted := false
+		for _, permission := range permissions {
+			if azureActionMatches(permission.Actions, action) &&
+				!azureActionMatches(permission.NotActions, action) {
+				permitted = true
+				break
+			}
+		}
+		if !permitted {
+			denied = append(denied, action)
+		}
+	}
+	return denied, nil
+}
+
+// returns whether the given action matches any of the
+// given action patterns which may contain wildcards
+func azureActionMatches(patterns []*string, action string) bool {
+	for _, pattern := range patterns {
+		if pattern == nil {
+			continue
+		}
+		expr := "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(*pattern), `\*`, ".*") + "$"
+		if matched, err := regexp.MatchString(expr, action); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *azureProvider) Region() *string {
 
 	config := p.cloudProvider.
@@ -555,6 +889,118 @@ func (p *azureProvider) GetRegions() []RegionInfo {
 	return regionInfoList
 }
 
+// Azure availability zones are logical zones numbered
+// per subscription. A zone is available if any VM size
+// can be deployed to it by the subscription.
+func (p *azureProvider) GetZones(region string) ([]ZoneInfo, error) {
+
+	var (
+		err error
+
+		client *armcompute.ResourceSKUsClient
+		resp   armcompute.ResourceSKUsClientListResponse
+	)
+
+	if !p.isInitialized {
+		return nil, fmt.Errorf("azure provider has not been initialized")
+	}
+	if region, err = zoneRegion(region, p.Region()); err != nil {
+		return nil, err
+	}
+
+	config := p.cloudProvider.
+		config.(*azureProviderConfig)
+
+	if client, err = armcompute.NewResourceSKUsClient(*config.SubscriptionID, p.clientCreds, p.clientOpts); err != nil {
+		return nil, err
+	}
+
+	// zone name => available
+	zoneAvailability := make(map[string]bool)
+
+	pager := client.NewListPager(&armcompute.ResourceSKUsClientListOptions{
+		Filter: to.Ptr(fmt.Sprintf("location eq '%s'", region)),
+	})
+	for pager.More() {
+		if resp, err = pager.NextPage(p.ctx); err != nil {
+			return nil, err
+		}
+		for _, sku := range resp.Value {
+			if sku.ResourceType == nil || *sku.ResourceType != "virtualMachines" {
+				continue
+			}
+
+			// zones in which the sku is
+			// restricted for the subscription
+			restricted := make(map[string]bool)
+			for _, r := range sku.Restrictions {
+				if r.RestrictionInfo == nil {
+					continue
+				}
+				if r.Type != nil && *r.Type == armcompute.ResourceSKURestrictionsTypeLocation {
+					// restricted in all zones of the location
+					for _, l := range sku.LocationInfo {
+						for _, z := range l.Zones {
+							restricted[*z] = true
+						}
+					}
+				}
+				for _, z := range r.RestrictionInfo.Zones {
+					restricted[*z] = true
+				}
+			}
+
+			for _, l := range sku.LocationInfo {
+				if l.Location == nil || !strings.EqualFold(*l.Location, region) {
+					continue
+				}
+				for _, z := range l.Zones {
+					zoneAvailability[*z] = zoneAvailability[*z] || !restricted[*z]
+				}
+			}
+		}
+	}
+
+	zones := make([]ZoneInfo, 0, len(zoneAvailability))
+	for name, available := range zoneAvailability {
+		status := "Available"
+		if !available {
+			status = "NotAvailableForSubscription"
+		}
+		zones = append(zones, ZoneInfo{
+			Name:      name,
+			Region:    region,
+			Available: available,
+			Status:    status,
+		})
+	}
+	sortZones(zones)
+	return zones, nil
+}
+
+// Terraform's azurerm provider needs to be told which
+// of the credential types other than a client secret
+// or certificate to authenticate with.
+func (p *azureProvider) GetVars(vars map[string]string) error {
+
+	if err := p.cloudProvider.GetVars(vars); err != nil {
+		return err
+	}
+
+	config := p.cloudProvider.
+		config.(*azureProviderConfig)
+
+	switch azureAuthMode(config) {
+	case azureAuthManagedIdentity:
+		vars["ARM_USE_MSI"] = "true"
+	case azureAuthCLI:
+		vars["ARM_USE_CLI"] = "true"
+	case azureAuthWorkloadIdentity:
+		vars["ARM_USE_OIDC"] = "true"
+	}
+	return nil
+}
+
 func (p *azureProvider) GetCompute() (cloud.Compute, error) {
 
 	if !p.isInitialized {
@@ -117,6 +117,18 @@ var _ = Describe("Azure Provider Tests", func() {
 				Expect(r.Description).To(Equal(azurePublicLocations[r.Name]))
 			}
 		})
+
+		It("retrieves the zones of the configured region", func() {
+			testZones(azureProvider)
+		})
+
+		It("validates the credentials and retrieves the identity they authenticate as", func() {
+			testValidate(azureProvider)
+		})
+
+		It("checks the permissions of the predefined permission sets", func() {
+			testCheckPermissions(azureProvider)
+		})
 	})
 
 	Context("azure provider config", func() {
@@ -144,6 +156,70 @@ var _ = Describe("Azure Provider Tests", func() {
 			test_data.ParseConfigDocument(azureProvider, azureConfigDocument, "azureProvider")
 			test_data.CopyConfigAndValidate(azureProvider, "client_id", "BC3974F4-02C2-4762-8561-8CD466450914", "random value for client_id")
 		})
+
+		It("validates the credentials required by each authentication mode", func() {
+
+			var (
+				inputForm forms.InputForm
+			)
+
+			inputForm, err = azureProvider.InputForm()
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("subscription_id", "33EA4208-F718-4206-94E4-8A06E041858E")
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("client_id", "BC3974F4-02C2-4762-8561-8CD466450914")
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("tenant_id", "185C4F21-8C20-4694-B450-A9ED71321C6E")
+			Expect(err).NotTo(HaveOccurred())
+
+			// the default client secret mode requires a secret
+			Expect(azureProvider.IsValid()).To(BeFalse())
+			err = inputForm.SetFieldValue("client_secret", "98D1264D-D4D0-4BC4-8038-F671F9DAE3D1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(azureProvider.IsValid()).To(BeTrue())
+
+			err = inputForm.SetFieldValue("auth_mode", "client_certificate")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(azureProvider.IsValid()).To(BeFalse())
+			err = inputForm.SetFieldValue("client_certificate_path", "/home/username/azure-sp.pem")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(azureProvider.IsValid()).To(BeTrue())
+
+			err = inputForm.SetFieldValue("auth_mode", "workload_identity")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(azureProvider.IsValid()).To(BeFalse())
+			err = inputForm.SetFieldValue("federated_token_file", "/var/run/secrets/azure/tokens/azure-identity-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(azureProvider.IsValid()).To(BeTrue())
+
+			for _, authMode := range []string{"managed_identity", "cli", "default"} {
+				err = inputForm.SetFieldValue("auth_mode", authMode)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(azureProvider.IsValid()).To(BeTrue())
+			}
+
+			err = inputForm.SetFieldValue("auth_mode", "password")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("exports the terraform flags of the authentication mode", func() {
+
+			var (
+				inputForm forms.InputForm
+			)
+
+			test_data.ParseConfigDocument(azureProvider, azureConfigDocument, "azureProvider")
+			inputForm, err = azureProvider.InputForm()
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("auth_mode", "managed_identity")
+			Expect(err).NotTo(HaveOccurred())
+
+			vars := make(map[string]string)
+			err = azureProvider.GetVars(vars)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vars["ARM_USE_MSI"]).To(Equal("true"))
+			Expect(vars["ARM_CLIENT_ID"]).To(Equal("BC3974F4-02C2-4762-8561-8CD466450914"))
+		})
 	})
 })
 
@@ -154,26 +230,43 @@ Microsoft Azure Cloud Computing Platform
 
 ` + term.ITALIC + `CONFIGURATION DATA INPUT REFERENCE` + term.NC + `
 
-* Environment                - The Azure environment. It will be sourced from
-                               the environment variable ARM_ENVIRONMENT if not
-                               provided.
-* Subscription ID            - The Azure subscription ID. It will be sourced
-                               from the environment variable ARM_SUBSCRIPTION_ID
-                               if not provided.
-* Client ID                  - The Client ID or Application ID of the Azure
-                               Service Principal. It will be sourced from the
-                               environment variable ARM_CLIENT_ID if not
-                               provided.
-* Client Secret              - The Client Secret or Password of the Azure
-                               Service Principal. It will be sourced from the
-                               environment variable ARM_CLIENT_SECRET if not
-                               provided.
-* Tenant ID                  - The Tenant ID from the Azure Service Principal.
-                               It will be sourced from the environment variable
-                               ARM_TENANT_ID if not provided.
-* Default Resource Group     - Resource group where common resources will be
-                               created.
-* Default Location or Region - The location of the default resource group.`
+* Environment                 - The Azure environment. It will be sourced from
+                                the environment variable ARM_ENVIRONMENT if not
+                                provided.
+* Subscription ID             - The Azure subscription ID. It will be sourced
+                                from the environment variable
+                                ARM_SUBSCRIPTION_ID if not provided.
+* Authentication Mode         - How to authenticate with Azure. One of
+                                "client_secret", "client_certificate",
+                                "managed_identity", "cli", "workload_identity"
+                                or "default".
+* Client ID                   - The Client ID or Application ID of the Azure
+                                Service Principal or user-assigned managed
+                                identity. It will be sourced from the
+                                environment variable ARM_CLIENT_ID if not
+                                provided.
+* Client Secret               - The Client Secret or Password of the Azure
+                                Service Principal. It will be sourced from the
+                                environment variable ARM_CLIENT_SECRET if not
+                                provided.
+* Client Certificate Path     - Path to the PEM or PKCS#12 certificate of the
+                                Azure Service Principal. It will be sourced from
+                                the environment variable
+                                ARM_CLIENT_CERTIFICATE_PATH if not provided.
+* Client Certificate Password - The password of a PKCS#12 client certificate. It
+                                will be sourced from the environment variable
+                                ARM_CLIENT_CERTIFICATE_PASSWORD if not provided.
+* Federated Token File        - Path to the federated token of a workload
+                                identity. It will be sourced from the
+                                environment variables
+                                AZURE_FEDERATED_TOKEN_FILE,
+                                ARM_OIDC_TOKEN_FILE_PATH if not provided.
+* Tenant ID                   - The Tenant ID from the Azure Service Principal.
+                                It will be sourced from the environment variable
+                                ARM_TENANT_ID if not provided.
+* Default Resource Group      - Resource group where common resources will be
+                                created.
+* Default Location or Region  - The location of the default resource group.`
 
 const azureConfigDocument = `
 {
@@ -1,8 +1,12 @@
 package provider
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/mevansam/gocloud/cloud"
 	"github.com/mevansam/goforms/config"
@@ -16,9 +20,39 @@ type CloudProvider interface {
 	config.Configurable
 
 	// Connects to the cloud provider using the
-	// configured credentials
+	// configured credentials. If the provider is
+	// connected it is reconnected if its config
+	// has changed or its credentials have expired.
 	Connect() error
 
+	// Discards the provider's clients and connects
+	// using the current config, which is updated by
+	// the credential supplier if one has been set
+	Reconnect() error
+
+	// Sets a hook that supplies credentials from
+	// an external secret source whenever the
+	// provider connects or reconnects
+	SetCredentialSupplier(supplier CredentialSupplier)
+
+	// Returns when the provider's credentials expire
+	// after which the provider reconnects. This is the
+	// zero time if the credentials do not expire, are
+	// refreshed automatically or their expiry is not known.
+	CredentialsExpiry() time.Time
+
+	// Validates the configured credentials by
+	// retrieving the identity they authenticate
+	// as. The provider is connected if it has
+	// not been connected.
+	Validate(ctx context.Context) (Identity, error)
+
+	// Checks whether the configured credentials are
+	// permitted to perform the given cloud specific
+	// actions and returns the actions that are not
+	// permitted.
+	CheckPermissions(actions []string) ([]string, error)
+
 	// The currently configured region for this provider
 	Region() *string
 
@@ -28,6 +62,12 @@ type CloudProvider interface {
 	// the cloud API is returned.
 	GetRegions() []RegionInfo
 
+	// Returns the availability zones of the given
+	// region or the configured region if the
+	// region is empty. The provider must be
+	// connected to retrieve the zones.
+	GetZones(region string) ([]ZoneInfo, error)
+
 	// Returns the provider's compute entity
 	GetCompute() (cloud.Compute, error)
 
@@ -39,18 +79,63 @@ type CloudProvider interface {
 	GetVars(vars map[string]string) error	
 }
 
+// hook that supplies credentials from an external secret
+// source by updating the given provider's configuration
+// i.e. via its input form. it is called while the provider
+// is connecting so it must not connect the provider.
+type CredentialSupplier func(cloudProvider CloudProvider) error
+
+// hook that returns the current code of a multi-factor
+// authentication device i.e. by prompting the user. it is
+// called whenever credentials that require a code are
+// retrieved or refreshed.
+type MFATokenProvider func() (string, error)
+
+// implemented by cloud providers whose credentials
+// can require a multi-factor authentication code
+type MFAConfigurable interface {
+	SetMFATokenProvider(tokenProvider MFATokenProvider)
+}
+
+// credentials are considered expired
+// this long before they actually expire
+const credentialsExpiryWindow = 5 * time.Minute
+
 // base cloud provider implementation
 type cloudProvider struct {
 	name   string
 	config interface{}
+
+	// name of the provider's input form group if
+	// it differs from the provider's name
+	group string
+
+	credentialSupplier CredentialSupplier
+
+	// serializes connecting and reconnecting
+	// as the provider may be shared
+	connectMx sync.Mutex
+
+	// the config the provider was last connected
+	// with and when the credentials expire
+	connectedConfig   []byte
+	credentialsExpiry time.Time
+
+	// guards the connected config
+	// and credentials expiry
+	mx sync.RWMutex
 }
 
 type newProvider func() (CloudProvider, error)
 
 var providerNames = map[string]newProvider{
-	"aws":    newAWSProvider,
-	"azure":  newAzureProvider,
-	"google": newGoogleProvider,
+	"aws":          newAWSProvider,
+	"azure":        newAzureProvider,
+	"digitalocean": newDigitalOceanProvider,
+	"google":       newGoogleProvider,
+	"local":        newLocalProvider,
+	"openstack":    newOpenStackProvider,
+	"s3compatible": newS3CompatibleProvider,
 }
 
 // in: the iaas to create a cloud provider configuration template for
@@ -90,6 +175,17 @@ func SortCloudProviders(providers []CloudProvider) {
 	sort.Sort(&cloudProviderSorter{providers})
 }
 
+// returns the name of the provider's input form group.
+// the form groups of providers and backends are kept in
+// the same collection so a provider having the same name
+// as a backend needs to use a different group.
+func (p *cloudProvider) groupName() string {
+	if len(p.group) > 0 {
+		return p.group
+	}
+	return p.name
+}
+
 // interface: config/Configurable functions for base cloud provider
 
 func (p *cloudProvider) Name() string {
@@ -97,7 +193,7 @@ func (p *cloudProvider) Name() string {
 }
 
 func (p *cloudProvider) Description() string {
-	return forms_config.CloudConfigForms.Group(p.name).Description()
+	return forms_config.CloudConfigForms.Group(p.groupName()).Description()
 }
 
 func (p *cloudProvider) InputForm() (forms.InputForm, error) {
@@ -106,7 +202,7 @@ func (p *cloudProvider) InputForm() (forms.InputForm, error) {
 		err error
 	)
 
-	form := forms_config.CloudConfigForms.Group(p.name)
+	form := forms_config.CloudConfigForms.Group(p.groupName())
 	if err = form.BindFields(p.config); err != nil {
 		return nil, err
 	}
@@ -162,6 +258,53 @@ func (p *cloudProvider) GetVars(vars map[string]string) error {
 	return nil
 }
 
+func (p *cloudProvider) SetCredentialSupplier(supplier CredentialSupplier) {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	p.credentialSupplier = supplier
+}
+
+func (p *cloudProvider) CredentialsExpiry() time.Time {
+	p.mx.RLock()
+	defer p.mx.RUnlock()
+	return p.credentialsExpiry
+}
+
+// returns whether the provider's clients need to be
+// rebuilt because the config has changed since the
+// provider connected or the credentials have expired
+func (p *cloudProvider) isStale() bool {
+
+	p.mx.RLock()
+	defer p.mx.RUnlock()
+
+	if !p.credentialsExpiry.IsZero() &&
+		time.Now().Add(credentialsExpiryWindow).After(p.credentialsExpiry) {
+		return true
+	}
+	config, err := json.Marshal(p.config)
+	return err != nil || !bytes.Equal(config, p.connectedConfig)
+}
+
+// calls the credential supplier if one has
+// been set before the provider connects
+func (p *cloudProvider) supplyCredentials(cloudProvider CloudProvider) error {
+	if p.credentialSupplier == nil {
+		return nil
+	}
+	return p.credentialSupplier(cloudProvider)
+}
+
+// records the config the provider connected with and
+// the expiry of credentials that are not refreshed
+// automatically, which is zero if not known
+func (p *cloudProvider) connected(credentialsExpiry time.Time) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.connectedConfig, _ = json.Marshal(p.config)
+	p.credentialsExpiry = credentialsExpiry
+}
+
 // interface: encoding/json/Unmarshaler
 
 func (p *cloudProvider) UnmarshalJSON(b []byte) error {
@@ -2,8 +2,10 @@ package provider_test
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"os"
+	"time"
 
 	"github.com/mevansam/gocloud/provider"
 
@@ -87,3 +89,80 @@ func testConfigReferenceOutput(cloudProvider provider.CloudProvider, expected st
 	logger.DebugMessage("\n%s\n", output)
 	Expect(output).To(Equal(expected))
 }
+
+func testZones(cloudProvider provider.CloudProvider) {
+
+	var (
+		err error
+
+		zones []provider.ZoneInfo
+	)
+
+	_, err = cloudProvider.GetZones("")
+	Expect(err).To(HaveOccurred())
+
+	err = cloudProvider.Connect()
+	Expect(err).NotTo(HaveOccurred())
+
+	region := cloudProvider.Region()
+	Expect(region).ToNot(BeNil())
+
+	zones, err = cloudProvider.GetZones("")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(len(zones)).To(BeNumerically(">", 0))
+
+	logger.DebugMessage("\nZones of region '%s':", *region)
+
+	available := 0
+	for _, z := range zones {
+		logger.DebugMessage("  * %s - %s", z.Name, z.Status)
+		Expect(len(z.Name)).To(BeNumerically(">", 0))
+		Expect(z.Region).To(Equal(*region))
+		if z.Available {
+			available++
+		}
+	}
+	Expect(available).To(BeNumerically(">", 0))
+}
+
+func testValidate(cloudProvider provider.CloudProvider) {
+
+	var (
+		err error
+
+		identity provider.Identity
+	)
+
+	identity, err = cloudProvider.Validate(context.Background())
+	Expect(err).NotTo(HaveOccurred())
+
+	logger.DebugMessage(
+		"\nCredentials authenticate as '%s' in account '%s' and expire at '%s'.",
+		identity.Principal, identity.AccountID, identity.Expires,
+	)
+
+	Expect(len(identity.AccountID)).To(BeNumerically(">", 0))
+	Expect(len(identity.Principal)).To(BeNumerically(">", 0))
+	if !identity.Expires.IsZero() {
+		Expect(identity.Expires.After(time.Now())).To(BeTrue())
+	}
+}
+
+func testCheckPermissions(cloudProvider provider.CloudProvider) {
+
+	var (
+		err error
+
+		denied []string
+	)
+
+	for _, set := range []string{
+		provider.StorageBackendBootstrap,
+		provider.ComputeLifecycle,
+	} {
+		denied, err = provider.CheckPermissionSet(cloudProvider, set)
+		Expect(err).NotTo(HaveOccurred())
+		logger.DebugMessage("\nActions of permission set '%s' that are denied: %# v", set, denied)
+		Expect(denied).To(BeEmpty())
+	}
+}
@@ -0,0 +1,463 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/digitalocean/godo"
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/goforms/config"
+	"github.com/mevansam/goforms/forms"
+	"github.com/mevansam/goutils/logger"
+	"github.com/mevansam/goutils/utils"
+
+	forms_config "github.com/mevansam/gocloud/forms"
+)
+
+// region expected by the S3 API of spaces. the region
+// of a space is determined by the endpoint it is in.
+const spacesSigningRegion = "us-east-1"
+
+type digitalOceanProvider struct {
+	cloudProvider
+
+	// background context
+	ctx context.Context
+
+	// indicates if provider client has
+	// been prepared to make API requests
+	isInitialized bool
+
+	client *godo.Client
+
+	// session for the S3 API of spaces which
+	// is nil if spaces keys were not provided
+	spacesSession *session.Session
+}
+
+type digitalOceanProviderConfig struct {
+	Token           *string `json:"token,omitempty" form_field:"token"`
+	Region          *string `json:"region,omitempty" form_field:"region"`
+	SpacesRegion    *string `json:"spaces_region,omitempty" form_field:"spaces_region"`
+	SpacesAccessKey *string `json:"spaces_access_key,omitempty" form_field:"spaces_access_key"`
+	SpacesSecretKey *string `json:"spaces_secret_key,omitempty" form_field:"spaces_secret_key"`
+}
+
+var staticDigitalOceanRegionMap = map[string]string{
+	"ams3": "Amsterdam 3",
+	"blr1": "Bangalore 1",
+	"fra1": "Frankfurt 1",
+	"lon1": "London 1",
+	"nyc1": "New York 1",
+	"nyc3": "New York 3",
+	"sfo2": "San Francisco 2",
+	"sfo3": "San Francisco 3",
+	"sgp1": "Singapore 1",
+	"syd1": "Sydney 1",
+	"tor1": "Toronto 1",
+}
+
+// regions that spaces are available in which
+// are not all of the regions droplets run in
+var digitalOceanSpacesRegions = []string{
+	"ams3",
+	"atl1",
+	"blr1",
+	"fra1",
+	"lon1",
+	"nyc3",
+	"sfo2",
+	"sfo3",
+	"sgp1",
+	"syd1",
+	"tor1",
+}
+
+func newDigitalOceanProvider() (CloudProvider, error) {
+
+	var (
+		err            error
+		providerConfig digitalOceanProviderConfig
+	)
+
+	provider := &digitalOceanProvider{
+		cloudProvider: cloudProvider{
+			name:   "digitalocean",
+			config: &providerConfig,
+		},
+		ctx:           context.Background(),
+		isInitialized: false,
+	}
+	err = provider.createDigitalOceanInputForm()
+	return provider, err
+}
+
+func (p *digitalOceanProvider) createDigitalOceanInputForm() error {
+
+	// Do not recreate form template if it exists
+	clougConfig := forms_config.CloudConfigForms
+	if clougConfig.HasGroup(p.name) {
+		return nil
+	}
+
+	var (
+		err  error
+		form *forms.InputGroup
+	)
+
+	regions := p.GetRegions()
+	regionList := make([]string, len(regions))
+	for i, r := range regions {
+		regionList[i] = r.Name
+	}
+
+	form = forms_config.CloudConfigForms.NewGroup(p.name, "DigitalOcean Cloud Platform")
+
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "token",
+		DisplayName: "Token",
+		Description: "The DigitalOcean personal access token.",
+		InputType:   forms.String,
+		Sensitive:   true,
+		EnvVars: []string{
+			"DIGITALOCEAN_TOKEN",
+			"DIGITALOCEAN_ACCESS_TOKEN",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "region",
+		DisplayName:  "Region",
+		Description:  "The DigitalOcean region to create resources in.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr("nyc3"),
+		Tags:         []string{"provider", "target-undeployed"},
+
+		AcceptedValues:             regionList,
+		AcceptedValuesErrorMessage: "Not a valid DigitalOcean region.",
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "spaces_region",
+		DisplayName:  "Spaces Region",
+		Description:  "The DigitalOcean region to create Spaces in for storage.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr("nyc3"),
+		Tags:         []string{"provider"},
+
+		AcceptedValues:             digitalOceanSpacesRegions,
+		AcceptedValuesErrorMessage: "Not a DigitalOcean region with Spaces.",
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "spaces_access_key",
+		DisplayName: "Spaces Access Key",
+		Description: "The access key id of the Spaces API key. It is required only for storage.",
+		InputType:   forms.String,
+		EnvVars: []string{
+			"SPACES_ACCESS_KEY_ID",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "spaces_secret_key",
+		DisplayName: "Spaces Secret Key",
+		Description: "The secret key of the Spaces API key. It is required only for storage.",
+		InputType:   forms.String,
+		Sensitive:   true,
+		EnvVars: []string{
+			"SPACES_SECRET_ACCESS_KEY",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// interface: config/Configurable functions of base cloud provider
+
+func (p *digitalOceanProvider) Copy() (config.Configurable, error) {
+
+	var (
+		err error
+
+		copy CloudProvider
+	)
+
+	if copy, err = newDigitalOceanProvider(); err != nil {
+		return nil, err
+	}
+
+	config := p.cloudProvider.
+		config.(*digitalOceanProviderConfig)
+	configCopy := copy.(*digitalOceanProvider).cloudProvider.
+		config.(*digitalOceanProviderConfig)
+
+	configCopy.Token = utils.CopyStrPtr(config.Token)
+	configCopy.Region = utils.CopyStrPtr(config.Region)
+	configCopy.SpacesRegion = utils.CopyStrPtr(config.SpacesRegion)
+	configCopy.SpacesAccessKey = utils.CopyStrPtr(config.SpacesAccessKey)
+	configCopy.SpacesSecretKey = utils.CopyStrPtr(config.SpacesSecretKey)
+
+	return copy, nil
+}
+
+func (p *digitalOceanProvider) IsValid() bool {
+
+	config := p.cloudProvider.
+		config.(*digitalOceanProviderConfig)
+
+	// the spaces keys are optional
+	// but must be provided together
+	hasAccessKey := config.SpacesAccessKey != nil && len(*config.SpacesAccessKey) > 0
+	hasSecretKey := config.SpacesSecretKey != nil && len(*config.SpacesSecretKey) > 0
+
+	return config.Token != nil && len(*config.Token) > 0 &&
+		config.Region != nil && len(*config.Region) > 0 &&
+		hasAccessKey == hasSecretKey &&
+		(!hasAccessKey || (config.SpacesRegion != nil && len(*config.SpacesRegion) > 0))
+}
+
+// interface: config/provider/CloudProvider functions
+
+func (p *digitalOceanProvider) Connect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	return p.connectLocked()
+}
+
+// connects if the provider has not been connected or its
+// clients are stale. the caller must hold the connect lock.
+func (p *digitalOceanProvider) connectLocked() error {
+
+	var (
+		err error
+	)
+
+	if p.isInitialized && p.isStale() {
+		// rebuild the clients using
+		// the current configuration
+		p.isInitialized = false
+	}
+	if !p.isInitialized {
+		if err = p.supplyCredentials(p); err != nil {
+			return err
+		}
+	}
+	if !p.IsValid() {
+		return fmt.Errorf("provider configuration is not valid")
+	}
+	if !p.isInitialized {
+		config := p.cloudProvider.
+			config.(*digitalOceanProviderConfig)
+
+		p.client = godo.NewFromToken(*config.Token)
+
+		p.spacesSession = nil
+		if config.SpacesAccessKey != nil && len(*config.SpacesAccessKey) > 0 {
+			if p.spacesSession, err = session.NewSession(&aws.Config{
+				Region:   aws.String(spacesSigningRegion),
+				Endpoint: aws.String(fmt.Sprintf("https://%s.digitaloceanspaces.com", *config.SpacesRegion)),
+				Credentials: credentials.NewStaticCredentials(
+					*config.SpacesAccessKey,
+					*config.SpacesSecretKey,
+					"",
+				),
+			}); err != nil {
+				return err
+			}
+		}
+
+		// personal access tokens are
+		// revoked rather than expired
+		p.connected(time.Time{})
+		p.isInitialized = true
+	}
+	return nil
+}
+
+// Compute and storage entities retrieved before the
+// provider reconnects continue to use the previous
+// clients and need to be retrieved again.
+func (p *digitalOceanProvider) Reconnect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	p.isInitialized = false
+	return p.connectLocked()
+}
+
+func (p *digitalOceanProvider) Validate(ctx context.Context) (Identity, error) {
+
+	var (
+		err error
+
+		account *godo.Account
+	)
+
+	if err = p.Connect(); err != nil {
+		return Identity{}, err
+	}
+	if account, _, err = p.client.Account.Get(ctx); err != nil {
+		return Identity{}, err
+	}
+	return Identity{
+		AccountID: account.UUID,
+		Principal: account.Email,
+	}, nil
+}
+
+// The scopes of a DigitalOcean token cannot
+// be queried so permissions are not checked.
+func (p *digitalOceanProvider) CheckPermissions(actions []string) ([]string, error) {
+	return nil, fmt.Errorf("digitalocean provider does not support checking permissions")
+}
+
+func (p *digitalOceanProvider) Region() *string {
+
+	config := p.cloudProvider.
+		config.(*digitalOceanProviderConfig)
+
+	return config.Region
+}
+
+func (p *digitalOceanProvider) GetRegions() []RegionInfo {
+
+	var (
+		err error
+
+		regions []godo.Region
+	)
+
+	regionInfoList := []RegionInfo{}
+
+	if p.isInitialized {
+		if regions, _, err = p.client.Regions.List(p.ctx,
+			&godo.ListOptions{PerPage: 200},
+		); err == nil {
+			for _, region := range regions {
+				regionInfoList = append(regionInfoList,
+					RegionInfo{
+						Name:        region.Slug,
+						Description: region.Name,
+					},
+				)
+			}
+			sortRegions(regionInfoList)
+			logger.TraceMessage("DigitalOcean region list retrieved via API: %# v", regionInfoList)
+
+			return regionInfoList
+		}
+
+		logger.DebugMessage("Unable to retrieve DigitalOcean regions via API call: %s", err.Error())
+	}
+
+	// The list is hard-coded as you need to be
+	// authenticated to retrieve it via the API.
+	for region, description := range staticDigitalOceanRegionMap {
+		regionInfoList = append(regionInfoList,
+			RegionInfo{
+				Name:        region,
+				Description: description,
+			},
+		)
+	}
+
+	sortRegions(regionInfoList)
+	return regionInfoList
+}
+
+// DigitalOcean regions are single data centers so
+// the region is returned as its only zone.
+func (p *digitalOceanProvider) GetZones(region string) ([]ZoneInfo, error) {
+
+	var (
+		err error
+
+		regions []godo.Region
+	)
+
+	if !p.isInitialized {
+		return nil, fmt.Errorf("digitalocean provider has not been initialized")
+	}
+	if region, err = zoneRegion(region, p.Region()); err != nil {
+		return nil, err
+	}
+	if regions, _, err = p.client.Regions.List(p.ctx,
+		&godo.ListOptions{PerPage: 200},
+	); err != nil {
+		return nil, err
+	}
+	for _, r := range regions {
+		if r.Slug == region {
+			status := "available"
+			if !r.Available {
+				status = "unavailable"
+			}
+			return []ZoneInfo{
+				{
+					Name:      r.Slug,
+					Region:    r.Slug,
+					Available: r.Available,
+					Status:    status,
+				},
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("digitalocean region '%s' was not found", region)
+}
+
+func (p *digitalOceanProvider) GetCompute() (cloud.Compute, error) {
+
+	if !p.isInitialized {
+		return nil, fmt.Errorf("digitalocean provider has not been initialized")
+	}
+
+	config := p.cloudProvider.
+		config.(*digitalOceanProviderConfig)
+
+	return cloud.NewDigitalOceanCompute(
+		p.client,
+		*config.Region,
+	)
+}
+
+// Spaces buckets are accessed via their S3 API
+// which requires the spaces keys to be provided.
+func (p *digitalOceanProvider) GetStorage() (cloud.Storage, error) {
+
+	var (
+		err error
+
+		storage cloud.Storage
+	)
+
+	if !p.isInitialized {
+		return nil, fmt.Errorf("digitalocean provider has not been initialized")
+	}
+	if p.spacesSession == nil {
+		return nil, fmt.Errorf("spaces keys are required for digitalocean storage")
+	}
+
+	if storage, err = cloud.NewAWSStorage(
+		p.spacesSession,
+		spacesSigningRegion,
+	); err != nil {
+		return nil, err
+	}
+	storage.SetProperties(cloud.AWSStorageProperties{
+		S3Compatible: true,
+	})
+	return storage, nil
+}
@@ -0,0 +1,8 @@
+package provider
+
+// exports unexported functions so they can
+// be tested from the provider_test package
+var (
+	AWSPolicySourceARN = awsPolicySourceARN
+	AzureActionMatches = azureActionMatches
+)
@@ -3,13 +3,21 @@ package provider
 import (
 	"context"
 	"fmt"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/mevansam/goutils/utils"
 
 	"cloud.google.com/go/storage"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/impersonate"
 	"google.golang.org/api/option"
 
+	cloudresourcemanager "google.golang.org/api/cloudresourcemanager/v1"
 	compute "google.golang.org/api/compute/v1"
+	oauth2api "google.golang.org/api/oauth2/v2"
 
 	"github.com/mevansam/gocloud/cloud"
 	"github.com/mevansam/goforms/config"
@@ -19,6 +27,10 @@ import (
 	forms_config "github.com/mevansam/gocloud/forms"
 )
 
+// google access tokens are issued
+// with a lifetime of at most an hour
+const googleAccessTokenLifetime = time.Hour
+
 type googleProvider struct {
 	cloudProvider
 
@@ -29,6 +41,7 @@ type googleProvider struct {
 	// been prepared to make API requests
 	isInitialized bool
 
+	tokenSource    oauth2.TokenSource
 	computeService *compute.Service
 	storageClient  *storage.Client
 }
@@ -39,6 +52,9 @@ type googleProviderConfig struct {
 		AccessToken *string `json:"access_token,omitempty" form_field:"access_token"`
 	} `json:"authentication"`
 
+	ImpersonateServiceAccount *string `json:"impersonate_service_account,omitempty" form_field:"impersonate_service_account"`
+	ImpersonateDelegates      *string `json:"impersonate_delegates,omitempty" form_field:"impersonate_delegates"`
+
 	Project *string `json:"project,omitempty" form_field:"project"`
 	Region  *string `json:"region,omitempty" form_field:"region"`
 }
@@ -129,7 +145,7 @@ func (p *googleProvider) createGoogleInputForm() error {
 	if _, err = form.NewInputField(forms.FieldAttributes{
 		Name:          "credentials",
 		DisplayName:   "Credentials",
-		Description:   "The contents of a service account key file in JSON format.",
+		Description:   "The contents of a service account key or workload identity federation configuration file in JSON format. Application default credentials are used if neither credentials nor an access token are provided.",
 		GroupID:       1,
 		InputType:     forms.String,
 		ValueFromFile: true,
@@ -157,6 +173,27 @@ func (p *googleProvider) createGoogleInputForm() error {
 	}); err != nil {
 		return err
 	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "impersonate_service_account",
+		DisplayName: "Impersonate Service Account",
+		Description: "The email of a service account to impersonate using the credentials.",
+		InputType:   forms.String,
+		EnvVars: []string{
+			"GOOGLE_IMPERSONATE_SERVICE_ACCOUNT",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "impersonate_delegates",
+		DisplayName: "Impersonation Delegates",
+		Description: "Comma separated list of service account emails in the delegation chain of the impersonated service account.",
+		InputType:   forms.String,
+		Tags:        []string{"provider"},
+	}); err != nil {
+		return err
+	}
 
 	if _, err = form.NewInputField(forms.FieldAttributes{
 		Name:        "project",
@@ -218,6 +255,8 @@ func (p *googleProvider) Copy() (config.Configurable, error) {
 
 	configCopy.Authentication.Credentials = utils.CopyStrPtr(config.Authentication.Credentials)
 	configCopy.Authentication.AccessToken = utils.CopyStrPtr(config.Authentication.AccessToken)
+	configCopy.ImpersonateServiceAccount = utils.CopyStrPtr(config.ImpersonateServiceAccount)
+	configCopy.ImpersonateDelegates = utils.CopyStrPtr(config.ImpersonateDelegates)
 	configCopy.Project = utils.CopyStrPtr(config.Project)
 	configCopy.Region = utils.CopyStrPtr(config.Region)
 
@@ -229,46 +268,296 @@ func (p *googleProvider) IsValid() bool {
 	config := p.cloudProvider.
 		config.(*googleProviderConfig)
 
+	// application default credentials are used
+	// if credentials or a token are not provided
 	return config.Project != nil && len(*config.Project) > 0 &&
-		config.Region != nil && len(*config.Region) > 0 &&
-		((config.Authentication.Credentials != nil && len(*config.Authentication.Credentials) > 0) ||
-			(config.Authentication.AccessToken != nil && len(*config.Authentication.AccessToken) > 0))
+		config.Region != nil && len(*config.Region) > 0
 }
 
 // interface: config/provider/CloudProvider functions
 
 func (p *googleProvider) Connect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	return p.connectLocked()
+}
+
+// connects if the provider has not been connected or its
+// clients are stale. the caller must hold the connect lock.
+func (p *googleProvider) connectLocked() error {
 
 	var (
 		err error
 	)
 
+	if p.isInitialized && p.isStale() {
+		// rebuild the clients using
+		// the current configuration
+		p.isInitialized = false
+	}
+	if !p.isInitialized {
+		if err = p.supplyCredentials(p); err != nil {
+			return err
+		}
+	}
 	if !p.IsValid() {
 		return fmt.Errorf("provider configuration is not valid")
 	}
 	if !p.isInitialized {
 
-		config := p.cloudProvider.
-			config.(*googleProviderConfig)
-
+		if p.tokenSource, err = p.newTokenSource(); err != nil {
+			return err
+		}
 		if p.computeService, err = compute.NewService(
 			p.ctx,
-			option.WithCredentialsJSON([]byte(*config.Authentication.Credentials)),
+			option.WithTokenSource(p.tokenSource),
 		); err != nil {
 			return err
 		}
 		if p.storageClient, err = storage.NewClient(
 			p.ctx,
-			option.WithCredentialsJSON([]byte(*config.Authentication.Credentials)),
+			option.WithTokenSource(p.tokenSource),
 		); err != nil {
 			return err
 		}
 
+		// credentials other than a static access token are
+		// refreshed so the provider only needs to reconnect
+		// with a new token before a static token expires
+		credentialsExpiry := time.Time{}
+		if p.hasStaticAccessToken() {
+			credentialsExpiry = p.accessTokenExpiry()
+		}
+		p.connected(credentialsExpiry)
 		p.isInitialized = true
 	}
 	return nil
 }
 
+// returns whether the provider authenticates
+// with a static access token that is not
+// refreshed when it expires
+func (p *googleProvider) hasStaticAccessToken() bool {
+
+	config := p.cloudProvider.
+		config.(*googleProviderConfig)
+	auth := config.Authentication
+
+	return (auth.Credentials == nil || len(*auth.Credentials) == 0) &&
+		auth.AccessToken != nil && len(*auth.AccessToken) > 0
+}
+
+// returns when the configured access token expires. if the
+// token's info cannot be retrieved it is assumed to expire
+// after the maximum lifetime of an access token.
+func (p *googleProvider) accessTokenExpiry() time.Time {
+
+	var (
+		err error
+
+		service   *oauth2api.Service
+		tokenInfo *oauth2api.Tokeninfo
+	)
+
+	config := p.cloudProvider.
+		config.(*googleProviderConfig)
+
+	ctx, cancel := context.WithTimeout(p.ctx, time.Second*30)
+	defer cancel()
+
+	now := time.Now()
+	if service, err = oauth2api.NewService(ctx, option.WithoutAuthentication()); err == nil {
+		if tokenInfo, err = service.Tokeninfo().
+			AccessToken(*config.Authentication.AccessToken).Context(ctx).Do(); err == nil && tokenInfo.ExpiresIn > 0 {
+
+			return now.Add(time.Duration(tokenInfo.ExpiresIn) * time.Second)
+		}
+	}
+	if err != nil {
+		logger.DebugMessage(
+			"Unable to retrieve the expiry of the google access token: %s", err.Error())
+	}
+	return now.Add(googleAccessTokenLifetime)
+}
+
+// Compute and storage entities retrieved before the
+// provider reconnects continue to use the previous
+// clients and need to be retrieved again.
+func (p *googleProvider) Reconnect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	p.isInitialized = false
+	return p.connectLocked()
+}
+
+// returns the source of the tokens that authenticate
+// api requests with the configured credentials
+func (p *googleProvider) newTokenSource() (oauth2.TokenSource, error) {
+
+	var (
+		err error
+
+		creds       *google.Credentials
+		tokenSource oauth2.TokenSource
+	)
+
+	config := p.cloudProvider.
+		config.(*googleProviderConfig)
+	auth := config.Authentication
+
+	switch {
+	case auth.Credentials != nil && len(*auth.Credentials) > 0:
+		// service account keys as well as workload identity
+		// federation (external_account) configurations
+		if creds, err = google.CredentialsFromJSON(
+			p.ctx, []byte(*auth.Credentials), compute.CloudPlatformScope,
+		); err != nil {
+			return nil, err
+		}
+		tokenSource = creds.TokenSource
+
+	case auth.AccessToken != nil && len(*auth.AccessToken) > 0:
+		// the token is not refreshed and
+		// will need to be replaced on expiry
+		tokenSource = oauth2.StaticTokenSource(&oauth2.Token{
+			AccessToken: *auth.AccessToken,
+		})
+
+	default:
+		// application default credentials i.e. those of a
+		// gcloud user login or the GCE metadata server
+		if creds, err = google.FindDefaultCredentials(p.ctx, compute.CloudPlatformScope); err != nil {
+			return nil, err
+		}
+		tokenSource = creds.TokenSource
+	}
+
+	if config.ImpersonateServiceAccount != nil && len(*config.ImpersonateServiceAccount) > 0 {
+		delegates := []string{}
+		if config.ImpersonateDelegates != nil {
+			for _, d := range strings.Split(*config.ImpersonateDelegates, ",") {
+				if d = strings.TrimSpace(d); len(d) > 0 {
+					delegates = append(delegates, d)
+				}
+			}
+		}
+
+		if tokenSource, err = impersonate.CredentialsTokenSource(p.ctx,
+			impersonate.CredentialsConfig{
+				TargetPrincipal: *config.ImpersonateServiceAccount,
+				Scopes:          []string{compute.CloudPlatformScope},
+				Delegates:       delegates,
+			},
+			option.WithTokenSource(tokenSource),
+		); err != nil {
+			return nil, err
+		}
+	}
+	return tokenSource, nil
+}
+
+func (p *googleProvider) Validate(ctx context.Context) (Identity, error) {
+
+	var (
+		err error
+
+		token     *oauth2.Token
+		service   *oauth2api.Service
+		tokenInfo *oauth2api.Tokeninfo
+		project   *compute.Project
+	)
+
+	if err = p.Connect(); err != nil {
+		return Identity{}, err
+	}
+
+	config := p.cloudProvider.
+		config.(*googleProviderConfig)
+
+	if token, err = p.tokenSource.Token(); err != nil {
+		return Identity{}, err
+	}
+	if service, err = oauth2api.NewService(ctx, option.WithoutAuthentication()); err != nil {
+		return Identity{}, err
+	}
+	if tokenInfo, err = service.Tokeninfo().AccessToken(token.AccessToken).Context(ctx).Do(); err != nil {
+		return Identity{}, err
+	}
+
+	// ensure the principal has access
+	// to the configured project
+	if project, err = p.computeService.Projects.Get(*config.Project).Context(ctx).Do(); err != nil {
+		return Identity{}, err
+	}
+
+	identity := Identity{
+		AccountID: project.Name,
+		Principal: tokenInfo.Email,
+	}
+	if len(identity.Principal) == 0 {
+		// the token does not have the email scope
+		identity.Principal = tokenInfo.UserId
+	}
+	if len(identity.Principal) == 0 {
+		identity.Principal = tokenInfo.IssuedTo
+	}
+	if tokenInfo.ExpiresIn > 0 {
+		identity.Expires = time.Now().Add(time.Duration(tokenInfo.ExpiresIn) * time.Second)
+	}
+	return identity, nil
+}
+
+// Actions are checked against the
+// IAM permissions on the project.
+func (p *googleProvider) CheckPermissions(actions []string) ([]string, error) {
+
+	var (
+		err error
+
+		service *cloudresourcemanager.Service
+		resp    *cloudresourcemanager.TestIamPermissionsResponse
+	)
+
+	if err = p.Connect(); err != nil {
+		return nil, err
+	}
+
+	config := p.cloudProvider.
+		config.(*googleProviderConfig)
+
+	if service, err = cloudresourcemanager.NewService(p.ctx, option.WithTokenSource(p.tokenSource)); err != nil {
+		return nil, err
+	}
+
+	granted := make(map[string]bool)
+	// at most 100 permissions can be tested per request
+	for i := 0; i < len(actions); i += 100 {
+		j := i + 100
+		if j > len(actions) {
+			j = len(actions)
+		}
+		if resp, err = service.Projects.TestIamPermissions(
+			*config.Project,
+			&cloudresourcemanager.TestIamPermissionsRequest{
+				Permissions: actions[i:j],
+			},
+		).Context(p.ctx).Do(); err != nil {
+			return nil, err
+		}
+		for _, permission := range resp.Permissions {
+			granted[permission] = true
+		}
+	}
+
+	denied := []string{}
+	for _, action := range actions {
+		if !granted[action] {
+			denied = append(denied, action)
+		}
+	}
+	return denied, nil
+}
+
 func (p *googleProvider) Region() *string {
 
 	config := p.cloudProvider.
@@ -333,6 +622,47 @@ func (p *googleProvider) GetRegions() []RegionInfo {
 	return regionInfoList
 }
 
+func (p *googleProvider) GetZones(region string) ([]ZoneInfo, error) {
+
+	var (
+		err error
+	)
+
+	if !p.isInitialized {
+		return nil, fmt.Errorf("google provider has not been initialized")
+	}
+	if region, err = zoneRegion(region, p.Region()); err != nil {
+		return nil, err
+	}
+
+	config := p.cloudProvider.
+		config.(*googleProviderConfig)
+
+	zones := []ZoneInfo{}
+	if err = p.computeService.Zones.List(*config.Project).
+		Pages(p.ctx,
+			func(page *compute.ZoneList) error {
+				for _, zone := range page.Items {
+					if path.Base(zone.Region) == region {
+						zones = append(zones,
+							ZoneInfo{
+								Name:      zone.Name,
+								Region:    region,
+								Available: zone.Status == "UP" && zone.Deprecated == nil,
+								Status:    zone.Status,
+							},
+						)
+					}
+				}
+				return nil
+			},
+		); err != nil {
+		return nil, err
+	}
+	sortZones(zones)
+	return zones, nil
+}
+
 func (p *googleProvider) GetCompute() (cloud.Compute, error) {
 
 	config := p.cloudProvider.
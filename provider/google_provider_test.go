@@ -1,7 +1,9 @@
 package provider_test
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/mevansam/gocloud/provider"
 	"github.com/mevansam/goforms/forms"
@@ -94,6 +96,18 @@ var _ = Describe("Google Provider Tests", func() {
 				Expect(found).To(BeTrue())
 			}
 		})
+
+		It("retrieves the zones of the configured region", func() {
+			testZones(googleProvider)
+		})
+
+		It("validates the credentials and retrieves the identity they authenticate as", func() {
+			testValidate(googleProvider)
+		})
+
+		It("checks the permissions of the predefined permission sets", func() {
+			testCheckPermissions(googleProvider)
+		})
 	})
 
 	Context("google provider config inputs", func() {
@@ -121,6 +135,82 @@ var _ = Describe("Google Provider Tests", func() {
 			test_data.ParseConfigDocument(googleProvider, googleConfigDocument, "googleProvider")
 			test_data.CopyConfigAndValidate(googleProvider, "access_token", "0640E5A6-8346-4F99-9ED7-7E384CCD0EAA", "random value for access_token")
 		})
+
+		It("connects using only an access token", func() {
+
+			var (
+				inputForm forms.InputForm
+			)
+
+			inputForm, err = googleProvider.InputForm()
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("project", "my-google-project")
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("region", "europe-west1")
+			Expect(err).NotTo(HaveOccurred())
+
+			// application default credentials
+			// are used if none are provided
+			Expect(googleProvider.IsValid()).To(BeTrue())
+
+			err = inputForm.SetFieldValue("access_token", "0640E5A6-8346-4F99-9ED7-7E384CCD0EAA")
+			Expect(err).NotTo(HaveOccurred())
+			err = googleProvider.Connect()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reconnects with credentials from the credential supplier when the config changes", func() {
+
+			var (
+				inputForm forms.InputForm
+			)
+
+			inputForm, err = googleProvider.InputForm()
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("project", "my-google-project")
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("region", "europe-west1")
+			Expect(err).NotTo(HaveOccurred())
+
+			supplied := 0
+			googleProvider.SetCredentialSupplier(func(cloudProvider provider.CloudProvider) error {
+				supplied++
+
+				inputForm, err := cloudProvider.InputForm()
+				if err != nil {
+					return err
+				}
+				return inputForm.SetFieldValue("access_token", fmt.Sprintf("access-token-%d", supplied))
+			})
+
+			err = googleProvider.Connect()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(supplied).To(Equal(1))
+
+			// the expiry of a static access token whose info
+			// cannot be retrieved is its maximum lifetime
+			Expect(googleProvider.CredentialsExpiry()).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+
+			// connecting again with the
+			// same config is a no-op
+			err = googleProvider.Connect()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(supplied).To(Equal(1))
+
+			err = inputForm.SetFieldValue("region", "us-east1")
+			Expect(err).NotTo(HaveOccurred())
+			err = googleProvider.Connect()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(supplied).To(Equal(2))
+
+			err = googleProvider.Reconnect()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(supplied).To(Equal(3))
+
+			accessToken, err := googleProvider.GetValue("access_token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*accessToken).To(Equal("access-token-3"))
+		})
 	})
 })
 
@@ -135,10 +225,13 @@ Google Cloud Platform
 
   Google Cloud authentication credentials
 
-  * Credentials - The contents of a service account key file in JSON format. It
-                  will be sourced from the environment variables
-                  GOOGLE_CREDENTIALS, GOOGLE_CLOUD_KEYFILE_JSON,
-                  GCLOUD_KEYFILE_JSON if not provided.
+  * Credentials - The contents of a service account key or workload identity
+                  federation configuration file in JSON format. Application
+                  default credentials are used if neither credentials nor an
+                  access token are provided. It will be sourced from the
+                  environment variables GOOGLE_CREDENTIALS,
+                  GOOGLE_CLOUD_KEYFILE_JSON, GCLOUD_KEYFILE_JSON if not
+                  provided.
 
   OR
 
@@ -146,13 +239,23 @@ Google Cloud Platform
                    Authorization server. It will be sourced from the environment
                    variable GOOGLE_OAUTH_ACCESS_TOKEN if not provided.
 
-* Project - The Google Cloud Platform project to manage resources in. It will be
-            sourced from the environment variables GOOGLE_PROJECT,
-            GOOGLE_CLOUD_PROJECT, GCLOUD_PROJECT, CLOUDSDK_CORE_PROJECT if not
-            provided.
-* Region  - The default region to manage resources in. It will be sourced from
-            the environment variables GOOGLE_REGION, GCLOUD_REGION,
-            CLOUDSDK_COMPUTE_REGION if not provided.`
+* Impersonate Service Account - The email of a service account to impersonate
+                                using the credentials. It will be sourced from
+                                the environment variable
+                                GOOGLE_IMPERSONATE_SERVICE_ACCOUNT if not
+                                provided.
+* Impersonation Delegates     - Comma separated list of service account emails
+                                in the delegation chain of the impersonated
+                                service account.
+* Project                     - The Google Cloud Platform project to manage
+                                resources in. It will be sourced from the
+                                environment variables GOOGLE_PROJECT,
+                                GOOGLE_CLOUD_PROJECT, GCLOUD_PROJECT,
+                                CLOUDSDK_CORE_PROJECT if not provided.
+* Region                      - The default region to manage resources in. It
+                                will be sourced from the environment variables
+                                GOOGLE_REGION, GCLOUD_REGION,
+                                CLOUDSDK_COMPUTE_REGION if not provided.`
 
 const googleConfigDocument = `
 {
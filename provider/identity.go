@@ -0,0 +1,19 @@
+package provider
+
+import "time"
+
+// the identity the provider's
+// credentials authenticate as
+type Identity struct {
+	// the aws account id, azure subscription
+	// id or google project id
+	AccountID string
+
+	// the arn, object id or service account
+	// email of the authenticated principal
+	Principal string
+
+	// the time the credentials expire. This is
+	// the zero time if they do not expire.
+	Expires time.Time
+}
@@ -0,0 +1,321 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/goforms/config"
+	"github.com/mevansam/goforms/forms"
+	"github.com/mevansam/goutils/utils"
+
+	forms_config "github.com/mevansam/gocloud/forms"
+)
+
+// the local host is the only region and zone
+const localRegion = "local"
+
+// sub-directories of the local provider's path
+// in which storage and process instances are kept
+const (
+	localStorageDir   = "storage"
+	localInstancesDir = "instances"
+)
+
+type localProvider struct {
+	cloudProvider
+
+	// indicates if provider client has
+	// been prepared to make API requests
+	isInitialized bool
+}
+
+type localProviderConfig struct {
+	ComputeType *string `json:"compute_type,omitempty" form_field:"compute_type"`
+	DockerHost  *string `json:"docker_host,omitempty" form_field:"docker_host"`
+	Path        *string `json:"path,omitempty" form_field:"path"`
+}
+
+func newLocalProvider() (CloudProvider, error) {
+
+	var (
+		err            error
+		providerConfig localProviderConfig
+	)
+
+	provider := &localProvider{
+		cloudProvider: cloudProvider{
+			name:   "local",
+			config: &providerConfig,
+
+			// the local backend's form
+			// group is named "local"
+			group: "local-provider",
+		},
+		isInitialized: false,
+	}
+	err = provider.createLocalInputForm()
+	return provider, err
+}
+
+func (p *localProvider) createLocalInputForm() error {
+
+	// Do not recreate form template if it exists
+	clougConfig := forms_config.CloudConfigForms
+	if clougConfig.HasGroup(p.groupName()) {
+		return nil
+	}
+
+	var (
+		err  error
+		form *forms.InputGroup
+	)
+
+	defaultPath := filepath.Join(os.TempDir(), "gocloud")
+	if home, err := os.UserHomeDir(); err == nil {
+		defaultPath = filepath.Join(home, ".gocloud", "local")
+	}
+
+	form = forms_config.CloudConfigForms.NewGroup(p.groupName(), "Local Development Host")
+
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "compute_type",
+		DisplayName:  "Compute Type",
+		Description:  "Whether instances are Docker containers or processes on the local host.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr("docker"),
+		Tags:         []string{"provider", "target-undeployed"},
+
+		AcceptedValues:             []string{"docker", "process"},
+		AcceptedValuesErrorMessage: "Compute type must be one of 'docker' or 'process'.",
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "docker_host",
+		DisplayName:  "Docker Host",
+		Description:  "The unix socket or tcp address of the Docker Engine API.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr("unix:///var/run/docker.sock"),
+		EnvVars: []string{
+			"DOCKER_HOST",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "path",
+		DisplayName:  "Path",
+		Description:  "The directory in which storage instances and process instances are kept.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr(defaultPath),
+		Tags:         []string{"provider", "target-undeployed"},
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// returns the value of the given field which will be
+// its default value if it has not been configured
+func (p *localProvider) value(name string) string {
+	if value, _ := p.GetValue(name); value != nil {
+		return *value
+	}
+	return ""
+}
+
+// interface: config/Configurable functions of base cloud provider
+
+func (p *localProvider) Copy() (config.Configurable, error) {
+
+	var (
+		err error
+
+		copy CloudProvider
+	)
+
+	if copy, err = newLocalProvider(); err != nil {
+		return nil, err
+	}
+
+	config := p.cloudProvider.
+		config.(*localProviderConfig)
+	configCopy := copy.(*localProvider).cloudProvider.
+		config.(*localProviderConfig)
+
+	configCopy.ComputeType = utils.CopyStrPtr(config.ComputeType)
+	configCopy.DockerHost = utils.CopyStrPtr(config.DockerHost)
+	configCopy.Path = utils.CopyStrPtr(config.Path)
+
+	return copy, nil
+}
+
+// All the fields have defaults so the provider is
+// valid without being configured. The docker host
+// is only required for docker instances.
+func (p *localProvider) IsValid() bool {
+
+	computeType := p.value("compute_type")
+	if computeType == "docker" && len(p.value("docker_host")) == 0 {
+		return false
+	}
+	return (computeType == "docker" || computeType == "process") &&
+		len(p.value("path")) > 0
+}
+
+// interface: config/provider/CloudProvider functions
+
+func (p *localProvider) Connect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	return p.connectLocked()
+}
+
+// connects if the provider has not been connected or its
+// clients are stale. the caller must hold the connect lock.
+func (p *localProvider) connectLocked() error {
+
+	var (
+		err error
+	)
+
+	if p.isInitialized && p.isStale() {
+		// rebuild the clients using
+		// the current configuration
+		p.isInitialized = false
+	}
+	if !p.isInitialized {
+		if err = p.supplyCredentials(p); err != nil {
+			return err
+		}
+	}
+	if !p.IsValid() {
+		return fmt.Errorf("provider configuration is not valid")
+	}
+	if !p.isInitialized {
+		if err = os.MkdirAll(p.value("path"), 0755); err != nil {
+			return err
+		}
+
+		// the local host does
+		// not have credentials
+		p.connected(time.Time{})
+		p.isInitialized = true
+	}
+	return nil
+}
+
+func (p *localProvider) Reconnect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	p.isInitialized = false
+	return p.connectLocked()
+}
+
+// The identity is the user running on the local host.
+// The docker engine is verified to be reachable if
+// instances are docker containers.
+func (p *localProvider) Validate(ctx context.Context) (Identity, error) {
+
+	var (
+		err error
+
+		currentUser *user.User
+		hostname    string
+	)
+
+	if err = p.Connect(); err != nil {
+		return Identity{}, err
+	}
+	if p.value("compute_type") == "docker" {
+		if _, err = p.GetCompute(); err != nil {
+			return Identity{}, err
+		}
+	}
+	if currentUser, err = user.Current(); err != nil {
+		return Identity{}, err
+	}
+	if hostname, err = os.Hostname(); err != nil {
+		return Identity{}, err
+	}
+	return Identity{
+		AccountID: hostname,
+		Principal: currentUser.Username,
+	}, nil
+}
+
+// Actions on the local host are not
+// restricted so they are all permitted.
+func (p *localProvider) CheckPermissions(actions []string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (p *localProvider) Region() *string {
+	return utils.PtrToStr(localRegion)
+}
+
+func (p *localProvider) GetRegions() []RegionInfo {
+	return []RegionInfo{
+		{
+			Name:        localRegion,
+			Description: "Local Host",
+		},
+	}
+}
+
+func (p *localProvider) GetZones(region string) ([]ZoneInfo, error) {
+
+	var (
+		err error
+	)
+
+	if region, err = zoneRegion(region, p.Region()); err != nil {
+		return nil, err
+	}
+	if region != localRegion {
+		return nil, fmt.Errorf("local region '%s' was not found", region)
+	}
+	return []ZoneInfo{
+		{
+			Name:      localRegion,
+			Region:    localRegion,
+			Available: true,
+			Status:    "available",
+		},
+	}, nil
+}
+
+// Instances are either the containers of the docker
+// engine or the processes whose specs are kept in
+// the "instances" sub-directory of the path.
+func (p *localProvider) GetCompute() (cloud.Compute, error) {
+
+	if !p.isInitialized {
+		return nil, fmt.Errorf("local provider has not been initialized")
+	}
+	if p.value("compute_type") == "process" {
+		return cloud.NewLocalProcessCompute(
+			filepath.Join(p.value("path"), localInstancesDir),
+		)
+	}
+	return cloud.NewLocalDockerCompute(p.value("docker_host"))
+}
+
+// Storage instances are directories within
+// the "storage" sub-directory of the path.
+func (p *localProvider) GetStorage() (cloud.Storage, error) {
+
+	if !p.isInitialized {
+		return nil, fmt.Errorf("local provider has not been initialized")
+	}
+	return cloud.NewLocalStorage(
+		filepath.Join(p.value("path"), localStorageDir),
+	)
+}
@@ -0,0 +1,171 @@
+package provider_test
+
+import (
+	"os"
+	"strings"
+
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/gocloud/provider"
+	"github.com/mevansam/goforms/forms"
+	"github.com/mevansam/goutils/term"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_data "github.com/mevansam/gocloud/test/data"
+)
+
+var _ = Describe("Local Provider Tests", func() {
+
+	var (
+		err error
+
+		outputBuffer  strings.Builder
+		localProvider provider.CloudProvider
+	)
+
+	BeforeEach(func() {
+		outputBuffer.Reset()
+
+		localProvider, err = provider.NewCloudProvider("local")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(localProvider).ToNot(BeNil())
+	})
+
+	Context("local provider config inputs", func() {
+
+		It("outputs a detailed input data form reference for local provider config inputs", func() {
+			testConfigReferenceOutput(localProvider, localInputDataReferenceOutput)
+		})
+
+		It("loads configuration values", func() {
+
+			test_data.ParseConfigDocument(localProvider, localConfigDocument, "localProvider")
+			test_data.ValidateLocalConfigDocument(localProvider)
+
+			// Run some negative tests
+			_, err = localProvider.GetValue("non_existent_key")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("saves configuration values", func() {
+			test_data.ParseConfigDocument(localProvider, localConfigDocument, "localProvider")
+			test_data.MarshalConfigDocumentAndValidate(localProvider, "localProvider", localConfigDocument)
+		})
+
+		It("validates the compute type", func() {
+
+			var (
+				inputForm forms.InputForm
+			)
+
+			// all fields have defaults
+			Expect(localProvider.IsValid()).To(BeTrue())
+
+			inputForm, err = localProvider.InputForm()
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("compute_type", "vm")
+			Expect(err).To(HaveOccurred())
+			err = inputForm.SetFieldValue("compute_type", "process")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(localProvider.IsValid()).To(BeTrue())
+		})
+
+		It("does not share its input form with the local backend", func() {
+			Expect(localProvider.Name()).To(Equal("local"))
+			Expect(localProvider.Description()).To(Equal("Local Development Host"))
+		})
+	})
+
+	It("provides compute and storage entities", func() {
+
+		var (
+			inputForm forms.InputForm
+
+			compute cloud.Compute
+			storage cloud.Storage
+		)
+
+		path, err := os.MkdirTemp("", "localprovidertest")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(path)
+
+		test_data.ParseConfigDocument(localProvider, localConfigDocument, "localProvider")
+		inputForm, err = localProvider.InputForm()
+		Expect(err).NotTo(HaveOccurred())
+		err = inputForm.SetFieldValue("path", path)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = localProvider.GetCompute()
+		Expect(err).To(HaveOccurred())
+
+		err = localProvider.Connect()
+		Expect(err).NotTo(HaveOccurred())
+
+		compute, err = localProvider.GetCompute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(compute).ToNot(BeNil())
+		storage, err = localProvider.GetStorage()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storage).ToNot(BeNil())
+
+		denied, err := localProvider.CheckPermissions([]string{"instance:start"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(denied).To(BeEmpty())
+
+		zones, err := localProvider.GetZones("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(zones)).To(Equal(1))
+		Expect(zones[0].Region).To(Equal("local"))
+
+		// docker instances require a
+		// reachable docker engine
+		err = inputForm.SetFieldValue("compute_type", "docker")
+		Expect(err).NotTo(HaveOccurred())
+		err = inputForm.SetFieldValue("docker_host", "unix://"+path+"/docker.sock")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = localProvider.Connect()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = localProvider.GetCompute()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns the terraform environment variables", func() {
+
+		test_data.ParseConfigDocument(localProvider, localConfigDocument, "localProvider")
+
+		vars := make(map[string]string)
+		err = localProvider.GetVars(vars)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vars["DOCKER_HOST"]).To(Equal("unix:///var/run/docker.sock"))
+	})
+
+	It("creates a copy of itself", func() {
+		test_data.ParseConfigDocument(localProvider, localConfigDocument, "localProvider")
+		test_data.CopyConfigAndValidate(localProvider, "path", "/tmp/gocloud-test/local", "/tmp/gocloud-test/copy")
+	})
+})
+
+const localInputDataReferenceOutput = term.BOLD + `Cloud Provider Configuration
+============================` + term.NC + `
+
+Local Development Host
+
+` + term.ITALIC + `CONFIGURATION DATA INPUT REFERENCE` + term.NC + `
+
+* Compute Type - Whether instances are Docker containers or processes on the
+                 local host.
+* Docker Host  - The unix socket or tcp address of the Docker Engine API. It
+                 will be sourced from the environment variable DOCKER_HOST if
+                 not provided.
+* Path         - The directory in which storage instances and process instances
+                 are kept.`
+
+const localConfigDocument = `
+{
+	"cloud": {
+		"localProvider": ` + test_data.LocalProviderConfig + `
+	}
+}
+`
@@ -1,6 +1,8 @@
 package provider
 
 import (
+	"context"
+
 	"github.com/mevansam/gocloud/cloud"
 	"github.com/mevansam/goforms/config"
 	"github.com/mevansam/goforms/forms"
@@ -53,6 +55,18 @@ func (p *nullProvider) Connect() error {
 	return nil
 }
 
+func (p *nullProvider) Reconnect() error {
+	return nil
+}
+
+func (p *nullProvider) Validate(ctx context.Context) (Identity, error) {
+	return Identity{}, nil
+}
+
+func (p *nullProvider) CheckPermissions(actions []string) ([]string, error) {
+	return []string{}, nil
+}
+
 func (p *nullProvider) Region() *string {
 	var region string
 	return &region
@@ -62,6 +76,10 @@ func (p *nullProvider) GetRegions() []RegionInfo {
 	return []RegionInfo{{Name: ""}}
 }
 
+func (p *nullProvider) GetZones(region string) ([]ZoneInfo, error) {
+	return []ZoneInfo{}, nil
+}
+
 func (p *nullProvider) GetCompute() (cloud.Compute, error) {
 	return nil, nil
 }
@@ -0,0 +1,527 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/availabilityzones"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/tokens"
+	"github.com/gophercloud/gophercloud/pagination"
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/goforms/config"
+	"github.com/mevansam/goforms/forms"
+	"github.com/mevansam/goutils/logger"
+	"github.com/mevansam/goutils/utils"
+
+	forms_config "github.com/mevansam/gocloud/forms"
+)
+
+type openStackProvider struct {
+	cloudProvider
+
+	// background context
+	ctx context.Context
+
+	// indicates if provider client has
+	// been prepared to make API requests
+	isInitialized bool
+
+	client *gophercloud.ProviderClient
+}
+
+type openStackProviderConfig struct {
+	AuthURL                     *string `json:"auth_url,omitempty" form_field:"auth_url"`
+	Region                      *string `json:"region,omitempty" form_field:"region"`
+	Username                    *string `json:"username,omitempty" form_field:"username"`
+	Password                    *string `json:"password,omitempty" form_field:"password"`
+	ProjectName                 *string `json:"project_name,omitempty" form_field:"project_name"`
+	UserDomainName              *string `json:"user_domain_name,omitempty" form_field:"user_domain_name"`
+	ProjectDomainName           *string `json:"project_domain_name,omitempty" form_field:"project_domain_name"`
+	ApplicationCredentialID     *string `json:"application_credential_id,omitempty" form_field:"application_credential_id"`
+	ApplicationCredentialSecret *string `json:"application_credential_secret,omitempty" form_field:"application_credential_secret"`
+}
+
+func newOpenStackProvider() (CloudProvider, error) {
+
+	var (
+		err            error
+		providerConfig openStackProviderConfig
+	)
+
+	provider := &openStackProvider{
+		cloudProvider: cloudProvider{
+			name:   "openstack",
+			config: &providerConfig,
+		},
+		ctx:           context.Background(),
+		isInitialized: false,
+	}
+	err = provider.createOpenStackInputForm()
+	return provider, err
+}
+
+func (p *openStackProvider) createOpenStackInputForm() error {
+
+	// Do not recreate form template if it exists
+	clougConfig := forms_config.CloudConfigForms
+	if clougConfig.HasGroup(p.name) {
+		return nil
+	}
+
+	var (
+		err  error
+		form *forms.InputGroup
+	)
+
+	form = forms_config.CloudConfigForms.NewGroup(p.name, "OpenStack Cloud")
+
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "auth_url",
+		DisplayName: "Authentication URL",
+		Description: "The URL of the OpenStack identity (keystone) service.",
+		InputType:   forms.String,
+		EnvVars: []string{
+			"OS_AUTH_URL",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "region",
+		DisplayName:  "Region",
+		Description:  "The OpenStack region to create resources in.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr("RegionOne"),
+		EnvVars: []string{
+			"OS_REGION_NAME",
+		},
+		Tags: []string{"provider", "target-undeployed"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "username",
+		DisplayName: "Username",
+		Description: "The name of the user to authenticate as. It is not required if an application credential is provided.",
+		InputType:   forms.String,
+		EnvVars: []string{
+			"OS_USERNAME",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "password",
+		DisplayName: "Password",
+		Description: "The password of the user.",
+		InputType:   forms.String,
+		Sensitive:   true,
+		EnvVars: []string{
+			"OS_PASSWORD",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "project_name",
+		DisplayName: "Project Name",
+		Description: "The name of the project to manage resources in.",
+		InputType:   forms.String,
+		EnvVars: []string{
+			"OS_PROJECT_NAME",
+			"OS_TENANT_NAME",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "user_domain_name",
+		DisplayName:  "User Domain Name",
+		Description:  "The name of the domain the user belongs to.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr("Default"),
+		EnvVars: []string{
+			"OS_USER_DOMAIN_NAME",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "project_domain_name",
+		DisplayName:  "Project Domain Name",
+		Description:  "The name of the domain the project belongs to.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr("Default"),
+		EnvVars: []string{
+			"OS_PROJECT_DOMAIN_NAME",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "application_credential_id",
+		DisplayName: "Application Credential ID",
+		Description: "The id of an application credential to authenticate with instead of a username and password.",
+		InputType:   forms.String,
+		EnvVars: []string{
+			"OS_APPLICATION_CREDENTIAL_ID",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "application_credential_secret",
+		DisplayName: "Application Credential Secret",
+		Description: "The secret of the application credential.",
+		InputType:   forms.String,
+		Sensitive:   true,
+		EnvVars: []string{
+			"OS_APPLICATION_CREDENTIAL_SECRET",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// returns whether an application credential
+// has been configured for authentication
+func (c *openStackProviderConfig) hasApplicationCredential() bool {
+	return c.ApplicationCredentialID != nil && len(*c.ApplicationCredentialID) > 0
+}
+
+// interface: config/Configurable functions of base cloud provider
+
+func (p *openStackProvider) Copy() (config.Configurable, error) {
+
+	var (
+		err error
+
+		copy CloudProvider
+	)
+
+	if copy, err = newOpenStackProvider(); err != nil {
+		return nil, err
+	}
+
+	config := p.cloudProvider.
+		config.(*openStackProviderConfig)
+	configCopy := copy.(*openStackProvider).cloudProvider.
+		config.(*openStackProviderConfig)
+
+	configCopy.AuthURL = utils.CopyStrPtr(config.AuthURL)
+	configCopy.Region = utils.CopyStrPtr(config.Region)
+	configCopy.Username = utils.CopyStrPtr(config.Username)
+	configCopy.Password = utils.CopyStrPtr(config.Password)
+	configCopy.ProjectName = utils.CopyStrPtr(config.ProjectName)
+	configCopy.UserDomainName = utils.CopyStrPtr(config.UserDomainName)
+	configCopy.ProjectDomainName = utils.CopyStrPtr(config.ProjectDomainName)
+	configCopy.ApplicationCredentialID = utils.CopyStrPtr(config.ApplicationCredentialID)
+	configCopy.ApplicationCredentialSecret = utils.CopyStrPtr(config.ApplicationCredentialSecret)
+
+	return copy, nil
+}
+
+func (p *openStackProvider) IsValid() bool {
+
+	config := p.cloudProvider.
+		config.(*openStackProviderConfig)
+
+	if config.AuthURL == nil || len(*config.AuthURL) == 0 ||
+		config.Region == nil || len(*config.Region) == 0 {
+		return false
+	}
+
+	// an application credential is scoped to its
+	// project so the username, password and project
+	// are only required if one is not provided
+	if config.hasApplicationCredential() {
+		return config.ApplicationCredentialSecret != nil &&
+			len(*config.ApplicationCredentialSecret) > 0
+	}
+	return config.Username != nil && len(*config.Username) > 0 &&
+		config.Password != nil && len(*config.Password) > 0 &&
+		config.ProjectName != nil && len(*config.ProjectName) > 0
+}
+
+// interface: config/provider/CloudProvider functions
+
+func (p *openStackProvider) Connect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	return p.connectLocked()
+}
+
+// connects if the provider has not been connected or its
+// clients are stale. the caller must hold the connect lock.
+func (p *openStackProvider) connectLocked() error {
+
+	var (
+		err error
+	)
+
+	if p.isInitialized && p.isStale() {
+		// rebuild the clients using
+		// the current configuration
+		p.isInitialized = false
+	}
+	if !p.isInitialized {
+		if err = p.supplyCredentials(p); err != nil {
+			return err
+		}
+	}
+	if !p.IsValid() {
+		return fmt.Errorf("provider configuration is not valid")
+	}
+	if !p.isInitialized {
+		config := p.cloudProvider.
+			config.(*openStackProviderConfig)
+
+		authOptions := gophercloud.AuthOptions{
+			IdentityEndpoint: *config.AuthURL,
+			AllowReauth:      true,
+		}
+		if config.hasApplicationCredential() {
+			authOptions.ApplicationCredentialID = *config.ApplicationCredentialID
+			authOptions.ApplicationCredentialSecret = *config.ApplicationCredentialSecret
+		} else {
+			// the domain names are read via the form
+			// so that their defaults are applied
+			domainName := func(name string) string {
+				if value, _ := p.GetValue(name); value != nil {
+					return *value
+				}
+				return ""
+			}
+			authOptions.Username = *config.Username
+			authOptions.Password = *config.Password
+			authOptions.DomainName = domainName("user_domain_name")
+			authOptions.Scope = &gophercloud.AuthScope{
+				ProjectName: *config.ProjectName,
+				DomainName:  domainName("project_domain_name"),
+			}
+		}
+
+		if p.client, err = openstack.AuthenticatedClient(authOptions); err != nil {
+			return err
+		}
+
+		// the token is re-issued automatically
+		// when it expires as reauth is allowed
+		p.connected(time.Time{})
+		p.isInitialized = true
+	}
+	return nil
+}
+
+// Compute and storage entities retrieved before the
+// provider reconnects continue to use the previous
+// client and need to be retrieved again.
+func (p *openStackProvider) Reconnect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	p.isInitialized = false
+	return p.connectLocked()
+}
+
+func (p *openStackProvider) Validate(ctx context.Context) (Identity, error) {
+
+	var (
+		err error
+		ok  bool
+
+		authResult tokens.CreateResult
+		user       *tokens.User
+		project    *tokens.Project
+	)
+
+	if err = p.Connect(); err != nil {
+		return Identity{}, err
+	}
+	if authResult, ok = p.client.GetAuthResult().(tokens.CreateResult); !ok {
+		return Identity{}, fmt.Errorf("the openstack identity service did not return a v3 token")
+	}
+	if user, err = authResult.ExtractUser(); err != nil {
+		return Identity{}, err
+	}
+	if project, err = authResult.ExtractProject(); err != nil {
+		return Identity{}, err
+	}
+
+	identity := Identity{
+		Principal: user.Name,
+	}
+	if project != nil {
+		identity.AccountID = project.ID
+	}
+	return identity, nil
+}
+
+// Keystone does not provide an api to evaluate the
+// policies of the services so permissions are not
+// checked.
+func (p *openStackProvider) CheckPermissions(actions []string) ([]string, error) {
+	return nil, fmt.Errorf("openstack provider does not support checking permissions")
+}
+
+func (p *openStackProvider) Region() *string {
+
+	config := p.cloudProvider.
+		config.(*openStackProviderConfig)
+
+	return config.Region
+}
+
+// The regions of an OpenStack cloud are specific to the
+// cloud so they are taken from the regions of the compute
+// endpoints in the service catalog. Only the configured
+// region is returned if the provider is not connected.
+func (p *openStackProvider) GetRegions() []RegionInfo {
+
+	var (
+		err error
+		ok  bool
+
+		authResult tokens.CreateResult
+		catalog    *tokens.ServiceCatalog
+	)
+
+	regionInfoList := []RegionInfo{}
+
+	if p.isInitialized {
+		if authResult, ok = p.client.GetAuthResult().(tokens.CreateResult); ok {
+			if catalog, err = authResult.ExtractServiceCatalog(); err == nil {
+				added := make(map[string]bool)
+				for _, entry := range catalog.Entries {
+					if entry.Type != "compute" {
+						continue
+					}
+					for _, endpoint := range entry.Endpoints {
+						if len(endpoint.Region) > 0 && !added[endpoint.Region] {
+							regionInfoList = append(regionInfoList,
+								RegionInfo{
+									Name:        endpoint.Region,
+									Description: endpoint.Region,
+								},
+							)
+							added[endpoint.Region] = true
+						}
+					}
+				}
+				sortRegions(regionInfoList)
+				logger.TraceMessage("OpenStack region list retrieved from service catalog: %# v", regionInfoList)
+
+				return regionInfoList
+			}
+
+			logger.DebugMessage("Unable to retrieve OpenStack service catalog: %s", err.Error())
+		}
+	}
+
+	if region := p.Region(); region != nil && len(*region) > 0 {
+		regionInfoList = append(regionInfoList,
+			RegionInfo{
+				Name:        *region,
+				Description: *region,
+			},
+		)
+	}
+	return regionInfoList
+}
+
+func (p *openStackProvider) GetZones(region string) ([]ZoneInfo, error) {
+
+	var (
+		err error
+
+		client *gophercloud.ServiceClient
+		page   pagination.Page
+		list   []availabilityzones.AvailabilityZone
+	)
+
+	if !p.isInitialized {
+		return nil, fmt.Errorf("openstack provider has not been initialized")
+	}
+	if region, err = zoneRegion(region, p.Region()); err != nil {
+		return nil, err
+	}
+	if client, err = openstack.NewComputeV2(p.client, gophercloud.EndpointOpts{
+		Region: region,
+	}); err != nil {
+		return nil, err
+	}
+	if page, err = availabilityzones.List(client).AllPages(); err != nil {
+		return nil, err
+	}
+	if list, err = availabilityzones.ExtractAvailabilityZones(page); err != nil {
+		return nil, err
+	}
+
+	zones := make([]ZoneInfo, 0, len(list))
+	for _, zone := range list {
+		status := "available"
+		if !zone.ZoneState.Available {
+			status = "unavailable"
+		}
+		zones = append(zones, ZoneInfo{
+			Name:      zone.ZoneName,
+			Region:    region,
+			Available: zone.ZoneState.Available,
+			Status:    status,
+		})
+	}
+	sortZones(zones)
+	return zones, nil
+}
+
+func (p *openStackProvider) GetCompute() (cloud.Compute, error) {
+
+	if !p.isInitialized {
+		return nil, fmt.Errorf("openstack provider has not been initialized")
+	}
+
+	config := p.cloudProvider.
+		config.(*openStackProviderConfig)
+
+	return cloud.NewOpenStackCompute(
+		p.client,
+		*config.Region,
+	)
+}
+
+// Storage instances are swift containers
+// in the configured region.
+func (p *openStackProvider) GetStorage() (cloud.Storage, error) {
+
+	var (
+		err error
+
+		client *gophercloud.ServiceClient
+	)
+
+	if !p.isInitialized {
+		return nil, fmt.Errorf("openstack provider has not been initialized")
+	}
+
+	config := p.cloudProvider.
+		config.(*openStackProviderConfig)
+
+	if client, err = openstack.NewObjectStorageV1(p.client, gophercloud.EndpointOpts{
+		Region: *config.Region,
+	}); err != nil {
+		return nil, err
+	}
+	return cloud.NewOpenStackStorage(client)
+}
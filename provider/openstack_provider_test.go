@@ -0,0 +1,181 @@
+package provider_test
+
+import (
+	"strings"
+
+	"github.com/mevansam/gocloud/provider"
+	"github.com/mevansam/goforms/forms"
+	"github.com/mevansam/goutils/term"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_data "github.com/mevansam/gocloud/test/data"
+)
+
+var _ = Describe("OpenStack Provider Tests", func() {
+
+	var (
+		err error
+
+		outputBuffer      strings.Builder
+		openStackProvider provider.CloudProvider
+	)
+
+	BeforeEach(func() {
+		outputBuffer.Reset()
+
+		openStackProvider, err = provider.NewCloudProvider("openstack")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(openStackProvider).ToNot(BeNil())
+	})
+
+	Context("openstack provider config inputs", func() {
+
+		It("outputs a detailed input data form reference for openstack provider config inputs", func() {
+			testConfigReferenceOutput(openStackProvider, openStackInputDataReferenceOutput)
+		})
+
+		It("loads configuration values", func() {
+
+			test_data.ParseConfigDocument(openStackProvider, openStackConfigDocument, "openStackProvider")
+			test_data.ValidateOpenStackConfigDocument(openStackProvider)
+
+			// Run some negative tests
+			_, err = openStackProvider.GetValue("non_existent_key")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("saves configuration values", func() {
+			test_data.ParseConfigDocument(openStackProvider, openStackConfigDocument, "openStackProvider")
+			test_data.MarshalConfigDocumentAndValidate(openStackProvider, "openStackProvider", openStackConfigDocument)
+		})
+
+		It("validates the application credential or the user credentials", func() {
+
+			var (
+				inputForm forms.InputForm
+			)
+
+			test_data.ParseConfigDocument(openStackProvider, openStackConfigDocument, "openStackProvider")
+			Expect(openStackProvider.IsValid()).To(BeTrue())
+
+			inputForm, err = openStackProvider.InputForm()
+			Expect(err).NotTo(HaveOccurred())
+
+			// an application credential
+			// requires its secret
+			err = inputForm.SetFieldValue("application_credential_secret", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(openStackProvider.IsValid()).To(BeFalse())
+
+			// without an application credential the
+			// user credentials and project are required
+			err = inputForm.SetFieldValue("application_credential_id", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(openStackProvider.IsValid()).To(BeTrue())
+			err = inputForm.SetFieldValue("password", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(openStackProvider.IsValid()).To(BeFalse())
+		})
+	})
+
+	It("does not provide compute and storage entities until connected", func() {
+
+		var (
+			inputForm forms.InputForm
+		)
+
+		test_data.ParseConfigDocument(openStackProvider, openStackConfigDocument, "openStackProvider")
+
+		_, err = openStackProvider.GetCompute()
+		Expect(err).To(HaveOccurred())
+		_, err = openStackProvider.GetStorage()
+		Expect(err).To(HaveOccurred())
+
+		_, err = openStackProvider.CheckPermissions([]string{"compute:create"})
+		Expect(err).To(HaveOccurred())
+
+		inputForm, err = openStackProvider.InputForm()
+		Expect(err).NotTo(HaveOccurred())
+		err = inputForm.SetFieldValue("auth_url", "")
+		Expect(err).NotTo(HaveOccurred())
+		err = openStackProvider.Connect()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns the terraform environment variables", func() {
+
+		test_data.ParseConfigDocument(openStackProvider, openStackConfigDocument, "openStackProvider")
+
+		vars := make(map[string]string)
+		err = openStackProvider.GetVars(vars)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vars["OS_AUTH_URL"]).To(Equal("https://keystone.example.com:5000/v3"))
+		Expect(vars["OS_REGION_NAME"]).To(Equal("RegionTwo"))
+		Expect(vars["OS_USERNAME"]).To(Equal("cbtest"))
+		Expect(vars["OS_PASSWORD"]).To(Equal("Qx7pZ2mK9vRt4sWn"))
+		Expect(vars["OS_PROJECT_NAME"]).To(Equal("cbproject"))
+		Expect(vars["OS_TENANT_NAME"]).To(Equal(vars["OS_PROJECT_NAME"]))
+		Expect(vars["OS_USER_DOMAIN_NAME"]).To(Equal("Default"))
+		Expect(vars["OS_PROJECT_DOMAIN_NAME"]).To(Equal("Default"))
+		Expect(vars["OS_APPLICATION_CREDENTIAL_ID"]).To(Equal("8d2b6f1e4a9c47d3b0e5f7a2c9d1e6b4"))
+		Expect(vars["OS_APPLICATION_CREDENTIAL_SECRET"]).To(Equal("Hk3vT9wQ2mZp7xRb5nLc8yFd4sJg6aUe"))
+	})
+
+	It("creates a copy of itself", func() {
+		test_data.ParseConfigDocument(openStackProvider, openStackConfigDocument, "openStackProvider")
+		test_data.CopyConfigAndValidate(openStackProvider, "region", "RegionTwo", "RegionThree")
+	})
+})
+
+const openStackInputDataReferenceOutput = term.BOLD + `Cloud Provider Configuration
+============================` + term.NC + `
+
+OpenStack Cloud
+
+` + term.ITALIC + `CONFIGURATION DATA INPUT REFERENCE` + term.NC + `
+
+* Authentication URL            - The URL of the OpenStack identity (keystone)
+                                  service. It will be sourced from the
+                                  environment variable OS_AUTH_URL if not
+                                  provided.
+* Region                        - The OpenStack region to create resources in.
+                                  It will be sourced from the environment
+                                  variable OS_REGION_NAME if not provided.
+* Username                      - The name of the user to authenticate as. It is
+                                  not required if an application credential is
+                                  provided. It will be sourced from the
+                                  environment variable OS_USERNAME if not
+                                  provided.
+* Password                      - The password of the user. It will be sourced
+                                  from the environment variable OS_PASSWORD if
+                                  not provided.
+* Project Name                  - The name of the project to manage resources
+                                  in. It will be sourced from the environment
+                                  variables OS_PROJECT_NAME, OS_TENANT_NAME if
+                                  not provided.
+* User Domain Name              - The name of the domain the user belongs to. It
+                                  will be sourced from the environment variable
+                                  OS_USER_DOMAIN_NAME if not provided.
+* Project Domain Name           - The name of the domain the project belongs to.
+                                  It will be sourced from the environment
+                                  variable OS_PROJECT_DOMAIN_NAME if not
+                                  provided.
+* Application Credential ID     - The id of an application credential to
+                                  authenticate with instead of a username and
+                                  password. It will be sourced from the
+                                  environment variable
+                                  OS_APPLICATION_CREDENTIAL_ID if not provided.
+* Application Credential Secret - The secret of the application credential. It
+                                  will be sourced from the environment variable
+                                  OS_APPLICATION_CREDENTIAL_SECRET if not
+                                  provided.`
+
+const openStackConfigDocument = `
+{
+	"cloud": {
+		"openStackProvider": ` + test_data.OpenStackProviderConfig + `
+	}
+}
+`
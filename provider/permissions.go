@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"fmt"
+)
+
+// names of the predefined sets of permissions
+// required by common cloud operations
+const (
+	// creating and accessing the storage
+	// used by a terraform state backend
+	StorageBackendBootstrap = "storage-backend-bootstrap"
+
+	// listing, starting, stopping, restarting
+	// and resizing compute instances
+	ComputeLifecycle = "compute-lifecycle"
+)
+
+// cloud specific actions of each permission set
+var permissionSets = map[string]map[string][]string{
+	StorageBackendBootstrap: {
+		"aws": {
+			"s3:ListAllMyBuckets",
+			"s3:GetBucketLocation",
+			"s3:CreateBucket",
+			"s3:PutBucketPublicAccessBlock",
+			"s3:PutBucketObjectLockConfiguration",
+			"s3:PutBucketVersioning",
+			"s3:ListBucket",
+			"s3:ListBucketVersions",
+			"s3:GetObject",
+			"s3:PutObject",
+			"s3:DeleteObject",
+			"s3:DeleteObjectVersion",
+		},
+		"azure": {
+			"Microsoft.Resources/subscriptions/resourceGroups/read",
+			"Microsoft.Storage/checknameavailability/read",
+			"Microsoft.Storage/storageAccounts/read",
+			"Microsoft.Storage/storageAccounts/write",
+			"Microsoft.Storage/storageAccounts/blobServices/containers/read",
+			"Microsoft.Storage/storageAccounts/blobServices/containers/write",
+			"Microsoft.Authorization/roleDefinitions/read",
+			"Microsoft.Authorization/roleAssignments/write",
+		},
+		"google": {
+			"storage.buckets.create",
+			"storage.buckets.get",
+			"storage.buckets.list",
+			"storage.objects.create",
+			"storage.objects.delete",
+			"storage.objects.get",
+			"storage.objects.list",
+		},
+	},
+	ComputeLifecycle: {
+		"aws": {
+			"ec2:DescribeInstances",
+			"ec2:DescribeInstanceTypes",
+			"ec2:StartInstances",
+			"ec2:StopInstances",
+			"ec2:RebootInstances",
+			"ec2:ModifyInstanceAttribute",
+			"ec2:GetConsoleOutput",
+		},
+		"azure": {
+			"Microsoft.Compute/virtualMachines/read",
+			"Microsoft.Compute/virtualMachines/write",
+			"Microsoft.Compute/virtualMachines/start/action",
+			"Microsoft.Compute/virtualMachines/powerOff/action",
+			"Microsoft.Compute/virtualMachines/deallocate/action",
+			"Microsoft.Compute/virtualMachines/restart/action",
+			"Microsoft.Compute/virtualMachines/vmSizes/read",
+			"Microsoft.Network/networkInterfaces/read",
+			"Microsoft.Network/publicIPAddresses/read",
+		},
+		"google": {
+			"compute.instances.list",
+			"compute.instances.get",
+			"compute.instances.start",
+			"compute.instances.stop",
+			"compute.instances.reset",
+			"compute.instances.suspend",
+			"compute.instances.resume",
+			"compute.instances.setMachineType",
+			"compute.instances.getSerialPortOutput",
+			"compute.machineTypes.list",
+			"compute.zones.list",
+			"compute.zoneOperations.get",
+		},
+	},
+}
+
+// Returns the cloud specific actions of the named
+// permission set for the given cloud provider
+func PermissionSet(cloudProvider CloudProvider, name string) ([]string, error) {
+
+	var (
+		ok bool
+
+		actionsByProvider map[string][]string
+		actions           []string
+	)
+
+	if actionsByProvider, ok = permissionSets[name]; !ok {
+		return nil, fmt.Errorf("unknown permission set '%s'", name)
+	}
+	if actions, ok = actionsByProvider[cloudProvider.Name()]; !ok {
+		return nil, fmt.Errorf(
+			"permission set '%s' is not defined for cloud provider '%s'",
+			name, cloudProvider.Name(),
+		)
+	}
+	return append([]string{}, actions...), nil
+}
+
+// Checks the actions of the named permission set
+// and returns the actions that are not permitted
+func CheckPermissionSet(cloudProvider CloudProvider, name string) ([]string, error) {
+
+	var (
+		err error
+
+		actions []string
+	)
+
+	if actions, err = PermissionSet(cloudProvider, name); err != nil {
+		return nil, err
+	}
+	return cloudProvider.CheckPermissions(actions)
+}
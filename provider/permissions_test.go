@@ -0,0 +1,152 @@
+package provider_test
+
+import (
+	"fmt"
+
+	"github.com/mevansam/gocloud/provider"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Permission Sets", func() {
+
+	It("returns the actions of the predefined permission sets for each cloud provider", func() {
+
+		for _, name := range []string{"aws", "azure", "google"} {
+			cloudProvider, err := provider.NewCloudProvider(name)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, set := range []string{
+				provider.StorageBackendBootstrap,
+				provider.ComputeLifecycle,
+			} {
+				actions, err := provider.PermissionSet(cloudProvider, set)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(actions)).To(BeNumerically(">", 0))
+			}
+		}
+	})
+
+	It("includes the actions required to clean up a versioned aws state bucket", func() {
+
+		cloudProvider, err := provider.NewCloudProvider("aws")
+		Expect(err).NotTo(HaveOccurred())
+
+		actions, err := provider.PermissionSet(cloudProvider, provider.StorageBackendBootstrap)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actions).To(ContainElements("s3:ListBucketVersions", "s3:DeleteObjectVersion"))
+	})
+
+	It("returns an error for unknown permission sets", func() {
+
+		cloudProvider, err := provider.NewCloudProvider("aws")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = provider.PermissionSet(cloudProvider, "unknown")
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("aws policy source", func() {
+
+		var (
+			roleLookups []string
+		)
+
+		// role arns as they would be returned by iam
+		roleARNs := map[string]string{
+			"cloudbuilder": "arn:aws:iam::123456789012:role/ops/automation/cloudbuilder",
+			"deployer":     "arn:aws-us-gov:iam::123456789012:role/deployer",
+		}
+		roleARN := func(role string) (string, error) {
+			roleLookups = append(roleLookups, role)
+			if arn, exists := roleARNs[role]; exists {
+				return arn, nil
+			}
+			return "", fmt.Errorf("role '%s' was not found", role)
+		}
+
+		BeforeEach(func() {
+			roleLookups = []string{}
+		})
+
+		DescribeTable("resolves the principal whose policies are simulated",
+			func(callerARN, expectedARN string, expectedRoot bool, expectedLookups []string) {
+				arn, isRoot, err := provider.AWSPolicySourceARN(callerARN, roleARN)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(arn).To(Equal(expectedARN))
+				Expect(isRoot).To(Equal(expectedRoot))
+				Expect(roleLookups).To(Equal(expectedLookups))
+			},
+			Entry("the root user",
+				"arn:aws:iam::123456789012:root",
+				"arn:aws:iam::123456789012:root", true, []string{}),
+			Entry("an iam user",
+				"arn:aws:iam::123456789012:user/cloudbuilder",
+				"arn:aws:iam::123456789012:user/cloudbuilder", false, []string{}),
+			Entry("an iam user with a path",
+				"arn:aws:iam::123456789012:user/ops/cloudbuilder",
+				"arn:aws:iam::123456789012:user/ops/cloudbuilder", false, []string{}),
+			Entry("an assumed role with a path",
+				"arn:aws:sts::123456789012:assumed-role/cloudbuilder/session",
+				"arn:aws:iam::123456789012:role/ops/automation/cloudbuilder", false, []string{"cloudbuilder"}),
+			Entry("an assumed role in another partition",
+				"arn:aws-us-gov:sts::123456789012:assumed-role/deployer/session",
+				"arn:aws-us-gov:iam::123456789012:role/deployer", false, []string{"deployer"}),
+			Entry("a caller that is not an arn",
+				"cloudbuilder", "cloudbuilder", false, []string{}),
+		)
+
+		It("returns an error if the assumed role cannot be looked up", func() {
+			_, _, err := provider.AWSPolicySourceARN("arn:aws:sts::123456789012:assumed-role/unknown/session", roleARN)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("role 'unknown' was not found"))
+
+			_, _, err = provider.AWSPolicySourceARN("arn:aws:sts::123456789012:assumed-role/", roleARN)
+			Expect(err).To(HaveOccurred())
+			Expect(roleLookups).To(Equal([]string{"unknown"}))
+		})
+	})
+
+	DescribeTable("matches azure actions against role definition patterns",
+		func(patterns []string, action string, expected bool) {
+			p := make([]*string, 0, len(patterns)+1)
+			for i := range patterns {
+				p = append(p, &patterns[i])
+			}
+			// nil patterns are ignored
+			p = append(p, nil)
+			Expect(provider.AzureActionMatches(p, action)).To(Equal(expected))
+		},
+		Entry("an exact action",
+			[]string{"Microsoft.Compute/virtualMachines/start/action"},
+			"Microsoft.Compute/virtualMachines/start/action", true),
+		Entry("an action in a different case",
+			[]string{"microsoft.compute/virtualmachines/start/action"},
+			"Microsoft.Compute/virtualMachines/start/action", true),
+		Entry("all actions",
+			[]string{"*"},
+			"Microsoft.Storage/storageAccounts/read", true),
+		Entry("all actions of a provider",
+			[]string{"Microsoft.Compute/*"},
+			"Microsoft.Compute/virtualMachines/deallocate/action", true),
+		Entry("a wildcard within an action",
+			[]string{"Microsoft.Compute/*/read"},
+			"Microsoft.Compute/virtualMachines/read", true),
+		Entry("a wildcard within an action that does not match",
+			[]string{"Microsoft.Compute/*/read"},
+			"Microsoft.Compute/virtualMachines/write", false),
+		Entry("an action of another provider",
+			[]string{"Microsoft.Compute/*"},
+			"Microsoft.Storage/storageAccounts/read", false),
+		Entry("a pattern with regular expression characters",
+			[]string{"Microsoft.Compute/virtualMachines/read"},
+			"MicrosoftXCompute/virtualMachines/read", false),
+		Entry("a prefix of the action",
+			[]string{"Microsoft.Compute/virtualMachines"},
+			"Microsoft.Compute/virtualMachines/read", false),
+		Entry("no patterns",
+			[]string{}, "Microsoft.Compute/virtualMachines/read", false),
+	)
+})
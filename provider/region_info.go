@@ -1,18 +1,53 @@
 package provider
 
-import "sort"
+import (
+	"fmt"
+	"sort"
+)
 
 type RegionInfo struct {
 	Name        string
 	Description string
 }
 
+type ZoneInfo struct {
+	Name   string
+	Region string
+
+	// whether new resources can be
+	// placed in the zone
+	Available bool
+
+	// cloud specific status of the zone
+	Status string
+}
+
 // sorts the given slice of cloud provider structs
 // in ascending order of name
 func sortRegions(regions []RegionInfo) {
 	sort.Sort(&regionSorter{regions})
 }
 
+// sorts the given slice of zones
+// in ascending order of name
+func sortZones(zones []ZoneInfo) {
+	sort.Slice(zones, func(i, j int) bool {
+		return zones[i].Name < zones[j].Name
+	})
+}
+
+// returns the given region or the configured
+// region if the given region is empty
+func zoneRegion(region string, configured *string) (string, error) {
+	if len(region) > 0 {
+		return region, nil
+	}
+	if configured == nil || len(*configured) == 0 {
+		return "", fmt.Errorf("a region was not provided and the provider has no region configured")
+	}
+	return *configured, nil
+}
+
 // sorter struct contains the slice of providers to
 // be sorted and implements the sort.Interface
 type regionSorter struct {
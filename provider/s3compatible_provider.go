@@ -0,0 +1,376 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/goforms/config"
+	"github.com/mevansam/goforms/forms"
+	"github.com/mevansam/goutils/utils"
+
+	forms_config "github.com/mevansam/gocloud/forms"
+)
+
+// provider for object stores that implement the S3 API
+// such as MinIO, Ceph, Wasabi and Cloudflare R2. Only
+// the storage entity is supported.
+type s3CompatibleProvider struct {
+	cloudProvider
+
+	// indicates if provider client has
+	// been prepared to make API requests
+	isInitialized bool
+
+	session *session.Session
+}
+
+type s3CompatibleProviderConfig struct {
+	AccessKey      *string `json:"access_key,omitempty" form_field:"access_key"`
+	SecretKey      *string `json:"secret_key,omitempty" form_field:"secret_key"`
+	Endpoint       *string `json:"endpoint,omitempty" form_field:"endpoint"`
+	Region         *string `json:"region,omitempty" form_field:"region"`
+	ForcePathStyle *string `json:"force_path_style,omitempty" form_field:"force_path_style"`
+	DisableSSL     *string `json:"disable_ssl,omitempty" form_field:"disable_ssl"`
+}
+
+func newS3CompatibleProvider() (CloudProvider, error) {
+
+	var (
+		err            error
+		providerConfig s3CompatibleProviderConfig
+	)
+
+	provider := &s3CompatibleProvider{
+		cloudProvider: cloudProvider{
+			name:   "s3compatible",
+			config: &providerConfig,
+		},
+		isInitialized: false,
+	}
+	err = provider.createS3CompatibleInputForm()
+	return provider, err
+}
+
+func (p *s3CompatibleProvider) createS3CompatibleInputForm() error {
+
+	// Do not recreate form template if it exists
+	clougConfig := forms_config.CloudConfigForms
+	if clougConfig.HasGroup(p.name) {
+		return nil
+	}
+
+	var (
+		err  error
+		form *forms.InputGroup
+	)
+
+	form = forms_config.CloudConfigForms.NewGroup(p.name, "S3 Compatible Object Storage")
+
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "access_key",
+		DisplayName: "Access Key",
+		Description: "The object store's access key id.",
+		InputType:   forms.String,
+		EnvVars: []string{
+			"AWS_ACCESS_KEY_ID",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "secret_key",
+		DisplayName: "Secret Key",
+		Description: "The object store's secret key.",
+		InputType:   forms.String,
+		Sensitive:   true,
+		EnvVars: []string{
+			"AWS_SECRET_ACCESS_KEY",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:        "endpoint",
+		DisplayName: "Endpoint",
+		Description: "The URL of the object store's S3 API endpoint i.e. \"http://localhost:9000\".",
+		InputType:   forms.String,
+		EnvVars: []string{
+			"AWS_ENDPOINT_URL_S3",
+		},
+		Tags: []string{"provider"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "region",
+		DisplayName:  "Region",
+		Description:  "The region to create buckets in. Cloudflare R2 expects \"auto\".",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr("us-east-1"),
+		EnvVars: []string{
+			"AWS_DEFAULT_REGION",
+		},
+		Tags: []string{"provider", "target-undeployed"},
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "force_path_style",
+		DisplayName:  "Force Path Style",
+		Description:  "Address buckets as a path of the endpoint URL instead of as a sub-domain of the endpoint's host.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr("true"),
+		Tags:         []string{"provider"},
+
+		AcceptedValues:             []string{"true", "false"},
+		AcceptedValuesErrorMessage: "Must be one of \"true\" or \"false\".",
+	}); err != nil {
+		return err
+	}
+	if _, err = form.NewInputField(forms.FieldAttributes{
+		Name:         "disable_ssl",
+		DisplayName:  "Disable SSL",
+		Description:  "Connect to the endpoint using plain HTTP.",
+		InputType:    forms.String,
+		DefaultValue: utils.PtrToStr("false"),
+		Tags:         []string{"provider"},
+
+		AcceptedValues:             []string{"true", "false"},
+		AcceptedValuesErrorMessage: "Must be one of \"true\" or \"false\".",
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// interface: config/Configurable functions of base cloud provider
+
+func (p *s3CompatibleProvider) Copy() (config.Configurable, error) {
+
+	var (
+		err error
+
+		copy CloudProvider
+	)
+
+	if copy, err = newS3CompatibleProvider(); err != nil {
+		return nil, err
+	}
+
+	config := p.cloudProvider.
+		config.(*s3CompatibleProviderConfig)
+	configCopy := copy.(*s3CompatibleProvider).cloudProvider.
+		config.(*s3CompatibleProviderConfig)
+
+	configCopy.AccessKey = utils.CopyStrPtr(config.AccessKey)
+	configCopy.SecretKey = utils.CopyStrPtr(config.SecretKey)
+	configCopy.Endpoint = utils.CopyStrPtr(config.Endpoint)
+	configCopy.Region = utils.CopyStrPtr(config.Region)
+	configCopy.ForcePathStyle = utils.CopyStrPtr(config.ForcePathStyle)
+	configCopy.DisableSSL = utils.CopyStrPtr(config.DisableSSL)
+
+	return copy, nil
+}
+
+func (p *s3CompatibleProvider) IsValid() bool {
+
+	config := p.cloudProvider.
+		config.(*s3CompatibleProviderConfig)
+
+	for _, flag := range []*string{config.ForcePathStyle, config.DisableSSL} {
+		if flag != nil {
+			if _, err := strconv.ParseBool(*flag); err != nil {
+				return false
+			}
+		}
+	}
+	return config.AccessKey != nil && len(*config.AccessKey) > 0 &&
+		config.SecretKey != nil && len(*config.SecretKey) > 0 &&
+		config.Endpoint != nil && len(*config.Endpoint) > 0 &&
+		config.Region != nil && len(*config.Region) > 0
+}
+
+// interface: config/provider/CloudProvider functions
+
+func (p *s3CompatibleProvider) Connect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	return p.connectLocked()
+}
+
+// connects if the provider has not been connected or its
+// clients are stale. the caller must hold the connect lock.
+func (p *s3CompatibleProvider) connectLocked() error {
+
+	var (
+		err error
+	)
+
+	if p.isInitialized && p.isStale() {
+		// rebuild the clients using
+		// the current configuration
+		p.isInitialized = false
+	}
+	if !p.isInitialized {
+		if err = p.supplyCredentials(p); err != nil {
+			return err
+		}
+	}
+	if !p.IsValid() {
+		return fmt.Errorf("provider configuration is not valid")
+	}
+	if !p.isInitialized {
+		config := p.cloudProvider.
+			config.(*s3CompatibleProviderConfig)
+
+		if p.session, err = session.NewSession(&aws.Config{
+			Region:           aws.String(*config.Region),
+			Endpoint:         aws.String(*config.Endpoint),
+			S3ForcePathStyle: aws.Bool(p.flag(config.ForcePathStyle)),
+			DisableSSL:       aws.Bool(p.flag(config.DisableSSL)),
+			Credentials: credentials.NewStaticCredentials(
+				*config.AccessKey,
+				*config.SecretKey,
+				"",
+			),
+		}); err != nil {
+			return err
+		}
+
+		p.connected(time.Time{})
+		p.isInitialized = true
+	}
+	return nil
+}
+
+// Storage entities retrieved before the provider
+// reconnects continue to use the previous client
+// and need to be retrieved again.
+func (p *s3CompatibleProvider) Reconnect() error {
+	p.connectMx.Lock()
+	defer p.connectMx.Unlock()
+	p.isInitialized = false
+	return p.connectLocked()
+}
+
+// S3 compatible stores do not implement STS so the
+// credentials are validated by listing the buckets
+// they have access to. The account ID is the ID of
+// the owner of the buckets.
+func (p *s3CompatibleProvider) Validate(ctx context.Context) (Identity, error) {
+
+	var (
+		err error
+
+		bucketListResult *s3.ListBucketsOutput
+	)
+
+	if err = p.Connect(); err != nil {
+		return Identity{}, err
+	}
+	svc := s3.New(p.session)
+
+	if bucketListResult, err = svc.ListBucketsWithContext(ctx, &s3.ListBucketsInput{}); err != nil {
+		return Identity{}, err
+	}
+
+	config := p.cloudProvider.
+		config.(*s3CompatibleProviderConfig)
+
+	identity := Identity{
+		Principal: *config.AccessKey,
+	}
+	if owner := bucketListResult.Owner; owner != nil {
+		identity.AccountID = aws.StringValue(owner.ID)
+		if len(aws.StringValue(owner.DisplayName)) > 0 {
+			identity.Principal = aws.StringValue(owner.DisplayName)
+		}
+	}
+	return identity, nil
+}
+
+func (p *s3CompatibleProvider) CheckPermissions(actions []string) ([]string, error) {
+	return nil, fmt.Errorf("s3 compatible providers do not support checking permissions")
+}
+
+func (p *s3CompatibleProvider) Region() *string {
+
+	config := p.cloudProvider.
+		config.(*s3CompatibleProviderConfig)
+
+	return config.Region
+}
+
+// The regions of an S3 compatible store are
+// not known so only the configured region
+// is returned.
+func (p *s3CompatibleProvider) GetRegions() []RegionInfo {
+
+	config := p.cloudProvider.
+		config.(*s3CompatibleProviderConfig)
+
+	regionInfoList := []RegionInfo{}
+	if config.Region != nil && len(*config.Region) > 0 {
+		regionInfoList = append(regionInfoList,
+			RegionInfo{
+				Name: *config.Region,
+			})
+	}
+	return regionInfoList
+}
+
+func (p *s3CompatibleProvider) GetZones(region string) ([]ZoneInfo, error) {
+	return nil, fmt.Errorf("s3 compatible providers do not have availability zones")
+}
+
+func (p *s3CompatibleProvider) GetCompute() (cloud.Compute, error) {
+	return nil, fmt.Errorf("s3 compatible providers do not support compute")
+}
+
+func (p *s3CompatibleProvider) GetStorage() (cloud.Storage, error) {
+
+	var (
+		err error
+
+		storage cloud.Storage
+	)
+
+	if !p.isInitialized {
+		return nil, fmt.Errorf("s3 compatible provider has not been initialized")
+	}
+
+	config := p.cloudProvider.
+		config.(*s3CompatibleProviderConfig)
+
+	if storage, err = cloud.NewAWSStorage(
+		p.session,
+		*config.Region,
+	); err != nil {
+		return nil, err
+	}
+	storage.SetProperties(cloud.AWSStorageProperties{
+		S3Compatible: true,
+	})
+	return storage, nil
+}
+
+// returns the boolean value of the given
+// flag which is false if not set
+func (p *s3CompatibleProvider) flag(value *string) bool {
+	if value == nil {
+		return false
+	}
+	// validated by IsValid()
+	b, _ := strconv.ParseBool(*value)
+	return b
+}
@@ -0,0 +1,136 @@
+package provider_test
+
+import (
+	"strings"
+
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/gocloud/provider"
+	"github.com/mevansam/goforms/forms"
+	"github.com/mevansam/goutils/term"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	test_data "github.com/mevansam/gocloud/test/data"
+)
+
+var _ = Describe("S3 Compatible Provider Tests", func() {
+
+	var (
+		err error
+
+		outputBuffer         strings.Builder
+		s3CompatibleProvider provider.CloudProvider
+	)
+
+	BeforeEach(func() {
+		outputBuffer.Reset()
+
+		s3CompatibleProvider, err = provider.NewCloudProvider("s3compatible")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s3CompatibleProvider).ToNot(BeNil())
+	})
+
+	Context("s3 compatible provider config inputs", func() {
+
+		It("outputs a detailed input data form reference for s3 compatible provider config inputs", func() {
+			testConfigReferenceOutput(s3CompatibleProvider, s3CompatibleInputDataReferenceOutput)
+		})
+
+		It("loads configuration values", func() {
+
+			test_data.ParseConfigDocument(s3CompatibleProvider, s3CompatibleConfigDocument, "s3CompatibleProvider")
+			test_data.ValidateS3CompatibleConfigDocument(s3CompatibleProvider)
+
+			// Run some negative tests
+			_, err = s3CompatibleProvider.GetValue("non_existent_key")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("saves configuration values", func() {
+			test_data.ParseConfigDocument(s3CompatibleProvider, s3CompatibleConfigDocument, "s3CompatibleProvider")
+			test_data.MarshalConfigDocumentAndValidate(s3CompatibleProvider, "s3CompatibleProvider", s3CompatibleConfigDocument)
+		})
+
+		It("validates the endpoint and the boolean options", func() {
+
+			var (
+				inputForm forms.InputForm
+			)
+
+			test_data.ParseConfigDocument(s3CompatibleProvider, s3CompatibleConfigDocument, "s3CompatibleProvider")
+			Expect(s3CompatibleProvider.IsValid()).To(BeTrue())
+
+			inputForm, err = s3CompatibleProvider.InputForm()
+			Expect(err).NotTo(HaveOccurred())
+			err = inputForm.SetFieldValue("force_path_style", "yes")
+			Expect(err).To(HaveOccurred())
+
+			err = inputForm.SetFieldValue("endpoint", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s3CompatibleProvider.IsValid()).To(BeFalse())
+		})
+	})
+
+	It("provides only a storage entity", func() {
+
+		var (
+			storage cloud.Storage
+		)
+
+		test_data.ParseConfigDocument(s3CompatibleProvider, s3CompatibleConfigDocument, "s3CompatibleProvider")
+
+		_, err = s3CompatibleProvider.GetStorage()
+		Expect(err).To(HaveOccurred())
+
+		err = s3CompatibleProvider.Connect()
+		Expect(err).NotTo(HaveOccurred())
+
+		storage, err = s3CompatibleProvider.GetStorage()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storage).ToNot(BeNil())
+
+		_, err = s3CompatibleProvider.GetCompute()
+		Expect(err).To(HaveOccurred())
+		_, err = s3CompatibleProvider.CheckPermissions([]string{"s3:ListBucket"})
+		Expect(err).To(HaveOccurred())
+
+		regions := s3CompatibleProvider.GetRegions()
+		Expect(len(regions)).To(Equal(1))
+		Expect(regions[0].Name).To(Equal("us-east-1"))
+	})
+
+	It("creates a copy of itself", func() {
+		test_data.ParseConfigDocument(s3CompatibleProvider, s3CompatibleConfigDocument, "s3CompatibleProvider")
+		test_data.CopyConfigAndValidate(s3CompatibleProvider, "endpoint", "http://localhost:9000", "https://storage.example.com")
+	})
+})
+
+const s3CompatibleInputDataReferenceOutput = term.BOLD + `Cloud Provider Configuration
+============================` + term.NC + `
+
+S3 Compatible Object Storage
+
+` + term.ITALIC + `CONFIGURATION DATA INPUT REFERENCE` + term.NC + `
+
+* Access Key       - The object store's access key id. It will be sourced from
+                     the environment variable AWS_ACCESS_KEY_ID if not provided.
+* Secret Key       - The object store's secret key. It will be sourced from the
+                     environment variable AWS_SECRET_ACCESS_KEY if not provided.
+* Endpoint         - The URL of the object store's S3 API endpoint i.e.
+                     "http://localhost:9000". It will be sourced from the
+                     environment variable AWS_ENDPOINT_URL_S3 if not provided.
+* Region           - The region to create buckets in. Cloudflare R2 expects
+                     "auto". It will be sourced from the environment variable
+                     AWS_DEFAULT_REGION if not provided.
+* Force Path Style - Address buckets as a path of the endpoint URL instead of as
+                     a sub-domain of the endpoint's host.
+* Disable SSL      - Connect to the endpoint using plain HTTP.`
+
+const s3CompatibleConfigDocument = `
+{
+	"cloud": {
+		"s3CompatibleProvider": ` + test_data.S3CompatibleProviderConfig + `
+	}
+}
+`
@@ -11,14 +11,22 @@ import (
 const S3BackendConfig = `
 {
 	"bucket": "mystatebucket",
-	"key": "mystatekey"
+	"key": "mystatekey",
+	"endpoint": "",
+	"force_path_style": "false",
+	"skip_credentials_validation": "false",
+	"skip_region_validation": "false"
 }
 `
 
 const ExpectedS3BackendConfig = `
 {
 	"bucket": "mystatebucket",
-	"key": "mystatekey"
+	"key": "mystatekey",
+	"endpoint": "",
+	"force_path_style": "false",
+	"skip_credentials_validation": "false",
+	"skip_region_validation": "false"
 }
 `
 
@@ -40,6 +48,11 @@ func ValidateS3ConfigDocument(s3Backend backend.CloudBackend) {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(value).NotTo(BeNil())
 	Expect(*value).To(Equal("mystatekey"))
+
+	value, err = s3Backend.GetValue("skip_credentials_validation")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(value).NotTo(BeNil())
+	Expect(*value).To(Equal("false"))
 }
 
 // azurerm provider test data
@@ -127,3 +140,37 @@ func ValidateGCSConfigDocument(gcsBackend backend.CloudBackend) {
 	Expect(value).NotTo(BeNil())
 	Expect(*value).To(Equal("mystateprefix"))
 }
+
+const SwiftBackendConfig = `
+{
+	"container": "mystatecontainer",
+	"state_name": "mystatename"
+}
+`
+
+const ExpectedSwiftBackendConfig = `
+{
+	"container": "mystatecontainer",
+	"state_name": "mystatename"
+}
+`
+
+func ValidateSwiftConfigDocument(swiftBackend backend.CloudBackend) {
+
+	var (
+		err   error
+		value *string
+	)
+
+	Expect(swiftBackend.IsValid()).To(BeTrue())
+
+	value, err = swiftBackend.GetValue("container")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(value).NotTo(BeNil())
+	Expect(*value).To(Equal("mystatecontainer"))
+
+	value, err = swiftBackend.GetValue("state_name")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(value).NotTo(BeNil())
+	Expect(*value).To(Equal("mystatename"))
+}
@@ -119,6 +119,71 @@ func CleanUpAWSTestData() {
 				Expect(err).NotTo(HaveOccurred())
 			}
 		}
+
+		testDataFilter := []*ec2.Filter{
+			{
+				Name:   aws.String("tag:Role"),
+				Values: []*string{aws.String("Cloudbuilder-Test")},
+			},
+		}
+
+		imagesResult, err := svc.DescribeImages(&ec2.DescribeImagesInput{
+			Owners:  []*string{aws.String("self")},
+			Filters: testDataFilter,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		for _, i := range imagesResult.Images {
+			logger.TraceMessage("Deregistering test image with ID '%s'.", *i.ImageId)
+
+			_, err = svc.DeregisterImage(&ec2.DeregisterImageInput{
+				ImageId: i.ImageId,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		snapshotsResult, err := svc.DescribeSnapshots(&ec2.DescribeSnapshotsInput{
+			Filters: testDataFilter,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		for _, s := range snapshotsResult.Snapshots {
+			logger.TraceMessage("Deleting test snapshot with ID '%s'.", *s.SnapshotId)
+
+			_, err = svc.DeleteSnapshot(&ec2.DeleteSnapshotInput{
+				SnapshotId: s.SnapshotId,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		volumesResult, err := svc.DescribeVolumes(&ec2.DescribeVolumesInput{
+			Filters: append(testDataFilter, &ec2.Filter{
+				Name:   aws.String("status"),
+				Values: []*string{aws.String("available")},
+			}),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		for _, v := range volumesResult.Volumes {
+			logger.TraceMessage("Deleting test volume with ID '%s'.", *v.VolumeId)
+
+			_, err = svc.DeleteVolume(&ec2.DeleteVolumeInput{
+				VolumeId: v.VolumeId,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		addressesResult, err := svc.DescribeAddresses(&ec2.DescribeAddressesInput{
+			Filters: testDataFilter,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		for _, a := range addressesResult.Addresses {
+			if a.AssociationId == nil {
+				logger.TraceMessage("Releasing test elastic ip with ID '%s'.", *a.AllocationId)
+
+				_, err = svc.ReleaseAddress(&ec2.ReleaseAddressInput{
+					AllocationId: a.AllocationId,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		}
 	}
 }
 
@@ -294,6 +294,30 @@ func AzureDeployTestInstances(name string, numInstances int) map[string]map[stri
 	return instances
 }
 
+// deallocates and generalizes the given test
+// VM so that an image can be captured from it
+func AzureGeneralizeTestInstance(name string) {
+
+	var (
+		err error
+
+		future compute.VirtualMachinesDeallocateFuture
+	)
+
+	vmClient := compute.NewVirtualMachinesClient(azureSubscriptionID)
+	vmClient.Authorizer = azureAuthorizer("AzurePublicCloud")
+	_ = vmClient.AddToUserAgent("cbs-test")
+
+	ctx := context.Background()
+	future, err = vmClient.Deallocate(ctx, azureDefaultResourceGroup, name, nil)
+	Expect(err).NotTo(HaveOccurred())
+	err = future.WaitForCompletionRef(ctx, vmClient.Client)
+	Expect(err).NotTo(HaveOccurred())
+
+	_, err = vmClient.Generalize(ctx, azureDefaultResourceGroup, name)
+	Expect(err).NotTo(HaveOccurred())
+}
+
 func AzureInstanceState(name string) string {
 
 	var (
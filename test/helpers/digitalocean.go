@@ -0,0 +1,205 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/digitalocean/godo"
+
+	"github.com/mevansam/gocloud/provider"
+	"github.com/mevansam/goforms/forms"
+	"github.com/mevansam/goutils/logger"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// tag of the droplets created for testing
+const digitalOceanTestTag = "cloudbuilder-test"
+
+// returns the digitalocean token and region from
+// the environment. tests that use the provider are
+// skipped if DIGITALOCEAN_TOKEN is not set.
+func digitalOceanEnvironment() (string, string) {
+
+	token := os.Getenv("DIGITALOCEAN_TOKEN")
+	if len(token) == 0 {
+		Skip("environment variable named DIGITALOCEAN_TOKEN was not provided")
+	}
+	region := os.Getenv("DIGITALOCEAN_REGION")
+	if len(region) == 0 {
+		region = "nyc3"
+	}
+	return token, region
+}
+
+// cleans up any test droplets created in the digitalocean account
+func CleanUpDigitalOceanTestData() {
+
+	token := os.Getenv("DIGITALOCEAN_TOKEN")
+	if len(token) == 0 {
+		return
+	}
+	if noCleanUp := os.Getenv("DIGITALOCEAN_NO_CLEANUP"); noCleanUp != "1" {
+		client := godo.NewFromToken(token)
+		_, err := client.Droplets.DeleteByTag(context.Background(), digitalOceanTestTag)
+		Expect(err).NotTo(HaveOccurred())
+
+		firewalls, _, err := client.Firewalls.List(context.Background(), &godo.ListOptions{PerPage: 200})
+		Expect(err).NotTo(HaveOccurred())
+		for _, firewall := range firewalls {
+			if strings.HasPrefix(firewall.Name, digitalOceanTestTag+"-") {
+				_, err = client.Firewalls.Delete(context.Background(), firewall.ID)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		}
+	}
+}
+
+// update digitalocean provider with environment credentials
+func InitializeDigitalOceanProvider(digitalOceanProvider provider.CloudProvider) {
+
+	var (
+		err error
+
+		inputForm forms.InputForm
+	)
+
+	token, region := digitalOceanEnvironment()
+
+	inputForm, err = digitalOceanProvider.InputForm()
+	Expect(err).NotTo(HaveOccurred())
+	err = inputForm.SetFieldValue("token", token)
+	Expect(err).NotTo(HaveOccurred())
+	err = inputForm.SetFieldValue("region", region)
+	Expect(err).NotTo(HaveOccurred())
+	if accessKey := os.Getenv("SPACES_ACCESS_KEY_ID"); len(accessKey) > 0 {
+		err = inputForm.SetFieldValue("spaces_access_key", accessKey)
+		Expect(err).NotTo(HaveOccurred())
+		err = inputForm.SetFieldValue("spaces_secret_key", os.Getenv("SPACES_SECRET_ACCESS_KEY"))
+		Expect(err).NotTo(HaveOccurred())
+	}
+}
+
+// creates digitalocean droplets for testing
+func DigitalOceanDeployTestDroplets(name string, numDroplets int) map[string]*godo.Droplet {
+
+	var (
+		err error
+		wg  sync.WaitGroup
+		mx  sync.Mutex
+	)
+
+	token, region := digitalOceanEnvironment()
+
+	ctx := context.Background()
+	client := godo.NewFromToken(token)
+
+	existing, _, err := client.Droplets.ListByTag(ctx, digitalOceanTestTag, &godo.ListOptions{PerPage: 200})
+	Expect(err).NotTo(HaveOccurred())
+
+	droplets := make(map[string]*godo.Droplet)
+
+	wg.Add(numDroplets)
+	for i := 0; i < numDroplets; i++ {
+
+		go func(i int) {
+			defer wg.Done()
+			defer GinkgoRecover()
+
+			var (
+				err     error
+				droplet *godo.Droplet
+			)
+
+			dropletName := fmt.Sprintf("%s-%d", name, i)
+			for j := range existing {
+				if existing[j].Name == dropletName {
+					droplet = &existing[j]
+					break
+				}
+			}
+			if droplet == nil {
+
+				logger.TraceMessage(
+					"Creating droplet with name '%s'.",
+					dropletName)
+
+				droplet, _, err = client.Droplets.Create(ctx, &godo.DropletCreateRequest{
+					Name:   dropletName,
+					Region: region,
+					Size:   "s-1vcpu-1gb",
+					Image: godo.DropletCreateImage{
+						Slug: "ubuntu-22-04-x64",
+					},
+					Tags: []string{digitalOceanTestTag},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				for droplet.Status != "active" {
+					time.Sleep(5 * time.Second)
+					droplet, _, err = client.Droplets.Get(ctx, droplet.ID)
+					Expect(err).NotTo(HaveOccurred())
+				}
+			}
+
+			logger.TraceMessage(
+				"Using droplet: ID - %d, name - %s",
+				droplet.ID, droplet.Name)
+
+			mx.Lock()
+			droplets[dropletName] = droplet
+			mx.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	return droplets
+}
+
+func DigitalOceanDropletStatus(id int) string {
+
+	token, _ := digitalOceanEnvironment()
+
+	droplet, _, err := godo.NewFromToken(token).Droplets.Get(context.Background(), id)
+	Expect(err).NotTo(HaveOccurred())
+	return droplet.Status
+}
+
+// applies a firewall to the given droplet that
+// only allows ssh if it does not have one yet
+func DigitalOceanAddTestFirewall(dropletID int) {
+
+	token, _ := digitalOceanEnvironment()
+	client := godo.NewFromToken(token)
+
+	ctx := context.Background()
+	name := fmt.Sprintf("%s-%d", digitalOceanTestTag, dropletID)
+
+	firewalls, _, err := client.Firewalls.ListByDroplet(ctx, dropletID, &godo.ListOptions{PerPage: 200})
+	Expect(err).NotTo(HaveOccurred())
+	for _, firewall := range firewalls {
+		if firewall.Name == name {
+			return
+		}
+	}
+
+	all := &godo.Sources{Addresses: []string{"0.0.0.0/0", "::/0"}}
+	_, _, err = client.Firewalls.Create(ctx, &godo.FirewallRequest{
+		Name: name,
+		InboundRules: []godo.InboundRule{
+			{Protocol: "tcp", PortRange: "22", Sources: all},
+		},
+		OutboundRules: []godo.OutboundRule{
+			{Protocol: "tcp", PortRange: "all", Destinations: &godo.Destinations{Addresses: all.Addresses}},
+			{Protocol: "udp", PortRange: "all", Destinations: &godo.Destinations{Addresses: all.Addresses}},
+			{Protocol: "icmp", Destinations: &godo.Destinations{Addresses: all.Addresses}},
+		},
+		DropletIDs: []int{dropletID},
+	})
+	Expect(err).NotTo(HaveOccurred())
+}
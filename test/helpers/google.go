@@ -95,6 +95,35 @@ func CleanUpGoogleTestData() {
 				Expect(err).NotTo(HaveOccurred())
 			}
 		}
+
+		imageList, err := computeService.Images.List(googleProject).
+			Filter("labels.role=cloudbuilder-test").Do()
+		Expect(err).NotTo(HaveOccurred())
+		for _, image := range imageList.Items {
+			_, err = computeService.Images.Delete(googleProject, image.Name).Do()
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		snapshotList, err := computeService.Snapshots.List(googleProject).
+			Filter("labels.role=cloudbuilder-test").Do()
+		Expect(err).NotTo(HaveOccurred())
+		for _, snapshot := range snapshotList.Items {
+			_, err = computeService.Snapshots.Delete(googleProject, snapshot.Name).Do()
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		// disks still attached to instances being deleted
+		// are deleted along with the instance if marked
+		// for auto-delete otherwise on a later run
+		diskList, err := computeService.Disks.List(googleProject, googleZone).
+			Filter("labels.role=cloudbuilder-test").Do()
+		Expect(err).NotTo(HaveOccurred())
+		for _, disk := range diskList.Items {
+			if len(disk.Users) == 0 {
+				_, err = computeService.Disks.Delete(googleProject, googleZone, disk.Name).Do()
+				Expect(err).NotTo(HaveOccurred())
+			}
+		}
 	}
 }
 
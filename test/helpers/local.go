@@ -0,0 +1,101 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/mevansam/gocloud/cloud"
+	"github.com/mevansam/gocloud/provider"
+	"github.com/mevansam/goforms/forms"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// directory in which the local provider keeps
+// its instances and storage during the tests
+var localTestPath string
+
+// update local provider to manage process instances and
+// storage kept in a temporary directory. tests that use
+// process instances are skipped on windows.
+func InitializeLocalProvider(localProvider provider.CloudProvider) {
+
+	var (
+		err error
+
+		inputForm forms.InputForm
+	)
+
+	if runtime.GOOS == "windows" {
+		Skip("local process instances are not tested on windows")
+	}
+	if len(localTestPath) == 0 {
+		localTestPath, err = os.MkdirTemp("", "gocloud-local")
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	inputForm, err = localProvider.InputForm()
+	Expect(err).NotTo(HaveOccurred())
+	err = inputForm.SetFieldValue("compute_type", "process")
+	Expect(err).NotTo(HaveOccurred())
+	err = inputForm.SetFieldValue("path", localTestPath)
+	Expect(err).NotTo(HaveOccurred())
+}
+
+// update local provider to manage the containers of
+// the docker engine api at the given address. tests
+// that use docker instances are skipped on windows.
+func InitializeLocalDockerProvider(localProvider provider.CloudProvider, dockerHost string) {
+
+	var (
+		err error
+
+		inputForm forms.InputForm
+	)
+
+	InitializeLocalProvider(localProvider)
+
+	inputForm, err = localProvider.InputForm()
+	Expect(err).NotTo(HaveOccurred())
+	err = inputForm.SetFieldValue("compute_type", "docker")
+	Expect(err).NotTo(HaveOccurred())
+	err = inputForm.SetFieldValue("docker_host", dockerHost)
+	Expect(err).NotTo(HaveOccurred())
+}
+
+// returns the path of the given file in the
+// directory of the local process instance
+func LocalProcessInstanceFile(name, file string) string {
+	return filepath.Join(localTestPath, "instances", name, file)
+}
+
+// removes the directory of the local provider
+func CleanUpLocalTestData() {
+	if len(localTestPath) > 0 {
+		_ = os.RemoveAll(localTestPath)
+	}
+}
+
+// saves the specs of local process instances for testing
+// which run until they are stopped and returns their names
+func LocalDeployTestProcesses(name string, numProcesses int) []string {
+
+	names := make([]string, 0, numProcesses)
+	for i := 0; i < numProcesses; i++ {
+		processName := fmt.Sprintf("%s-%d", name, i)
+		err := cloud.SaveLocalProcessSpec(
+			filepath.Join(localTestPath, "instances"),
+			processName,
+			cloud.LocalProcessSpec{
+				Command: []string{"/bin/sh", "-c", "echo started $TEST_NAME; exec sleep 3600"},
+				Env:     []string{"TEST_NAME=" + processName},
+			},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		names = append(names, processName)
+	}
+	return names
+}
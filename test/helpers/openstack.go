@@ -0,0 +1,220 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/flavors"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/pagination"
+
+	"github.com/mevansam/gocloud/provider"
+	"github.com/mevansam/goforms/forms"
+	"github.com/mevansam/goutils/logger"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// metadata key of the servers created for testing
+const openStackTestMetadata = "cloudbuilder-test"
+
+// returns a compute client authenticated using
+// the standard OS_* environment variables. tests
+// that use the provider are skipped if OS_AUTH_URL
+// is not set.
+func openStackComputeClient() *gophercloud.ServiceClient {
+
+	if len(os.Getenv("OS_AUTH_URL")) == 0 {
+		Skip("environment variable named OS_AUTH_URL was not provided")
+	}
+	authOptions, err := openstack.AuthOptionsFromEnv()
+	Expect(err).NotTo(HaveOccurred())
+	providerClient, err := openstack.AuthenticatedClient(authOptions)
+	Expect(err).NotTo(HaveOccurred())
+	client, err := openstack.NewComputeV2(providerClient, gophercloud.EndpointOpts{
+		Region: os.Getenv("OS_REGION_NAME"),
+	})
+	Expect(err).NotTo(HaveOccurred())
+	return client
+}
+
+// returns the test servers in the openstack project
+func openStackTestServers(client *gophercloud.ServiceClient) []servers.Server {
+
+	testServers := []servers.Server{}
+	err := servers.List(client, servers.ListOpts{}).EachPage(func(page pagination.Page) (bool, error) {
+		list, err := servers.ExtractServers(page)
+		if err != nil {
+			return false, err
+		}
+		for _, server := range list {
+			if _, ok := server.Metadata[openStackTestMetadata]; ok {
+				testServers = append(testServers, server)
+			}
+		}
+		return true, nil
+	})
+	Expect(err).NotTo(HaveOccurred())
+	return testServers
+}
+
+// cleans up any test servers created in the openstack project
+func CleanUpOpenStackTestData() {
+
+	if len(os.Getenv("OS_AUTH_URL")) == 0 {
+		return
+	}
+	if noCleanUp := os.Getenv("OS_NO_CLEANUP"); noCleanUp != "1" {
+		client := openStackComputeClient()
+		for _, server := range openStackTestServers(client) {
+			err := servers.Delete(client, server.ID).ExtractErr()
+			Expect(err).NotTo(HaveOccurred())
+		}
+	}
+}
+
+// update openstack provider with environment credentials
+func InitializeOpenStackProvider(openStackProvider provider.CloudProvider) {
+
+	var (
+		err error
+
+		inputForm forms.InputForm
+	)
+
+	if len(os.Getenv("OS_AUTH_URL")) == 0 {
+		Skip("environment variable named OS_AUTH_URL was not provided")
+	}
+
+	// the provider's fields are sourced from the
+	// OS_* environment variables so only fields
+	// that are set in the environment are bound
+	inputForm, err = openStackProvider.InputForm()
+	Expect(err).NotTo(HaveOccurred())
+	for _, field := range inputForm.InputFields() {
+		if value := field.Value(); value != nil {
+			err = inputForm.SetFieldValue(field.Name(), *value)
+			Expect(err).NotTo(HaveOccurred())
+		}
+	}
+}
+
+// returns the flavor id given by the
+// environment variable or the default
+func openStackTestFlavorID(name, defaultID string) string {
+
+	if flavorID := os.Getenv(name); len(flavorID) > 0 {
+		return flavorID
+	}
+	return defaultID
+}
+
+// returns the names of the flavor of the test servers
+// and of the flavor to resize them to which is given
+// by the OS_TEST_RESIZE_FLAVOR_ID environment variable
+func OpenStackTestFlavors() (string, string) {
+
+	client := openStackComputeClient()
+
+	flavor, err := flavors.Get(client, openStackTestFlavorID("OS_TEST_FLAVOR_ID", "2")).Extract()
+	Expect(err).NotTo(HaveOccurred())
+	resizeFlavor, err := flavors.Get(client, openStackTestFlavorID("OS_TEST_RESIZE_FLAVOR_ID", "3")).Extract()
+	Expect(err).NotTo(HaveOccurred())
+
+	return flavor.Name, resizeFlavor.Name
+}
+
+// creates openstack servers for testing using the image
+// and flavor given by the OS_TEST_IMAGE_ID and
+// OS_TEST_FLAVOR_ID environment variables
+func OpenStackDeployTestServers(name string, numServers int) map[string]*servers.Server {
+
+	var (
+		wg sync.WaitGroup
+		mx sync.Mutex
+	)
+
+	client := openStackComputeClient()
+
+	imageID := os.Getenv("OS_TEST_IMAGE_ID")
+	if len(imageID) == 0 {
+		Skip("environment variable named OS_TEST_IMAGE_ID was not provided")
+	}
+	flavorID := openStackTestFlavorID("OS_TEST_FLAVOR_ID", "2")
+	networks := []servers.Network{}
+	if networkID := os.Getenv("OS_TEST_NETWORK_ID"); len(networkID) > 0 {
+		networks = append(networks, servers.Network{UUID: networkID})
+	}
+
+	existing := openStackTestServers(client)
+	testServers := make(map[string]*servers.Server)
+
+	wg.Add(numServers)
+	for i := 0; i < numServers; i++ {
+
+		go func(i int) {
+			defer wg.Done()
+			defer GinkgoRecover()
+
+			var (
+				err    error
+				server *servers.Server
+			)
+
+			serverName := fmt.Sprintf("%s-%d", name, i)
+			for j := range existing {
+				if existing[j].Name == serverName {
+					server = &existing[j]
+					break
+				}
+			}
+			if server == nil {
+
+				logger.TraceMessage(
+					"Creating server with name '%s'.",
+					serverName)
+
+				server, err = servers.Create(client, servers.CreateOpts{
+					Name:      serverName,
+					ImageRef:  imageID,
+					FlavorRef: flavorID,
+					Networks:  networks,
+					Metadata: map[string]string{
+						openStackTestMetadata: "true",
+					},
+				}).Extract()
+				Expect(err).NotTo(HaveOccurred())
+
+				for server.Status != "ACTIVE" {
+					Expect(server.Status).NotTo(Equal("ERROR"))
+					time.Sleep(5 * time.Second)
+					server, err = servers.Get(client, server.ID).Extract()
+					Expect(err).NotTo(HaveOccurred())
+				}
+			}
+
+			logger.TraceMessage(
+				"Using server: ID - %s, name - %s",
+				server.ID, server.Name)
+
+			mx.Lock()
+			testServers[serverName] = server
+			mx.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	return testServers
+}
+
+func OpenStackServerStatus(id string) string {
+
+	server, err := servers.Get(openStackComputeClient(), id).Extract()
+	Expect(err).NotTo(HaveOccurred())
+	return server.Status
+}
@@ -0,0 +1,54 @@
+package helpers
+
+import (
+	"os"
+
+	"github.com/mevansam/gocloud/provider"
+	"github.com/mevansam/goforms/forms"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// update s3 compatible provider with the endpoint and credentials
+// from the environment. tests that use the provider are skipped if
+// S3_COMPATIBLE_ENDPOINT is not set. a local MinIO container that
+// can be used with the default credentials can be started with
+//
+//	docker run -d -p 9000:9000 minio/minio server /data
+//
+// and setting S3_COMPATIBLE_ENDPOINT=http://localhost:9000
+func InitializeS3CompatibleProvider(s3CompatibleProvider provider.CloudProvider) {
+
+	var (
+		err error
+
+		inputForm forms.InputForm
+	)
+
+	endpoint := os.Getenv("S3_COMPATIBLE_ENDPOINT")
+	if len(endpoint) == 0 {
+		Skip("environment variable named S3_COMPATIBLE_ENDPOINT was not provided")
+	}
+	accessKey := os.Getenv("S3_COMPATIBLE_ACCESS_KEY")
+	if len(accessKey) == 0 {
+		accessKey = "minioadmin"
+	}
+	secretKey := os.Getenv("S3_COMPATIBLE_SECRET_KEY")
+	if len(secretKey) == 0 {
+		secretKey = "minioadmin"
+	}
+
+	inputForm, err = s3CompatibleProvider.InputForm()
+	Expect(err).NotTo(HaveOccurred())
+	err = inputForm.SetFieldValue("endpoint", endpoint)
+	Expect(err).NotTo(HaveOccurred())
+	err = inputForm.SetFieldValue("access_key", accessKey)
+	Expect(err).NotTo(HaveOccurred())
+	err = inputForm.SetFieldValue("secret_key", secretKey)
+	Expect(err).NotTo(HaveOccurred())
+	if region := os.Getenv("S3_COMPATIBLE_REGION"); len(region) > 0 {
+		err = inputForm.SetFieldValue("region", region)
+		Expect(err).NotTo(HaveOccurred())
+	}
+}
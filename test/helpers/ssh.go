@@ -0,0 +1,271 @@
+package helpers
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/mevansam/goutils/logger"
+
+	. "github.com/onsi/gomega"
+)
+
+// in-process ssh server which runs exec requests
+// via the local shell and forwards direct-tcpip
+// channels for testing ssh clients
+type SSHServer struct {
+	Address            string
+	HostKeyFingerprint string
+
+	listener net.Listener
+	config   *ssh.ServerConfig
+}
+
+// generates an ed25519 key pair returning the PEM
+// encoded private key and the ssh public key
+func GenerateSSHKey() ([]byte, ssh.PublicKey) {
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	Expect(err).NotTo(HaveOccurred())
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	Expect(err).NotTo(HaveOccurred())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), sshPublicKey
+}
+
+// starts an ssh server on a random local port that
+// only accepts the given public key for authentication
+func StartSSHServer(authorizedKey ssh.PublicKey) *SSHServer {
+
+	_, hostPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	hostSigner, err := ssh.NewSignerFromKey(hostPrivateKey)
+	Expect(err).NotTo(HaveOccurred())
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if ssh.FingerprintSHA256(key) == ssh.FingerprintSHA256(authorizedKey) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown public key for %s", conn.User())
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+
+	server := &SSHServer{
+		Address:            listener.Addr().String(),
+		HostKeyFingerprint: ssh.FingerprintSHA256(hostSigner.PublicKey()),
+
+		listener: listener,
+		config:   config,
+	}
+	go server.serve()
+	return server
+}
+
+func (s *SSHServer) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *SSHServer) Stop() {
+	s.listener.Close()
+}
+
+func (s *SSHServer) serve() {
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			_, channels, requests, err := ssh.NewServerConn(conn, s.config)
+			if err != nil {
+				logger.DebugMessage("Test ssh server handshake failed: %s", err.Error())
+				return
+			}
+			go ssh.DiscardRequests(requests)
+
+			for newChannel := range channels {
+				switch newChannel.ChannelType() {
+				case "session":
+					go handleSSHSession(newChannel)
+				case "direct-tcpip":
+					go handleSSHDirectTCPIP(newChannel)
+				default:
+					_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+				}
+			}
+		}()
+	}
+}
+
+// in-process ssh agent listening on a unix
+// socket that counts its open connections
+type SSHAgent struct {
+	Path string
+
+	mx          sync.Mutex
+	connections int
+
+	dir      string
+	keyring  agent.Agent
+	listener net.Listener
+}
+
+// starts an ssh agent holding the given PEM encoded key
+func StartSSHAgent(privateKey []byte) *SSHAgent {
+
+	key, err := ssh.ParseRawPrivateKey(privateKey)
+	Expect(err).NotTo(HaveOccurred())
+
+	dir, err := os.MkdirTemp("", "agent")
+	Expect(err).NotTo(HaveOccurred())
+	path := filepath.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", path)
+	Expect(err).NotTo(HaveOccurred())
+
+	keyring := agent.NewKeyring()
+	Expect(keyring.Add(agent.AddedKey{PrivateKey: key})).To(Succeed())
+
+	a := &SSHAgent{
+		Path: path,
+
+		dir:      dir,
+		keyring:  keyring,
+		listener: listener,
+	}
+	go a.serve()
+	return a
+}
+
+func (a *SSHAgent) OpenConnections() int {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	return a.connections
+}
+
+func (a *SSHAgent) Stop() {
+	a.listener.Close()
+	os.RemoveAll(a.dir)
+}
+
+func (a *SSHAgent) serve() {
+
+	for {
+		conn, err := a.listener.Accept()
+		if err != nil {
+			return
+		}
+		a.mx.Lock()
+		a.connections++
+		a.mx.Unlock()
+
+		go func() {
+			_ = agent.ServeAgent(a.keyring, conn)
+			conn.Close()
+
+			a.mx.Lock()
+			a.connections--
+			a.mx.Unlock()
+		}()
+	}
+}
+
+func handleSSHSession(newChannel ssh.NewChannel) {
+
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	defer channel.Close()
+
+	for request := range requests {
+		if request.Type != "exec" {
+			_ = request.Reply(false, nil)
+			continue
+		}
+
+		payload := struct{ Command string }{}
+		if err = ssh.Unmarshal(request.Payload, &payload); err != nil {
+			_ = request.Reply(false, nil)
+			continue
+		}
+		_ = request.Reply(true, nil)
+
+		cmd := exec.Command("sh", "-c", payload.Command)
+		cmd.Stdin = channel
+		cmd.Stdout = channel
+		cmd.Stderr = channel.Stderr()
+
+		status := struct{ Status uint32 }{}
+		if err = cmd.Run(); err != nil {
+			exitErr := &exec.ExitError{}
+			if errors.As(err, &exitErr) {
+				status.Status = uint32(exitErr.ExitCode())
+			} else {
+				status.Status = 255
+			}
+		}
+		_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(&status))
+		return
+	}
+}
+
+func handleSSHDirectTCPIP(newChannel ssh.NewChannel) {
+
+	target := struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}{}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	conn, err := net.Dial("tcp", net.JoinHostPort(target.Host, fmt.Sprintf("%d", target.Port)))
+	if err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	defer conn.Close()
+
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	defer channel.Close()
+	go ssh.DiscardRequests(requests)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(conn, channel)
+		conn.(*net.TCPConn).CloseWrite()
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(channel, conn)
+		_ = channel.CloseWrite()
+	}()
+	wg.Wait()
+}
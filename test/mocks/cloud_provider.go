@@ -1,7 +1,9 @@
 package mocks
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/mevansam/gocloud/cloud"
 	"github.com/mevansam/gocloud/provider"
@@ -24,6 +26,28 @@ func (f *FakeCloudProvider) Connect() error {
 	return nil
 }
 
+func (f *FakeCloudProvider) Reconnect() error {
+	return nil
+}
+
+func (f *FakeCloudProvider) SetCredentialSupplier(supplier provider.CredentialSupplier) {
+}
+
+func (f *FakeCloudProvider) CredentialsExpiry() time.Time {
+	return time.Time{}
+}
+
+func (f *FakeCloudProvider) Validate(ctx context.Context) (provider.Identity, error) {
+	return provider.Identity{
+		AccountID: "fake-account",
+		Principal: "fake-principal",
+	}, nil
+}
+
+func (f *FakeCloudProvider) CheckPermissions(actions []string) ([]string, error) {
+	return []string{}, nil
+}
+
 func (f *FakeCloudProvider) Name() string {
 	return "fake"
 }
@@ -40,6 +64,10 @@ func (f *FakeCloudProvider) GetRegions() []provider.RegionInfo {
 	return []provider.RegionInfo{}
 }
 
+func (f *FakeCloudProvider) GetZones(region string) ([]provider.ZoneInfo, error) {
+	return []provider.ZoneInfo{}, nil
+}
+
 func (f *FakeCloudProvider) GetCompute() (cloud.Compute, error) {
 
 	testInstance := FakeComputeInstance{
@@ -88,12 +116,81 @@ func (f *FakeCompute) ListInstances() ([]cloud.ComputeInstance, error) {
 	return f.Instances, nil
 }
 
+func (f *FakeCompute) ListSizes() ([]cloud.InstanceSize, error) {
+	return []cloud.InstanceSize{}, nil
+}
+
+func (f *FakeCompute) StartInstances(ids []string) ([]cloud.InstanceResult, error) {
+	return f.runBatch(ids, func(i cloud.ComputeInstance) error { return i.Start() })
+}
+
+func (f *FakeCompute) StopInstances(ids []string) ([]cloud.InstanceResult, error) {
+	return f.runBatch(ids, func(i cloud.ComputeInstance) error { return i.Stop() })
+}
+
+func (f *FakeCompute) RestartInstances(ids []string) ([]cloud.InstanceResult, error) {
+	return f.runBatch(ids, func(i cloud.ComputeInstance) error { return i.Restart() })
+}
+
+func (f *FakeCompute) CreateVolume(spec cloud.VolumeSpec) (cloud.Volume, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (f *FakeCompute) FindImages(filter cloud.ImageFilter) ([]cloud.Image, error) {
+	return []cloud.Image{}, nil
+}
+
+func (f *FakeCompute) CreateImage(instance cloud.ComputeInstance, name string) (cloud.Image, error) {
+	return cloud.Image{}, fmt.Errorf("not implemented")
+}
+
+func (f *FakeCompute) DeleteImage(id string) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (f *FakeCompute) AllocateStaticIP(name string, tags map[string]string) (cloud.StaticIP, error) {
+	return cloud.StaticIP{}, fmt.Errorf("not implemented")
+}
+
+func (f *FakeCompute) ListStaticIPs() ([]cloud.StaticIP, error) {
+	return []cloud.StaticIP{}, nil
+}
+
+func (f *FakeCompute) ReleaseStaticIP(ip cloud.StaticIP) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (f *FakeCompute) runBatch(ids []string, run func(i cloud.ComputeInstance) error) ([]cloud.InstanceResult, error) {
+	var err error
+	results := make([]cloud.InstanceResult, len(ids))
+	for n, id := range ids {
+		results[n].ID = id
+		results[n].Err = fmt.Errorf("not found")
+		for _, i := range f.Instances {
+			if id == i.ID() {
+				results[n].Err = run(i)
+				break
+			}
+		}
+		if results[n].Err != nil {
+			err = fmt.Errorf("one or more instance operations failed")
+		}
+	}
+	return results, err
+}
+
 type FakeComputeInstance struct {
 	id,
 	name,
-	publicIP string
+	publicIP,
+	privateIP string
 
 	state cloud.InstanceState
+
+	firewallRules []cloud.FirewallRule
+	nextRuleID    int
+
+	userData string
 }
 
 func (i *FakeComputeInstance) SetValues(
@@ -108,6 +205,10 @@ func (i *FakeComputeInstance) SetValues(
 	i.state = state
 }
 
+func (i *FakeComputeInstance) SetPrivateIP(privateIP string) {
+	i.privateIP = privateIP
+}
+
 func (i *FakeComputeInstance) ID() string {
 	return i.id
 }
@@ -124,6 +225,10 @@ func (i *FakeComputeInstance) PublicDNS() string {
 	return ""
 }
 
+func (i *FakeComputeInstance) PrivateIP() string {
+	return i.privateIP
+}
+
 func (i *FakeComputeInstance) State() (cloud.InstanceState, error) {
 	return i.state, nil
 }
@@ -140,6 +245,130 @@ func (i *FakeComputeInstance) Stop() error {
 	return fmt.Errorf("not implemented")
 }
 
+func (i *FakeComputeInstance) StartAsync() (cloud.Operation, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (i *FakeComputeInstance) RestartAsync() (cloud.Operation, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (i *FakeComputeInstance) StopAsync() (cloud.Operation, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (i *FakeComputeInstance) Capabilities() (cloud.InstanceCapabilities, error) {
+	return cloud.InstanceCapabilities{}, nil
+}
+
+func (i *FakeComputeInstance) Suspend() error {
+	return fmt.Errorf("not implemented")
+}
+
+func (i *FakeComputeInstance) Resume() error {
+	return fmt.Errorf("not implemented")
+}
+
+func (i *FakeComputeInstance) Resize(size string, autoRestart bool) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (i *FakeComputeInstance) ConsoleOutput() (string, error) {
+	return "", nil
+}
+
+func (i *FakeComputeInstance) WaitForState(ctx context.Context, state cloud.InstanceState, timeout time.Duration) error {
+	if i.state != state {
+		return fmt.Errorf("instance '%s' timed out waiting for state '%s'", i.name, state)
+	}
+	return nil
+}
+
 func (i *FakeComputeInstance) CanConnect(port int) bool {
 	return false
 }
+
+func (i *FakeComputeInstance) HealthCheck(ctx context.Context, check cloud.HealthCheck) cloud.HealthCheckResult {
+	return cloud.CheckHealth(ctx, i, check)
+}
+
+func (i *FakeComputeInstance) WaitUntilHealthy(ctx context.Context, checks ...cloud.HealthCheck) error {
+	return cloud.WaitUntilHealthy(ctx, i, checks...)
+}
+
+func (i *FakeComputeInstance) Volumes() ([]cloud.Volume, error) {
+	return []cloud.Volume{}, nil
+}
+
+func (i *FakeComputeInstance) AttachVolume(volume cloud.Volume) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (i *FakeComputeInstance) DetachVolume(volume cloud.Volume) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (i *FakeComputeInstance) Scheduling() (cloud.InstanceScheduling, error) {
+	return cloud.InstanceScheduling{}, nil
+}
+
+func (i *FakeComputeInstance) InterruptionNotice() (cloud.InterruptionNotice, error) {
+	return cloud.InterruptionNotice{}, nil
+}
+
+func (i *FakeComputeInstance) FirewallRules() ([]cloud.FirewallRule, error) {
+	return append([]cloud.FirewallRule{}, i.firewallRules...), nil
+}
+
+// a negative ttl adds a rule that has already expired
+func (i *FakeComputeInstance) AddIngressRule(spec cloud.IngressRuleSpec) (cloud.FirewallRule, error) {
+
+	i.nextRuleID++
+	rule := cloud.FirewallRule{
+		ID:          fmt.Sprintf("rule-%d", i.nextRuleID),
+		Description: spec.Description,
+		Protocol:    spec.Protocol,
+		FromPort:    spec.Port,
+		ToPort:      spec.Port,
+		CIDR:        spec.CIDR,
+	}
+	if len(rule.Protocol) == 0 {
+		rule.Protocol = cloud.ProtocolTCP
+	}
+	if spec.TTL != 0 {
+		rule.Expires = time.Now().Add(spec.TTL)
+	}
+	i.firewallRules = append(i.firewallRules, rule)
+	return rule, nil
+}
+
+func (i *FakeComputeInstance) RemoveFirewallRule(rule cloud.FirewallRule) error {
+
+	for j, r := range i.firewallRules {
+		if r.ID == rule.ID {
+			i.firewallRules = append(i.firewallRules[:j], i.firewallRules[j+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("rule '%s' not found", rule.ID)
+}
+
+func (i *FakeComputeInstance) AssociateStaticIP(ip cloud.StaticIP) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (i *FakeComputeInstance) DisassociateStaticIP(ip cloud.StaticIP) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (i *FakeComputeInstance) UserData() (string, error) {
+	return i.userData, nil
+}
+
+func (i *FakeComputeInstance) SetUserData(data string) error {
+	if i.state != cloud.StateStopped {
+		return fmt.Errorf("instance '%s' must be stopped before its user data can be changed", i.name)
+	}
+	i.userData = data
+	return nil
+}